@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"reverse-engineering-backend/config"
+	"reverse-engineering-backend/infrastructure/persistence"
+	"reverse-engineering-backend/usecases/vulnerability"
+
+	"github.com/joho/godotenv"
+)
+
+// osv-import ローカルに保存した OSV ダンプ（JSON / all.zip / ディレクトリ）を脆弱性データベースに取り込む
+//
+//	go run ./cmd/osv-import -path ./osv/all.zip
+func main() {
+	path := flag.String("path", "", "OSV dump: a JSON file, a zip archive (all.zip) or a directory")
+	flag.Parse()
+
+	if *path == "" {
+		fmt.Fprintln(os.Stderr, "usage: osv-import -path <dump>")
+		os.Exit(2)
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Warning: .env file not found")
+	}
+
+	db, err := config.InitDatabase()
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	importUseCase := vulnerability.NewOSVImportUseCase(persistence.NewPostgresAdvisoryRepository(db))
+	stats, err := importUseCase.Execute(context.Background(), *path)
+	if err != nil {
+		log.Fatalf("OSV import failed after %d records: %v", stats.Read, err)
+	}
+
+	log.Printf("OSV import completed: read=%d imported=%d skipped=%d", stats.Read, stats.Imported, stats.Skipped)
+}
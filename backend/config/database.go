@@ -26,10 +26,32 @@ func InitDatabase() (*gorm.DB, error) {
 		&models.File{},
 		&models.Analysis{},
 		&models.User{},
+		&models.CodeMetric{},
+		&models.Vulnerability{},
+		&models.VulnerabilityAffected{},
+		&models.Issue{},
+		&models.SecretRule{},
+		&models.RedactionAudit{},
+		&models.QualityGate{},
+		&models.BinaryString{},
+		&models.Commit{},
+		&models.CommitFile{},
+		&models.Blob{},
 	)
 	if err != nil {
 		return nil, err
 	}
+	if err := migrateFilePaths(db); err != nil {
+		return nil, err
+	}
 
 	return db, nil
 }
+
+// migrateFilePaths 以前の形式で Path にディスク上のパスを保存していたファイルを、
+// Path にプロジェクト内の相対パス、StoragePath にディスク上のパスを持つ形式に移行する
+func migrateFilePaths(db *gorm.DB) error {
+	return db.Exec(`UPDATE files
+		SET storage_path = path, path = name, name = regexp_replace(name, '^.*/', '')
+		WHERE storage_path = '' AND (blob_hash = '' OR path LIKE '%/' || blob_hash)`).Error
+}
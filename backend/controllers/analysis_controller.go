@@ -8,6 +8,7 @@ import (
 
 	"reverse-engineering-backend/models"
 	"reverse-engineering-backend/infrastructure/external/openai"
+	"reverse-engineering-backend/infrastructure/persistence"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
@@ -18,6 +19,7 @@ type AnalysisController struct {
 	db            *gorm.DB
 	redis         *redis.Client
 	aiService     *openai.OpenAIService
+	git           *GitController
 	analysisQueue string
 }
 
@@ -25,7 +27,8 @@ func NewAnalysisController(db *gorm.DB, redis *redis.Client) *AnalysisController
 	return &AnalysisController{
 		db:            db,
 		redis:         redis,
-		aiService:     openai.NewOpenAIService().(*openai.OpenAIService),
+		aiService:     openai.NewOpenAIService(persistence.NewPostgresRedactionAuditRepository(db)).(*openai.OpenAIService),
+		git:           NewGitController(db),
 		analysisQueue: "analysis:queue",
 	}
 }
@@ -34,6 +37,7 @@ func (ac *AnalysisController) StartAnalysis(c *gin.Context) {
 	var request struct {
 		ProjectID uint     `json:"project_id" binding:"required"`
 		Types     []string `json:"types" binding:"required"` // code_analysis, dependency_map, documentation, pattern_detection
+		Commit    string   `json:"commit"`                   // git から取り込んだプロジェクトで解析するコミット（省略時は取り込んだ ref）
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -65,14 +69,30 @@ func (ac *AnalysisController) StartAnalysis(c *gin.Context) {
 		return
 	}
 
+	// コミットを指定した場合は、そのツリーを解析できるようにファイルとして取り出しておく
+	var commitHash string
+	if request.Commit != "" {
+		if project.SourceType != "git" {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "commit can only be specified for projects imported from git",
+			})
+			return
+		}
+		var ok bool
+		if commitHash, ok = ac.git.checkoutCommit(c, project, request.Commit); !ok {
+			return
+		}
+	}
+
 	var createdAnalyses []models.Analysis
 
 	// 解析タスクの作成
 	for _, analysisType := range request.Types {
 		analysis := models.Analysis{
-			ProjectID: request.ProjectID,
-			Type:      analysisType,
-			Status:    "pending",
+			ProjectID:  request.ProjectID,
+			Type:       analysisType,
+			Status:     "pending",
+			CommitHash: commitHash,
 		}
 
 		if err := ac.db.Create(&analysis).Error; err != nil {
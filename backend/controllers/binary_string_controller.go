@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"reverse-engineering-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type BinaryStringController struct {
+	db *gorm.DB
+}
+
+func NewBinaryStringController(db *gorm.DB) *BinaryStringController {
+	return &BinaryStringController{
+		db: db,
+	}
+}
+
+// GetAnalysisStrings binary_triage 解析で抽出した文字列をオフセット順にページ単位で返す
+// （file_id / encoding / min_length / q（部分一致）で絞り込み可能）
+func (bc *BinaryStringController) GetAnalysisStrings(c *gin.Context) {
+	analysisID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid analysis ID",
+		})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 1000 {
+		limit = 100
+	}
+
+	var analysis models.Analysis
+	if err := bc.db.First(&analysis, analysisID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Analysis not found",
+		})
+		return
+	}
+	if analysis.Type != "binary_triage" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Analysis is not a binary_triage analysis",
+		})
+		return
+	}
+
+	query := bc.db.Model(&models.BinaryString{}).Where("analysis_id = ?", analysisID)
+	if fileID := c.Query("file_id"); fileID != "" {
+		id, err := strconv.ParseUint(fileID, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Invalid file ID",
+			})
+			return
+		}
+		query = query.Where("file_id = ?", id)
+	}
+	if encoding := c.Query("encoding"); encoding != "" {
+		query = query.Where("encoding = ?", encoding)
+	}
+	if minLength, err := strconv.Atoi(c.Query("min_length")); err == nil && minLength > 0 {
+		query = query.Where("length >= ?", minLength)
+	}
+	if q := c.Query("q"); q != "" {
+		escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(q)
+		query = query.Where("value ILIKE ?", "%"+escaped+"%")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to count strings",
+		})
+		return
+	}
+
+	var strs []models.BinaryString
+	err = query.
+		Order("file_id, \"offset\", id").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&strs).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch strings",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"analysis_id": analysis.ID,
+		"status":      analysis.Status,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+		"strings":     strs,
+	})
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"reverse-engineering-backend/infrastructure/storage"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type BlobController struct {
+	db    *gorm.DB
+	blobs *storage.BlobStore
+}
+
+func NewBlobController(db *gorm.DB) *BlobController {
+	return &BlobController{
+		db:    db,
+		blobs: storage.NewBlobStore(db),
+	}
+}
+
+// GetStats Blob の数・実際のサイズ・参照数と、重複排除前の論理サイズを返す
+func (bc *BlobController) GetStats(c *gin.Context) {
+	stats, err := bc.blobs.Stats(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch blob stats",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"stats":       stats,
+		"saved_bytes": stats.LogicalSize - stats.Size,
+	})
+}
+
+// CollectGarbage 参照されなくなった Blob をすぐに回収する（grace で猶予期間を指定可能）
+func (bc *BlobController) CollectGarbage(c *gin.Context) {
+	grace := storage.DefaultGracePeriod
+	if value := c.Query("grace"); value != "" {
+		d, err := time.ParseDuration(value)
+		if err != nil || d < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "grace must be a non-negative duration such as 30m",
+			})
+			return
+		}
+		grace = d
+	}
+
+	result, err := bc.blobs.CollectGarbage(c.Request.Context(), grace)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to collect blobs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"grace":  grace.String(),
+		"result": result,
+	})
+}
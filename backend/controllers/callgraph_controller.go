@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/infrastructure/storage"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/callgraph"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type CallGraphController struct {
+	db               *gorm.DB
+	blobs            *storage.BlobStore
+	callGraphUseCase *callgraph.CallGraphUseCase
+}
+
+func NewCallGraphController(db *gorm.DB) *CallGraphController {
+	return &CallGraphController{
+		db:               db,
+		blobs:            storage.NewBlobStore(db),
+		callGraphUseCase: callgraph.NewCallGraphUseCase(),
+	}
+}
+
+// GetCallGraph エントリポイントから静的な呼び出しグラフを構築し、JSON と Mermaid のシーケンス図で返す
+func (cc *CallGraphController) GetCallGraph(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	entry := c.Query("entry")
+	if entry == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Query parameter 'entry' is required",
+		})
+		return
+	}
+
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", "5"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid depth",
+		})
+		return
+	}
+	includeExternal := c.DefaultQuery("external", "true") != "false"
+
+	var project models.Project
+	if err := cc.db.First(&project, projectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Project not found",
+		})
+		return
+	}
+
+	// git から取り込んだプロジェクトで複数のコミットのツリーが混ざらないよう、commit（省略時は取り込んだ ref）に絞り込む
+	var files []models.File
+	if err := cc.db.Scopes(models.ProjectFiles(project, c.Query("commit"))).Where("language = ?", "go").Find(&files).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch files",
+		})
+		return
+	}
+
+	if len(files) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "No Go files found in project",
+		})
+		return
+	}
+
+	if err := cc.blobs.LoadContents(files); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to read files",
+		})
+		return
+	}
+
+	infos := make([]entities.FileInfo, 0, len(files))
+	for _, file := range files {
+		infos = append(infos, entities.FileInfo{
+			Name:     file.Path,
+			Language: file.Language,
+			Content:  file.Content,
+		})
+	}
+
+	graph, err := cc.callGraphUseCase.Execute(c.Request.Context(), infos, entry, depth, includeExternal)
+	if err != nil {
+		switch {
+		case errors.Is(err, callgraph.ErrEntryNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		case errors.Is(err, callgraph.ErrAmbiguousEntry):
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to build call graph: " + err.Error(),
+			})
+		}
+		return
+	}
+
+	diagram := callgraph.RenderSequenceDiagram(graph)
+	if c.Query("format") == "mermaid" {
+		c.String(http.StatusOK, diagram)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"graph":   graph,
+		"mermaid": diagram,
+	})
+}
@@ -0,0 +1,144 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/infrastructure/storage"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/binary"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type DebugInfoController struct {
+	db               *gorm.DB
+	blobs            *storage.BlobStore
+	debugInfoUseCase *binary.DebugInfoUseCase
+}
+
+func NewDebugInfoController(db *gorm.DB) *DebugInfoController {
+	return &DebugInfoController{
+		db:               db,
+		blobs:            storage.NewBlobStore(db),
+		debugInfoUseCase: binary.NewDebugInfoUseCase(),
+	}
+}
+
+// GetDebugInfo 実行ファイルの DWARF デバッグ情報を閲覧する
+// view=summary（コンパイル単位と件数）, tree（ソースツリー）, types, functions。types と functions は q（名前の部分一致）と page / limit で絞り込む
+func (dc *DebugInfoController) GetDebugInfo(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid file ID",
+		})
+		return
+	}
+
+	view := c.DefaultQuery("view", "summary")
+	if view != "summary" && view != "tree" && view != "types" && view != "functions" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "view must be summary, tree, types or functions",
+		})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	var file models.File
+	if err := dc.db.First(&file, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "File not found",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch file",
+			})
+		}
+		return
+	}
+	if file.IsText() || file.Quarantined {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "File is not an executable",
+		})
+		return
+	}
+
+	info, err := dc.debugInfoUseCase.Execute(c.Request.Context(), dc.blobs.Locate(file))
+	if err != nil {
+		if errors.Is(err, binary.ErrNoDebugInfo) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		} else {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": "Failed to read debug info: " + err.Error(),
+			})
+		}
+		return
+	}
+
+	response := gin.H{
+		"file_id":         file.ID,
+		"types_total":     info.TypesTotal,
+		"functions_total": info.FunctionsTotal,
+		"capped":          info.Capped,
+	}
+	query := strings.ToLower(c.Query("q"))
+	switch view {
+	case "summary":
+		response["compile_units"] = info.CompileUnits
+	case "tree":
+		response["source_tree"] = info.SourceTree
+	case "types":
+		types := []entities.DebugType{}
+		for _, t := range info.Types {
+			if query == "" || strings.Contains(strings.ToLower(t.Name), query) {
+				types = append(types, t)
+			}
+		}
+		start, end := pageRange(len(types), page, limit)
+		response["types"] = types[start:end]
+		response["total"] = len(types)
+	case "functions":
+		functions := []entities.DebugFunction{}
+		for _, fn := range info.Functions {
+			if query == "" || strings.Contains(strings.ToLower(fn.Name), query) {
+				functions = append(functions, fn)
+			}
+		}
+		start, end := pageRange(len(functions), page, limit)
+		response["functions"] = functions[start:end]
+		response["total"] = len(functions)
+	}
+	response["page"] = page
+	response["limit"] = limit
+
+	c.JSON(http.StatusOK, response)
+}
+
+// pageRange 1 始まりのページ番号を total 件のスライス範囲に変換する
+func pageRange(total, page, limit int) (int, int) {
+	start := (page - 1) * limit
+	if start > total {
+		start = total
+	}
+	end := start + limit
+	if end > total {
+		end = total
+	}
+	return start, end
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"reverse-engineering-backend/domain/services"
+	"reverse-engineering-backend/infrastructure/external/openai"
+	"reverse-engineering-backend/infrastructure/persistence"
+	"reverse-engineering-backend/infrastructure/storage"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/binary"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxExplainInstructions LLM に送る命令数の上限
+const maxExplainInstructions = 800
+
+type DisassemblyController struct {
+	db                 *gorm.DB
+	blobs              *storage.BlobStore
+	llmService         services.LLMService
+	disassemblyUseCase *binary.DisassemblyUseCase
+}
+
+func NewDisassemblyController(db *gorm.DB) *DisassemblyController {
+	return &DisassemblyController{
+		db:                 db,
+		blobs:              storage.NewBlobStore(db),
+		llmService:         openai.NewOpenAIService(persistence.NewPostgresRedactionAuditRepository(db)),
+		disassemblyUseCase: binary.NewDisassemblyUseCase(),
+	}
+}
+
+// GetFunctionDisassembly アップロードされた実行ファイルの関数を逆アセンブルし、LLM による疑似コードと振る舞いの説明を返す
+// （explain=false で説明を省略。"/" を含むシンボルは URL エンコードして指定する）
+func (dc *DisassemblyController) GetFunctionDisassembly(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid file ID",
+		})
+		return
+	}
+
+	var file models.File
+	if err := dc.db.First(&file, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "File not found",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch file",
+			})
+		}
+		return
+	}
+	if file.IsText() || file.Quarantined {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "File is not an executable",
+		})
+		return
+	}
+
+	disassembly, err := dc.disassemblyUseCase.Execute(c.Request.Context(), dc.blobs.Locate(file), c.Param("symbol"))
+	if err != nil {
+		switch {
+		case errors.Is(err, binary.ErrSymbolNotFound):
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+		default:
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error": "Failed to disassemble: " + err.Error(),
+			})
+		}
+		return
+	}
+
+	response := gin.H{
+		"file_id":     file.ID,
+		"disassembly": disassembly,
+	}
+	if c.DefaultQuery("explain", "true") != "false" {
+		// 大きな関数は先頭だけを説明対象にする
+		listing := *disassembly
+		if len(listing.Instructions) > maxExplainInstructions {
+			listing.Instructions = listing.Instructions[:maxExplainInstructions]
+			listing.Truncated = true
+		}
+		explanation, err := dc.llmService.ExplainDisassembly(c.Request.Context(), binary.FormatDisassembly(&listing), disassembly.Arch)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{
+				"error": "Failed to explain disassembly: " + err.Error(),
+			})
+			return
+		}
+		response["explanation"] = explanation
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -1,14 +1,24 @@
 package controllers
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"time"
 
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/infrastructure/persistence"
+	"reverse-engineering-backend/infrastructure/storage"
 	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/archive"
+	"reverse-engineering-backend/usecases/secrets"
 	"reverse-engineering-backend/utils"
 
 	"github.com/gin-gonic/gin"
@@ -16,12 +26,14 @@ import (
 )
 
 type FileController struct {
-	db *gorm.DB
+	db    *gorm.DB
+	blobs *storage.BlobStore
 }
 
 func NewFileController(db *gorm.DB) *FileController {
 	return &FileController{
-		db: db,
+		db:    db,
+		blobs: storage.NewBlobStore(db),
 	}
 }
 
@@ -73,31 +85,67 @@ func (fc *FileController) UploadFiles(c *gin.Context) {
 		return
 	}
 
+	// multipart のファイル名はディレクトリを含まないため、フォルダのアップロードなどでは
+	// paths にファイルと同じ順でプロジェクト内の相対パスを指定する
+	relPaths := form.Value["paths"]
+	if len(relPaths) > 0 && len(relPaths) != len(files) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "paths must have one entry per file",
+		})
+		return
+	}
+
 	uploadPath := os.Getenv("UPLOAD_PATH")
 	if uploadPath == "" {
 		uploadPath = "./uploads"
 	}
 
-	// アップロードディレクトリの作成
+	// アップロードごとに保存先を分け、相対パスが既存のファイルや隔離ディレクトリと衝突しないようにする
 	projectUploadPath := filepath.Join(uploadPath, strconv.FormatUint(projectID, 10))
-	if err := os.MkdirAll(projectUploadPath, 0755); err != nil {
+	root := filepath.Join(projectUploadPath, "upload-"+strconv.FormatInt(time.Now().UnixNano(), 36))
+	if err := os.MkdirAll(root, 0755); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to create upload directory",
 		})
 		return
 	}
 
-	var uploadedFiles []models.File
+	scanner, policy, ok := fc.uploadSecretScanner(c, project)
+	if !ok {
+		return
+	}
 
-	for _, file := range files {
-		// ファイル名の安全性チェック
-		filename := filepath.Base(file.Filename)
-		if filename == "." || filename == ".." || strings.Contains(filename, "/") {
+	var uploadedFiles []models.File
+	var blockedFiles []string
+	var scanned []scannedFile
+	// 保存しなかったファイルは理由とともに返す
+	skipped := []entities.SkippedEntry{}
+	seen := make(map[string]bool)
+
+	for i, file := range files {
+		// アーカイブのエントリーと同じ規則でパスを正規化し、ルートの外に出るものなどは拒否する
+		name := file.Filename
+		if len(relPaths) > 0 {
+			name = relPaths[i]
+		}
+		filename, reason := archive.CleanEntryPath(name)
+		if reason == "" && seen[filename] {
+			reason = archive.ReasonDuplicate
+		}
+		if reason != "" {
+			skipped = append(skipped, entities.SkippedEntry{Path: name, Reason: reason})
 			continue
 		}
+		seen[filename] = true
 
 		// ファイルの保存
-		savePath := filepath.Join(projectUploadPath, filename)
+		savePath := filepath.Join(root, filepath.FromSlash(filename))
+		if err := os.MkdirAll(filepath.Dir(savePath), 0755); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to create upload directory",
+			})
+			return
+		}
 		if err := c.SaveUploadedFile(file, savePath); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to save file: " + filename,
@@ -105,24 +153,41 @@ func (fc *FileController) UploadFiles(c *gin.Context) {
 			return
 		}
 
-		// ファイル内容の読み取り
-		content, err := fc.readFileContent(savePath)
-		if err != nil {
-			content = "" // バイナリファイルなどは内容を保存しない
+		// データベースへの保存
+		fileModel := fc.newUploadedFile(project, filename, savePath, file.Size, file.Header.Get("Content-Type"))
+
+		// 保存前にシークレットを検出し、プロジェクトのポリシーを適用する
+		findings := scanner.Scan(filename, fileModel.Content)
+		if len(findings) > 0 {
+			blocked, err := fc.applySecretPolicy(policy, &fileModel, findings)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to apply secret policy: " + filename,
+				})
+				return
+			}
+			if blocked {
+				blockedFiles = append(blockedFiles, filename)
+				scanned = append(scanned, scannedFile{findings: findings})
+				continue
+			}
 		}
 
-		// データベースへの保存
-		fileModel := models.File{
-			ProjectID: uint(projectID),
-			Name:      filename,
-			Path:      savePath,
-			Size:      file.Size,
-			MimeType:  file.Header.Get("Content-Type"),
-			Content:   content,
-			Language:  utils.DetectLanguage(filename),
+		// 内容は SHA-256 をキーにした Blob として保存し、同じ内容のファイルは 1 つにまとめる
+		if err := fc.storeBlob(&fileModel); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to store file: " + filename,
+			})
+			return
 		}
 
-		if err := fc.db.Create(&fileModel).Error; err != nil {
+		err := fc.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&fileModel).Error; err != nil {
+				return err
+			}
+			return fc.blobs.Acquire(tx, []models.File{fileModel})
+		})
+		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to save file metadata: " + filename,
 			})
@@ -130,12 +195,463 @@ func (fc *FileController) UploadFiles(c *gin.Context) {
 		}
 
 		uploadedFiles = append(uploadedFiles, fileModel)
+		if len(findings) > 0 {
+			scanned = append(scanned, scannedFile{fileID: &fileModel.ID, findings: findings})
+		}
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	// 内容は Blob に移したので、保存先には隔離したファイルだけが残る
+	utils.RemoveEmptyDirs(root)
+
+	response := gin.H{
 		"message": "Files uploaded successfully",
 		"files":   uploadedFiles,
+		"skipped": skipped,
+	}
+	if len(scanned) > 0 {
+		analysis, total, err := fc.recordSecretFindings(project.ID, policy, scanned)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to record secret findings",
+			})
+			return
+		}
+		response["secret_scan"] = gin.H{
+			"analysis_id": analysis.ID,
+			"policy":      policy,
+			"findings":    total,
+			"blocked":     blockedFiles,
+		}
+	}
+
+	// すべてのファイルがブロックされた場合は保存されたものがない
+	if len(uploadedFiles) == 0 && len(blockedFiles) > 0 {
+		response["message"] = "All files were blocked by the secret policy"
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+	if len(uploadedFiles) == 0 {
+		response["message"] = "No files could be saved"
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// UploadArchive zip・tar・tar.gz・tar.zst のアーカイブを展開し、ディレクトリ構造を保ったままファイルとして登録する
+func (fc *FileController) UploadArchive(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.PostForm("project_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project_id",
+		})
+		return
+	}
+
+	// プロジェクトの存在確認
+	var project models.Project
+	if err := fc.db.First(&project, projectID).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Project not found",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to verify project",
+			})
+		}
+		return
+	}
+
+	header, err := c.FormFile("archive")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "archive is required",
+		})
+		return
+	}
+	if !archive.IsArchive(header.Filename) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Unsupported archive type (zip, tar, tar.gz, tar.zst)",
+		})
+		return
+	}
+
+	uploadPath := os.Getenv("UPLOAD_PATH")
+	if uploadPath == "" {
+		uploadPath = "./uploads"
+	}
+
+	// アーカイブごとに展開先を分け、既存のファイルや他のアーカイブと衝突しないようにする
+	projectUploadPath := filepath.Join(uploadPath, strconv.FormatUint(projectID, 10))
+	root := filepath.Join(projectUploadPath, archiveRootName(header.Filename))
+	if err := os.MkdirAll(root, 0755); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create upload directory",
+		})
+		return
+	}
+
+	// アーカイブ本体は展開後に不要なので一時ファイルに保存する
+	tmp, err := os.CreateTemp("", "upload-archive-*")
+	if err != nil {
+		os.RemoveAll(root)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to save archive",
+		})
+		return
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+	if err := c.SaveUploadedFile(header, tmp.Name()); err != nil {
+		os.RemoveAll(root)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to save archive",
+		})
+		return
+	}
+
+	extraction, err := archive.NewArchiveExtractUseCase(archive.DefaultLimits()).Execute(c.Request.Context(), tmp.Name(), root)
+	if err != nil {
+		os.RemoveAll(root)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Failed to extract archive: " + err.Error(),
+		})
+		return
+	}
+
+	registered, ok := fc.registerExtractedFiles(c, project, extraction.Entries, root, "", nil)
+	if !ok {
+		return
+	}
+	// 内容は Blob に移したので、展開先には隔離したファイルだけが残る
+	utils.RemoveEmptyDirs(root)
+
+	response := gin.H{
+		"message": "Archive uploaded successfully",
+		"files":   registered.files,
+		"archive": gin.H{
+			"name":       header.Filename,
+			"format":     extraction.Format,
+			"extracted":  len(registered.files),
+			"total_size": extraction.TotalSize,
+			"skipped":    extraction.Skipped,
+			"aborted":    extraction.Aborted,
+		},
+	}
+	if registered.secretScan != nil {
+		response["secret_scan"] = registered.secretScan
+	}
+
+	// 登録できるファイルがなかった場合は展開先を残さない
+	if len(registered.files) == 0 {
+		os.RemoveAll(root)
+		response["message"] = "Archive contains no files that could be extracted"
+		c.JSON(http.StatusUnprocessableEntity, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// extractedFiles registerExtractedFiles で登録したファイル
+type extractedFiles struct {
+	files      []models.File
+	blocked    []string
+	secretScan gin.H // シークレットが検出されなかった場合は nil
+}
+
+// registerExtractedFiles アーカイブや git のツリーから展開したファイルにシークレットポリシーを適用して登録する。
+// extra は同じトランザクションで行う追加の保存処理。保存に失敗した場合は root を削除し、レスポンスを返して false
+func (fc *FileController) registerExtractedFiles(c *gin.Context, project models.Project, entries []entities.ArchiveEntry, root, commitHash string, extra func(tx *gorm.DB) error) (*extractedFiles, bool) {
+	scanner, policy, ok := fc.uploadSecretScanner(c, project)
+	if !ok {
+		os.RemoveAll(root)
+		return nil, false
+	}
+
+	// 展開したファイルを登録用に読み取り、シークレットポリシーを適用する
+	result := &extractedFiles{files: []models.File{}}
+	var fileFindings [][]entities.SecretFinding
+	var scanned []scannedFile
+	for _, entry := range entries {
+		fileModel := fc.newUploadedFile(project, entry.Path, entry.DiskPath, entry.Size, mime.TypeByExtension(filepath.Ext(entry.Path)))
+		fileModel.CommitHash = commitHash
+
+		findings := scanner.Scan(entry.Path, fileModel.Content)
+		if len(findings) > 0 {
+			blocked, err := fc.applySecretPolicy(policy, &fileModel, findings)
+			if err != nil {
+				os.RemoveAll(root)
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to apply secret policy: " + entry.Path,
+				})
+				return nil, false
+			}
+			if blocked {
+				result.blocked = append(result.blocked, entry.Path)
+				scanned = append(scanned, scannedFile{findings: findings})
+				continue
+			}
+		}
+		if err := fc.storeBlob(&fileModel); err != nil {
+			os.RemoveAll(root)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to store file: " + entry.Path,
+			})
+			return nil, false
+		}
+		result.files = append(result.files, fileModel)
+		fileFindings = append(fileFindings, findings)
+	}
+
+	// 一部だけ登録された状態にならないよう、メタデータはまとめて保存する
+	err := fc.db.Transaction(func(tx *gorm.DB) error {
+		if len(result.files) > 0 {
+			if err := tx.CreateInBatches(&result.files, 200).Error; err != nil {
+				return err
+			}
+			if err := fc.blobs.Acquire(tx, result.files); err != nil {
+				return err
+			}
+		}
+		if extra != nil {
+			return extra(tx)
+		}
+		return nil
 	})
+	if err != nil {
+		os.RemoveAll(root)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to save file metadata",
+		})
+		return nil, false
+	}
+	for i, findings := range fileFindings {
+		if len(findings) > 0 {
+			scanned = append(scanned, scannedFile{fileID: &result.files[i].ID, findings: findings})
+		}
+	}
+
+	if len(scanned) > 0 {
+		analysis, total, err := fc.recordSecretFindings(project.ID, policy, scanned)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to record secret findings",
+			})
+			return nil, false
+		}
+		result.secretScan = gin.H{
+			"analysis_id": analysis.ID,
+			"policy":      policy,
+			"findings":    total,
+			"blocked":     result.blocked,
+		}
+	}
+	return result, true
+}
+
+// archiveRootName アーカイブ名から展開先ディレクトリ名を作る（同名アーカイブの再アップロードでも衝突しない）
+func archiveRootName(filename string) string {
+	stem := filepath.Base(filename)
+	lower := strings.ToLower(stem)
+	for _, ext := range []string{".tar.gz", ".tar.zst", ".tgz", ".tzst", ".tar", ".zip"} {
+		if strings.HasSuffix(lower, ext) {
+			stem = stem[:len(stem)-len(ext)]
+			break
+		}
+	}
+	stem = utils.SanitizeFilename(stem)
+	if stem == "" || strings.HasPrefix(stem, ".") {
+		stem = "archive"
+	}
+	return stem + "-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+}
+
+// uploadSecretScanner プロジェクト固有ルールを含むシークレットスキャナーと適用するポリシーを準備する。失敗時はレスポンスを返して false
+func (fc *FileController) uploadSecretScanner(c *gin.Context, project models.Project) (*secrets.SecretScanUseCase, string, bool) {
+	customRules, err := persistence.NewPostgresSecretRuleRepository(fc.db).FindByProject(c.Request.Context(), project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to load secret rules",
+		})
+		return nil, "", false
+	}
+	scanner, err := secrets.NewSecretScanUseCase(customRules)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Invalid secret rule: " + err.Error(),
+		})
+		return nil, "", false
+	}
+	policy := project.SecretPolicy
+	if !secrets.IsValidPolicy(policy) {
+		policy = secrets.PolicyQuarantine
+	}
+	return scanner, policy, true
+}
+
+// newUploadedFile 保存済みのファイルから内容・文字コード・言語を判定して File を作る。relPath はプロジェクト内の相対パス
+func (fc *FileController) newUploadedFile(project models.Project, relPath, savePath string, size int64, mimeType string) models.File {
+	// ファイル内容の読み取り
+	content, encoding, err := fc.readFileContent(savePath, project.Charset)
+	if err != nil {
+		content = "" // バイナリファイルなどは内容を保存しない
+	}
+
+	language := utils.DetectLanguageFromContent(relPath, content)
+	file := models.File{
+		ProjectID:          project.ID,
+		Name:               path.Base(relPath),
+		Path:               relPath,
+		StoragePath:        savePath,
+		Size:               size,
+		MimeType:           mimeType,
+		Content:            content,
+		Language:           language.Language,
+		LanguageConfidence: language.Confidence,
+		LanguageSource:     language.Source,
+	}
+	if content != "" {
+		file.Encoding = encoding.Encoding
+		file.EncodingBOM = encoding.BOM
+	}
+	return file
+}
+
+// storeBlob ファイルを Blob ストアに移す。内容は BlobHash から参照するため StoragePath と Content は保存しない（隔離されたファイルは隔離ディレクトリに残す）
+func (fc *FileController) storeBlob(file *models.File) error {
+	if file.Quarantined {
+		return nil
+	}
+	hash, size, err := fc.blobs.Put(file.StoragePath)
+	if err != nil {
+		return err
+	}
+	file.BlobHash = hash
+	file.StoragePath = ""
+	file.Content = ""
+	file.Size = size
+	return nil
+}
+
+// scannedFile アップロード時にシークレットが検出されたファイル（ブロックされた場合 fileID は nil）
+type scannedFile struct {
+	fileID   *uint
+	findings []entities.SecretFinding
+}
+
+// applySecretPolicy シークレットを含むファイルにポリシーを適用する。ブロックした場合は true を返す
+func (fc *FileController) applySecretPolicy(policy string, file *models.File, findings []entities.SecretFinding) (bool, error) {
+	switch policy {
+	case secrets.PolicyBlock:
+		if err := os.Remove(file.StoragePath); err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+		return true, nil
+
+	case secrets.PolicyQuarantine:
+		// 内容は DB に保存せず、元ファイルは隔離ディレクトリへ移動する
+		quarantineDir := filepath.Join(filepath.Dir(file.StoragePath), ".quarantine")
+		if err := os.MkdirAll(quarantineDir, 0700); err != nil {
+			return false, err
+		}
+		quarantinePath := filepath.Join(quarantineDir, filepath.Base(file.StoragePath))
+		if err := os.Rename(file.StoragePath, quarantinePath); err != nil {
+			return false, err
+		}
+		if err := os.Chmod(quarantinePath, 0600); err != nil {
+			return false, err
+		}
+		file.StoragePath = quarantinePath
+		file.Content = ""
+		file.Quarantined = true
+
+	case secrets.PolicyRedact:
+		file.Content = secrets.RedactContent(file.Content, findings)
+		// ディスク上のファイルは元の文字コードのまま置き換える
+		data, err := utils.EncodeFromUTF8(file.Content, file.Encoding, file.EncodingBOM)
+		if err != nil {
+			return false, err
+		}
+		if err := os.WriteFile(file.StoragePath, data, 0644); err != nil {
+			return false, err
+		}
+		file.Size = int64(len(data))
+	}
+	return false, nil
+}
+
+// recordSecretFindings アップロード時のスキャン結果を secret_scan 解析と Issue として保存する
+func (fc *FileController) recordSecretFindings(projectID uint, policy string, scanned []scannedFile) (*models.Analysis, int, error) {
+	total := 0
+	bySeverity := make(map[string]int)
+	for _, file := range scanned {
+		total += len(file.findings)
+		for _, finding := range file.findings {
+			bySeverity[finding.Severity]++
+		}
+	}
+
+	result, err := json.Marshal(map[string]interface{}{
+		"policy":      policy,
+		"files":       len(scanned),
+		"findings":    total,
+		"by_severity": bySeverity,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	analysis := models.Analysis{
+		ProjectID: projectID,
+		Type:      "secret_scan",
+		Status:    "completed",
+		Result:    string(result),
+	}
+
+	err = fc.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&analysis).Error; err != nil {
+			return err
+		}
+
+		var issues []models.Issue
+		for _, file := range scanned {
+			for _, finding := range file.findings {
+				metadata, err := json.Marshal(map[string]interface{}{
+					"description":  finding.Description,
+					"redacted":     finding.Redacted,
+					"entropy":      finding.Entropy,
+					"fingerprint":  finding.Fingerprint,
+					"start_column": finding.Column,
+					"end_column":   finding.EndColumn,
+					"action":       policy,
+				})
+				if err != nil {
+					return err
+				}
+				issues = append(issues, models.Issue{
+					AnalysisID: analysis.ID,
+					ProjectID:  projectID,
+					FileID:     file.fileID,
+					Source:     "secret",
+					RuleID:     finding.RuleID,
+					Severity:   finding.Severity,
+					Message:    fmt.Sprintf("%s detected (%s)", finding.Description, finding.Redacted),
+					Path:       finding.Path,
+					StartLine:  finding.Line,
+					EndLine:    finding.Line,
+					Metadata:   string(metadata),
+				})
+			}
+		}
+		return tx.CreateInBatches(issues, 200).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return &analysis, total, nil
 }
 
 func (fc *FileController) GetFilesByProject(c *gin.Context) {
@@ -147,8 +663,17 @@ func (fc *FileController) GetFilesByProject(c *gin.Context) {
 		return
 	}
 
+	var project models.Project
+	if err := fc.db.First(&project, projectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Project not found",
+		})
+		return
+	}
+
+	// git から取り込んだプロジェクトでは commit（省略時は取り込んだ ref）のツリーに絞り込む
 	var files []models.File
-	if err := fc.db.Where("project_id = ?", projectID).Find(&files).Error; err != nil {
+	if err := fc.db.Scopes(models.ProjectFiles(project, c.Query("commit"))).Find(&files).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to fetch files",
 		})
@@ -183,11 +708,105 @@ func (fc *FileController) GetFile(c *gin.Context) {
 		return
 	}
 
+	// テキストの内容は Blob から読み込んで返す
+	content, err := fc.blobs.ReadText(file)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to read file content",
+		})
+		return
+	}
+	file.Content = content
+
 	c.JSON(http.StatusOK, gin.H{
 		"file": file,
 	})
 }
 
+// ReclassifyFile ファイルの言語を内容から判定し直す。language を指定した場合はその言語に固定する
+func (fc *FileController) ReclassifyFile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid file ID",
+		})
+		return
+	}
+
+	var request struct {
+		Language string `json:"language"`
+	}
+	// ボディは省略できる
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+
+	var file models.File
+	if err := fc.db.First(&file, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "File not found",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch file",
+			})
+		}
+		return
+	}
+
+	detection := utils.LanguageDetection{
+		Language:   strings.ToLower(strings.TrimSpace(request.Language)),
+		Confidence: 1,
+		Source:     utils.LanguageSourceManual,
+	}
+	// 解析ハンドラーが扱えない言語が保存されないよう、判定器が返しうる言語だけを受け付ける
+	if detection.Language != "" && !utils.IsKnownLanguage(detection.Language) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":               "Unsupported language: " + detection.Language,
+			"supported_languages": utils.KnownLanguages(),
+		})
+		return
+	}
+	if detection.Language == "" {
+		// 隔離されたファイルは内容を読み込まないため、ファイル名だけで判定する
+		content, err := fc.blobs.ReadText(file)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to read file content",
+			})
+			return
+		}
+		detection = utils.DetectLanguageFromContent(file.Path, content)
+	}
+
+	previous := file.Language
+	if err := fc.db.Model(&file).Updates(map[string]interface{}{
+		"language":            detection.Language,
+		"language_confidence": detection.Confidence,
+		"language_source":     detection.Source,
+	}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to update file language",
+		})
+		return
+	}
+	file.Language = detection.Language
+	file.LanguageConfidence = detection.Confidence
+	file.LanguageSource = detection.Source
+
+	c.JSON(http.StatusOK, gin.H{
+		"file":              file,
+		"previous_language": previous,
+		"changed":           previous != detection.Language,
+	})
+}
+
 func (fc *FileController) DeleteFile(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
@@ -211,40 +830,71 @@ func (fc *FileController) DeleteFile(c *gin.Context) {
 		return
 	}
 
-	// ファイルシステムからファイルを削除
-	if err := os.Remove(file.Path); err != nil {
-		// ファイルが既に存在しない場合は無視
+	// バンドルから復元されたファイルも削除
+	var derived []models.File
+	if err := fc.db.Where("derived_from_id = ?", file.ID).Find(&derived).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch derived files",
+		})
+		return
 	}
 
-	// データベースからファイルを削除
-	if err := fc.db.Delete(&file).Error; err != nil {
+	// データベースからファイルを削除し、Blob の参照を外す（参照のなくなった Blob はガベージコレクションで削除される）
+	err = fc.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&file).Error; err != nil {
+			return err
+		}
+		if len(derived) > 0 {
+			if err := tx.Delete(&derived).Error; err != nil {
+				return err
+			}
+		}
+		return fc.blobs.Release(tx, append(derived, file))
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to delete file",
 		})
 		return
 	}
 
+	// Blob に保存していないファイル（隔離されたもの・以前の形式のもの）はファイルシステムから直接削除
+	if file.BlobHash == "" {
+		os.Remove(file.StoragePath)
+		os.RemoveAll(filepath.Join(filepath.Dir(file.StoragePath), ".derived", strconv.FormatUint(uint64(file.ID), 10)))
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "File deleted successfully",
 	})
 }
 
-func (fc *FileController) readFileContent(filePath string) (string, error) {
+func (fc *FileController) readFileContent(filePath, charset string) (string, utils.EncodingDetection, error) {
 	file, err := os.Open(filePath)
 	if err != nil {
-		return "", err
+		return "", utils.EncodingDetection{}, err
 	}
 	defer file.Close()
 
 	content, err := io.ReadAll(file)
 	if err != nil {
-		return "", err
+		return "", utils.EncodingDetection{}, err
+	}
+
+	// 文字コードを判定し、Shift_JIS などは UTF-8 に変換する
+	encoding := utils.DetectEncoding(content, charset)
+	if encoding.Encoding == "" {
+		return "", encoding, nil // バイナリファイルは内容を保存しない
+	}
+	text, err := utils.DecodeToUTF8(content, encoding)
+	if err != nil {
+		return "", encoding, nil
 	}
 
 	// テキストファイルかどうかをチェック（簡単な実装）
-	if utils.IsTextFile(content) {
-		return string(content), nil
+	if utils.IsTextFile([]byte(text)) {
+		return text, encoding, nil
 	}
 
-	return "", nil // バイナリファイルは内容を保存しない
+	return "", encoding, nil // バイナリファイルは内容を保存しない
 }
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"reverse-engineering-backend/infrastructure/persistence"
+	"reverse-engineering-backend/infrastructure/storage"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/filetree"
+	"reverse-engineering-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type FileTreeController struct {
+	db    *gorm.DB
+	blobs *storage.BlobStore
+}
+
+func NewFileTreeController(db *gorm.DB) *FileTreeController {
+	return &FileTreeController{
+		db:    db,
+		blobs: storage.NewBlobStore(db),
+	}
+}
+
+// GetTree プロジェクトのファイルを path のディレクトリ単位で返す。内容は含めず、
+// サブディレクトリは配下のファイル数・サイズ・言語を集計して返す（commit で git のコミットを指定可能）
+func (tc *FileTreeController) GetTree(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	dir, err := filetree.CleanPath(c.Query("path"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid path",
+		})
+		return
+	}
+
+	var project models.Project
+	if err := tc.db.First(&project, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Project not found",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch project",
+			})
+		}
+		return
+	}
+
+	// 内容は読み込まず、1 階層分のファイルとサブディレクトリごとの集計だけを取得する
+	// git から取り込んだプロジェクトは、指定がなければ取り込んだ ref のツリーを返す
+	commit := project.ResolveCommit(c.Query("commit"))
+	useCase := filetree.NewFileTreeUseCase(persistence.NewPostgresFileTreeRepository(tc.db))
+	tree, err := useCase.Execute(c.Request.Context(), project.ID, commit, dir)
+	if err != nil {
+		if errors.Is(err, filetree.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Directory not found",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch files",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"project_id": project.ID,
+		"commit":     commit,
+		"tree":       tree,
+	})
+}
+
+// GetRawContent ファイルの内容をそのまま返す（Range リクエストに対応）。
+// encoding=utf-8 を指定すると、元の文字コードではなく UTF-8 に変換した内容を返す
+func (tc *FileTreeController) GetRawContent(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid file ID",
+		})
+		return
+	}
+
+	var file models.File
+	if err := tc.db.First(&file, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "File not found",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch file",
+			})
+		}
+		return
+	}
+
+	// シークレットを含むため隔離されたファイルの内容は返さない
+	if file.Quarantined {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "File is quarantined because it contains secrets",
+		})
+		return
+	}
+
+	requested := c.DefaultQuery("encoding", "original")
+	if encoding, ok := utils.NormalizeEncoding(requested); requested != "original" && (!ok || encoding != utils.EncodingUTF8) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "encoding must be original or utf-8",
+		})
+		return
+	}
+
+	var content io.ReadSeeker
+	modTime := file.UpdatedAt
+	charset := file.Encoding
+	if requested == "original" {
+		f, err := os.Open(tc.blobs.Locate(file))
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "File content not found",
+			})
+			return
+		}
+		defer f.Close()
+		if info, err := f.Stat(); err == nil {
+			modTime = info.ModTime()
+		}
+		content = f
+	} else {
+		// Blob の内容を元の文字コードから UTF-8 に変換する
+		if !file.IsText() {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "File is not a text file",
+			})
+			return
+		}
+		text, err := tc.blobs.ReadText(file)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "File content not found",
+			})
+			return
+		}
+		content = strings.NewReader(text)
+		charset = utils.EncodingUTF8
+	}
+
+	// アップロードされた HTML などがブラウザで実行されないよう、テキストは text/plain、それ以外はダウンロードとして返す
+	header := c.Writer.Header()
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("ETag", rawContentETag(file, charset, modTime))
+	if charset != "" {
+		header.Set("Content-Type", mime.FormatMediaType("text/plain", map[string]string{"charset": charset}))
+		header.Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": file.Name}))
+	} else {
+		header.Set("Content-Type", "application/octet-stream")
+		header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Name}))
+	}
+
+	http.ServeContent(c.Writer, c.Request, "", modTime, content)
+}
+
+// rawContentETag 内容が変わると変わる ETag（If-Range で分割ダウンロードの整合性を保つ）
+func rawContentETag(file models.File, charset string, modTime time.Time) string {
+	// Blob に保存した元の内容はハッシュがそのまま内容を表す
+	if file.BlobHash != "" && charset == file.Encoding {
+		return `"` + file.BlobHash + `"`
+	}
+	return fmt.Sprintf(`"%d-%s-%d-%x"`, file.ID, charset, file.Size, modTime.UnixNano())
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"reverse-engineering-backend/domain/repositories"
+	"reverse-engineering-backend/infrastructure/persistence"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/report"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type FindingsController struct {
+	db          *gorm.DB
+	findingRepo repositories.FindingRepository
+}
+
+func NewFindingsController(db *gorm.DB) *FindingsController {
+	return &FindingsController{
+		db:          db,
+		findingRepo: persistence.NewPostgresFindingRepository(db),
+	}
+}
+
+// GetSARIF 各解析タイプの最新結果（LLM・SAST・シークレット・脆弱性）を SARIF 2.1.0 形式で返す
+func (fc *FindingsController) GetSARIF(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	var project models.Project
+	if err := fc.db.First(&project, projectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Project not found",
+		})
+		return
+	}
+
+	findings, err := fc.findingRepo.FindByProject(c.Request.Context(), project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch findings",
+		})
+		return
+	}
+
+	c.Header("Content-Type", "application/sarif+json")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="project-%d.sarif"`, project.ID))
+	c.JSON(http.StatusOK, report.BuildSARIF(project.Name, findings))
+}
@@ -0,0 +1,391 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/gitimport"
+	"reverse-engineering-backend/utils"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type GitController struct {
+	db    *gorm.DB
+	files *FileController
+}
+
+func NewGitController(db *gorm.DB) *GitController {
+	return &GitController{
+		db:    db,
+		files: NewFileController(db),
+	}
+}
+
+// ImportRepository ローカルの git リポジトリ（GIT_IMPORT_ROOT 配下）またはアップロードされた git bundle から
+// 指定した ref のツリーとコミット履歴をプロジェクトに取り込む
+func (gc *GitController) ImportRepository(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	var request struct {
+		RepositoryPath string `json:"repository_path" form:"repository_path"`
+		Ref            string `json:"ref" form:"ref"`
+	}
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	bundle, _ := c.FormFile("bundle")
+	if (request.RepositoryPath == "") == (bundle == nil) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Either repository_path or bundle is required",
+		})
+		return
+	}
+
+	var project models.Project
+	if err := gc.db.First(&project, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Project not found",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to verify project",
+			})
+		}
+		return
+	}
+	if project.SourceType == "git" {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "Project already has an imported repository",
+		})
+		return
+	}
+
+	source := gitimport.GitSource{Ref: request.Ref}
+	if request.RepositoryPath != "" {
+		repositoryPath, err := allowedRepositoryPath(request.RepositoryPath)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		source.RepositoryPath = repositoryPath
+	}
+
+	uploadPath := os.Getenv("UPLOAD_PATH")
+	if uploadPath == "" {
+		uploadPath = "./uploads"
+	}
+	root, err := filepath.Abs(filepath.Join(uploadPath, strconv.FormatUint(id, 10), "git-"+strconv.FormatInt(time.Now().UnixNano(), 36)))
+	if err == nil {
+		err = os.MkdirAll(root, 0755)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to create upload directory",
+		})
+		return
+	}
+
+	if bundle != nil {
+		// bundle はミラーを作った後は不要
+		tmp, err := os.CreateTemp("", "git-bundle-*")
+		if err == nil {
+			tmp.Close()
+			defer os.Remove(tmp.Name())
+			err = c.SaveUploadedFile(bundle, tmp.Name())
+		}
+		if err != nil {
+			os.RemoveAll(root)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to save bundle",
+			})
+			return
+		}
+		source.BundlePath = tmp.Name()
+	}
+
+	imported, err := gitimport.NewGitImportUseCase().Execute(c.Request.Context(), source, root)
+	if err != nil {
+		os.RemoveAll(root)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Failed to import repository: " + err.Error(),
+		})
+		return
+	}
+
+	commits, err := commitModels(project.ID, imported.Commits)
+	if err != nil {
+		os.RemoveAll(root)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to prepare commits",
+		})
+		return
+	}
+
+	// ファイル・コミット履歴・プロジェクトの取り込み元は同じトランザクションで保存する
+	registered, ok := gc.files.registerExtractedFiles(c, project, imported.Tree.Entries, root, imported.Commit, func(tx *gorm.DB) error {
+		if err := saveCommits(tx, commits); err != nil {
+			return err
+		}
+		return tx.Model(&project).Updates(map[string]interface{}{
+			"source_type":     "git",
+			"source_ref":      imported.Ref,
+			"head_commit":     imported.Commit,
+			"repository_path": filepath.Join(root, gitimport.RepositoryDir),
+		}).Error
+	})
+	if !ok {
+		return
+	}
+	// 内容は Blob に移したので、ツリーには隔離したファイルだけが残る
+	utils.RemoveEmptyDirs(filepath.Join(root, gitimport.TreeDir(imported.Commit)))
+	project.SourceType = "git"
+	project.SourceRef = imported.Ref
+	project.HeadCommit = imported.Commit
+
+	response := gin.H{
+		"message": "Repository imported successfully",
+		"project": project,
+		"files":   registered.files,
+		"import": gin.H{
+			"ref":               imported.Ref,
+			"commit":            imported.Commit,
+			"commits":           len(commits),
+			"commits_truncated": imported.CommitsTruncated,
+			"extracted":         len(registered.files),
+			"total_size":        imported.Tree.TotalSize,
+			"skipped":           imported.Tree.Skipped,
+			"aborted":           imported.Tree.Aborted,
+		},
+	}
+	if registered.secretScan != nil {
+		response["secret_scan"] = registered.secretScan
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetCommits 取り込んだコミット履歴を新しい順にページ単位で返す（path / author で絞り込み可能）
+func (gc *GitController) GetCommits(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	var project models.Project
+	if err := gc.db.First(&project, id).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Project not found",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch project",
+			})
+		}
+		return
+	}
+
+	query := gc.db.Model(&models.Commit{}).Where("project_id = ?", id)
+	if path := c.Query("path"); path != "" {
+		query = query.Where("id IN (?)", gc.db.Model(&models.CommitFile{}).Select("commit_id").Where("path = ? OR old_path = ?", path, path))
+	}
+	if author := c.Query("author"); author != "" {
+		query = query.Where("author_email = ? OR author_name = ?", author, author)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to count commits",
+		})
+		return
+	}
+
+	var commits []models.Commit
+	err = query.
+		Preload("Files").
+		Order("committed_at DESC, id").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&commits).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch commits",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"project_id":  project.ID,
+		"source_ref":  project.SourceRef,
+		"head_commit": project.HeadCommit,
+		"total":       total,
+		"page":        page,
+		"limit":       limit,
+		"commits":     commits,
+	})
+}
+
+// checkoutCommit git から取り込んだプロジェクトのコミットを解決し、そのツリーが未登録であれば取り出して登録する。
+// 失敗時はレスポンスを返して false
+func (gc *GitController) checkoutCommit(c *gin.Context, project models.Project, rev string) (string, bool) {
+	uc := gitimport.NewGitImportUseCase()
+	commit, err := uc.ResolveCommit(c.Request.Context(), project.RepositoryPath, rev)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return "", false
+	}
+	if commit == project.HeadCommit {
+		return commit, true
+	}
+
+	var count int64
+	if err := gc.db.Model(&models.File{}).Where("project_id = ? AND commit_hash = ?", project.ID, commit).Count(&count).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch files",
+		})
+		return "", false
+	}
+	if count > 0 {
+		return commit, true
+	}
+
+	// 以前の取り出しが途中で失敗していれば作り直す
+	dir := filepath.Join(filepath.Dir(project.RepositoryPath), gitimport.TreeDir(commit))
+	if err := os.RemoveAll(dir); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to prepare commit checkout",
+		})
+		return "", false
+	}
+	tree, err := uc.Checkout(c.Request.Context(), project.RepositoryPath, commit, dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check out commit: " + err.Error(),
+		})
+		return "", false
+	}
+	if _, ok := gc.files.registerExtractedFiles(c, project, tree.Entries, dir, commit, nil); !ok {
+		return "", false
+	}
+	utils.RemoveEmptyDirs(dir)
+	return commit, true
+}
+
+// allowedRepositoryPath シンボリックリンクを解決し、GIT_IMPORT_ROOT 配下のリポジトリだけを許可する
+func allowedRepositoryPath(repositoryPath string) (string, error) {
+	root := os.Getenv("GIT_IMPORT_ROOT")
+	if root == "" {
+		return "", errors.New("Local repository import is disabled (GIT_IMPORT_ROOT is not set)")
+	}
+	if !filepath.IsAbs(repositoryPath) {
+		return "", errors.New("Repository path must be absolute")
+	}
+	root, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", errors.New("GIT_IMPORT_ROOT does not exist")
+	}
+	resolved, err := filepath.EvalSymlinks(repositoryPath)
+	if err != nil {
+		return "", errors.New("Repository not found")
+	}
+	rel, err := filepath.Rel(root, resolved)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New("Repository is outside GIT_IMPORT_ROOT")
+	}
+	return resolved, nil
+}
+
+// commitModels 取り込んだコミットを保存用のモデルに変換する
+func commitModels(projectID uint, commits []entities.GitCommit) ([]models.Commit, error) {
+	result := make([]models.Commit, 0, len(commits))
+	for _, commit := range commits {
+		parents, err := json.Marshal(commit.Parents)
+		if err != nil {
+			return nil, err
+		}
+		files := make([]models.CommitFile, 0, len(commit.Files))
+		for _, file := range commit.Files {
+			files = append(files, models.CommitFile{
+				Path:      file.Path,
+				OldPath:   file.OldPath,
+				Status:    file.Status,
+				Additions: file.Additions,
+				Deletions: file.Deletions,
+				Binary:    file.Binary,
+			})
+		}
+		result = append(result, models.Commit{
+			ProjectID:      projectID,
+			Hash:           commit.Hash,
+			Parents:        string(parents),
+			AuthorName:     commit.AuthorName,
+			AuthorEmail:    commit.AuthorEmail,
+			AuthoredAt:     commit.AuthoredAt,
+			CommitterName:  commit.CommitterName,
+			CommitterEmail: commit.CommitterEmail,
+			CommittedAt:    commit.CommittedAt,
+			Message:        commit.Message,
+			Files:          files,
+		})
+	}
+	return result, nil
+}
+
+// saveCommits コミットと変更ファイルを保存する。変更ファイルは件数が多いため関連付けとは別にまとめて挿入する
+func saveCommits(tx *gorm.DB, commits []models.Commit) error {
+	if len(commits) == 0 {
+		return nil
+	}
+	if err := tx.Omit("Files").CreateInBatches(&commits, 200).Error; err != nil {
+		return err
+	}
+	var files []models.CommitFile
+	for _, commit := range commits {
+		for _, file := range commit.Files {
+			file.CommitID = commit.ID
+			files = append(files, file)
+		}
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&files, 1000).Error
+}
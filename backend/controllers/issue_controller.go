@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"reverse-engineering-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type IssueController struct {
+	db *gorm.DB
+}
+
+func NewIssueController(db *gorm.DB) *IssueController {
+	return &IssueController{
+		db: db,
+	}
+}
+
+// GetAnalysisIssues 解析で検出された Issue を深刻度の高い順に返す（severity / source で絞り込み可能）
+func (ic *IssueController) GetAnalysisIssues(c *gin.Context) {
+	analysisID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid analysis ID",
+		})
+		return
+	}
+
+	var analysis models.Analysis
+	if err := ic.db.First(&analysis, analysisID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Analysis not found",
+		})
+		return
+	}
+
+	query := ic.db.Where("analysis_id = ?", analysisID)
+	if severity := c.Query("severity"); severity != "" {
+		query = query.Where("severity = ?", severity)
+	}
+	if source := c.Query("source"); source != "" {
+		query = query.Where("source = ?", source)
+	}
+
+	var issues []models.Issue
+	err = query.
+		Order("CASE severity WHEN 'critical' THEN 0 WHEN 'high' THEN 1 WHEN 'medium' THEN 2 WHEN 'low' THEN 3 ELSE 4 END").
+		Order("path, start_line, id").
+		Find(&issues).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch issues",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"analysis_id": analysis.ID,
+		"status":      analysis.Status,
+		"total":       len(issues),
+		"issues":      issues,
+	})
+}
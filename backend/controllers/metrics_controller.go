@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"reverse-engineering-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// hotspotOrders ホットスポットの並び替えに使える指標
+var hotspotOrders = map[string]string{
+	"cyclomatic": "cyclomatic DESC, cognitive DESC",
+	"cognitive":  "cognitive DESC, cyclomatic DESC",
+	"nesting":    "max_nesting DESC, cognitive DESC",
+	"loc":        "physical_loc DESC, cyclomatic DESC",
+	"params":     "param_count DESC, cyclomatic DESC",
+}
+
+type MetricsController struct {
+	db *gorm.DB
+}
+
+func NewMetricsController(db *gorm.DB) *MetricsController {
+	return &MetricsController{
+		db: db,
+	}
+}
+
+// GetHotspots 最新の code_metrics 解析から複雑度の高い関数を上位N件返す
+func (mc *MetricsController) GetHotspots(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 || limit > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit must be between 1 and 100",
+		})
+		return
+	}
+
+	sortBy := c.DefaultQuery("sort", "cyclomatic")
+	order, exists := hotspotOrders[sortBy]
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid sort: " + sortBy,
+		})
+		return
+	}
+
+	// 最新の完了済みメトリクス解析を取得
+	var analysis models.Analysis
+	if err := mc.db.Where("project_id = ? AND type = ? AND status = ?", projectID, "code_metrics", "completed").
+		Order("created_at DESC").First(&analysis).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "No completed code_metrics analysis found for project",
+			})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to fetch analysis",
+			})
+		}
+		return
+	}
+
+	var hotspots []models.CodeMetric
+	if err := mc.db.Where("analysis_id = ? AND scope = ?", analysis.ID, "function").
+		Order(order).Limit(limit).Find(&hotspots).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch hotspots",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"analysis_id": analysis.ID,
+		"sort":        sortBy,
+		"hotspots":    hotspots,
+	})
+}
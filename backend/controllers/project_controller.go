@@ -4,7 +4,10 @@ import (
 	"net/http"
 	"strconv"
 
+	"reverse-engineering-backend/infrastructure/storage"
 	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/secrets"
+	"reverse-engineering-backend/utils"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
@@ -14,12 +17,14 @@ import (
 type ProjectController struct {
 	db    *gorm.DB
 	redis *redis.Client
+	blobs *storage.BlobStore
 }
 
 func NewProjectController(db *gorm.DB, redis *redis.Client) *ProjectController {
 	return &ProjectController{
 		db:    db,
 		redis: redis,
+		blobs: storage.NewBlobStore(db),
 	}
 }
 
@@ -111,6 +116,10 @@ func (pc *ProjectController) UpdateProject(c *gin.Context) {
 		Name        string `json:"name"`
 		Description string `json:"description"`
 		Status      string `json:"status"`
+		// SecretPolicy record, redact, quarantine, block
+		SecretPolicy string `json:"secret_policy"`
+		// Charset auto, utf-8, shift_jis, euc-jp, iso-2022-jp, utf-16le, utf-16be
+		Charset string `json:"charset"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -145,6 +154,25 @@ func (pc *ProjectController) UpdateProject(c *gin.Context) {
 	if request.Status != "" {
 		updates["status"] = request.Status
 	}
+	if request.SecretPolicy != "" {
+		if !secrets.IsValidPolicy(request.SecretPolicy) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "secret_policy must be one of record, redact, quarantine, block",
+			})
+			return
+		}
+		updates["secret_policy"] = request.SecretPolicy
+	}
+	if request.Charset != "" {
+		charset, ok := utils.NormalizeEncoding(request.Charset)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "charset must be one of auto, utf-8, shift_jis, euc-jp, iso-2022-jp, utf-16le, utf-16be",
+			})
+			return
+		}
+		updates["charset"] = charset
+	}
 
 	if err := pc.db.Model(&project).Updates(updates).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -167,7 +195,23 @@ func (pc *ProjectController) DeleteProject(c *gin.Context) {
 		return
 	}
 
-	if err := pc.db.Delete(&models.Project{}, id).Error; err != nil {
+	// プロジェクトのファイルも削除して Blob の参照を外す（他のプロジェクトと共有している内容は残る）
+	err = pc.db.Transaction(func(tx *gorm.DB) error {
+		var files []models.File
+		if err := tx.Where("project_id = ?", id).Find(&files).Error; err != nil {
+			return err
+		}
+		if len(files) > 0 {
+			if err := tx.Delete(&files).Error; err != nil {
+				return err
+			}
+			if err := pc.blobs.Release(tx, files); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&models.Project{}, id).Error
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to delete project",
 		})
@@ -0,0 +1,425 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/domain/repositories"
+	"reverse-engineering-backend/infrastructure/persistence"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/gate"
+	"reverse-engineering-backend/usecases/report"
+	"reverse-engineering-backend/workers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// gatePollInterval 解析完了を確認する間隔
+const gatePollInterval = time.Second
+
+type QualityGateController struct {
+	db            *gorm.DB
+	redis         *redis.Client
+	findingRepo   repositories.FindingRepository
+	analysisQueue string
+	analysisTypes []string
+}
+
+func NewQualityGateController(db *gorm.DB, redis *redis.Client) *QualityGateController {
+	return &QualityGateController{
+		db:            db,
+		redis:         redis,
+		findingRepo:   persistence.NewPostgresFindingRepository(db),
+		analysisQueue: "analysis:queue",
+		analysisTypes: workers.AnalysisTypes(),
+	}
+}
+
+// GetQualityGate プロジェクトの品質ゲート設定を返す（未設定の場合は既定値）
+func (qc *QualityGateController) GetQualityGate(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	config, configured, err := qc.loadGate(uint(projectID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch quality gate",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"configured":   configured,
+		"quality_gate": config,
+		"analyses":     gate.RequiredAnalyses(config),
+	})
+}
+
+// UpdateQualityGate プロジェクトの品質ゲート設定を作成または更新する
+func (qc *QualityGateController) UpdateQualityGate(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	var config entities.QualityGate
+	if err := c.ShouldBindJSON(&config); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := gate.Validate(config, qc.analysisTypes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var project models.Project
+	if err := qc.db.First(&project, projectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Project not found",
+		})
+		return
+	}
+
+	row := models.QualityGate{
+		ProjectID:                   project.ID,
+		MaxHighIssues:               config.MaxHighIssues,
+		MaxComplexity:               config.MaxComplexity,
+		NoNewVulnerableDependencies: config.NoNewVulnerableDependencies,
+		NoSecrets:                   config.NoSecrets,
+		Analyses:                    strings.Join(config.Analyses, ","),
+		TimeoutSeconds:              gate.Timeout(config),
+	}
+	err = qc.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_high_issues", "max_complexity", "no_new_vulnerable_dependencies", "no_secrets", "analyses", "timeout_seconds", "updated_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to save quality gate",
+		})
+		return
+	}
+
+	config.TimeoutSeconds = row.TimeoutSeconds
+	c.JSON(http.StatusOK, gin.H{
+		"quality_gate": config,
+		"analyses":     gate.RequiredAnalyses(config),
+	})
+}
+
+// RunQualityGate ゲートに必要な解析を実行して完了まで待ち、判定結果を返す。
+// 合格は 200、不合格は 422、タイムアウトは 504。format=junit で JUnit XML を返す
+func (qc *QualityGateController) RunQualityGate(c *gin.Context) {
+	started := time.Now()
+
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "junit" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "format must be json or junit",
+		})
+		return
+	}
+
+	var project models.Project
+	if err := qc.db.First(&project, projectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Project not found",
+		})
+		return
+	}
+
+	var fileCount int64
+	qc.db.Model(&models.File{}).Scopes(models.ProjectFiles(project, "")).Count(&fileCount)
+	if fileCount == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "No files found in project",
+		})
+		return
+	}
+
+	config, _, err := qc.loadGate(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch quality gate",
+		})
+		return
+	}
+	// 保存済みの設定にワーカーが処理できない解析が含まれていると、タイムアウトまで待つことになる
+	if err := gate.Validate(config, qc.analysisTypes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid quality gate: " + err.Error(),
+		})
+		return
+	}
+
+	// 必要な解析をキューに登録
+	analyses := make(map[string]uint)
+	for _, analysisType := range gate.RequiredAnalyses(config) {
+		analysis := models.Analysis{
+			ProjectID: project.ID,
+			Type:      analysisType,
+			Status:    "pending",
+		}
+		if err := qc.db.Create(&analysis).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to create analysis task",
+			})
+			return
+		}
+
+		taskJSON, _ := json.Marshal(map[string]interface{}{
+			"analysis_id": analysis.ID,
+			"project_id":  project.ID,
+			"type":        analysisType,
+		})
+		if err := qc.redis.LPush(c.Request.Context(), qc.analysisQueue, taskJSON).Err(); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to queue analysis task",
+			})
+			return
+		}
+		analyses[analysisType] = analysis.ID
+	}
+	if len(analyses) > 0 {
+		qc.db.Model(&project).Update("status", "analyzing")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), time.Duration(gate.Timeout(config))*time.Second)
+	defer cancel()
+	finished := qc.waitForAnalyses(ctx, analyses)
+
+	evidence, err := qc.collectEvidence(c.Request.Context(), project.ID, analyses, finished)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to collect analysis results",
+		})
+		return
+	}
+
+	verdict := gate.Evaluate(config, evidence)
+	verdict.Analyses = analyses
+	verdict.Duration = time.Since(started).Seconds()
+
+	status := http.StatusOK
+	switch verdict.Status {
+	case "failed":
+		status = http.StatusUnprocessableEntity
+	case "timeout":
+		status = http.StatusGatewayTimeout
+	}
+
+	if format == "junit" {
+		body, err := report.BuildJUnit(project.Name, verdict, time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to render JUnit report",
+			})
+			return
+		}
+		c.Data(status, "application/xml; charset=utf-8", body)
+		return
+	}
+
+	c.JSON(status, gin.H{
+		"project_id": project.ID,
+		"verdict":    verdict,
+	})
+}
+
+// loadGate プロジェクトの設定を読み込む。未設定なら既定値を返す
+func (qc *QualityGateController) loadGate(projectID uint) (entities.QualityGate, bool, error) {
+	var row models.QualityGate
+	err := qc.db.Where("project_id = ?", projectID).First(&row).Error
+	if err == gorm.ErrRecordNotFound {
+		return gate.DefaultQualityGate(), false, nil
+	}
+	if err != nil {
+		return entities.QualityGate{}, false, err
+	}
+
+	config := entities.QualityGate{
+		MaxHighIssues:               row.MaxHighIssues,
+		MaxComplexity:               row.MaxComplexity,
+		NoNewVulnerableDependencies: row.NoNewVulnerableDependencies,
+		NoSecrets:                   row.NoSecrets,
+		TimeoutSeconds:              row.TimeoutSeconds,
+	}
+	for _, analysisType := range strings.Split(row.Analyses, ",") {
+		if analysisType = strings.TrimSpace(analysisType); analysisType != "" {
+			config.Analyses = append(config.Analyses, analysisType)
+		}
+	}
+	return config, true, nil
+}
+
+// waitForAnalyses 全解析が完了・失敗するかコンテキストが終了するまで待ち、その時点の解析を返す
+func (qc *QualityGateController) waitForAnalyses(ctx context.Context, analyses map[string]uint) []models.Analysis {
+	ids := make([]uint, 0, len(analyses))
+	for _, id := range analyses {
+		ids = append(ids, id)
+	}
+
+	ticker := time.NewTicker(gatePollInterval)
+	defer ticker.Stop()
+
+	var current []models.Analysis
+	for {
+		// タイムアウト後も最新状態で判定できるよう、コンテキストに依存せず取得する
+		current = nil
+		qc.db.Where("id IN ?", ids).Find(&current)
+
+		done := len(current) == len(ids)
+		for _, analysis := range current {
+			if analysis.Status == "pending" || analysis.Status == "processing" {
+				done = false
+			}
+		}
+		if done {
+			return current
+		}
+
+		select {
+		case <-ctx.Done():
+			return current
+		case <-ticker.C:
+		}
+	}
+}
+
+// collectEvidence 解析結果からゲート判定の根拠を集める
+func (qc *QualityGateController) collectEvidence(ctx context.Context, projectID uint, analyses map[string]uint, finished []models.Analysis) (gate.Evidence, error) {
+	evidence := gate.Evidence{Failed: make(map[string]string)}
+
+	completed := make(map[string]bool)
+	status := make(map[uint]models.Analysis)
+	for _, analysis := range finished {
+		status[analysis.ID] = analysis
+	}
+	for analysisType, id := range analyses {
+		analysis, ok := status[id]
+		switch {
+		case ok && analysis.Status == "completed":
+			completed[analysisType] = true
+		case ok && analysis.Status == "failed":
+			var result struct {
+				Error string `json:"error"`
+			}
+			_ = json.Unmarshal([]byte(analysis.Result), &result)
+			evidence.Failed[analysisType] = result.Error
+		default:
+			evidence.Incomplete = append(evidence.Incomplete, analysisType)
+		}
+	}
+
+	findings, err := qc.findingRepo.FindByProject(ctx, projectID)
+	if err != nil {
+		return evidence, err
+	}
+	// 再アップロードで重複したシークレットなどは 1 件として数える
+	for _, finding := range report.Fingerprint(findings) {
+		evidence.Findings = append(evidence.Findings, finding.Finding)
+	}
+
+	if completed["code_metrics"] {
+		var metrics []models.CodeMetric
+		err := qc.db.WithContext(ctx).
+			Where("analysis_id = ? AND scope = ?", analyses["code_metrics"], "function").
+			Order("cyclomatic DESC").
+			Limit(50).
+			Find(&metrics).Error
+		if err != nil {
+			return evidence, err
+		}
+		for _, metric := range metrics {
+			evidence.Functions = append(evidence.Functions, gate.ComplexFunction{
+				Path:       metric.Path,
+				Function:   metric.FunctionName,
+				StartLine:  metric.StartLine,
+				Cyclomatic: metric.Cyclomatic,
+			})
+		}
+	}
+
+	if completed["vulnerability_scan"] {
+		if err := qc.collectNewVulnerabilities(ctx, projectID, analyses["vulnerability_scan"], &evidence); err != nil {
+			return evidence, err
+		}
+	}
+	return evidence, nil
+}
+
+// collectNewVulnerabilities 前回の脆弱性スキャンになかったアドバイザリとパッケージの組を新規とする
+func (qc *QualityGateController) collectNewVulnerabilities(ctx context.Context, projectID, analysisID uint, evidence *gate.Evidence) error {
+	key := func(issue models.Issue) string {
+		var metadata struct {
+			Ecosystem string `json:"ecosystem"`
+			Package   string `json:"package"`
+		}
+		_ = json.Unmarshal([]byte(issue.Metadata), &metadata)
+		return issue.RuleID + "|" + metadata.Ecosystem + "|" + metadata.Package
+	}
+
+	var current []models.Issue
+	if err := qc.db.WithContext(ctx).Where("analysis_id = ? AND source = ?", analysisID, "osv").Order("id").Find(&current).Error; err != nil {
+		return err
+	}
+
+	var previous models.Analysis
+	err := qc.db.WithContext(ctx).
+		Where("project_id = ? AND type = ? AND status = ? AND id < ?", projectID, "vulnerability_scan", "completed", analysisID).
+		Order("id DESC").
+		First(&previous).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	known := make(map[string]bool)
+	if err == nil {
+		evidence.HasBaseline = true
+		var baseline []models.Issue
+		if err := qc.db.WithContext(ctx).Where("analysis_id = ? AND source = ?", previous.ID, "osv").Find(&baseline).Error; err != nil {
+			return err
+		}
+		for _, issue := range baseline {
+			known[key(issue)] = true
+		}
+	}
+
+	for _, issue := range current {
+		k := key(issue)
+		if known[k] {
+			continue
+		}
+		known[k] = true
+		evidence.NewVulnerabilities = append(evidence.NewVulnerabilities, issue.Message)
+	}
+	return nil
+}
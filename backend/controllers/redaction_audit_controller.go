@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"reverse-engineering-backend/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RedactionAuditController struct {
+	db *gorm.DB
+}
+
+func NewRedactionAuditController(db *gorm.DB) *RedactionAuditController {
+	return &RedactionAuditController{
+		db: db,
+	}
+}
+
+// GetRedactionAudits LLM への送信ごとのマスキング監査ログを新しい順に返す（operation / since で絞り込み可能）
+func (rc *RedactionAuditController) GetRedactionAudits(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "limit must be between 1 and 1000",
+		})
+		return
+	}
+
+	query := rc.db.Model(&models.RedactionAudit{})
+	if operation := c.Query("operation"); operation != "" {
+		query = query.Where("operation = ?", operation)
+	}
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "since must be an RFC3339 timestamp",
+			})
+			return
+		}
+		query = query.Where("created_at >= ?", t)
+	}
+
+	// 件数は limit に関係なく集計する
+	var summary struct {
+		Requests   int64
+		Redactions int64
+		Residual   int64
+		Blocked    int64
+	}
+	err = query.Session(&gorm.Session{}).
+		Select("COUNT(*) AS requests, COALESCE(SUM(redactions), 0) AS redactions, COALESCE(SUM(residual), 0) AS residual, COALESCE(SUM(CASE WHEN blocked THEN 1 ELSE 0 END), 0) AS blocked").
+		Scan(&summary).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch redaction audits",
+		})
+		return
+	}
+
+	var audits []models.RedactionAudit
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&audits).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch redaction audits",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"requests":   summary.Requests,
+		"redactions": summary.Redactions,
+		"residual":   summary.Residual,
+		"blocked":    summary.Blocked,
+		"audits":     audits,
+	})
+}
@@ -0,0 +1,106 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/infrastructure/storage"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/binary"
+	"reverse-engineering-backend/usecases/dependency"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type SBOMController struct {
+	db                *gorm.DB
+	blobs             *storage.BlobStore
+	dependencyUseCase *dependency.DependencyUseCase
+}
+
+func NewSBOMController(db *gorm.DB) *SBOMController {
+	return &SBOMController{
+		db:                db,
+		blobs:             storage.NewBlobStore(db),
+		dependencyUseCase: dependency.NewDependencyUseCase(),
+	}
+}
+
+// GetSBOM プロジェクト内のマニフェストから依存関係を抽出し、CycloneDX または SPDX 形式の SBOM を返す
+func (sc *SBOMController) GetSBOM(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	format := c.DefaultQuery("format", "cyclonedx")
+	if format != "cyclonedx" && format != "spdx" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "format must be cyclonedx or spdx",
+		})
+		return
+	}
+
+	var project models.Project
+	if err := sc.db.First(&project, projectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Project not found",
+		})
+		return
+	}
+
+	// git から取り込んだプロジェクトで複数のコミットのツリーが混ざらないよう、commit（省略時は取り込んだ ref）に絞り込む
+	var files []models.File
+	if err := sc.db.Scopes(models.ProjectFiles(project, c.Query("commit"))).Find(&files).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch files",
+		})
+		return
+	}
+
+	infos := make([]entities.FileInfo, 0, len(files))
+	for _, file := range files {
+		// テキストでないファイルは Go 実行ファイルならビルド情報をマニフェストとして扱う
+		if !file.IsText() && !file.Quarantined {
+			if manifest, ok := binary.GoBuildInfoManifest(sc.blobs.Locate(file), file.Path); ok {
+				infos = append(infos, manifest)
+			}
+			continue
+		}
+		if !dependency.IsManifest(file.Path) {
+			continue
+		}
+		content, err := sc.blobs.ReadText(file)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to read file: " + file.Path,
+			})
+			return
+		}
+		infos = append(infos, entities.FileInfo{
+			Name:     file.Path,
+			Language: file.Language,
+			Content:  content,
+		})
+	}
+
+	inventory, err := sc.dependencyUseCase.Execute(c.Request.Context(), infos)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": "Failed to parse manifests: " + err.Error(),
+		})
+		return
+	}
+
+	subject := dependency.SBOMSubject{Name: project.Name}
+	if format == "spdx" {
+		c.JSON(http.StatusOK, dependency.BuildSPDX(subject, inventory))
+		return
+	}
+	c.JSON(http.StatusOK, dependency.BuildCycloneDX(subject, inventory))
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/infrastructure/persistence"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/secrets"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SecretRuleController struct {
+	db *gorm.DB
+}
+
+func NewSecretRuleController(db *gorm.DB) *SecretRuleController {
+	return &SecretRuleController{
+		db: db,
+	}
+}
+
+// GetSecretRules 組み込みルールとプロジェクト固有ルールを返す
+func (sc *SecretRuleController) GetSecretRules(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	custom, err := persistence.NewPostgresSecretRuleRepository(sc.db).FindByProject(c.Request.Context(), uint(projectID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to fetch secret rules",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"builtin": secrets.BuiltinRules,
+		"custom":  custom,
+	})
+}
+
+// SaveSecretRule プロジェクト固有ルールを作成または更新する
+func (sc *SecretRuleController) SaveSecretRule(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	var rule entities.SecretRule
+	if err := c.ShouldBindJSON(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := secrets.ValidateRule(rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var project models.Project
+	if err := sc.db.First(&project, projectID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Project not found",
+		})
+		return
+	}
+
+	row := models.SecretRule{
+		ProjectID:   project.ID,
+		RuleID:      rule.ID,
+		Description: rule.Description,
+		Pattern:     rule.Pattern,
+		SecretGroup: rule.SecretGroup,
+		Keywords:    strings.Join(rule.Keywords, ","),
+		MinEntropy:  rule.MinEntropy,
+		Severity:    rule.Severity,
+	}
+	if row.Severity == "" {
+		row.Severity = "high"
+	}
+
+	err = sc.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project_id"}, {Name: "rule_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"description", "pattern", "secret_group", "keywords", "min_entropy", "severity", "updated_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to save secret rule",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rule": persistence.SecretRuleEntity(row),
+	})
+}
+
+// DeleteSecretRule プロジェクト固有ルールを削除する
+func (sc *SecretRuleController) DeleteSecretRule(c *gin.Context) {
+	projectID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Invalid project ID",
+		})
+		return
+	}
+
+	result := sc.db.Where("project_id = ? AND rule_id = ?", projectID, c.Param("rule_id")).Delete(&models.SecretRule{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to delete secret rule",
+		})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "Secret rule not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Secret rule deleted successfully",
+	})
+}
@@ -0,0 +1,42 @@
+package entities
+
+// AndroidInspection Android APK のマニフェストとバイトコード
+type AndroidInspection struct {
+	Package     string `json:"package"`
+	VersionCode string `json:"version_code,omitempty"`
+	VersionName string `json:"version_name,omitempty"`
+	MinSDK      string `json:"min_sdk,omitempty"`
+	TargetSDK   string `json:"target_sdk,omitempty"`
+	// Debuggable・AllowBackup・UsesCleartextTraffic は application 要素の属性（未指定なら空）
+	Debuggable           string             `json:"debuggable,omitempty"`
+	AllowBackup          string             `json:"allow_backup,omitempty"`
+	UsesCleartextTraffic string             `json:"uses_cleartext_traffic,omitempty"`
+	Permissions          []string           `json:"permissions"`
+	Components           []AndroidComponent `json:"components"`
+	Manifest             string             `json:"manifest"` // テキストに戻した AndroidManifest.xml
+	Dex                  []DexFile          `json:"dex"`
+	NativeLibraries      []string           `json:"native_libraries"` // lib/<abi>/*.so
+	Packages             []JavaPackage      `json:"packages"`
+	Classes              []JavaClass        `json:"classes"`
+	ClassesTotal         int                `json:"classes_total"`
+	Errors               []JavaParseError   `json:"errors"`
+	Capped               bool               `json:"capped,omitempty"`
+}
+
+// AndroidComponent アクティビティ、サービス、レシーバー、プロバイダー
+type AndroidComponent struct {
+	Kind       string   `json:"kind"` // activity, activity-alias, service, receiver, provider
+	Name       string   `json:"name"`
+	Exported   string   `json:"exported,omitempty"` // true, false（未指定なら空）
+	Permission string   `json:"permission,omitempty"`
+	Actions    []string `json:"actions,omitempty"` // intent-filter の action
+}
+
+// DexFile APK の classes*.dex ファイル
+type DexFile struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Classes int    `json:"classes"`
+	Methods int    `json:"methods"` // 定義されたメソッドの数
+	Strings int    `json:"strings"`
+}
@@ -0,0 +1,23 @@
+package entities
+
+// ArchiveExtraction アップロードされたアーカイブの展開結果
+type ArchiveExtraction struct {
+	Format    string         `json:"format"` // zip, tar, tar.gz, tar.zst
+	Entries   []ArchiveEntry `json:"entries"`
+	Skipped   []SkippedEntry `json:"skipped"`
+	TotalSize int64          `json:"total_size"`        // 展開したファイルの合計サイズ
+	Aborted   string         `json:"aborted,omitempty"` // 上限を超えて展開を打ち切った理由
+}
+
+// ArchiveEntry アーカイブから展開した通常のファイル
+type ArchiveEntry struct {
+	Path     string `json:"path"` // アーカイブ内の相対パス（/ 区切り）
+	DiskPath string `json:"-"`
+	Size     int64  `json:"size"`
+}
+
+// SkippedEntry 展開しなかったアーカイブのエントリー
+type SkippedEntry struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,124 @@
+package entities
+
+// BinaryInfo ELF、PE、Mach-O 実行ファイルの構造
+type BinaryInfo struct {
+	Format        string          `json:"format"` // elf, pe, macho
+	Architecture  string          `json:"architecture"`
+	Bits          int             `json:"bits"`
+	Endianness    string          `json:"endianness"`
+	Type          string          `json:"type"` // executable, pie_executable, shared_object, dll, dylib, relocatable, ...
+	EntryPoint    uint64          `json:"entry_point"`
+	Interpreter   string          `json:"interpreter,omitempty"`
+	Universal     []string        `json:"universal,omitempty"` // Mach-O fat バイナリに含まれるアーキテクチャ
+	Sections      []BinarySection `json:"sections"`
+	Libraries     []string        `json:"libraries"`
+	Imports       []BinarySymbol  `json:"imports"`
+	Exports       []BinarySymbol  `json:"exports"`
+	ImportsTotal  int             `json:"imports_total"`
+	ExportsTotal  int             `json:"exports_total"`
+	Security      BinarySecurity  `json:"security"`
+	Stripped      bool            `json:"stripped"`
+	SymbolsCapped bool            `json:"symbols_capped,omitempty"`
+}
+
+// BinarySection 実行ファイルのセクション
+type BinarySection struct {
+	Name        string `json:"name"`
+	Address     uint64 `json:"address"`
+	Offset      uint64 `json:"offset"`
+	Size        uint64 `json:"size"`
+	Permissions string `json:"permissions"` // rwx 形式
+}
+
+// BinarySymbol インポートまたはエクスポートされたシンボル
+type BinarySymbol struct {
+	Name    string `json:"name"`
+	Library string `json:"library,omitempty"`
+	Address uint64 `json:"address,omitempty"`
+}
+
+// BinarySecurity 実行ファイルで検出した脆弱性緩和策
+// RELRO は ELF のみ意味があり、他の形式では "n/a"。canary と fortify はインポートした
+// 補助関数から推測したもの
+type BinarySecurity struct {
+	PIE         bool   `json:"pie"`
+	NX          bool   `json:"nx"`
+	RELRO       string `json:"relro"` // none, partial, full, n/a
+	StackCanary bool   `json:"stack_canary"`
+	Fortify     bool   `json:"fortify,omitempty"`
+	CFG         bool   `json:"cfg,omitempty"`    // PE Control Flow Guard
+	Signed      bool   `json:"signed,omitempty"` // PE Authenticode / Mach-O コード署名
+}
+
+// GoBinaryInfo strip されている可能性のある Go 実行ファイルから復元できる情報
+type GoBinaryInfo struct {
+	GoVersion      string           `json:"go_version"`
+	Path           string           `json:"path"` // main パッケージのパス
+	Main           GoModule         `json:"main"`
+	Deps           []GoModule       `json:"deps"`
+	Settings       []GoBuildSetting `json:"settings"`
+	Packages       []GoPackage      `json:"packages"`
+	Functions      []GoFunction     `json:"functions"`
+	FunctionsTotal int              `json:"functions_total"`
+	// SymbolsSource は関数名の取得元（gopclntab セクション、シグネチャ探索など）
+	SymbolsSource string `json:"symbols_source,omitempty"`
+	SymbolsError  string `json:"symbols_error,omitempty"`
+}
+
+// GoModule ビルド情報に記録されたモジュール
+type GoModule struct {
+	Path    string    `json:"path"`
+	Version string    `json:"version"`
+	Sum     string    `json:"sum,omitempty"`
+	Replace *GoModule `json:"replace,omitempty"`
+}
+
+// GoBuildSetting key=value 形式のビルド設定（GOOS、-ldflags、vcs.revision など）
+type GoBuildSetting struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// GoPackage パッケージと、そこから復元した関数の数
+type GoPackage struct {
+	Name      string `json:"name"`
+	Functions int    `json:"functions"`
+}
+
+// GoFunction Go の pclntab から復元した関数
+type GoFunction struct {
+	Name    string `json:"name"`
+	Package string `json:"package"`
+	Entry   uint64 `json:"entry"`
+	End     uint64 `json:"end"`
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line,omitempty"`
+}
+
+// Disassembly 1 関数の機械語
+type Disassembly struct {
+	Symbol       string        `json:"symbol"`
+	Address      uint64        `json:"address"`
+	Size         uint64        `json:"size"`
+	Arch         string        `json:"arch"`
+	Syntax       string        `json:"syntax"` // intel, gnu
+	Instructions []Instruction `json:"instructions"`
+	// Truncated は関数が上限より大きく、先頭だけを逆アセンブルしたことを示す
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// Instruction デコードした 1 命令
+type Instruction struct {
+	Address uint64 `json:"address"`
+	Bytes   string `json:"bytes"` // 16 進表記
+	Text    string `json:"text"`
+	// Target は分岐・呼び出し先のシンボル（symbol+0x10 の形式）
+	Target string `json:"target,omitempty"`
+}
+
+// FunctionExplanation 逆アセンブルした関数を LLM が読み解いた結果
+type FunctionExplanation struct {
+	PseudoCode string   `json:"pseudo_code"`
+	Summary    string   `json:"summary"`
+	Behaviours []string `json:"behaviours"` // ファイル操作、ネットワーク通信、暗号処理など
+}
@@ -0,0 +1,18 @@
+package entities
+
+// BlobStats 内容アドレス方式の blob ストアの集計
+type BlobStats struct {
+	Blobs        int64 `json:"blobs"`
+	Size         int64 `json:"size"`         // ディスク上の合計サイズ
+	References   int64 `json:"references"`   // Blob を参照しているファイル数
+	LogicalSize  int64 `json:"logical_size"` // 重複を除かない場合の合計サイズ
+	Unreferenced int64 `json:"unreferenced"` // 次のガベージコレクションで削除される候補
+}
+
+// BlobCollection blob のガベージコレクションの結果
+type BlobCollection struct {
+	Recounted      int64 `json:"recounted"`       // 参照数を数え直して修正した Blob
+	Removed        int64 `json:"removed"`         // 参照されなくなり削除した Blob
+	OrphansRemoved int64 `json:"orphans_removed"` // DB に記録のないディスク上の Blob
+	FreedBytes     int64 `json:"freed_bytes"`
+}
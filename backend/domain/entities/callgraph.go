@@ -0,0 +1,30 @@
+package entities
+
+// CallNode コールグラフ内の関数またはメソッド
+type CallNode struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Package     string `json:"package"`
+	Receiver    string `json:"receiver,omitempty"`
+	Participant string `json:"participant"`
+	Path        string `json:"path,omitempty"`
+	Line        int    `json:"line,omitempty"`
+	External    bool   `json:"external"`
+}
+
+// CallEdge あるノードから別のノードへの呼び出し箇所
+type CallEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Line int    `json:"line"`
+	Kind string `json:"kind"` // call, go, defer, dynamic
+}
+
+// CallGraph エントリーポイントから到達できる静的なコールグラフ
+type CallGraph struct {
+	Entry     string     `json:"entry"`
+	Depth     int        `json:"depth"`
+	Nodes     []CallNode `json:"nodes"`
+	Edges     []CallEdge `json:"edges"`
+	Truncated bool       `json:"truncated"`
+}
@@ -0,0 +1,31 @@
+package entities
+
+// Symbol ソースファイルで見つかったトップレベルの宣言
+type Symbol struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"` // function, method, type, const, var, class, interface
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Language string `json:"language"`
+	Exported bool   `json:"exported"`
+}
+
+// DeadSymbol 使われていない宣言
+type DeadSymbol struct {
+	Symbol
+	Reason     string `json:"reason"` // unreferenced, unreachable, unused_export
+	References int    `json:"references"`
+}
+
+// EntryPoint 参照グラフの起点として扱うシンボル
+type EntryPoint struct {
+	Symbol
+	Reason string `json:"reason"` // main, init, test, http_handler, module_default, module_init, exported_api
+}
+
+// DeadCodeReport 未使用コード検出の結果
+type DeadCodeReport struct {
+	Symbols     int          `json:"symbols"`
+	EntryPoints []EntryPoint `json:"entry_points"`
+	Findings    []DeadSymbol `json:"findings"`
+}
@@ -0,0 +1,21 @@
+package entities
+
+// Dependency プロジェクトのマニフェストで見つかったサードパーティのパッケージ
+// Ecosystem は OSV のエコシステム名（Go、npm、PyPI、Maven、crates.io、Packagist）
+type Dependency struct {
+	Name      string   `json:"name"`
+	Version   string   `json:"version"`
+	Ecosystem string   `json:"ecosystem"`
+	PURL      string   `json:"purl"`
+	Direct    bool     `json:"direct"`
+	Scope     string   `json:"scope"` // runtime, development, test, optional
+	Licenses  []string `json:"licenses,omitempty"`
+	Manifest  string   `json:"manifest"`
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+// DependencyInventory プロジェクトのマニフェスト全体で見つかった依存関係
+type DependencyInventory struct {
+	Manifests    []string     `json:"manifests"`
+	Dependencies []Dependency `json:"dependencies"`
+}
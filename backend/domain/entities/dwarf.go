@@ -0,0 +1,78 @@
+package entities
+
+// DebugInfo 実行ファイルの DWARF デバッグ情報
+type DebugInfo struct {
+	CompileUnits   []CompileUnit   `json:"compile_units"`
+	SourceTree     *SourceNode     `json:"source_tree"`
+	Types          []DebugType     `json:"types"`
+	Functions      []DebugFunction `json:"functions"`
+	TypesTotal     int             `json:"types_total"`
+	FunctionsTotal int             `json:"functions_total"`
+	// Capped は型・関数の一覧が上限で打ち切られたことを示す
+	Capped bool `json:"capped,omitempty"`
+}
+
+// CompileUnit DWARF のコンパイル単位
+type CompileUnit struct {
+	Name     string   `json:"name"`
+	Language string   `json:"language"`
+	Producer string   `json:"producer,omitempty"` // コンパイラとオプション
+	CompDir  string   `json:"comp_dir,omitempty"`
+	Files    []string `json:"files"`
+}
+
+// SourceNode デバッグ情報が参照するディレクトリまたはソースファイル
+type SourceNode struct {
+	Name     string        `json:"name"`
+	Path     string        `json:"path"`
+	Dir      bool          `json:"dir"`
+	Children []*SourceNode `json:"children,omitempty"`
+}
+
+// DebugType 構造体、共用体、クラス、列挙型、typedef
+type DebugType struct {
+	Name        string            `json:"name"`
+	Kind        string            `json:"kind"` // struct, union, class, enum, typedef
+	Size        int64             `json:"size"`
+	Members     []DebugMember     `json:"members,omitempty"`
+	Enumerators []DebugEnumerator `json:"enumerators,omitempty"`
+	Underlying  string            `json:"underlying,omitempty"` // typedef の元の型
+	DeclFile    string            `json:"decl_file,omitempty"`
+	DeclLine    int64             `json:"decl_line,omitempty"`
+}
+
+// DebugMember 構造体、共用体、クラスのフィールド
+type DebugMember struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Offset    int64  `json:"offset"`
+	BitSize   int64  `json:"bit_size,omitempty"`
+	BitOffset int64  `json:"bit_offset,omitempty"`
+}
+
+// DebugEnumerator 名前付きの列挙値
+type DebugEnumerator struct {
+	Name  string `json:"name"`
+	Value int64  `json:"value"`
+}
+
+// DebugFunction デバッグ情報に記録された関数のシグネチャ
+type DebugFunction struct {
+	Name        string       `json:"name"`
+	LinkageName string       `json:"linkage_name,omitempty"` // マングルされた名前
+	ReturnType  string       `json:"return_type"`
+	Params      []DebugParam `json:"params"`
+	Variadic    bool         `json:"variadic,omitempty"`
+	External    bool         `json:"external"`
+	LowPC       uint64       `json:"low_pc,omitempty"`
+	HighPC      uint64       `json:"high_pc,omitempty"`
+	DeclFile    string       `json:"decl_file,omitempty"`
+	DeclLine    int64        `json:"decl_line,omitempty"`
+	CompileUnit string       `json:"compile_unit"`
+}
+
+// DebugParam 関数の仮引数
+type DebugParam struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
@@ -0,0 +1,34 @@
+package entities
+
+// FileTree プロジェクトのファイルのディレクトリ 1 階層
+type FileTree struct {
+	Path        string          `json:"path"` // プロジェクトのルートは空
+	Directories []TreeDirectory `json:"directories"`
+	Files       []TreeFile      `json:"files"`
+	FileCount   int             `json:"file_count"` // 配下のすべてのファイル数
+	Size        int64           `json:"size"`
+	Languages   map[string]int  `json:"languages"` // 配下のファイルの言語ごとの数
+}
+
+// TreeDirectory サブディレクトリ。件数などは配下のすべてのファイルを集計したもの
+type TreeDirectory struct {
+	Name      string         `json:"name"`
+	Path      string         `json:"path"`
+	FileCount int            `json:"file_count"`
+	Size      int64          `json:"size"`
+	Languages map[string]int `json:"languages"`
+}
+
+// TreeFile ツリー内のファイル（内容は含まない）
+type TreeFile struct {
+	ID            uint   `json:"id"`
+	Name          string `json:"name"`
+	Path          string `json:"path"`
+	Size          int64  `json:"size"`
+	Language      string `json:"language,omitempty"`
+	MimeType      string `json:"mime_type,omitempty"`
+	Encoding      string `json:"encoding,omitempty"`
+	Quarantined   bool   `json:"quarantined,omitempty"`
+	DerivedFromID *uint  `json:"derived_from_id,omitempty"`
+	CommitHash    string `json:"commit_hash,omitempty"`
+}
@@ -0,0 +1,22 @@
+package entities
+
+// Finding レポートとゲートのために正規化した、あらゆる検出元（LLM 解析、SAST ルール、
+// シークレットスキャン、脆弱性スキャン）の指摘
+// FingerprintKey は行番号に依存しない検出元ごとの識別子で、同じ問題は実行をまたいで
+// 同じフィンガープリントになる
+type Finding struct {
+	Source         string `json:"source"` // llm, sast, secret, osv
+	RuleID         string `json:"rule_id"`
+	RuleName       string `json:"rule_name,omitempty"`
+	Severity       string `json:"severity"` // critical, high, medium, low, info
+	Message        string `json:"message"`
+	Path           string `json:"path,omitempty"`
+	StartLine      int    `json:"start_line,omitempty"`
+	EndLine        int    `json:"end_line,omitempty"`
+	StartColumn    int    `json:"start_column,omitempty"`
+	EndColumn      int    `json:"end_column,omitempty"`
+	CWE            string `json:"cwe,omitempty"`
+	Snippet        string `json:"snippet,omitempty"`
+	FingerprintKey string `json:"-"`
+	AnalysisID     uint   `json:"analysis_id"`
+}
@@ -0,0 +1,36 @@
+package entities
+
+import "time"
+
+// GitImport 解決したコミットで取り込んだ git リポジトリ
+type GitImport struct {
+	Ref              string             `json:"ref"`
+	Commit           string             `json:"commit"`
+	Tree             *ArchiveExtraction `json:"tree"`
+	Commits          []GitCommit        `json:"commits"`
+	CommitsTruncated bool               `json:"commits_truncated"` // 上限を超えた古いコミットは取り込まない
+}
+
+// GitCommit 取り込んだ ref から到達できるコミット
+type GitCommit struct {
+	Hash           string          `json:"hash"`
+	Parents        []string        `json:"parents"`
+	AuthorName     string          `json:"author_name"`
+	AuthorEmail    string          `json:"author_email"`
+	AuthoredAt     time.Time       `json:"authored_at"`
+	CommitterName  string          `json:"committer_name"`
+	CommitterEmail string          `json:"committer_email"`
+	CommittedAt    time.Time       `json:"committed_at"`
+	Message        string          `json:"message"`
+	Files          []GitFileChange `json:"files"` // マージコミットでは空
+}
+
+// GitFileChange コミットで変更されたファイル
+type GitFileChange struct {
+	Path      string `json:"path"`
+	OldPath   string `json:"old_path,omitempty"` // リネーム・コピー元
+	Status    string `json:"status"`             // added, modified, deleted, renamed, copied, type_changed
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Binary    bool   `json:"binary,omitempty"`
+}
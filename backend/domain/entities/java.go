@@ -0,0 +1,79 @@
+package entities
+
+// JavaInventory アップロードされた JAR、WAR、EAR、class ファイルで見つかったクラス
+type JavaInventory struct {
+	Archives     []JavaArchive    `json:"archives"`
+	Packages     []JavaPackage    `json:"packages"`
+	Classes      []JavaClass      `json:"classes"`
+	Graph        JavaClassGraph   `json:"graph"`
+	ClassesTotal int              `json:"classes_total"`
+	Errors       []JavaParseError `json:"errors"`
+	// Capped はクラス数またはグラフの辺が上限で打ち切られたことを示す
+	Capped bool `json:"capped,omitempty"`
+}
+
+// JavaArchive JAR（または JAR 内にネストした JAR）
+type JavaArchive struct {
+	Name     string            `json:"name"`
+	Manifest map[string]string `json:"manifest,omitempty"` // Main-Class, Implementation-Version など
+	Entries  int               `json:"entries"`
+	Classes  int               `json:"classes"`
+	Nested   []string          `json:"nested,omitempty"` // 同梱された JAR（BOOT-INF/lib/*.jar など）
+}
+
+// JavaPackage パッケージと、含まれるクラスの数
+type JavaPackage struct {
+	Name    string `json:"name"`
+	Classes int    `json:"classes"`
+}
+
+// JavaClass 解析した class ファイル
+type JavaClass struct {
+	Name       string       `json:"name"` // com.example.Foo$Bar
+	Package    string       `json:"package"`
+	Source     string       `json:"source"` // app.jar!/com/example/Foo$Bar.class
+	Kind       string       `json:"kind"`   // class, interface, enum, annotation, module
+	Access     []string     `json:"access"`
+	Version    string       `json:"version"` // 52.0 (Java 8)
+	SourceFile string       `json:"source_file,omitempty"`
+	SuperClass string       `json:"super_class,omitempty"`
+	Interfaces []string     `json:"interfaces"`
+	Fields     []JavaMember `json:"fields"`
+	Methods    []JavaMember `json:"methods"`
+	// References は定数プールと記述子から参照されているクラス
+	References []string `json:"references"`
+}
+
+// JavaMember フィールドまたはメソッド
+type JavaMember struct {
+	Name        string   `json:"name"`
+	Descriptor  string   `json:"descriptor"`
+	Declaration string   `json:"declaration"` // public static void main(java.lang.String[])
+	Access      []string `json:"access"`
+}
+
+// JavaClassGraph クラス間の参照関係
+type JavaClassGraph struct {
+	Nodes []JavaClassNode `json:"nodes"`
+	Edges []JavaClassEdge `json:"edges"`
+}
+
+// JavaClassNode 依存グラフ内のクラス
+type JavaClassNode struct {
+	ID      string `json:"id"`
+	Package string `json:"package"`
+	// External はアップロードされたファイルに含まれないクラス（JDK・未同梱のライブラリ）
+	External bool `json:"external"`
+}
+
+// JavaClassEdge あるクラスから別のクラスへの参照
+type JavaClassEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// JavaParseError 解析できなかったエントリー
+type JavaParseError struct {
+	Source string `json:"source"`
+	Error  string `json:"error"`
+}
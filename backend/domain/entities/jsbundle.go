@@ -0,0 +1,21 @@
+package entities
+
+// JSBundle 圧縮された JavaScript バンドルから復元したソースファイル
+type JSBundle struct {
+	Bundler string `json:"bundler"` // webpack, source_map, esbuild, minified, none
+	// SourceMap は使用したソースマップのファイル名
+	SourceMap            string          `json:"source_map,omitempty"`
+	SourceMapError       string          `json:"source_map_error,omitempty"`
+	Files                []RecoveredFile `json:"files"`
+	IdentifiersRecovered int             `json:"identifiers_recovered"` // ソースマップの names で元に戻した識別子の数
+	Skipped              int             `json:"skipped"`               // node_modules やバンドラーのランタイムとして除外したモジュール・ソースの数
+	Capped               bool            `json:"capped,omitempty"`
+}
+
+// RecoveredFile バンドルから復元したファイル
+type RecoveredFile struct {
+	Name    string `json:"name"`   // バンドル内での相対パス
+	Origin  string `json:"origin"` // beautified, webpack_module, source_map, bundle_section
+	Size    int    `json:"size"`
+	Content string `json:"-"`
+}
@@ -0,0 +1,25 @@
+package entities
+
+// FileMetrics 1 ファイルについて計算した静的なコードメトリクス
+type FileMetrics struct {
+	Path           string            `json:"path"`
+	Language       string            `json:"language"`
+	PhysicalLOC    int               `json:"physical_loc"`
+	LogicalLOC     int               `json:"logical_loc"`
+	CommentLines   int               `json:"comment_lines"`
+	BlankLines     int               `json:"blank_lines"`
+	CommentDensity float64           `json:"comment_density"`
+	Functions      []FunctionMetrics `json:"functions"`
+}
+
+// FunctionMetrics 1 関数について計算した静的なコードメトリクス
+type FunctionMetrics struct {
+	Name       string `json:"name"`
+	StartLine  int    `json:"start_line"`
+	EndLine    int    `json:"end_line"`
+	LOC        int    `json:"loc"`
+	Cyclomatic int    `json:"cyclomatic"`
+	Cognitive  int    `json:"cognitive"`
+	MaxNesting int    `json:"max_nesting"`
+	Params     int    `json:"params"`
+}
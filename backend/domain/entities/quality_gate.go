@@ -0,0 +1,31 @@
+package entities
+
+// QualityGate CI でプロジェクトに適用する合否の閾値
+// 閾値が nil の条件は無効
+type QualityGate struct {
+	MaxHighIssues               *int     `json:"max_high_issues"` // critical/high の SAST・シークレット・脆弱性 Issue 数
+	MaxComplexity               *int     `json:"max_complexity"`  // 関数の循環的複雑度の上限
+	NoNewVulnerableDependencies bool     `json:"no_new_vulnerable_dependencies"`
+	NoSecrets                   bool     `json:"no_secrets"`
+	Analyses                    []string `json:"analyses"` // 判定に使わないが合わせて実行する解析
+	TimeoutSeconds              int      `json:"timeout_seconds"`
+}
+
+// GateCondition 品質ゲートの 1 条件の結果
+type GateCondition struct {
+	Name      string   `json:"name"`
+	Status    string   `json:"status"` // passed, failed, error
+	Threshold string   `json:"threshold"`
+	Actual    string   `json:"actual"`
+	Reason    string   `json:"reason"`
+	Details   []string `json:"details,omitempty"`
+}
+
+// GateVerdict 品質ゲートの評価結果
+type GateVerdict struct {
+	Status     string          `json:"status"` // passed, failed, timeout
+	Passed     bool            `json:"passed"`
+	Conditions []GateCondition `json:"conditions"`
+	Analyses   map[string]uint `json:"analyses"` // 解析タイプ → 解析 ID
+	Duration   float64         `json:"duration_seconds"`
+}
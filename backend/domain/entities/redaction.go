@@ -0,0 +1,27 @@
+package entities
+
+import "time"
+
+// RedactionEvent ネットワークの外に出る前にプレースホルダーに置き換えた機密情報
+// 値そのものは記録せず、ハッシュだけを残す
+type RedactionEvent struct {
+	Kind        string `json:"kind"` // secret, email, ip, identifier
+	RuleID      string `json:"rule_id,omitempty"`
+	Placeholder string `json:"placeholder"`
+	Fingerprint string `json:"fingerprint"`
+	Occurrences int    `json:"occurrences"`
+}
+
+// RedactionAudit 外部への LLM リクエスト 1 件の監査記録
+type RedactionAudit struct {
+	RequestID    string           `json:"request_id"`
+	Operation    string           `json:"operation"`
+	Model        string           `json:"model"`
+	PromptSHA256 string           `json:"prompt_sha256"` // 送信した（マスク済み）プロンプトのハッシュ
+	PromptChars  int              `json:"prompt_chars"`
+	Events       []RedactionEvent `json:"events"`
+	// Residual はマスク後のプロンプトを再スキャンして残っていた検出数。0 でなければ送信しない
+	Residual  int       `json:"residual"`
+	Blocked   bool      `json:"blocked"`
+	CreatedAt time.Time `json:"created_at"`
+}
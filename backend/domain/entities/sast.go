@@ -0,0 +1,44 @@
+package entities
+
+// SASTRule YAML から読み込む決定的な検出ルール
+// ファイル全体に適用する正規表現（Pattern）か、構文木で評価する構造パターン（AST）の
+// どちらかでマッチする
+type SASTRule struct {
+	ID         string      `json:"id" yaml:"id"`
+	Languages  []string    `json:"languages" yaml:"languages"`
+	Message    string      `json:"message" yaml:"message"`
+	Severity   string      `json:"severity" yaml:"severity"` // critical, high, medium, low, info
+	CWE        string      `json:"cwe,omitempty" yaml:"cwe"`
+	Pattern    string      `json:"pattern,omitempty" yaml:"pattern"`
+	PatternNot string      `json:"pattern_not,omitempty" yaml:"pattern_not"` // マッチ箇所がこれにも一致すれば除外
+	AST        *ASTPattern `json:"ast,omitempty" yaml:"ast"`
+}
+
+// ASTPattern Go の構文木に対する構造的なマッチ
+//
+// Kind "call" は呼び出し先が Callee のいずれか（"exec.Command"、任意のレシーバーの
+// メソッドなら "*.Query"）で、Arg 番目の引数（-1 は任意の引数）が "dynamic"（定数でない）
+// または "concat"（+ や fmt.Sprintf で組み立てた）の呼び出しにマッチする
+// Kind "field" は複合リテラルのキーまたは Field への代入で、値が Value のものにマッチする
+// Type を指定するとその型のリテラルに限る
+type ASTPattern struct {
+	Kind   string   `json:"kind" yaml:"kind"`
+	Callee []string `json:"callee,omitempty" yaml:"callee"`
+	Arg    int      `json:"arg,omitempty" yaml:"arg"`
+	ArgIs  string   `json:"arg_is,omitempty" yaml:"arg_is"`
+	Field  string   `json:"field,omitempty" yaml:"field"`
+	Value  string   `json:"value,omitempty" yaml:"value"`
+	Type   string   `json:"type,omitempty" yaml:"type"`
+}
+
+// RuleFinding ファイル内の SAST ルールのマッチ
+type RuleFinding struct {
+	RuleID    string `json:"rule_id"`
+	Message   string `json:"message"`
+	Severity  string `json:"severity"`
+	CWE       string `json:"cwe,omitempty"`
+	Path      string `json:"path"`
+	StartLine int    `json:"start_line"`
+	EndLine   int    `json:"end_line"`
+	Snippet   string `json:"snippet"`
+}
@@ -0,0 +1,30 @@
+package entities
+
+// SecretRule ハードコードされた認証情報の検出ルール
+// Pattern は正規表現で、SecretGroup が設定されている場合はそのキャプチャグループ、
+// それ以外はマッチ全体をシークレットとする。正規表現の前に Keywords を大文字小文字を
+// 区別せずに照合し、MinEntropy でプレースホルダーのようなエントロピーの低い値を除く
+type SecretRule struct {
+	ID          string   `json:"id"`
+	Description string   `json:"description"`
+	Pattern     string   `json:"pattern"`
+	SecretGroup int      `json:"secret_group,omitempty"`
+	Keywords    []string `json:"keywords,omitempty"`
+	MinEntropy  float64  `json:"min_entropy,omitempty"`
+	Severity    string   `json:"severity"` // critical, high, medium, low
+}
+
+// SecretFinding ファイルで検出したシークレット
+// シークレット自体は保存せず、Redacted には確認用の短い先頭部分だけを残す
+type SecretFinding struct {
+	RuleID      string  `json:"rule_id"`
+	Description string  `json:"description"`
+	Severity    string  `json:"severity"`
+	Path        string  `json:"path"`
+	Line        int     `json:"line"`
+	Column      int     `json:"column"`
+	EndColumn   int     `json:"end_column"`
+	Redacted    string  `json:"redacted"`
+	Entropy     float64 `json:"entropy"`
+	Fingerprint string  `json:"fingerprint"`
+}
@@ -0,0 +1,45 @@
+package entities
+
+// BinaryTriage バイナリファイルの一次トリアージ
+type BinaryTriage struct {
+	Format   string           `json:"format,omitempty"` // elf, pe, macho（実行形式でなければ空）
+	Size     int64            `json:"size"`
+	Entropy  float64          `json:"entropy"`
+	Sections []SectionEntropy `json:"sections"`
+	Packers  []PackerMatch    `json:"packers"`
+	IOCs     []IOC            `json:"iocs"`
+	// StringsTotal は抽出した文字列の件数。文字列自体は別テーブルにページ単位で保存する
+	StringsTotal  int  `json:"strings_total"`
+	StringsCapped bool `json:"strings_capped,omitempty"`
+}
+
+// SectionEntropy セクション（実行ファイルでない場合は固定サイズのブロック）のシャノンエントロピー
+type SectionEntropy struct {
+	Name    string  `json:"name"`
+	Offset  int64   `json:"offset"`
+	Size    int64   `json:"size"`
+	Entropy float64 `json:"entropy"` // 0〜8 bit/byte
+	// HighEntropy は圧縮・暗号化されている可能性が高い領域
+	HighEntropy bool `json:"high_entropy"`
+}
+
+// PackerMatch 検出したパッカーまたはプロテクター
+type PackerMatch struct {
+	Name     string `json:"name"`
+	Evidence string `json:"evidence"`
+}
+
+// IOC バイナリの文字列から見つかった侵害の痕跡（Indicator of Compromise）
+type IOC struct {
+	Kind   string `json:"kind"` // url, ip, path, registry, email
+	Value  string `json:"value"`
+	Offset int64  `json:"offset"` // 最初に見つかった位置
+	Count  int    `json:"count"`
+}
+
+// ExtractedString バイナリから見つかった印字可能な文字列
+type ExtractedString struct {
+	Offset   int64  `json:"offset"`
+	Encoding string `json:"encoding"` // ascii, utf16le
+	Value    string `json:"value"`
+}
@@ -0,0 +1,49 @@
+package entities
+
+import "time"
+
+// Advisory OSV 形式の脆弱性情報
+type Advisory struct {
+	ID        string            `json:"id"`
+	Summary   string            `json:"summary"`
+	Details   string            `json:"details,omitempty"`
+	Aliases   []string          `json:"aliases,omitempty"`
+	Severity  string            `json:"severity"` // critical, high, medium, low
+	CVSSScore float64           `json:"cvss_score,omitempty"`
+	Published time.Time         `json:"published"`
+	Modified  time.Time         `json:"modified"`
+	Affected  []AffectedPackage `json:"affected"`
+}
+
+// AffectedPackage アドバイザリーの影響を受ける 1 パッケージのバージョン
+type AffectedPackage struct {
+	Ecosystem string          `json:"ecosystem"`
+	Name      string          `json:"name"`
+	Ranges    []AffectedRange `json:"ranges,omitempty"`
+	Versions  []string        `json:"versions,omitempty"`
+}
+
+// AffectedRange OSV の範囲（SEMVER または ECOSYSTEM）。順序付きのイベントの列
+type AffectedRange struct {
+	Type   string       `json:"type"`
+	Events []RangeEvent `json:"events"`
+}
+
+// RangeEvent OSV の範囲イベント。いずれか 1 つのフィールドだけが設定される
+type RangeEvent struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// VulnerabilityFinding アドバイザリーに該当した依存関係
+type VulnerabilityFinding struct {
+	Dependency    Dependency `json:"dependency"`
+	AdvisoryID    string     `json:"advisory_id"`
+	Aliases       []string   `json:"aliases,omitempty"`
+	Summary       string     `json:"summary"`
+	Severity      string     `json:"severity"`
+	CVSSScore     float64    `json:"cvss_score,omitempty"`
+	FixedVersions []string   `json:"fixed_versions,omitempty"`
+}
@@ -0,0 +1,91 @@
+package entities
+
+// WasmModule WebAssembly バイナリモジュールの構造
+type WasmModule struct {
+	Version   uint32         `json:"version"`
+	Name      string         `json:"name,omitempty"` // name セクションのモジュール名
+	Types     []WasmFuncType `json:"types"`
+	Imports   []WasmImport   `json:"imports"`
+	Exports   []WasmExport   `json:"exports"`
+	Functions []WasmFunction `json:"functions"`
+	Tables    []WasmTable    `json:"tables"`
+	Memories  []WasmMemory   `json:"memories"`
+	Globals   []WasmGlobal   `json:"globals"`
+	// Start は start セクションで指定された関数のインデックス
+	Start           *uint32             `json:"start,omitempty"`
+	ElementSegments int                 `json:"element_segments"`
+	DataSegments    int                 `json:"data_segments"`
+	CustomSections  []WasmCustomSection `json:"custom_sections"`
+	// Producers は producers セクションに記録されたコンパイラ・ツールチェーン
+	Producers       map[string][]string `json:"producers,omitempty"`
+	FunctionsTotal  int                 `json:"functions_total"`
+	FunctionsCapped bool                `json:"functions_capped,omitempty"`
+	Text            string              `json:"text,omitempty"` // WAT 形式のテキスト（小さなモジュールのみ）
+	TextTruncated   bool                `json:"text_truncated,omitempty"`
+}
+
+// WasmFuncType 関数のシグネチャ
+type WasmFuncType struct {
+	Params  []string `json:"params"`
+	Results []string `json:"results"`
+}
+
+// WasmImport インポートした関数、テーブル、メモリー、グローバル、タグ
+type WasmImport struct {
+	Module string `json:"module"`
+	Name   string `json:"name"`
+	Kind   string `json:"kind"` // func, table, memory, global, tag
+	Type   string `json:"type"` // シグネチャ、リミット、グローバルの型
+}
+
+// WasmExport エクスポートした項目
+type WasmExport struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind"`
+	Index uint32 `json:"index"`
+}
+
+// WasmFunction 関数インデックス空間内の関数
+type WasmFunction struct {
+	Index     uint32 `json:"index"`
+	Name      string `json:"name,omitempty"` // name セクションまたはエクスポート名
+	Signature string `json:"signature"`
+	Imported  bool   `json:"imported"`
+	Locals    int    `json:"locals,omitempty"`
+	CodeSize  int    `json:"code_size,omitempty"`
+}
+
+// WasmTable テーブル
+type WasmTable struct {
+	ElemType string  `json:"elem_type"`
+	Min      uint64  `json:"min"`
+	Max      *uint64 `json:"max,omitempty"`
+}
+
+// WasmMemory 線形メモリー。サイズは 64KiB のページ単位
+type WasmMemory struct {
+	Min      uint64  `json:"min"`
+	Max      *uint64 `json:"max,omitempty"`
+	Shared   bool    `json:"shared,omitempty"`
+	Memory64 bool    `json:"memory64,omitempty"`
+}
+
+// WasmGlobal グローバル変数
+type WasmGlobal struct {
+	Type    string `json:"type"`
+	Mutable bool   `json:"mutable"`
+	Init    string `json:"init,omitempty"` // 初期化式
+}
+
+// WasmCustomSection name、producers、DWARF などのカスタムセクション
+type WasmCustomSection struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+}
+
+// WasmExplanation WebAssembly モジュールを LLM が読み解いた結果
+type WasmExplanation struct {
+	Summary    string   `json:"summary"`
+	Toolchain  string   `json:"toolchain"`  // Emscripten, wasm-bindgen, TinyGo などの推定
+	Behaviours []string `json:"behaviours"` // ホストとのやり取り、暗号処理、マイニングなど
+}
@@ -0,0 +1,13 @@
+package repositories
+
+import (
+	"context"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// AdvisoryRepository ローカル脆弱性データベースのインターフェース
+type AdvisoryRepository interface {
+	SaveAdvisories(ctx context.Context, advisories []entities.Advisory) error
+	FindByPackage(ctx context.Context, ecosystem, name string) ([]entities.Advisory, error)
+}
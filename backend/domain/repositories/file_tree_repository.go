@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"context"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// FileTreeRepository プロジェクトのファイルをディレクトリ 1 階層ずつ読み込むインターフェース
+// commit は対象のコミット（空ならコミットで絞り込まない）、dir はスラッシュ区切りの正規化したパス（ルートは空）
+type FileTreeRepository interface {
+	// FindFiles dir の直下のファイルを返す（内容は読み込まない）
+	FindFiles(ctx context.Context, projectID uint, commit, dir string) ([]entities.TreeFile, error)
+	// FindDirectories dir の直下のサブディレクトリを、配下のすべてのファイルを集計して返す
+	FindDirectories(ctx context.Context, projectID uint, commit, dir string) ([]entities.TreeDirectory, error)
+}
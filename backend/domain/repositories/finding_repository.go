@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"context"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// FindingRepository プロジェクトの現在の指摘を読み込むインターフェース
+type FindingRepository interface {
+	FindByProject(ctx context.Context, projectID uint) ([]entities.Finding, error)
+}
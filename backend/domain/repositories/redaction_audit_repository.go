@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"context"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// RedactionAuditRepository 外部への LLM リクエストの監査証跡のインターフェース
+type RedactionAuditRepository interface {
+	SaveAudit(ctx context.Context, audit entities.RedactionAudit) error
+}
@@ -0,0 +1,12 @@
+package repositories
+
+import (
+	"context"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// SecretRuleRepository プロジェクト固有のシークレット検出ルールのインターフェース
+type SecretRuleRepository interface {
+	FindByProject(ctx context.Context, projectID uint) ([]entities.SecretRule, error)
+}
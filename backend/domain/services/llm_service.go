@@ -11,6 +11,8 @@ type LLMService interface {
 	GenerateEmbedding(ctx context.Context, text string) ([]float64, error)
 	AnalyzeCode(ctx context.Context, code, language string) (*entities.AnalysisResult, error)
 	GenerateDocumentation(ctx context.Context, code, language string) (string, error)
-	DetectPatterns(ctx context.Context, code, language string) (*entities.AnalysisResult, error)
+	DetectPatterns(ctx context.Context, code, language string, metrics *entities.FileMetrics) (*entities.AnalysisResult, error)
 	AnalyzeDependencies(ctx context.Context, files []entities.FileInfo) (*entities.AnalysisResult, error)
+	ExplainDisassembly(ctx context.Context, disassembly, arch string) (*entities.FunctionExplanation, error)
+	ExplainWasmModule(ctx context.Context, summary string) (*entities.WasmExplanation, error)
 }
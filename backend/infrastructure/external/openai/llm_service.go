@@ -4,27 +4,43 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/domain/repositories"
 	"reverse-engineering-backend/domain/services"
+	"reverse-engineering-backend/usecases/redaction"
+	"strings"
 
 	"github.com/sashabaranov/go-openai"
 )
 
-// OpenAIService implements LLMService using OpenAI API
+// OpenAIService implements LLMService using OpenAI API.
+// Every prompt is redacted before it is sent and restored in the response.
 type OpenAIService struct {
-	client *openai.Client
+	client    *openai.Client
+	redactor  *redaction.Redactor
+	auditRepo repositories.RedactionAuditRepository
 }
 
-// NewOpenAIService creates a new OpenAI service instance
-func NewOpenAIService() services.LLMService {
+// NewOpenAIService creates a new OpenAI service instance; auditRepo may be nil
+// in which case redaction events are only logged
+func NewOpenAIService(auditRepo repositories.RedactionAuditRepository) services.LLMService {
 	apiKey := os.Getenv("OPENAI_API_KEY")
 	if apiKey == "" {
 		return &OpenAIService{client: nil}
 	}
 
+	// 生成に失敗した場合 redactor は nil のままとなり、送信は拒否される
+	redactor, err := redaction.NewRedactorFromEnv()
+	if err != nil {
+		log.Printf("Warning: Failed to initialize prompt redactor: %v", err)
+	}
+
 	return &OpenAIService{
-		client: openai.NewClient(apiKey),
+		client:    openai.NewClient(apiKey),
+		redactor:  redactor,
+		auditRepo: auditRepo,
 	}
 }
 
@@ -44,25 +60,12 @@ func (o *OpenAIService) GenerateAnswer(ctx context.Context, question, context st
 プロジェクトの知識ベースに基づいて回答してください。
 `, context, question)
 
-	resp, err := o.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: openai.GPT3Dot5Turbo,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			MaxTokens: 2000,
-		},
-	)
-
+	content, err := o.complete(ctx, "generate_answer", prompt, 2000, false)
 	if err != nil {
 		return "", err
 	}
 
-	return resp.Choices[0].Message.Content, nil
+	return content, nil
 }
 
 // GenerateEmbedding generates embeddings using OpenAI
@@ -72,10 +75,16 @@ func (o *OpenAIService) GenerateEmbedding(ctx context.Context, text string) ([]f
 		return make([]float64, 1536), nil
 	}
 
+	// 埋め込みは復元不要だが、送信前のマスキングと監査は同様に行う
+	redacted, _, err := o.redact(ctx, "generate_embedding", "text-embedding-ada-002", text)
+	if err != nil {
+		return nil, err
+	}
+
 	resp, err := o.client.CreateEmbeddings(
 		ctx,
 		openai.EmbeddingRequest{
-			Input: text,
+			Input: redacted,
 			Model: "text-embedding-ada-002",
 		},
 	)
@@ -118,27 +127,14 @@ func (o *OpenAIService) AnalyzeCode(ctx context.Context, code, language string)
 JSON形式で回答してください。
 `, language, code)
 
-	resp, err := o.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: openai.GPT3Dot5Turbo,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			MaxTokens: 2000,
-		},
-	)
-
+	content, err := o.complete(ctx, "analyze_code", prompt, 2000, true)
 	if err != nil {
 		return nil, err
 	}
 
 	// Parse JSON response
 	var result entities.AnalysisResult
-	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &result); err != nil {
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
 		return nil, err
 	}
 
@@ -166,29 +162,16 @@ func (o *OpenAIService) GenerateDocumentation(ctx context.Context, code, languag
 Markdown形式で回答してください。
 `, language, code)
 
-	resp, err := o.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: openai.GPT3Dot5Turbo,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			MaxTokens: 3000,
-		},
-	)
-
+	content, err := o.complete(ctx, "generate_documentation", prompt, 3000, false)
 	if err != nil {
 		return "", err
 	}
 
-	return resp.Choices[0].Message.Content, nil
+	return content, nil
 }
 
-// DetectPatterns detects patterns using OpenAI
-func (o *OpenAIService) DetectPatterns(ctx context.Context, code, language string) (*entities.AnalysisResult, error) {
+// DetectPatterns detects patterns using OpenAI, using static metrics as evidence when available
+func (o *OpenAIService) DetectPatterns(ctx context.Context, code, language string, metrics *entities.FileMetrics) (*entities.AnalysisResult, error) {
 	if o.client == nil {
 		return o.mockPatternDetection(code, language), nil
 	}
@@ -200,33 +183,20 @@ func (o *OpenAIService) DetectPatterns(ctx context.Context, code, language strin
 2. アンチパターン（God Object, Spaghetti Code, etc.）
 3. コード品質の評価
 4. リファクタリング提案
-
+%s
 コード：
 %s
 
 JSON形式で回答してください。
-`, language, code)
-
-	resp, err := o.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: openai.GPT3Dot5Turbo,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			MaxTokens: 2000,
-		},
-	)
+`, language, formatMetricsEvidence(metrics), code)
 
+	content, err := o.complete(ctx, "detect_patterns", prompt, 2000, true)
 	if err != nil {
 		return nil, err
 	}
 
 	var result entities.AnalysisResult
-	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &result); err != nil {
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
 		return nil, err
 	}
 
@@ -258,32 +228,104 @@ func (o *OpenAIService) AnalyzeDependencies(ctx context.Context, files []entitie
 JSON形式で回答してください。
 `, fileList)
 
-	resp, err := o.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: openai.GPT3Dot5Turbo,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			MaxTokens: 2000,
-		},
-	)
-
+	content, err := o.complete(ctx, "analyze_dependencies", prompt, 2000, true)
 	if err != nil {
 		return nil, err
 	}
 
 	var result entities.AnalysisResult
-	if err := json.Unmarshal([]byte(resp.Choices[0].Message.Content), &result); err != nil {
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
 		return nil, err
 	}
 
 	return &result, nil
 }
 
+// ExplainDisassembly reconstructs pseudo-code and a behavioural summary from a disassembly listing
+func (o *OpenAIService) ExplainDisassembly(ctx context.Context, disassembly, arch string) (*entities.FunctionExplanation, error) {
+	if o.client == nil {
+		return o.mockDisassemblyExplanation(disassembly, arch), nil
+	}
+
+	prompt := fmt.Sprintf(`
+以下は%sの関数を逆アセンブルした結果です。リバースエンジニアリングの観点から解析し、以下の情報をJSON形式で提供してください：
+
+1. pseudo_code: C言語風の疑似コード（引数・戻り値・ローカル変数は推定した名前を付ける）
+2. summary: 関数が何をしているかの説明
+3. behaviours: 観測できる振る舞いの一覧（ファイル操作、ネットワーク通信、プロセス生成、暗号処理、アンチデバッグなど）
+
+命令列から読み取れないことは推測であると明記してください。
+
+逆アセンブル：
+%s
+
+JSON形式で回答してください。
+`, arch, disassembly)
+
+	content, err := o.complete(ctx, "explain_disassembly", prompt, 3000, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var result entities.FunctionExplanation
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// ExplainWasmModule describes what a WebAssembly module does from its imports, exports and text format
+func (o *OpenAIService) ExplainWasmModule(ctx context.Context, summary string) (*entities.WasmExplanation, error) {
+	if o.client == nil {
+		return o.mockWasmExplanation(summary), nil
+	}
+
+	prompt := fmt.Sprintf(`
+以下はWebAssemblyモジュールの構造（インポート、エクスポート、メモリ、カスタムセクション、テキスト形式の抜粋）です。リバースエンジニアリングの観点から解析し、以下の情報をJSON形式で提供してください：
+
+1. summary: モジュールが何をするものかの説明（エクスポートされた関数の役割を含む）
+2. toolchain: 生成に使われたと推定されるコンパイラ・ツールチェーン（Emscripten、wasm-bindgen、TinyGo、Go、AssemblyScriptなど）と根拠
+3. behaviours: 観測できる振る舞いの一覧（ホストとのやり取り、ファイル・ネットワークアクセス、暗号処理、暗号通貨のマイニング、難読化など）
+
+構造から読み取れないことは推測であると明記してください。
+
+モジュール：
+%s
+
+JSON形式で回答してください。
+`, summary)
+
+	content, err := o.complete(ctx, "explain_wasm_module", prompt, 3000, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var result entities.WasmExplanation
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// formatMetricsEvidence renders static metrics as a prompt section
+func formatMetricsEvidence(metrics *entities.FileMetrics) string {
+	if metrics == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("\n静的解析で計測したメトリクス（根拠として参照してください）：\n")
+	fmt.Fprintf(&b, "- 物理行数: %d, 論理行数: %d, コメント密度: %.1f%%\n",
+		metrics.PhysicalLOC, metrics.LogicalLOC, metrics.CommentDensity*100)
+	for _, fn := range metrics.Functions {
+		fmt.Fprintf(&b, "- %s (L%d-%d): 循環的複雑度 %d, 認知的複雑度 %d, 最大ネスト %d, 引数 %d\n",
+			fn.Name, fn.StartLine, fn.EndLine, fn.Cyclomatic, fn.Cognitive, fn.MaxNesting, fn.Params)
+	}
+	return b.String()
+}
+
 // Mock methods for development
 func (o *OpenAIService) mockAnswer(question string) string {
 	return "プロジェクトの知識ベースを参考にした回答です。"
@@ -325,3 +367,19 @@ func (o *OpenAIService) mockDependencyAnalysis(files []entities.FileInfo) *entit
 		Recommendations: []string{"依存関係の整理"},
 	}
 }
+
+func (o *OpenAIService) mockDisassemblyExplanation(disassembly, arch string) *entities.FunctionExplanation {
+	return &entities.FunctionExplanation{
+		PseudoCode: "void function(void) {\n    // 疑似コード\n}",
+		Summary:    "逆アセンブル解析結果",
+		Behaviours: []string{},
+	}
+}
+
+func (o *OpenAIService) mockWasmExplanation(summary string) *entities.WasmExplanation {
+	return &entities.WasmExplanation{
+		Summary:    "WebAssemblyモジュール解析結果",
+		Toolchain:  "不明",
+		Behaviours: []string{},
+	}
+}
@@ -0,0 +1,99 @@
+package openai
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/usecases/redaction"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// redact プロンプトをマスクし、機密情報が残っていないことを確認して監査を記録する
+// ネットワークの外に出るすべてのプロンプトを記録するため、監査を書き込めない場合は
+// リクエストを拒否する
+func (o *OpenAIService) redact(ctx context.Context, operation, model, prompt string) (string, *redaction.Session, error) {
+	if o.redactor == nil {
+		return "", nil, fmt.Errorf("redactor is not available")
+	}
+
+	session := o.redactor.NewSession()
+	redacted := session.Redact(prompt)
+	sum := sha256.Sum256([]byte(redacted))
+
+	audit := entities.RedactionAudit{
+		RequestID:    newRequestID(),
+		Operation:    operation,
+		Model:        model,
+		PromptSHA256: hex.EncodeToString(sum[:]),
+		PromptChars:  len(redacted),
+		Events:       session.Events(),
+		Residual:     o.redactor.Residual(redacted),
+		CreatedAt:    time.Now(),
+	}
+	// プレースホルダー置換後も検出が残る場合は送信しない
+	audit.Blocked = audit.Residual > 0
+
+	if o.auditRepo != nil {
+		if err := o.auditRepo.SaveAudit(ctx, audit); err != nil {
+			return "", nil, fmt.Errorf("failed to record redaction audit: %w", err)
+		}
+	} else {
+		log.Printf("redaction: %s request %s masked %d value(s), residual %d", operation, audit.RequestID, len(audit.Events), audit.Residual)
+	}
+
+	if audit.Blocked {
+		return "", nil, fmt.Errorf("prompt still contains %d sensitive value(s) after redaction", audit.Residual)
+	}
+	return redacted, session, nil
+}
+
+// complete マスクしたチャットプロンプトを送り、レスポンスの値を元に戻す
+// JSON のレスポンスはパースできるよう、エスケープした値で戻す
+func (o *OpenAIService) complete(ctx context.Context, operation, prompt string, maxTokens int, jsonResponse bool) (string, error) {
+	redacted, session, err := o.redact(ctx, operation, openai.GPT3Dot5Turbo, prompt)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := o.client.CreateChatCompletion(
+		ctx,
+		openai.ChatCompletionRequest{
+			Model: openai.GPT3Dot5Turbo,
+			Messages: []openai.ChatCompletionMessage{
+				{
+					Role:    openai.ChatMessageRoleUser,
+					Content: redacted,
+				},
+			},
+			MaxTokens: maxTokens,
+		},
+	)
+
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no completion choices received")
+	}
+
+	if jsonResponse {
+		return session.RestoreJSON(resp.Choices[0].Message.Content), nil
+	}
+	return session.Restore(resp.Choices[0].Message.Content), nil
+}
+
+// newRequestID 監査記録とログを関連付けるためのランダムな識別子を返す
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
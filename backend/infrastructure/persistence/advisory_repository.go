@@ -0,0 +1,145 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/domain/repositories"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/dependency"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PostgresAdvisoryRepository OSV アドバイザリーを vulnerabilities テーブルに保存する
+type PostgresAdvisoryRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresAdvisoryRepository GORM を使ったアドバイザリーリポジトリを作成
+func NewPostgresAdvisoryRepository(db *gorm.DB) repositories.AdvisoryRepository {
+	return &PostgresAdvisoryRepository{
+		db: db,
+	}
+}
+
+// SaveAdvisories アドバイザリーを upsert し、影響を受けるパッケージを置き換える
+func (r *PostgresAdvisoryRepository) SaveAdvisories(ctx context.Context, advisories []entities.Advisory) error {
+	vulns := make([]models.Vulnerability, 0, len(advisories))
+	var affected []models.VulnerabilityAffected
+	ids := make([]string, 0, len(advisories))
+
+	for _, advisory := range advisories {
+		aliases, err := json.Marshal(advisory.Aliases)
+		if err != nil {
+			return err
+		}
+		vulns = append(vulns, models.Vulnerability{
+			ID:        advisory.ID,
+			Summary:   advisory.Summary,
+			Details:   advisory.Details,
+			Aliases:   string(aliases),
+			Severity:  advisory.Severity,
+			CVSSScore: advisory.CVSSScore,
+			Published: advisory.Published,
+			Modified:  advisory.Modified,
+		})
+		ids = append(ids, advisory.ID)
+
+		for _, pkg := range advisory.Affected {
+			ranges, err := json.Marshal(pkg.Ranges)
+			if err != nil {
+				return err
+			}
+			versions, err := json.Marshal(pkg.Versions)
+			if err != nil {
+				return err
+			}
+			affected = append(affected, models.VulnerabilityAffected{
+				VulnerabilityID: advisory.ID,
+				Ecosystem:       pkg.Ecosystem,
+				PackageName:     dependency.NormalizePackageName(pkg.Ecosystem, pkg.Name),
+				Ranges:          string(ranges),
+				Versions:        string(versions),
+			})
+		}
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"summary", "details", "aliases", "severity", "cvss_score", "published", "modified", "updated_at"}),
+		}).CreateInBatches(vulns, 200).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("vulnerability_id IN ?", ids).Delete(&models.VulnerabilityAffected{}).Error; err != nil {
+			return err
+		}
+		if len(affected) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(affected, 500).Error
+	})
+}
+
+// FindByPackage パッケージに影響するアドバイザリーを返す。name は正規化済みであること
+func (r *PostgresAdvisoryRepository) FindByPackage(ctx context.Context, ecosystem, name string) ([]entities.Advisory, error) {
+	var rows []models.VulnerabilityAffected
+	err := r.db.WithContext(ctx).
+		Where("ecosystem = ? AND package_name = ?", ecosystem, name).
+		Find(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.VulnerabilityID)
+	}
+	var vulns []models.Vulnerability
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&vulns).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*entities.Advisory, len(vulns))
+	advisories := make([]entities.Advisory, 0, len(vulns))
+	for _, vuln := range vulns {
+		advisory := entities.Advisory{
+			ID:        vuln.ID,
+			Summary:   vuln.Summary,
+			Details:   vuln.Details,
+			Severity:  vuln.Severity,
+			CVSSScore: vuln.CVSSScore,
+			Published: vuln.Published,
+			Modified:  vuln.Modified,
+		}
+		_ = json.Unmarshal([]byte(vuln.Aliases), &advisory.Aliases)
+		advisories = append(advisories, advisory)
+	}
+	for i := range advisories {
+		byID[advisories[i].ID] = &advisories[i]
+	}
+
+	for _, row := range rows {
+		advisory, ok := byID[row.VulnerabilityID]
+		if !ok {
+			continue
+		}
+		pkg := entities.AffectedPackage{
+			Ecosystem: row.Ecosystem,
+			Name:      row.PackageName,
+		}
+		if err := json.Unmarshal([]byte(row.Ranges), &pkg.Ranges); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(row.Versions), &pkg.Versions); err != nil {
+			return nil, err
+		}
+		advisory.Affected = append(advisory.Affected, pkg)
+	}
+
+	return advisories, nil
+}
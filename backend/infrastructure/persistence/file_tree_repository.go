@@ -0,0 +1,121 @@
+package persistence
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/domain/repositories"
+	"reverse-engineering-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// PostgresFileTreeRepository files テーブルのパスをディレクトリごとに SQL で集計する
+type PostgresFileTreeRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresFileTreeRepository GORM を使ったファイルツリーリポジトリを作成
+func NewPostgresFileTreeRepository(db *gorm.DB) repositories.FileTreeRepository {
+	return &PostgresFileTreeRepository{
+		db: db,
+	}
+}
+
+// FindFiles dir の直下のファイルを名前順に返す
+func (r *PostgresFileTreeRepository) FindFiles(ctx context.Context, projectID uint, commit, dir string) ([]entities.TreeFile, error) {
+	var rows []models.File
+	err := r.underDir(ctx, projectID, commit, dir, false).
+		Select("id, name, path, size, language, mime_type, encoding, quarantined, derived_from_id, commit_hash").
+		Order("name, id").
+		Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]entities.TreeFile, 0, len(rows))
+	for _, row := range rows {
+		files = append(files, entities.TreeFile{
+			ID:            row.ID,
+			Name:          row.Name,
+			Path:          row.Path,
+			Size:          row.Size,
+			Language:      row.Language,
+			MimeType:      row.MimeType,
+			Encoding:      row.Encoding,
+			Quarantined:   row.Quarantined,
+			DerivedFromID: row.DerivedFromID,
+			CommitHash:    row.CommitHash,
+		})
+	}
+	return files, nil
+}
+
+// FindDirectories dir の直下のサブディレクトリを名前順に返す
+// パスの次の区切りまでと言語でまとめた件数・サイズを、ディレクトリごとに足し合わせる
+func (r *PostgresFileTreeRepository) FindDirectories(ctx context.Context, projectID uint, commit, dir string) ([]entities.TreeDirectory, error) {
+	var rows []struct {
+		Name      string
+		Language  string
+		FileCount int
+		Size      int64
+	}
+	err := r.underDir(ctx, projectID, commit, dir, true).
+		Select("split_part(substr(path, ?), '/', 1) AS name, language, COUNT(*) AS file_count, COALESCE(SUM(size), 0) AS size", restStart(dir)).
+		// name は files の列と同名のため、GROUP BY / ORDER BY は位置で指定する
+		Group("1, 2").
+		Order("1").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := dirPrefix(dir)
+	var directories []entities.TreeDirectory
+	for _, row := range rows {
+		if len(directories) == 0 || directories[len(directories)-1].Name != row.Name {
+			directories = append(directories, entities.TreeDirectory{
+				Name:      row.Name,
+				Path:      prefix + row.Name,
+				Languages: make(map[string]int),
+			})
+		}
+		directory := &directories[len(directories)-1]
+		language := row.Language
+		if language == "" {
+			language = "unknown"
+		}
+		directory.FileCount += row.FileCount
+		directory.Size += row.Size
+		directory.Languages[language] += row.FileCount
+	}
+	return directories, nil
+}
+
+// underDir dir 配下のファイルに絞り込むクエリ。nested が false なら直下のファイル、true ならより深いファイル
+func (r *PostgresFileTreeRepository) underDir(ctx context.Context, projectID uint, commit, dir string, nested bool) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.File{}).
+		Scopes(models.ProjectFiles(models.Project{ID: projectID}, commit))
+	if prefix := dirPrefix(dir); prefix != "" {
+		escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix)
+		query = query.Where("path LIKE ?", escaped+"%")
+	}
+	if nested {
+		return query.Where("strpos(substr(path, ?), '/') > 0", restStart(dir))
+	}
+	return query.Where("strpos(substr(path, ?), '/') = 0", restStart(dir))
+}
+
+func dirPrefix(dir string) string {
+	if dir == "" {
+		return ""
+	}
+	return dir + "/"
+}
+
+// restStart dir の下の部分が始まる位置（PostgreSQL の substr は 1 始まりの文字単位）
+func restStart(dir string) int {
+	return utf8.RuneCountInString(dirPrefix(dir)) + 1
+}
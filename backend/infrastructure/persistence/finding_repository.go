@@ -0,0 +1,177 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/domain/repositories"
+	"reverse-engineering-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// llmIssueAnalyses 結果に AnalysisResult.Issues を含む LLM 解析の種類
+var llmIssueAnalyses = map[string]bool{
+	"code_analysis":     true,
+	"pattern_detection": true,
+	"dependency_map":    true,
+}
+
+// PostgresFindingRepository issues テーブルと LLM 解析の結果から指摘をまとめる
+type PostgresFindingRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresFindingRepository GORM を使った指摘リポジトリを作成
+func NewPostgresFindingRepository(db *gorm.DB) repositories.FindingRepository {
+	return &PostgresFindingRepository{
+		db: db,
+	}
+}
+
+// FindByProject 種類ごとに最新の完了した解析の指摘を返す
+// シークレットスキャンはアップロードごとに実行され対象ファイルが異なるため、すべて含める
+func (r *PostgresFindingRepository) FindByProject(ctx context.Context, projectID uint) ([]entities.Finding, error) {
+	var analyses []models.Analysis
+	err := r.db.WithContext(ctx).
+		Select("id", "type").
+		Where("project_id = ? AND status = ?", projectID, "completed").
+		Order("id DESC").
+		Find(&analyses).Error
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var issueAnalysisIDs, llmAnalysisIDs []uint
+	for _, analysis := range analyses {
+		if analysis.Type != "secret_scan" {
+			if seen[analysis.Type] {
+				continue
+			}
+			seen[analysis.Type] = true
+		}
+		if llmIssueAnalyses[analysis.Type] {
+			llmAnalysisIDs = append(llmAnalysisIDs, analysis.ID)
+		} else {
+			issueAnalysisIDs = append(issueAnalysisIDs, analysis.ID)
+		}
+	}
+
+	var findings []entities.Finding
+	if len(issueAnalysisIDs) > 0 {
+		var issues []models.Issue
+		// 削除済みファイルの Issue は現在の指摘ではないため除外する
+		err := r.db.WithContext(ctx).
+			Where("analysis_id IN ?", issueAnalysisIDs).
+			Where("file_id IS NULL OR file_id IN (?)", r.db.Model(&models.File{}).Select("id").Where("project_id = ?", projectID)).
+			Order("id").
+			Find(&issues).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, issue := range issues {
+			findings = append(findings, IssueFinding(issue))
+		}
+	}
+
+	if len(llmAnalysisIDs) > 0 {
+		var llmAnalyses []models.Analysis
+		if err := r.db.WithContext(ctx).Where("id IN ?", llmAnalysisIDs).Order("id").Find(&llmAnalyses).Error; err != nil {
+			return nil, err
+		}
+		for _, analysis := range llmAnalyses {
+			findings = append(findings, llmFindings(analysis)...)
+		}
+	}
+	return findings, nil
+}
+
+// IssueFinding 保存された Issue を指摘に変換
+func IssueFinding(issue models.Issue) entities.Finding {
+	var metadata map[string]interface{}
+	_ = json.Unmarshal([]byte(issue.Metadata), &metadata)
+	str := func(key string) string {
+		s, _ := metadata[key].(string)
+		return s
+	}
+	num := func(key string) int {
+		n, _ := metadata[key].(float64)
+		return int(n)
+	}
+
+	finding := entities.Finding{
+		Source:     issue.Source,
+		RuleID:     issue.RuleID,
+		Severity:   issue.Severity,
+		Message:    issue.Message,
+		Path:       issue.Path,
+		StartLine:  issue.StartLine,
+		EndLine:    issue.EndLine,
+		AnalysisID: issue.AnalysisID,
+	}
+
+	// 行番号に依存しない識別子をソースごとに組み立てる
+	switch issue.Source {
+	case "secret":
+		finding.RuleName = str("description")
+		finding.StartColumn = num("start_column")
+		finding.EndColumn = num("end_column")
+		finding.FingerprintKey = strings.Join([]string{issue.Source, issue.RuleID, issue.Path, str("fingerprint")}, "|")
+	case "sast":
+		finding.CWE = str("cwe")
+		finding.Snippet = str("snippet")
+		finding.FingerprintKey = strings.Join([]string{issue.Source, issue.RuleID, issue.Path, strings.Join(strings.Fields(finding.Snippet), " ")}, "|")
+	case "osv":
+		finding.FingerprintKey = strings.Join([]string{issue.Source, issue.RuleID, issue.Path, str("ecosystem"), str("package"), str("version")}, "|")
+	default:
+		finding.FingerprintKey = strings.Join([]string{issue.Source, issue.RuleID, issue.Path, issue.Message}, "|")
+	}
+	return finding
+}
+
+// llmFindings LLM 解析の結果から AnalysisResult.Issues を取り出す
+// ファイル単位の解析はファイルごとの結果のリスト、dependency_map はプロジェクト全体の
+// 1 つの結果を保存している
+func llmFindings(analysis models.Analysis) []entities.Finding {
+	type fileResult struct {
+		Name   string                   `json:"name"`
+		Result *entities.AnalysisResult `json:"result"`
+	}
+
+	var results []fileResult
+	if analysis.Type == "dependency_map" {
+		var result entities.AnalysisResult
+		if err := json.Unmarshal([]byte(analysis.Result), &result); err != nil {
+			return nil
+		}
+		results = append(results, fileResult{Result: &result})
+	} else if err := json.Unmarshal([]byte(analysis.Result), &results); err != nil {
+		return nil
+	}
+
+	var findings []entities.Finding
+	for _, result := range results {
+		if result.Result == nil {
+			continue
+		}
+		for _, message := range result.Result.Issues {
+			message = strings.TrimSpace(message)
+			if message == "" {
+				continue
+			}
+			findings = append(findings, entities.Finding{
+				Source:         "llm",
+				RuleID:         "llm/" + analysis.Type,
+				Severity:       "info",
+				Message:        message,
+				Path:           result.Name,
+				FingerprintKey: strings.Join([]string{"llm", analysis.Type, result.Name, strings.ToLower(strings.Join(strings.Fields(message), " "))}, "|"),
+				AnalysisID:     analysis.ID,
+			})
+		}
+	}
+	return findings
+}
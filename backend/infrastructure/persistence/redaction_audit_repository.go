@@ -0,0 +1,51 @@
+package persistence
+
+import (
+	"context"
+	"encoding/json"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/domain/repositories"
+	"reverse-engineering-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// PostgresRedactionAuditRepository マスキングの監査を redaction_audits テーブルに保存する
+type PostgresRedactionAuditRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresRedactionAuditRepository GORM を使ったマスキング監査リポジトリを作成
+func NewPostgresRedactionAuditRepository(db *gorm.DB) repositories.RedactionAuditRepository {
+	return &PostgresRedactionAuditRepository{
+		db: db,
+	}
+}
+
+// SaveAudit 外部へのリクエスト 1 件を記録
+func (r *PostgresRedactionAuditRepository) SaveAudit(ctx context.Context, audit entities.RedactionAudit) error {
+	events, err := json.Marshal(audit.Events)
+	if err != nil {
+		return err
+	}
+
+	redactions := 0
+	for _, event := range audit.Events {
+		redactions += event.Occurrences
+	}
+
+	row := models.RedactionAudit{
+		RequestID:    audit.RequestID,
+		Operation:    audit.Operation,
+		Model:        audit.Model,
+		PromptSHA256: audit.PromptSHA256,
+		PromptChars:  audit.PromptChars,
+		Redactions:   redactions,
+		Events:       string(events),
+		Residual:     audit.Residual,
+		Blocked:      audit.Blocked,
+		CreatedAt:    audit.CreatedAt,
+	}
+	return r.db.WithContext(ctx).Create(&row).Error
+}
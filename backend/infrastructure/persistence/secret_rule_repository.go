@@ -0,0 +1,57 @@
+package persistence
+
+import (
+	"context"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/domain/repositories"
+	"reverse-engineering-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// PostgresSecretRuleRepository secret_rules テーブルからカスタムルールを読み込む
+type PostgresSecretRuleRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresSecretRuleRepository GORM を使ったシークレットルールリポジトリを作成
+func NewPostgresSecretRuleRepository(db *gorm.DB) repositories.SecretRuleRepository {
+	return &PostgresSecretRuleRepository{
+		db: db,
+	}
+}
+
+// FindByProject プロジェクトのカスタムルールをルール ID 順に返す
+func (r *PostgresSecretRuleRepository) FindByProject(ctx context.Context, projectID uint) ([]entities.SecretRule, error) {
+	var rows []models.SecretRule
+	if err := r.db.WithContext(ctx).Where("project_id = ?", projectID).Order("rule_id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	rules := make([]entities.SecretRule, 0, len(rows))
+	for _, row := range rows {
+		rules = append(rules, SecretRuleEntity(row))
+	}
+	return rules, nil
+}
+
+// SecretRuleEntity 保存されたルールをドメインエンティティに変換
+func SecretRuleEntity(row models.SecretRule) entities.SecretRule {
+	var keywords []string
+	for _, keyword := range strings.Split(row.Keywords, ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			keywords = append(keywords, keyword)
+		}
+	}
+	return entities.SecretRule{
+		ID:          row.RuleID,
+		Description: row.Description,
+		Pattern:     row.Pattern,
+		SecretGroup: row.SecretGroup,
+		Keywords:    keywords,
+		MinEntropy:  row.MinEntropy,
+		Severity:    row.Severity,
+	}
+}
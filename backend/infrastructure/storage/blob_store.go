@@ -0,0 +1,382 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultGracePeriod 参照されなくなった blob を削除するまでの猶予期間
+// アップロード中の blob が削除されないようにする
+const DefaultGracePeriod = time.Hour
+
+var hashPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// BlobStore ファイルの内容を SHA-256 ハッシュごとに 1 つだけディスクに保存し、
+// 各 blob を参照するファイル数を blobs テーブルで管理する
+type BlobStore struct {
+	db   *gorm.DB
+	root string
+}
+
+// NewBlobStore BLOB_PATH（未設定の場合は UPLOAD_PATH/blobs）に blob ストアを作成
+func NewBlobStore(db *gorm.DB) *BlobStore {
+	root := os.Getenv("BLOB_PATH")
+	if root == "" {
+		uploadPath := os.Getenv("UPLOAD_PATH")
+		if uploadPath == "" {
+			uploadPath = "./uploads"
+		}
+		root = filepath.Join(uploadPath, "blobs")
+	}
+	return &BlobStore{
+		db:   db,
+		root: root,
+	}
+}
+
+// Path blob のディスク上のパスを返す
+func (s *BlobStore) Path(hash string) string {
+	// 1 つのディレクトリにファイルが集中しないよう、先頭 4 文字で 2 段に分ける
+	return filepath.Join(s.root, hash[:2], hash[2:4], hash)
+}
+
+// Put srcPath のファイルをストアに移動し、ハッシュとサイズを返す
+// 同じ内容が保存済みの場合は srcPath を削除するだけ。blob はそのファイルに対して
+// Acquire を呼ぶまで参照されない
+func (s *BlobStore) Put(srcPath string) (string, int64, error) {
+	hash, size, err := hashFile(srcPath)
+	if err != nil {
+		return "", 0, err
+	}
+
+	dst := s.Path(hash)
+	if s.touch(dst) {
+		return hash, size, os.Remove(srcPath)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", 0, err
+	}
+	// 同じファイルシステムなら移動し、そうでなければ一時ファイルにコピーしてから置き換える
+	if err := os.Rename(srcPath, dst); err == nil {
+		return hash, size, nil
+	}
+	if err := s.copyFile(dst, srcPath); err != nil {
+		return "", 0, err
+	}
+	return hash, size, os.Remove(srcPath)
+}
+
+// PutBytes データを保存してハッシュを返す
+func (s *BlobStore) PutBytes(data []byte) (string, error) {
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	dst := s.Path(hash)
+	if s.touch(dst) {
+		return hash, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", err
+	}
+	err := s.writeAtomic(dst, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	return hash, err
+}
+
+// Locate ファイルの内容のディスク上のパスを返す
+// blob があればそのパス、ストア外に置かれた内容（隔離ファイル）は StoragePath
+func (s *BlobStore) Locate(file models.File) string {
+	if file.BlobHash != "" {
+		return s.Path(file.BlobHash)
+	}
+	return file.StoragePath
+}
+
+// ReadText テキストファイルの内容を UTF-8 に変換して返す
+// バイナリファイルと隔離ファイルにはテキストがないため空文字列を返す
+func (s *BlobStore) ReadText(file models.File) (string, error) {
+	if file.Content != "" {
+		// 以前の形式では DB に内容を保存していた
+		return file.Content, nil
+	}
+	if file.Encoding == "" || file.Quarantined {
+		return "", nil
+	}
+	data, err := os.ReadFile(s.Locate(file))
+	if err != nil {
+		return "", err
+	}
+	return utils.DecodeToUTF8(data, utils.EncodingDetection{Encoding: file.Encoding, BOM: file.EncodingBOM})
+}
+
+// LoadContents 解析できるよう、テキストファイルの Content を blob から読み込む
+func (s *BlobStore) LoadContents(files []models.File) error {
+	for i := range files {
+		text, err := s.ReadText(files[i])
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", files[i].Path, err)
+		}
+		files[i].Content = text
+	}
+	return nil
+}
+
+// Acquire blob を持つ各ファイルについて参照を追加する
+// ファイルを作成するトランザクション内で呼ぶこと
+func (s *BlobStore) Acquire(tx *gorm.DB, files []models.File) error {
+	blobs := countBlobs(files)
+	if len(blobs) == 0 {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "hash"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"ref_count":  gorm.Expr("blobs.ref_count + excluded.ref_count"),
+			"updated_at": gorm.Expr("excluded.updated_at"),
+		}),
+	}).Create(&blobs).Error
+}
+
+// Release 削除するファイルの参照を外す
+// 参照数が 0 になった blob は CollectGarbage が削除する
+func (s *BlobStore) Release(tx *gorm.DB, files []models.File) error {
+	for _, blob := range countBlobs(files) {
+		err := tx.Model(&models.Blob{}).
+			Where("hash = ?", blob.Hash).
+			Updates(map[string]interface{}{
+				"ref_count":  gorm.Expr("GREATEST(ref_count - ?, 0)", blob.RefCount),
+				"updated_at": time.Now(),
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stats blob の集計と重複排除で節約できた容量を返す
+func (s *BlobStore) Stats(ctx context.Context) (*entities.BlobStats, error) {
+	var stats entities.BlobStats
+	err := s.db.WithContext(ctx).Model(&models.Blob{}).
+		Select("COUNT(*) AS blobs, COALESCE(SUM(size), 0) AS size, COALESCE(SUM(ref_count), 0) AS \"references\", " +
+			"COALESCE(SUM(size * ref_count), 0) AS logical_size, COUNT(*) FILTER (WHERE ref_count = 0) AS unreferenced").
+		Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// CollectGarbage files テーブルから各 blob の参照数を数え直し、grace より古い
+// 参照されていない blob と、行のない blob ファイルを削除する
+func (s *BlobStore) CollectGarbage(ctx context.Context, grace time.Duration) (*entities.BlobCollection, error) {
+	result := &entities.BlobCollection{}
+	db := s.db.WithContext(ctx)
+
+	// 参照数の更新漏れがあっても、実際に参照しているファイルから数え直す
+	recount := db.Exec(`UPDATE blobs SET ref_count = counted.n, updated_at = NOW()
+		FROM (SELECT blobs.hash, COUNT(files.id) AS n FROM blobs
+			LEFT JOIN files ON files.blob_hash = blobs.hash AND files.deleted_at IS NULL
+			GROUP BY blobs.hash) AS counted
+		WHERE blobs.hash = counted.hash AND blobs.ref_count <> counted.n`)
+	if recount.Error != nil {
+		return nil, recount.Error
+	}
+	result.Recounted = recount.RowsAffected
+
+	// 参照のなくなった Blob は、猶予期間を過ぎてから行とファイルを削除する
+	cutoff := time.Now().Add(-grace)
+	var unreferenced []models.Blob
+	err := db.Clauses(clause.Returning{}).
+		Where("ref_count <= 0 AND updated_at < ?", cutoff).
+		Delete(&unreferenced).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, blob := range unreferenced {
+		removed, err := s.removeIfOlder(s.Path(blob.Hash), cutoff)
+		if err != nil {
+			log.Printf("Failed to remove blob %s: %v", blob.Hash, err)
+			continue
+		}
+		if removed {
+			result.Removed++
+			result.FreedBytes += blob.Size
+		}
+	}
+
+	// Acquire されないまま残ったファイル（保存に失敗したアップロードなど）を削除する
+	var hashes []string
+	if err := db.Model(&models.Blob{}).Pluck("hash", &hashes).Error; err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(hashes))
+	for _, hash := range hashes {
+		known[hash] = true
+	}
+	err = filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() || !hashPattern.MatchString(d.Name()) || known[d.Name()] {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if removed, err := s.removeIfOlder(path, cutoff); err == nil && removed {
+			result.OrphansRemoved++
+			result.FreedBytes += info.Size()
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// StartGarbageCollector ctx がキャンセルされるまで interval ごとに CollectGarbage を実行
+func (s *BlobStore) StartGarbageCollector(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.CollectGarbage(ctx, DefaultGracePeriod)
+			if err != nil {
+				log.Printf("Blob garbage collection failed: %v", err)
+				continue
+			}
+			if result.Removed > 0 || result.OrphansRemoved > 0 {
+				log.Printf("Blob garbage collection removed %d blobs and %d orphans (%d bytes)",
+					result.Removed, result.OrphansRemoved, result.FreedBytes)
+			}
+		}
+	}
+}
+
+// touch blob が存在するかを返し、更新日時を新しくする
+// 同時に実行中のガベージコレクションで削除されないようにするため
+func (s *BlobStore) touch(path string) bool {
+	if _, err := os.Stat(path); err != nil {
+		return false
+	}
+	now := time.Now()
+	return os.Chtimes(path, now, now) == nil
+}
+
+// removeIfOlder cutoff 以降に保存・touch されていなければ blob ファイルを削除
+func (s *BlobStore) removeIfOlder(path string, cutoff time.Time) (bool, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if info.ModTime().After(cutoff) {
+		return false, nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, err
+	}
+	return true, nil
+}
+
+// writeAtomic 同じディレクトリの一時ファイルを経由して blob を書き込む
+// 書き込み途中の blob が読まれることはない
+func (s *BlobStore) writeAtomic(dst string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// copyFile srcPath のコピーを dst の blob に書き込む
+func (s *BlobStore) copyFile(dst, srcPath string) error {
+	return s.writeAtomic(dst, func(w io.Writer) error {
+		src, err := os.Open(srcPath)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		_, err = io.Copy(w, src)
+		return err
+	})
+}
+
+// hashFile path のファイルの SHA-256 とサイズを返す
+func hashFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, err
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// countBlobs ファイルを blob ごとにまとめ、参照しているファイル数を数える
+func countBlobs(files []models.File) []models.Blob {
+	index := make(map[string]int)
+	var blobs []models.Blob
+	now := time.Now()
+	for _, file := range files {
+		if file.BlobHash == "" {
+			continue
+		}
+		if i, ok := index[file.BlobHash]; ok {
+			blobs[i].RefCount++
+			continue
+		}
+		index[file.BlobHash] = len(blobs)
+		blobs = append(blobs, models.Blob{Hash: file.BlobHash, Size: file.Size, RefCount: 1, CreatedAt: now, UpdatedAt: now})
+	}
+	return blobs
+}
@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// legacyBatchSize 一度に移行するファイル数
+const legacyBatchSize = 100
+
+// MigrateLegacyFiles blob ストア導入前に保存されたファイルの内容をストアに移し、
+// blob を持つすべてのファイルの Content を空にする
+// ストアに保存済みのファイルは飛ばすため、起動のたびに実行してよい
+func (s *BlobStore) MigrateLegacyFiles(ctx context.Context) error {
+	db := s.db.WithContext(ctx)
+
+	// Blob に保存済みのファイルは DB の内容の重複を削除する
+	cleared := db.Model(&models.File{}).
+		Where("blob_hash <> '' AND (content <> '' OR storage_path <> '')").
+		Updates(map[string]interface{}{"content": "", "storage_path": ""})
+	if cleared.Error != nil {
+		return cleared.Error
+	}
+
+	// 移行したファイルのパス。同じパスを複数の行が参照していることがあるため、すべて移行してから削除する
+	migrated := make(map[string]bool)
+	var moved, skipped int
+	lastID := uint(0)
+	for {
+		var files []models.File
+		err := db.Where("blob_hash = '' AND quarantined = ? AND id > ?", false, lastID).
+			Order("id").Limit(legacyBatchSize).Find(&files).Error
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			break
+		}
+		for _, file := range files {
+			lastID = file.ID
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			ok, err := s.migrateLegacyFile(db, file)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				skipped++
+				continue
+			}
+			moved++
+			if file.StoragePath != "" {
+				migrated[file.StoragePath] = true
+			}
+		}
+	}
+
+	for storagePath := range migrated {
+		var count int64
+		if err := db.Model(&models.File{}).Where("storage_path = ?", storagePath).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if err := os.Remove(storagePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Failed to remove migrated file %s: %v", storagePath, err)
+			continue
+		}
+		utils.RemoveEmptyDirs(filepath.Dir(storagePath))
+	}
+
+	if moved > 0 || skipped > 0 || cleared.RowsAffected > 0 {
+		log.Printf("Blob migration moved %d files, skipped %d without content, cleared %d duplicated contents",
+			moved, skipped, cleared.RowsAffected)
+	}
+	return nil
+}
+
+// migrateLegacyFile 1 ファイルの内容を blob として保存し、ファイルから参照させる
+// 内容がもう存在しない場合は false を返す
+func (s *BlobStore) migrateLegacyFile(db *gorm.DB, file models.File) (bool, error) {
+	var hash string
+	var size int64
+	var err error
+	switch {
+	case file.Content != "":
+		// 再アップロードでディスク上のファイルが上書きされていることがあるため、テキストは DB の内容を正とする
+		encoding := file.Encoding
+		if encoding == "" {
+			encoding = utils.EncodingUTF8
+		}
+		data, encodeErr := utils.EncodeFromUTF8(file.Content, encoding, file.EncodingBOM)
+		if encodeErr != nil {
+			data, encoding = []byte(file.Content), utils.EncodingUTF8
+		}
+		file.Encoding = encoding
+		hash, err = s.PutBytes(data)
+		size = int64(len(data))
+	case file.StoragePath != "":
+		hash, size, err = s.copyIn(file.StoragePath)
+		if errors.Is(err, fs.ErrNotExist) {
+			log.Printf("Content of file %d (%s) no longer exists, not migrated", file.ID, file.Path)
+			return false, nil
+		}
+	default:
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	file.BlobHash = hash
+	file.Size = size
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.File{}).Where("id = ?", file.ID).Updates(map[string]interface{}{
+			"blob_hash":    hash,
+			"size":         size,
+			"encoding":     file.Encoding,
+			"content":      "",
+			"storage_path": "",
+		}).Error
+		if err != nil {
+			return err
+		}
+		return s.Acquire(tx, []models.File{file})
+	})
+	return err == nil, err
+}
+
+// copyIn srcPath のファイルをストアにコピーする（srcPath は残す）
+func (s *BlobStore) copyIn(srcPath string) (string, int64, error) {
+	hash, size, err := hashFile(srcPath)
+	if err != nil {
+		return "", 0, err
+	}
+	dst := s.Path(hash)
+	if s.touch(dst) {
+		return hash, size, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", 0, err
+	}
+	return hash, size, s.copyFile(dst, srcPath)
+}
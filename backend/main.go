@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"reverse-engineering-backend/config"
 	"reverse-engineering-backend/controllers"
 	"reverse-engineering-backend/infrastructure/external/chromadb"
 	"reverse-engineering-backend/infrastructure/external/openai"
+	"reverse-engineering-backend/infrastructure/persistence"
+	"reverse-engineering-backend/infrastructure/storage"
 	"reverse-engineering-backend/routes"
+	"reverse-engineering-backend/workers"
+	"time"
 
 	"reverse-engineering-backend/usecases/rag"
 
@@ -35,7 +40,7 @@ func main() {
 	}
 
 	// インフラストラクチャ層の初期化
-	llmService := openai.NewOpenAIService()
+	llmService := openai.NewOpenAIService(persistence.NewPostgresRedactionAuditRepository(db))
 	vectorRepo := chromadb.NewChromaDBVectorRepository(
 		os.Getenv("CHROMADB_URL"),
 		"project_knowledge_base",
@@ -50,6 +55,28 @@ func main() {
 		log.Printf("Warning: Failed to initialize RAG service: %v", err)
 	}
 
+	// 解析ワーカーの起動
+	analysisWorker := workers.NewAnalysisWorker(db, redis, llmService, ragIndexingUseCase)
+	go analysisWorker.Start(context.Background())
+
+	// 参照されなくなった Blob の定期回収
+	gcInterval := time.Hour
+	if value := os.Getenv("BLOB_GC_INTERVAL"); value != "" {
+		if d, err := time.ParseDuration(value); err == nil && d > 0 {
+			gcInterval = d
+		} else {
+			log.Printf("Warning: invalid BLOB_GC_INTERVAL %q, using %s", value, gcInterval)
+		}
+	}
+	blobStore := storage.NewBlobStore(db)
+	go func() {
+		// 以前の形式で保存されたファイルを Blob ストアに移してから回収を始める
+		if err := blobStore.MigrateLegacyFiles(context.Background()); err != nil {
+			log.Printf("Warning: Failed to migrate files to the blob store: %v", err)
+		}
+		blobStore.StartGarbageCollector(context.Background(), gcInterval)
+	}()
+
 	// コントローラー層の初期化
 	ragController := controllers.NewRAGController(ragQueryUseCase, ragIndexingUseCase)
 
@@ -59,6 +86,8 @@ func main() {
 	}
 
 	r := gin.Default()
+	// Go のシンボル名（github.com/x/y.Func）など、"/" を %2F でエンコードしたパスパラメータを扱えるようにする
+	r.UseRawPath = true
 
 	// CORS設定
 	corsConfig := cors.DefaultConfig()
@@ -67,7 +96,9 @@ func main() {
 		"http://127.0.0.1:3000",
 	}
 	corsConfig.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
-	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization"}
+	corsConfig.AllowHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "Range"}
+	// ファイル内容の分割取得（Range リクエスト）の結果をフロントエンドから参照できるようにする
+	corsConfig.ExposeHeaders = []string{"Content-Range", "Accept-Ranges", "Content-Length", "ETag"}
 	corsConfig.AllowCredentials = true
 
 	r.Use(cors.New(corsConfig))
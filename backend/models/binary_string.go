@@ -0,0 +1,15 @@
+package models
+
+// BinaryString binary_triage 解析でバイナリから抽出した文字列（件数が多いため解析結果とは別に保存する）
+type BinaryString struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	AnalysisID uint   `json:"analysis_id" gorm:"not null;index"`
+	FileID     uint   `json:"file_id" gorm:"not null;index"`
+	Offset     int64  `json:"offset"`
+	Encoding   string `json:"encoding" gorm:"not null"` // ascii, utf16le
+	Value      string `json:"value" gorm:"type:text"`
+	Length     int    `json:"length"`
+
+	// リレーション
+	Analysis Analysis `json:"-" gorm:"foreignKey:AnalysisID"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// Blob SHA-256 をキーにしたファイル内容。同じ内容のファイルはプロジェクトをまたいで 1 つの Blob を参照する
+type Blob struct {
+	Hash      string    `json:"hash" gorm:"primaryKey;size:64"`
+	Size      int64     `json:"size"`
+	RefCount  int64     `json:"ref_count" gorm:"not null;default:0;index"` // 参照している File の数
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+// Commit git リポジトリから取り込んだプロジェクトのコミット履歴
+type Commit struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	ProjectID      uint      `json:"project_id" gorm:"not null;index;uniqueIndex:idx_commit_project_hash"`
+	Hash           string    `json:"hash" gorm:"not null;uniqueIndex:idx_commit_project_hash"`
+	Parents        string    `json:"parents" gorm:"type:text"` // JSON 配列
+	AuthorName     string    `json:"author_name"`
+	AuthorEmail    string    `json:"author_email" gorm:"index"`
+	AuthoredAt     time.Time `json:"authored_at"`
+	CommitterName  string    `json:"committer_name"`
+	CommitterEmail string    `json:"committer_email"`
+	CommittedAt    time.Time `json:"committed_at" gorm:"index"`
+	Message        string    `json:"message" gorm:"type:text"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	// リレーション
+	Files []CommitFile `json:"files" gorm:"foreignKey:CommitID;constraint:OnDelete:CASCADE"`
+}
+
+// CommitFile コミットで変更されたファイル
+type CommitFile struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	CommitID  uint   `json:"commit_id" gorm:"not null;index"`
+	Path      string `json:"path" gorm:"not null;index"`
+	OldPath   string `json:"old_path,omitempty"`
+	Status    string `json:"status"` // added, modified, deleted, renamed, copied, type_changed
+	Additions int    `json:"additions"`
+	Deletions int    `json:"deletions"`
+	Binary    bool   `json:"binary"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+)
+
+// Issue 解析で検出された個々の問題（脆弱性・シークレット・ルール違反など）
+type Issue struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	AnalysisID uint      `json:"analysis_id" gorm:"not null;index"`
+	ProjectID  uint      `json:"project_id" gorm:"not null;index"`
+	FileID     *uint     `json:"file_id,omitempty" gorm:"index"`
+	Source     string    `json:"source" gorm:"not null"`   // osv, secret, sast
+	RuleID     string    `json:"rule_id" gorm:"not null"`  // アドバイザリ ID やルール ID
+	Severity   string    `json:"severity" gorm:"not null"` // critical, high, medium, low, info
+	Message    string    `json:"message" gorm:"type:text"`
+	Path       string    `json:"path"`
+	StartLine  int       `json:"start_line"`
+	EndLine    int       `json:"end_line"`
+	Metadata   string    `json:"metadata" gorm:"type:text"` // JSON
+	CreatedAt  time.Time `json:"created_at"`
+
+	// リレーション
+	Analysis Analysis `json:"-" gorm:"foreignKey:AnalysisID"`
+}
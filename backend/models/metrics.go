@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// CodeMetric 解析実行ごとのコードメトリクス（ファイル単位・関数単位）
+type CodeMetric struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	AnalysisID     uint      `json:"analysis_id" gorm:"not null;index"`
+	ProjectID      uint      `json:"project_id" gorm:"not null;index"`
+	FileID         uint      `json:"file_id" gorm:"not null"`
+	Path           string    `json:"path" gorm:"not null"`
+	Language       string    `json:"language"`
+	Scope          string    `json:"scope" gorm:"not null"` // file, function
+	FunctionName   string    `json:"function_name,omitempty"`
+	StartLine      int       `json:"start_line"`
+	EndLine        int       `json:"end_line"`
+	PhysicalLOC    int       `json:"physical_loc"`
+	LogicalLOC     int       `json:"logical_loc"`
+	CommentLines   int       `json:"comment_lines"`
+	CommentDensity float64   `json:"comment_density"`
+	Cyclomatic     int       `json:"cyclomatic"`
+	Cognitive      int       `json:"cognitive"`
+	MaxNesting     int       `json:"max_nesting"`
+	ParamCount     int       `json:"param_count"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	// リレーション
+	Analysis Analysis `json:"-" gorm:"foreignKey:AnalysisID"`
+}
@@ -16,6 +16,18 @@ type Project struct {
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// SecretPolicy アップロードされたファイルにシークレットが含まれていた場合の扱い
+	SecretPolicy string `json:"secret_policy" gorm:"default:quarantine"` // record, redact, quarantine, block
+
+	// Charset アップロードされたテキストの文字コードのヒント（auto なら自動判定）
+	Charset string `json:"charset" gorm:"default:auto"` // auto, utf-8, shift_jis, euc-jp, iso-2022-jp, utf-16le, utf-16be
+
+	// SourceType ファイルの取り込み元。git の場合は取り込んだ ref と、その解決先のコミットを持つ
+	SourceType     string `json:"source_type" gorm:"default:upload"` // upload, git
+	SourceRef      string `json:"source_ref,omitempty"`
+	HeadCommit     string `json:"head_commit,omitempty"`
+	RepositoryPath string `json:"-"` // 取り込んだリポジトリのミラー
+
 	// リレーション
 	User     User       `json:"user" gorm:"foreignKey:UserID"`
 	Files    []File     `json:"files" gorm:"foreignKey:ProjectID"`
@@ -25,25 +37,74 @@ type Project struct {
 type File struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
 	ProjectID uint           `json:"project_id" gorm:"not null"`
-	Name      string         `json:"name" gorm:"not null"`
-	Path      string         `json:"path" gorm:"not null"`
+	Name      string         `json:"name" gorm:"not null"` // ファイル名
+	Path      string         `json:"path" gorm:"not null"` // プロジェクト内の相対パス（/ 区切り）
 	Size      int64          `json:"size"`
 	MimeType  string         `json:"mime_type"`
-	Content   string         `json:"content,omitempty" gorm:"type:text"`
+	Content   string         `json:"content,omitempty" gorm:"type:text"` // 以前の形式で保存されたファイルのみ。それ以外は Blob から読み込む
 	Language  string         `json:"language"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// StoragePath Blob に保存していないファイル（隔離されたファイル）の内容のディスク上のパス
+	StoragePath string `json:"-"`
+
+	// Quarantined シークレットを含むため内容を保存せず、解析対象から除外されたファイル
+	Quarantined bool `json:"quarantined" gorm:"default:false"`
+
+	// Encoding アップロードされたファイルの元の文字コード。Content は UTF-8 に変換して保存し、書き出す際に元に戻す
+	Encoding    string `json:"encoding,omitempty"` // utf-8, shift_jis, euc-jp, iso-2022-jp, utf-16le, utf-16be
+	EncodingBOM bool   `json:"encoding_bom,omitempty"`
+
+	// LanguageConfidence 言語判定の確信度（0〜1）と根拠
+	LanguageConfidence float64 `json:"language_confidence"`
+	LanguageSource     string  `json:"language_source,omitempty"` // modeline, shebang, filename, template, extension, content, manual
+
+	// DerivedFromID 解析の過程で元のファイル（バンドルなど）から復元されたファイルの場合、その元ファイル
+	DerivedFromID *uint  `json:"derived_from_id,omitempty" gorm:"index"`
+	DerivedBy     string `json:"derived_by,omitempty"` // beautified, webpack_module, source_map, bundle_section
+
+	// CommitHash git から取り込んだファイルの場合、そのツリーのコミット
+	CommitHash string `json:"commit_hash,omitempty" gorm:"index"`
+
+	// BlobHash 内容を保存している Blob（SHA-256）。隔離されたファイルは空で、StoragePath に保存している
+	BlobHash string `json:"blob_hash,omitempty" gorm:"index"`
+
 	// リレーション
 	Project Project `json:"project" gorm:"foreignKey:ProjectID"`
 }
 
+// IsText テキストとして判定されたファイルかどうか（以前の形式では Content のみ保存されている）
+func (f File) IsText() bool {
+	return f.Encoding != "" || f.Content != ""
+}
+
+// ResolveCommit 対象とするコミットを返す。指定がなければ git から取り込んだプロジェクトは取り込んだ ref のコミット
+func (p Project) ResolveCommit(commit string) string {
+	if commit == "" && p.SourceType == "git" {
+		return p.HeadCommit
+	}
+	return commit
+}
+
+// ProjectFiles プロジェクトのファイルに絞り込むスコープ。git から取り込んだプロジェクトでは
+// commit（空なら取り込んだ ref）のツリーと、個別にアップロードされたファイルだけを対象にする
+func ProjectFiles(project Project, commit string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("project_id = ?", project.ID)
+		if commit = project.ResolveCommit(commit); commit != "" {
+			db = db.Where("commit_hash IN ?", []string{"", commit})
+		}
+		return db
+	}
+}
+
 type Analysis struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
 	ProjectID uint           `json:"project_id" gorm:"not null"`
 	FileID    *uint          `json:"file_id,omitempty"`
-	Type      string         `json:"type" gorm:"not null"`          // code_analysis, dependency_map, documentation, pattern_detection
+	Type      string         `json:"type" gorm:"not null"`          // code_analysis, dependency_map, documentation, pattern_detection, code_metrics, dead_code, vulnerability_scan, sast, binary_inspection, go_binary, debug_info, binary_triage, java_inventory, wasm_inspection, android_inspection, js_bundle, secret_scan (アップロード時に作成)
 	Status    string         `json:"status" gorm:"default:pending"` // pending, processing, completed, failed
 	Result    string         `json:"result,omitempty" gorm:"type:text"`
 	Metadata  string         `json:"metadata,omitempty" gorm:"type:json"`
@@ -51,6 +112,9 @@ type Analysis struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
+	// CommitHash git から取り込んだプロジェクトで解析対象とするコミット（空ならプロジェクトの HeadCommit）
+	CommitHash string `json:"commit_hash,omitempty"`
+
 	// リレーション
 	Project Project `json:"project" gorm:"foreignKey:ProjectID"`
 	File    *File   `json:"file,omitempty" gorm:"foreignKey:FileID"`
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// QualityGate プロジェクトごとの品質ゲート設定（NULL の閾値は無効）
+type QualityGate struct {
+	ID                          uint      `json:"id" gorm:"primaryKey"`
+	ProjectID                   uint      `json:"project_id" gorm:"not null;uniqueIndex"`
+	MaxHighIssues               *int      `json:"max_high_issues"`
+	MaxComplexity               *int      `json:"max_complexity"`
+	NoNewVulnerableDependencies bool      `json:"no_new_vulnerable_dependencies"`
+	NoSecrets                   bool      `json:"no_secrets"`
+	Analyses                    string    `json:"analyses"` // カンマ区切り
+	TimeoutSeconds              int       `json:"timeout_seconds" gorm:"default:600"`
+	CreatedAt                   time.Time `json:"created_at"`
+	UpdatedAt                   time.Time `json:"updated_at"`
+}
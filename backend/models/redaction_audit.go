@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// RedactionAudit LLM への送信ごとのマスキング監査ログ（元の値は保存せずハッシュのみ）
+type RedactionAudit struct {
+	ID           uint      `json:"id" gorm:"primaryKey"`
+	RequestID    string    `json:"request_id" gorm:"uniqueIndex;not null"`
+	Operation    string    `json:"operation" gorm:"index"` // generate_answer, analyze_code, embedding, ...
+	Model        string    `json:"model"`
+	PromptSHA256 string    `json:"prompt_sha256"`
+	PromptChars  int       `json:"prompt_chars"`
+	Redactions   int       `json:"redactions"`
+	Events       string    `json:"events" gorm:"type:text"` // JSON（種別・プレースホルダー・フィンガープリント）
+	Residual     int       `json:"residual"`
+	Blocked      bool      `json:"blocked"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+}
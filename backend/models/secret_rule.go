@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// SecretRule プロジェクト固有のシークレット検出ルール（組み込みルールと同じ ID で上書き可能）
+type SecretRule struct {
+	ID          uint      `json:"id" gorm:"primaryKey"`
+	ProjectID   uint      `json:"project_id" gorm:"not null;uniqueIndex:idx_secret_rule_project_rule"`
+	RuleID      string    `json:"rule_id" gorm:"not null;uniqueIndex:idx_secret_rule_project_rule"`
+	Description string    `json:"description"`
+	Pattern     string    `json:"pattern" gorm:"type:text;not null"`
+	SecretGroup int       `json:"secret_group"`
+	Keywords    string    `json:"keywords"` // カンマ区切り
+	MinEntropy  float64   `json:"min_entropy"`
+	Severity    string    `json:"severity" gorm:"default:high"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// Vulnerability ローカルに取り込んだ OSV アドバイザリ
+type Vulnerability struct {
+	ID        string    `json:"id" gorm:"primaryKey"` // OSV ID (GHSA-..., GO-..., PYSEC-...)
+	Summary   string    `json:"summary"`
+	Details   string    `json:"details" gorm:"type:text"`
+	Aliases   string    `json:"aliases" gorm:"type:text"` // JSON 配列 (CVE など)
+	Severity  string    `json:"severity"`                 // critical, high, medium, low
+	CVSSScore float64   `json:"cvss_score"`
+	Published time.Time `json:"published"`
+	Modified  time.Time `json:"modified"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// リレーション
+	Affected []VulnerabilityAffected `json:"affected" gorm:"foreignKey:VulnerabilityID;constraint:OnDelete:CASCADE"`
+}
+
+// VulnerabilityAffected アドバイザリが影響するパッケージとバージョン範囲
+type VulnerabilityAffected struct {
+	ID              uint   `json:"id" gorm:"primaryKey"`
+	VulnerabilityID string `json:"vulnerability_id" gorm:"not null;index"`
+	Ecosystem       string `json:"ecosystem" gorm:"not null;index:idx_vulnerability_affected_package"`
+	PackageName     string `json:"package_name" gorm:"not null;index:idx_vulnerability_affected_package"` // エコシステムごとに正規化した名前
+	Ranges          string `json:"ranges" gorm:"type:text"`                                               // JSON ([]entities.AffectedRange)
+	Versions        string `json:"versions" gorm:"type:text"`                                             // JSON 配列
+}
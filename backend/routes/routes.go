@@ -13,6 +13,20 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, redis *redis.Client, ragController
 	projectController := controllers.NewProjectController(db, redis)
 	fileController := controllers.NewFileController(db)
 	analysisController := controllers.NewAnalysisController(db, redis)
+	metricsController := controllers.NewMetricsController(db)
+	callGraphController := controllers.NewCallGraphController(db)
+	sbomController := controllers.NewSBOMController(db)
+	issueController := controllers.NewIssueController(db)
+	secretRuleController := controllers.NewSecretRuleController(db)
+	redactionAuditController := controllers.NewRedactionAuditController(db)
+	findingsController := controllers.NewFindingsController(db)
+	qualityGateController := controllers.NewQualityGateController(db, redis)
+	disassemblyController := controllers.NewDisassemblyController(db)
+	debugInfoController := controllers.NewDebugInfoController(db)
+	binaryStringController := controllers.NewBinaryStringController(db)
+	gitController := controllers.NewGitController(db)
+	fileTreeController := controllers.NewFileTreeController(db)
+	blobController := controllers.NewBlobController(db)
 
 	// ヘルスチェック
 	r.GET("/health", func(c *gin.Context) {
@@ -33,15 +47,40 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, redis *redis.Client, ragController
 			projects.GET("/:id", projectController.GetProject)
 			projects.PUT("/:id", projectController.UpdateProject)
 			projects.DELETE("/:id", projectController.DeleteProject)
+			projects.GET("/:id/hotspots", metricsController.GetHotspots)
+			projects.GET("/:id/callgraph", callGraphController.GetCallGraph)
+			projects.GET("/:id/sbom", sbomController.GetSBOM)
+			projects.GET("/:id/secret-rules", secretRuleController.GetSecretRules)
+			projects.POST("/:id/secret-rules", secretRuleController.SaveSecretRule)
+			projects.DELETE("/:id/secret-rules/:rule_id", secretRuleController.DeleteSecretRule)
+			projects.GET("/:id/findings.sarif", findingsController.GetSARIF)
+			projects.GET("/:id/quality-gate", qualityGateController.GetQualityGate)
+			projects.PUT("/:id/quality-gate", qualityGateController.UpdateQualityGate)
+			projects.POST("/:id/gate", qualityGateController.RunQualityGate)
+			projects.POST("/:id/git-import", gitController.ImportRepository)
+			projects.GET("/:id/commits", gitController.GetCommits)
+			projects.GET("/:id/tree", fileTreeController.GetTree)
 		}
 
 		// ファイル管理
 		files := v1.Group("/files")
 		{
 			files.POST("/upload", fileController.UploadFiles)
+			files.POST("/upload-archive", fileController.UploadArchive)
 			files.GET("/project/:project_id", fileController.GetFilesByProject)
 			files.GET("/:id", fileController.GetFile)
 			files.DELETE("/:id", fileController.DeleteFile)
+			files.POST("/:id/reclassify", fileController.ReclassifyFile)
+			files.GET("/:id/raw", fileTreeController.GetRawContent)
+			files.GET("/:id/functions/:symbol/disasm", disassemblyController.GetFunctionDisassembly)
+			files.GET("/:id/debug-info", debugInfoController.GetDebugInfo)
+		}
+
+		// 重複排除したファイル内容の保存領域
+		blobs := v1.Group("/blobs")
+		{
+			blobs.GET("/stats", blobController.GetStats)
+			blobs.POST("/gc", blobController.CollectGarbage)
 		}
 
 		// AI解析
@@ -51,6 +90,8 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, redis *redis.Client, ragController
 			analysis.GET("/project/:project_id", analysisController.GetAnalysisByProject)
 			analysis.GET("/:id", analysisController.GetAnalysis)
 			analysis.GET("/:id/status", analysisController.GetAnalysisStatus)
+			analysis.GET("/:id/issues", issueController.GetAnalysisIssues)
+			analysis.GET("/:id/strings", binaryStringController.GetAnalysisStrings)
 		}
 
 		// RAG機能
@@ -61,5 +102,8 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, redis *redis.Client, ragController
 			rag.GET("/search", ragController.Search)
 			rag.GET("/health", ragController.HealthCheck)
 		}
+
+		// LLM 送信時のマスキング監査
+		v1.GET("/redaction-audits", redactionAuditController.GetRedactionAudits)
 	}
 }
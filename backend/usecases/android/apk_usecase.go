@@ -0,0 +1,362 @@
+package android
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/usecases/java"
+)
+
+const (
+	// maxManifestSize と maxDexSize は展開するエントリーの上限
+	maxManifestSize = 8 << 20
+	maxDexSize      = 128 << 20
+	// maxClasses を超えるクラスは一覧に含めない（件数だけ数える）
+	maxClasses = 20000
+	// maxAndroidDocuments と classesPerDocument は RAG に登録する文書の上限と 1 文書あたりのクラス数
+	maxAndroidDocuments = 500
+	classesPerDocument  = 20
+)
+
+// dexEntryPattern APK のルートにある classes.dex、classes2.dex、... にマッチする
+var dexEntryPattern = regexp.MustCompile(`^classes\d*\.dex$`)
+
+// libraryPackages ナレッジベースから除く Android フレームワークと一般的なライブラリの
+// パッケージのプレフィックス
+var libraryPackages = []string{
+	"android.", "androidx.", "kotlin.", "kotlinx.", "java.", "javax.",
+	"com.google.android.", "com.google.firebase.", "com.google.gson.",
+	"okhttp3.", "okio.", "retrofit2.",
+}
+
+// ApkInspectionUseCase APK のマニフェストをデコードし、DEX ファイルのクラスとメソッドを一覧にする
+type ApkInspectionUseCase struct{}
+
+// NewApkInspectionUseCase APK 解析のユースケースを作成
+func NewApkInspectionUseCase() *ApkInspectionUseCase {
+	return &ApkInspectionUseCase{}
+}
+
+// IsApkFile .apk 拡張子の zip アーカイブかを判定
+func IsApkFile(name, filePath string) bool {
+	if strings.ToLower(filepath.Ext(name)) != ".apk" {
+		return false
+	}
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return false
+	}
+	defer reader.Close()
+	for _, entry := range reader.File {
+		if entry.Name == "AndroidManifest.xml" {
+			return true
+		}
+	}
+	return false
+}
+
+// Execute filePath に保存された APK を解析
+func (uc *ApkInspectionUseCase) Execute(ctx context.Context, filePath string) (*entities.AndroidInspection, error) {
+	reader, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	inspection := &entities.AndroidInspection{
+		Permissions:     []string{},
+		Components:      []entities.AndroidComponent{},
+		Dex:             []entities.DexFile{},
+		NativeLibraries: []string{},
+		Packages:        []entities.JavaPackage{},
+		Classes:         []entities.JavaClass{},
+		Errors:          []entities.JavaParseError{},
+	}
+	addError := func(source string, err error) {
+		inspection.Errors = append(inspection.Errors, entities.JavaParseError{Source: source, Error: err.Error()})
+	}
+
+	manifestFound := false
+	for _, entry := range reader.File {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		switch {
+		case entry.Name == "AndroidManifest.xml":
+			manifestFound = true
+			data, err := readEntry(entry, maxManifestSize)
+			if err != nil {
+				addError(entry.Name, err)
+				continue
+			}
+			root, err := parseBinaryXML(data)
+			if err != nil {
+				addError(entry.Name, err)
+				continue
+			}
+			readManifest(root, inspection)
+		case dexEntryPattern.MatchString(entry.Name):
+			data, err := readEntry(entry, maxDexSize)
+			if err != nil {
+				addError(entry.Name, err)
+				continue
+			}
+			classes, dex, err := java.ParseDex(data, entry.Name)
+			if err != nil {
+				addError(entry.Name, err)
+			}
+			if dex != nil {
+				inspection.Dex = append(inspection.Dex, *dex)
+			}
+			for _, class := range classes {
+				inspection.ClassesTotal++
+				if len(inspection.Classes) >= maxClasses {
+					inspection.Capped = true
+					continue
+				}
+				inspection.Classes = append(inspection.Classes, class)
+			}
+		case strings.HasPrefix(entry.Name, "lib/") && strings.HasSuffix(entry.Name, ".so"):
+			inspection.NativeLibraries = append(inspection.NativeLibraries, entry.Name)
+		}
+	}
+	if !manifestFound {
+		return nil, fmt.Errorf("AndroidManifest.xml not found")
+	}
+
+	sort.Slice(inspection.Dex, func(i, j int) bool {
+		return dexOrder(inspection.Dex[i].Name) < dexOrder(inspection.Dex[j].Name)
+	})
+	inspection.Packages = java.Packages(inspection.Classes)
+	return inspection, nil
+}
+
+// dexOrder classes.dex (1)、classes2.dex (2)、... の順番を返す
+func dexOrder(name string) int {
+	number, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "classes"), ".dex"))
+	if err != nil {
+		return 1
+	}
+	return number
+}
+
+// readEntry zip のエントリーを読む。展開後のサイズが limit を超えるエントリーは拒否する
+func readEntry(entry *zip.File, limit int64) ([]byte, error) {
+	if entry.UncompressedSize64 > uint64(limit) {
+		return nil, fmt.Errorf("entry is larger than %d bytes", limit)
+	}
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	// ヘッダーのサイズは偽装できるため、実際に読む量も制限する
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("entry is larger than %d bytes", limit)
+	}
+	return data, nil
+}
+
+// readManifest デコードしたマニフェストからパッケージ、SDK レベル、パーミッション、
+// コンポーネントを取り出す
+func readManifest(root *xmlElement, inspection *entities.AndroidInspection) {
+	var manifest strings.Builder
+	root.render(&manifest, 0)
+	inspection.Manifest = manifest.String()
+
+	inspection.Package = root.attr("package")
+	inspection.VersionCode = root.attr("versionCode")
+	inspection.VersionName = root.attr("versionName")
+
+	for _, child := range root.children {
+		switch child.name {
+		case "uses-sdk":
+			inspection.MinSDK = child.attr("minSdkVersion")
+			inspection.TargetSDK = child.attr("targetSdkVersion")
+		case "uses-permission", "uses-permission-sdk-23":
+			if name := child.attr("name"); name != "" {
+				inspection.Permissions = append(inspection.Permissions, name)
+			}
+		case "application":
+			inspection.Debuggable = child.attr("debuggable")
+			inspection.AllowBackup = child.attr("allowBackup")
+			inspection.UsesCleartextTraffic = child.attr("usesCleartextTraffic")
+			for _, component := range child.children {
+				switch component.name {
+				case "activity", "activity-alias", "service", "receiver", "provider":
+					inspection.Components = append(inspection.Components, readComponent(component, inspection.Package))
+				}
+			}
+		}
+	}
+}
+
+func readComponent(element *xmlElement, packageName string) entities.AndroidComponent {
+	component := entities.AndroidComponent{
+		Kind:       element.name,
+		Name:       element.attr("name"),
+		Exported:   element.attr("exported"),
+		Permission: element.attr("permission"),
+	}
+	// ".MainActivity" のような相対名はパッケージ名で補う
+	if strings.HasPrefix(component.Name, ".") {
+		component.Name = packageName + component.Name
+	}
+	for _, filter := range element.children {
+		if filter.name != "intent-filter" {
+			continue
+		}
+		for _, action := range filter.children {
+			if action.name == "action" {
+				component.Actions = append(component.Actions, action.attr("name"))
+			}
+		}
+	}
+	return component
+}
+
+// AndroidDocuments APK の解析結果を RAG のドキュメントに変換
+// マニフェストに 1 つ、パッケージごとのアプリケーションのクラスのまとまりに 1 つずつ作成する
+func AndroidDocuments(inspection *entities.AndroidInspection, idPrefix, fileName string, metadata map[string]interface{}) []entities.Document {
+	var documents []entities.Document
+	newDocument := func(id, title, content, kind string) entities.Document {
+		docMetadata := map[string]interface{}{
+			"title":    title,
+			"category": "android",
+			"kind":     kind,
+			"apk":      fileName,
+		}
+		for key, value := range metadata {
+			docMetadata[key] = value
+		}
+		return entities.Document{
+			ID:       idPrefix + "_" + id,
+			Content:  fmt.Sprintf("タイトル: %s\n\n%s", title, content),
+			Metadata: docMetadata,
+		}
+	}
+
+	documents = append(documents, newDocument("manifest",
+		fmt.Sprintf("AndroidManifest %s (%s)", inspection.Package, fileName),
+		formatManifestSummary(inspection), "manifest"))
+
+	byPackage := make(map[string][]entities.JavaClass)
+	var order []string
+	for _, class := range inspection.Classes {
+		if isLibraryClass(class.Name) {
+			continue
+		}
+		if _, ok := byPackage[class.Package]; !ok {
+			order = append(order, class.Package)
+		}
+		byPackage[class.Package] = append(byPackage[class.Package], class)
+	}
+	sort.Strings(order)
+
+	for i, pkg := range order {
+		classes := byPackage[pkg]
+		for start := 0; start < len(classes); start += classesPerDocument {
+			if len(documents) >= maxAndroidDocuments {
+				return documents
+			}
+			end := start + classesPerDocument
+			if end > len(classes) {
+				end = len(classes)
+			}
+			var content strings.Builder
+			for _, class := range classes[start:end] {
+				content.WriteString(formatClass(class))
+				content.WriteString("\n")
+			}
+			title := fmt.Sprintf("package %s (%s)", pkg, fileName)
+			if len(classes) > classesPerDocument {
+				title = fmt.Sprintf("package %s %d/%d (%s)", pkg, start/classesPerDocument+1, (len(classes)+classesPerDocument-1)/classesPerDocument, fileName)
+			}
+			documents = append(documents, newDocument(fmt.Sprintf("package_%d_%d", i, start/classesPerDocument), title, content.String(), "classes"))
+		}
+	}
+	return documents
+}
+
+func isLibraryClass(name string) bool {
+	for _, prefix := range libraryPackages {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatManifestSummary(inspection *entities.AndroidInspection) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "パッケージ: %s\n", inspection.Package)
+	fmt.Fprintf(&b, "バージョン: %s (%s)\n", inspection.VersionName, inspection.VersionCode)
+	fmt.Fprintf(&b, "minSdk: %s, targetSdk: %s\n", inspection.MinSDK, inspection.TargetSDK)
+	if inspection.Debuggable != "" {
+		fmt.Fprintf(&b, "debuggable: %s\n", inspection.Debuggable)
+	}
+	if inspection.AllowBackup != "" {
+		fmt.Fprintf(&b, "allowBackup: %s\n", inspection.AllowBackup)
+	}
+	if inspection.UsesCleartextTraffic != "" {
+		fmt.Fprintf(&b, "usesCleartextTraffic: %s\n", inspection.UsesCleartextTraffic)
+	}
+	b.WriteString("\nパーミッション:\n")
+	for _, permission := range inspection.Permissions {
+		b.WriteString("- " + permission + "\n")
+	}
+	b.WriteString("\nコンポーネント:\n")
+	for _, component := range inspection.Components {
+		fmt.Fprintf(&b, "- %s %s", component.Kind, component.Name)
+		if component.Exported != "" {
+			fmt.Fprintf(&b, " exported=%s", component.Exported)
+		}
+		if component.Permission != "" {
+			fmt.Fprintf(&b, " permission=%s", component.Permission)
+		}
+		if len(component.Actions) > 0 {
+			fmt.Fprintf(&b, " actions=%s", strings.Join(component.Actions, ","))
+		}
+		b.WriteString("\n")
+	}
+	if len(inspection.NativeLibraries) > 0 {
+		b.WriteString("\nネイティブライブラリ:\n")
+		for _, library := range inspection.NativeLibraries {
+			b.WriteString("- " + path.Base(library) + " (" + path.Dir(library) + ")\n")
+		}
+	}
+	return b.String()
+}
+
+func formatClass(class entities.JavaClass) string {
+	var b strings.Builder
+	b.WriteString(strings.Join(append(append([]string{}, class.Access...), class.Kind, class.Name), " "))
+	if class.SuperClass != "" && class.SuperClass != "java.lang.Object" {
+		b.WriteString(" extends " + class.SuperClass)
+	}
+	if len(class.Interfaces) > 0 {
+		b.WriteString(" implements " + strings.Join(class.Interfaces, ", "))
+	}
+	b.WriteString("\n")
+	for _, field := range class.Fields {
+		b.WriteString("  " + field.Declaration + "\n")
+	}
+	for _, method := range class.Methods {
+		b.WriteString("  " + method.Declaration + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,379 @@
+package android
+
+import (
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf16"
+)
+
+// バイナリ XML のチャンク種別
+const (
+	chunkStringPool     = 0x0001
+	chunkXML            = 0x0003
+	chunkStartNamespace = 0x0100
+	chunkStartElement   = 0x0102
+	chunkEndElement     = 0x0103
+	chunkResourceMap    = 0x0180
+)
+
+// 属性値の型（Res_value の dataType）
+const (
+	typeNull      = 0x00
+	typeReference = 0x01
+	typeAttribute = 0x02
+	typeString    = 0x03
+	typeFloat     = 0x04
+	typeDimension = 0x05
+	typeFraction  = 0x06
+	typeIntDec    = 0x10
+	typeIntHex    = 0x11
+	typeIntBool   = 0x12
+)
+
+const androidNamespace = "http://schemas.android.com/apk/res/android"
+
+// androidAttributes フレームワークの属性のリソース ID と名前の対応
+// 難読化ツールが文字列プールから属性名を削除している場合に使う
+var androidAttributes = map[uint32]string{
+	0x01010000: "theme",
+	0x01010001: "label",
+	0x01010002: "icon",
+	0x01010003: "name",
+	0x01010006: "permission",
+	0x0101000e: "enabled",
+	0x0101000f: "debuggable",
+	0x01010010: "exported",
+	0x01010011: "process",
+	0x01010018: "authorities",
+	0x01010024: "value",
+	0x01010025: "resource",
+	0x01010027: "scheme",
+	0x01010028: "host",
+	0x01010029: "port",
+	0x0101002a: "path",
+	0x0101002b: "pathPrefix",
+	0x0101002c: "pathPattern",
+	0x0101002d: "action",
+	0x0101002e: "data",
+	0x0101002f: "targetPackage",
+	0x01010202: "protectionLevel",
+	0x0101020c: "minSdkVersion",
+	0x0101021b: "versionCode",
+	0x0101021c: "versionName",
+	0x01010270: "targetSdkVersion",
+	0x01010271: "maxSdkVersion",
+	0x01010280: "allowBackup",
+	0x0101028e: "required",
+	0x010104ec: "usesCleartextTraffic",
+	0x01010572: "compileSdkVersion",
+}
+
+// xmlElement デコードしたバイナリ XML ドキュメントの要素
+type xmlElement struct {
+	name       string
+	attributes []xmlAttribute
+	children   []*xmlElement
+	namespaces [][2]string // 要素で宣言された prefix と URI
+}
+
+type xmlAttribute struct {
+	prefix string
+	name   string
+	value  string
+}
+
+// attr android:（またはプレフィックスなし）の属性 name の値を返す
+func (e *xmlElement) attr(name string) string {
+	for _, a := range e.attributes {
+		if a.name == name && (a.prefix == "android" || a.prefix == "") {
+			return a.value
+		}
+	}
+	return ""
+}
+
+type axmlParser struct {
+	strings     []string
+	resourceIDs []uint32
+	prefixes    map[string]string // URI → prefix
+	pending     [][2]string
+}
+
+// parseBinaryXML APK 内の AndroidManifest.xml で使われるコンパイル済み XML 形式を
+// デコードし、ルート要素を返す
+func parseBinaryXML(data []byte) (*xmlElement, error) {
+	if len(data) < 8 || binary.LittleEndian.Uint16(data) != chunkXML {
+		return nil, errors.New("not a binary XML document")
+	}
+	p := &axmlParser{prefixes: make(map[string]string)}
+
+	root := &xmlElement{}
+	stack := []*xmlElement{root}
+	offset := int(binary.LittleEndian.Uint16(data[2:]))
+	for offset+8 <= len(data) {
+		chunkType := binary.LittleEndian.Uint16(data[offset:])
+		headerSize := int(binary.LittleEndian.Uint16(data[offset+2:]))
+		size := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		if size < 8 || offset+size > len(data) || headerSize > size {
+			return nil, fmt.Errorf("invalid chunk at 0x%x", offset)
+		}
+		chunk := data[offset : offset+size]
+
+		switch chunkType {
+		case chunkStringPool:
+			if err := p.readStringPool(chunk); err != nil {
+				return nil, err
+			}
+		case chunkResourceMap:
+			for i := headerSize; i+4 <= size; i += 4 {
+				p.resourceIDs = append(p.resourceIDs, binary.LittleEndian.Uint32(chunk[i:]))
+			}
+		case chunkStartNamespace:
+			if size >= 24 {
+				prefix := p.string(binary.LittleEndian.Uint32(chunk[16:]))
+				uri := p.string(binary.LittleEndian.Uint32(chunk[20:]))
+				p.prefixes[uri] = prefix
+				p.pending = append(p.pending, [2]string{prefix, uri})
+			}
+		case chunkStartElement:
+			element, err := p.readElement(chunk, headerSize)
+			if err != nil {
+				return nil, err
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, element)
+			stack = append(stack, element)
+		case chunkEndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+		offset += size
+	}
+
+	// 途中で切れたドキュメントは、チャンクヘッダーの残りや閉じていない要素でわかる
+	if offset < len(data) {
+		return nil, fmt.Errorf("truncated chunk at 0x%x", offset)
+	}
+	if len(stack) > 1 {
+		return nil, errors.New("binary XML document ends inside an element")
+	}
+	if len(root.children) == 0 {
+		return nil, errors.New("binary XML document has no root element")
+	}
+	return root.children[0], nil
+}
+
+func (p *axmlParser) readStringPool(chunk []byte) error {
+	if len(chunk) < 28 {
+		return errors.New("truncated string pool")
+	}
+	count := int(binary.LittleEndian.Uint32(chunk[8:]))
+	flags := binary.LittleEndian.Uint32(chunk[16:])
+	stringsStart := int(binary.LittleEndian.Uint32(chunk[20:]))
+	headerSize := int(binary.LittleEndian.Uint16(chunk[2:]))
+	if headerSize+count*4 > len(chunk) {
+		return errors.New("truncated string pool")
+	}
+	utf8Pool := flags&0x100 != 0
+
+	p.strings = make([]string, count)
+	for i := 0; i < count; i++ {
+		offset := stringsStart + int(binary.LittleEndian.Uint32(chunk[headerSize+i*4:]))
+		if offset >= len(chunk) {
+			continue
+		}
+		if utf8Pool {
+			p.strings[i] = decodeUTF8String(chunk[offset:])
+		} else {
+			p.strings[i] = decodeUTF16String(chunk[offset:])
+		}
+	}
+	return nil
+}
+
+// decodeUTF8String UTF-8 プールの文字列を読む
+// UTF-16 での長さ、バイト長（それぞれ 1 または 2 バイト）、バイト列の順に並んでいる
+func decodeUTF8String(b []byte) string {
+	length := func() int {
+		if len(b) == 0 {
+			return 0
+		}
+		n := int(b[0])
+		if n&0x80 != 0 && len(b) > 1 {
+			n = (n&0x7f)<<8 | int(b[1])
+			b = b[2:]
+			return n
+		}
+		b = b[1:]
+		return n
+	}
+	length()
+	n := length()
+	if n > len(b) {
+		n = len(b)
+	}
+	return string(b[:n])
+}
+
+// decodeUTF16String UTF-16 プールの文字列を読む
+// コードユニット数（1 または 2 個の u16）、コードユニットの順に並んでいる
+func decodeUTF16String(b []byte) string {
+	if len(b) < 2 {
+		return ""
+	}
+	n := int(binary.LittleEndian.Uint16(b))
+	b = b[2:]
+	if n&0x8000 != 0 && len(b) >= 2 {
+		n = (n&0x7fff)<<16 | int(binary.LittleEndian.Uint16(b))
+		b = b[2:]
+	}
+	if n*2 > len(b) {
+		n = len(b) / 2
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(b[i*2:])
+	}
+	return string(utf16.Decode(units))
+}
+
+func (p *axmlParser) string(index uint32) string {
+	if int(index) < len(p.strings) {
+		return p.strings[index]
+	}
+	return ""
+}
+
+func (p *axmlParser) readElement(chunk []byte, headerSize int) (*xmlElement, error) {
+	if headerSize+20 > len(chunk) {
+		return nil, errors.New("truncated start element")
+	}
+	ext := chunk[headerSize:]
+	element := &xmlElement{
+		name:       p.string(binary.LittleEndian.Uint32(ext[4:])),
+		namespaces: p.pending,
+	}
+	p.pending = nil
+
+	attributeStart := int(binary.LittleEndian.Uint16(ext[8:]))
+	attributeSize := int(binary.LittleEndian.Uint16(ext[10:]))
+	attributeCount := int(binary.LittleEndian.Uint16(ext[12:]))
+	if attributeSize < 20 {
+		attributeSize = 20
+	}
+	for i := 0; i < attributeCount; i++ {
+		start := headerSize + attributeStart + i*attributeSize
+		if start+20 > len(chunk) {
+			return nil, errors.New("truncated attribute")
+		}
+		a := chunk[start:]
+		namespace := p.string(binary.LittleEndian.Uint32(a))
+		nameIndex := binary.LittleEndian.Uint32(a[4:])
+		raw := binary.LittleEndian.Uint32(a[8:])
+		dataType := a[15]
+		value := binary.LittleEndian.Uint32(a[16:])
+
+		attribute := xmlAttribute{prefix: p.prefixes[namespace], name: p.string(nameIndex)}
+		// 難読化された APK では属性名が空になっているため、リソース ID から補う
+		if int(nameIndex) < len(p.resourceIDs) {
+			if name, ok := androidAttributes[p.resourceIDs[nameIndex]]; ok && (attribute.name == "" || namespace == androidNamespace) {
+				attribute.name = name
+			}
+		}
+		if attribute.prefix == "" && namespace == androidNamespace {
+			attribute.prefix = "android"
+		}
+		if raw != math.MaxUint32 {
+			attribute.value = p.string(raw)
+		} else {
+			attribute.value = formatValue(dataType, value, p)
+		}
+		element.attributes = append(element.attributes, attribute)
+	}
+	return element, nil
+}
+
+// formatValue 型付きの属性値を aapt dump と同じ形式にする
+func formatValue(dataType byte, value uint32, p *axmlParser) string {
+	switch dataType {
+	case typeNull:
+		return ""
+	case typeReference:
+		return fmt.Sprintf("@0x%08x", value)
+	case typeAttribute:
+		return fmt.Sprintf("?0x%08x", value)
+	case typeString:
+		return p.string(value)
+	case typeFloat:
+		return fmt.Sprint(math.Float32frombits(value))
+	case typeDimension:
+		units := []string{"px", "dp", "sp", "pt", "in", "mm"}
+		unit := ""
+		if int(value&0xf) < len(units) {
+			unit = units[value&0xf]
+		}
+		return fmt.Sprintf("%g%s", complexValue(value), unit)
+	case typeFraction:
+		suffix := "%"
+		if value&0xf == 1 {
+			suffix = "%p"
+		}
+		return fmt.Sprintf("%g%s", complexValue(value)*100, suffix)
+	case typeIntDec:
+		return fmt.Sprint(int32(value))
+	case typeIntHex:
+		return fmt.Sprintf("0x%x", value)
+	case typeIntBool:
+		if value != 0 {
+			return "true"
+		}
+		return "false"
+	}
+	if dataType >= 0x1c && dataType <= 0x1f {
+		return fmt.Sprintf("#%08x", value)
+	}
+	return fmt.Sprintf("0x%x", value)
+}
+
+// complexValue 寸法または割合の仮数と基数をデコード
+func complexValue(value uint32) float64 {
+	radixShift := []uint{0, 7, 15, 23}
+	mantissa := float64(int32(value&0xffffff00)) / 256
+	return mantissa / float64(uint32(1)<<radixShift[(value>>4)&0x3])
+}
+
+// render 要素ツリーをインデントした XML テキストとして書き出す
+func (e *xmlElement) render(b *strings.Builder, depth int) {
+	indent := strings.Repeat("  ", depth)
+	b.WriteString(indent + "<" + e.name)
+	for _, ns := range e.namespaces {
+		fmt.Fprintf(b, " xmlns:%s=\"%s\"", ns[0], escapeXML(ns[1]))
+	}
+	for _, a := range e.attributes {
+		name := a.name
+		if a.prefix != "" {
+			name = a.prefix + ":" + name
+		}
+		fmt.Fprintf(b, " %s=\"%s\"", name, escapeXML(a.value))
+	}
+	if len(e.children) == 0 {
+		b.WriteString(" />\n")
+		return
+	}
+	b.WriteString(">\n")
+	for _, child := range e.children {
+		child.render(b, depth+1)
+	}
+	b.WriteString(indent + "</" + e.name + ">\n")
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
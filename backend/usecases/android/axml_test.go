@@ -0,0 +1,255 @@
+package android
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+const noIndex = 0xffffffff
+
+// axmlBuilder テスト用のバイナリ XML をチャンクごとに組み立てる
+type axmlBuilder struct {
+	chunks [][]byte
+}
+
+func axmlChunk(chunkType, headerSize uint16, fields ...interface{}) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, chunkType)
+	binary.Write(&b, binary.LittleEndian, headerSize)
+	binary.Write(&b, binary.LittleEndian, uint32(0))
+	for _, field := range fields {
+		binary.Write(&b, binary.LittleEndian, field)
+	}
+	data := b.Bytes()
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)))
+	return data
+}
+
+// stringPool UTF-16 の文字列プール
+func (b *axmlBuilder) stringPool(strs ...string) {
+	var offsets []uint32
+	var body bytes.Buffer
+	for _, s := range strs {
+		offsets = append(offsets, uint32(body.Len()))
+		units := utf16.Encode([]rune(s))
+		binary.Write(&body, binary.LittleEndian, uint16(len(units)))
+		binary.Write(&body, binary.LittleEndian, units)
+		binary.Write(&body, binary.LittleEndian, uint16(0))
+	}
+	for body.Len()%4 != 0 {
+		body.WriteByte(0)
+	}
+	stringsStart := uint32(28 + len(offsets)*4)
+	b.chunks = append(b.chunks, axmlChunk(chunkStringPool, 28,
+		uint32(len(strs)), uint32(0), uint32(0), stringsStart, uint32(0), offsets, body.Bytes()))
+}
+
+func (b *axmlBuilder) resourceMap(ids ...uint32) {
+	b.chunks = append(b.chunks, axmlChunk(chunkResourceMap, 8, ids))
+}
+
+func (b *axmlBuilder) namespace(prefix, uri uint32) {
+	b.chunks = append(b.chunks, axmlChunk(chunkStartNamespace, 16, uint32(1), uint32(noIndex), prefix, uri))
+}
+
+// axmlAttribute 文字列の属性は raw に、型付きの値は dataType と value に入れる
+type axmlAttribute struct {
+	namespace, name, raw uint32
+	dataType             byte
+	value                uint32
+}
+
+func (b *axmlBuilder) start(name uint32, attributes ...axmlAttribute) {
+	var attrs bytes.Buffer
+	for _, a := range attributes {
+		binary.Write(&attrs, binary.LittleEndian, []uint32{a.namespace, a.name, a.raw})
+		binary.Write(&attrs, binary.LittleEndian, uint16(8))
+		attrs.Write([]byte{0, a.dataType})
+		binary.Write(&attrs, binary.LittleEndian, a.value)
+	}
+	b.chunks = append(b.chunks, axmlChunk(chunkStartElement, 16,
+		uint32(1), uint32(noIndex), uint32(noIndex), name,
+		[]uint16{20, 20, uint16(len(attributes)), 0, 0, 0}, attrs.Bytes()))
+}
+
+func (b *axmlBuilder) end(name uint32) {
+	b.chunks = append(b.chunks, axmlChunk(chunkEndElement, 16, uint32(1), uint32(noIndex), uint32(noIndex), name))
+}
+
+func (b *axmlBuilder) bytes() []byte {
+	body := bytes.Join(b.chunks, nil)
+	return append(axmlChunk(chunkXML, 8), body...)
+}
+
+// testManifest 難読化で属性名が消された debuggable を含むマニフェスト
+func testManifest() []byte {
+	const (
+		sVersionCode = iota
+		sName
+		sDebuggable // 空文字列。リソース ID から名前を補う
+		sAndroid
+		sAndroidNamespace
+		sManifest
+		sPackage
+		sPackageName
+		sUsesPermission
+		sInternet
+		sApplication
+		sActivity
+		sMainActivity
+		sExported
+	)
+	var b axmlBuilder
+	b.stringPool("versionCode", "name", "", "android", androidNamespace, "manifest", "package",
+		"com.example.app", "uses-permission", "android.permission.INTERNET", "application",
+		"activity", ".MainActivity", "exported")
+	b.resourceMap(0x0101021b, 0x01010003, 0x0101000f)
+	b.namespace(sAndroid, sAndroidNamespace)
+	b.start(sManifest,
+		axmlAttribute{namespace: sAndroidNamespace, name: sVersionCode, raw: noIndex, dataType: typeIntDec, value: 3},
+		axmlAttribute{namespace: noIndex, name: sPackage, raw: sPackageName, dataType: typeString, value: sPackageName})
+	b.start(sUsesPermission, axmlAttribute{namespace: sAndroidNamespace, name: sName, raw: sInternet, dataType: typeString, value: sInternet})
+	b.end(sUsesPermission)
+	b.start(sApplication, axmlAttribute{namespace: sAndroidNamespace, name: sDebuggable, raw: noIndex, dataType: typeIntBool, value: noIndex})
+	b.start(sActivity,
+		axmlAttribute{namespace: sAndroidNamespace, name: sName, raw: sMainActivity, dataType: typeString, value: sMainActivity},
+		axmlAttribute{namespace: sAndroidNamespace, name: sExported, raw: noIndex, dataType: typeIntBool, value: 0})
+	b.end(sActivity)
+	b.end(sApplication)
+	b.end(sManifest)
+	return b.bytes()
+}
+
+func TestParseBinaryXML(t *testing.T) {
+	root, err := parseBinaryXML(testManifest())
+	if err != nil {
+		t.Fatalf("parseBinaryXML returned error: %v", err)
+	}
+
+	var got strings.Builder
+	root.render(&got, 0)
+	want := `<manifest xmlns:android="http://schemas.android.com/apk/res/android" android:versionCode="3" package="com.example.app">
+  <uses-permission android:name="android.permission.INTERNET" />
+  <application android:debuggable="true">
+    <activity android:name=".MainActivity" android:exported="false" />
+  </application>
+</manifest>
+`
+	if got.String() != want {
+		t.Errorf("render() = %s, want %s", got.String(), want)
+	}
+
+	inspection := &entities.AndroidInspection{}
+	readManifest(root, inspection)
+	if inspection.Package != "com.example.app" || inspection.VersionCode != "3" || inspection.Debuggable != "true" {
+		t.Errorf("readManifest() = %+v, want com.example.app version 3, debuggable", inspection)
+	}
+	if len(inspection.Components) != 1 || inspection.Components[0].Name != "com.example.app.MainActivity" {
+		t.Errorf("Components = %+v, want com.example.app.MainActivity", inspection.Components)
+	}
+}
+
+func TestParseBinaryXMLTruncated(t *testing.T) {
+	data := testManifest()
+	for n := 0; n < len(data); n++ {
+		if root, err := parseBinaryXML(data[:n]); err == nil {
+			t.Errorf("parseBinaryXML(%d of %d bytes) = %+v, want error", n, len(data), root)
+		}
+	}
+}
+
+func TestParseBinaryXMLMalformed(t *testing.T) {
+	data := testManifest()
+	pool := 8
+	element := bytes.Index(data, []byte{0x02, 0x01, 0x10, 0x00})
+	corrupt := func(offset int, value ...byte) []byte {
+		corrupted := append([]byte(nil), data...)
+		copy(corrupted[offset:], value)
+		return corrupted
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not binary xml", []byte("<manifest/>")},
+		{"chunk smaller than its header", corrupt(pool+4, 4, 0, 0, 0)},
+		{"chunk past end", corrupt(pool+4, 0xff, 0xff, 0xff, 0x7f)},
+		{"header larger than chunk", corrupt(pool+2, 0xff, 0xff)},
+		{"string count past end", corrupt(pool+8, 0xff, 0xff, 0xff, 0x0f)},
+		{"start element header larger than chunk", corrupt(element+2, 0xf0, 0)},
+		{"attribute count past end", corrupt(element+16+12, 0xff, 0xff)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if root, err := parseBinaryXML(tt.data); err == nil {
+				t.Errorf("parseBinaryXML() = %+v, want error", root)
+			}
+		})
+	}
+}
+
+func TestApkInspectionMalformedEntries(t *testing.T) {
+	manifest := testManifest()
+
+	writeApk := func(entries map[string][]byte) string {
+		var apk bytes.Buffer
+		zw := zip.NewWriter(&apk)
+		for name, content := range entries {
+			w, err := zw.Create(name)
+			if err != nil {
+				t.Fatalf("failed to create apk entry: %v", err)
+			}
+			w.Write(content)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatalf("failed to write apk: %v", err)
+		}
+		path := filepath.Join(t.TempDir(), "app.apk")
+		if err := os.WriteFile(path, apk.Bytes(), 0644); err != nil {
+			t.Fatalf("failed to write apk: %v", err)
+		}
+		return path
+	}
+
+	// 壊れたマニフェストと DEX はエラーとして記録し、APK の解析は続ける
+	path := writeApk(map[string][]byte{
+		"AndroidManifest.xml":     manifest[:len(manifest)-10],
+		"classes.dex":             []byte("dex\n035\x00"),
+		"lib/arm64-v8a/libapp.so": []byte("\x7fELF"),
+	})
+	inspection, err := NewApkInspectionUseCase().Execute(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if len(inspection.Errors) != 2 {
+		t.Errorf("Errors = %+v, want errors for AndroidManifest.xml and classes.dex", inspection.Errors)
+	}
+	if len(inspection.NativeLibraries) != 1 {
+		t.Errorf("NativeLibraries = %v, want lib/arm64-v8a/libapp.so", inspection.NativeLibraries)
+	}
+
+	if _, err := NewApkInspectionUseCase().Execute(context.Background(), writeApk(map[string][]byte{"classes.dex": nil})); err == nil {
+		t.Errorf("Execute() without AndroidManifest.xml returned no error")
+	}
+}
+
+func FuzzParseBinaryXML(f *testing.F) {
+	f.Add(testManifest())
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// 壊れたマニフェストでもエラーを返し、panic しない
+		if root, err := parseBinaryXML(data); err == nil {
+			readManifest(root, &entities.AndroidInspection{})
+		}
+	})
+}
@@ -0,0 +1,420 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// アーカイブの形式
+const (
+	FormatZip    = "zip"
+	FormatTar    = "tar"
+	FormatTarGz  = "tar.gz"
+	FormatTarZst = "tar.zst"
+)
+
+// 展開しなかったエントリーの理由
+const (
+	ReasonAbsolutePath  = "absolute path"
+	ReasonTraversal     = "path traversal"
+	ReasonInvalidName   = "invalid file name"
+	ReasonMetadata      = "archive metadata"
+	ReasonLink          = "symbolic or hard link"
+	ReasonSpecialFile   = "special file"
+	ReasonEncrypted     = "encrypted entry"
+	ReasonDuplicate     = "duplicate entry"
+	ReasonTooLarge      = "file too large"
+	ReasonRatio         = "compression ratio too high"
+	ReasonPathConflict  = "conflicts with another entry"
+	ReasonAbortedRemain = "not extracted after the archive was aborted"
+)
+
+// ratioCheckSize 圧縮率の上限はこのサイズを超えるエントリーにだけ適用する
+const ratioCheckSize = 1 << 20
+
+var drivePattern = regexp.MustCompile(`^[A-Za-z]:`)
+
+// metadataNames アーカイバーやバージョン管理が追加した、プロジェクトに含まれないディレクトリとファイル
+var metadataNames = map[string]bool{
+	"__MACOSX": true, ".git": true, ".svn": true, ".hg": true, ".DS_Store": true, "Thumbs.db": true,
+}
+
+// Limits アーカイブの展開後の上限（zip bomb 対策）
+type Limits struct {
+	MaxEntries   int   // 展開するファイル数
+	MaxFileSize  int64 // 1 ファイルのサイズ
+	MaxTotalSize int64 // 展開後の合計サイズ
+	MaxRatio     int64 // zip エントリーの圧縮率（展開後 / 圧縮後）
+}
+
+// DefaultLimits アップロードに使う上限を返す
+func DefaultLimits() Limits {
+	return Limits{
+		MaxEntries:   10000,
+		MaxFileSize:  100 << 20,
+		MaxTotalSize: 1 << 30,
+		MaxRatio:     200,
+	}
+}
+
+// ArchiveExtractUseCase zip と tar のアーカイブをディレクトリに展開する
+// ディレクトリ構造は保ち、安全でないエントリーは拒否する
+type ArchiveExtractUseCase struct {
+	limits Limits
+}
+
+// NewArchiveExtractUseCase アーカイブ展開のユースケースを作成
+func NewArchiveExtractUseCase(limits Limits) *ArchiveExtractUseCase {
+	return &ArchiveExtractUseCase{limits: limits}
+}
+
+// IsArchive ファイル名がアーカイブの拡張子を持つかを判定
+func IsArchive(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range []string{".zip", ".tar", ".tar.gz", ".tgz", ".tar.zst", ".tzst"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectFormat マジックバイトからアーカイブの形式を判定
+func DetectFormat(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	header := make([]byte, 512)
+	n, _ := io.ReadFull(f, header)
+	header = header[:n]
+	switch {
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return FormatZip, nil
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return FormatTarGz, nil
+	case bytes.HasPrefix(header, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		return FormatTarZst, nil
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return FormatTar, nil
+	}
+	return "", errors.New("unsupported archive format")
+}
+
+// Execute archivePath のアーカイブを destDir に展開する
+// 安全でないエントリーや大きすぎるエントリーは飛ばして報告し、アーカイブ全体が上限を
+// 超えた場合は展開を打ち切って Aborted を設定する
+func (uc *ArchiveExtractUseCase) Execute(ctx context.Context, archivePath, destDir string) (*entities.ArchiveExtraction, error) {
+	format, err := DetectFormat(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	x := &extractor{
+		limits:  uc.limits,
+		destDir: destDir,
+		seen:    make(map[string]bool),
+		result: &entities.ArchiveExtraction{
+			Format:  format,
+			Entries: []entities.ArchiveEntry{},
+			Skipped: []entities.SkippedEntry{},
+		},
+	}
+
+	switch format {
+	case FormatZip:
+		err = x.extractZip(ctx, archivePath)
+	default:
+		err = x.extractTarFile(ctx, archivePath, format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return x.result, nil
+}
+
+type extractor struct {
+	limits  Limits
+	destDir string
+	seen    map[string]bool
+	result  *entities.ArchiveExtraction
+}
+
+func (x *extractor) skip(name, reason string) {
+	x.result.Skipped = append(x.result.Skipped, entities.SkippedEntry{Path: name, Reason: reason})
+}
+
+// abort アーカイブが上限を超えたら展開を打ち切る
+func (x *extractor) abort(reason string) {
+	if x.result.Aborted == "" {
+		x.result.Aborted = reason
+	}
+}
+
+// admit エントリー名を検証し、整理した相対パスを返す
+// 飛ばす場合は理由を記録して "" を返す
+func (x *extractor) admit(name string) string {
+	rel, reason := CleanEntryPath(name)
+	if reason != "" {
+		x.skip(name, reason)
+		return ""
+	}
+	if x.seen[rel] {
+		x.skip(name, ReasonDuplicate)
+		return ""
+	}
+	if len(x.result.Entries) >= x.limits.MaxEntries {
+		x.abort(fmt.Sprintf("more than %d files", x.limits.MaxEntries))
+		x.skip(name, ReasonAbortedRemain)
+		return ""
+	}
+	return rel
+}
+
+// CleanEntryPath エントリーの相対パス、または拒否する理由を返す（個別にアップロードされたファイルのパスにも使う）
+// 展開先の外に出る名前は悪意のあるアーカイブにしか現れないため、整理せずに拒否する
+func CleanEntryPath(name string) (string, string) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") || drivePattern.MatchString(name) {
+		return "", ReasonAbsolutePath
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return "", ReasonInvalidName
+		}
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", ReasonTraversal
+		}
+		if metadataNames[part] {
+			return "", ReasonMetadata
+		}
+	}
+	rel := path.Clean(name)
+	if rel == "." || rel == "" {
+		return "", ReasonInvalidName
+	}
+	return rel, ""
+}
+
+// write エントリーをディスクに書き出す
+// ヘッダーのサイズは偽装できるため、実際に読んだバイト数でファイルと合計のサイズの上限を確認する
+func (x *extractor) write(name, rel string, r io.Reader) error {
+	target := filepath.Join(x.destDir, filepath.FromSlash(rel))
+	// 念のため、展開先の外に出るパスでないことを確認する
+	if within, err := filepath.Rel(x.destDir, target); err != nil || within == ".." || strings.HasPrefix(within, ".."+string(filepath.Separator)) {
+		x.skip(name, ReasonTraversal)
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		x.skip(name, ReasonPathConflict)
+		return nil
+	}
+	f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		x.skip(name, ReasonPathConflict)
+		return nil
+	}
+
+	limit := x.limits.MaxFileSize
+	remaining := x.limits.MaxTotalSize - x.result.TotalSize
+	if remaining < limit {
+		limit = remaining
+	}
+	n, err := io.Copy(f, io.LimitReader(r, limit+1))
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(target)
+		return fmt.Errorf("failed to extract %s: %w", name, err)
+	}
+	if n > limit {
+		os.Remove(target)
+		if n > x.limits.MaxFileSize {
+			x.skip(name, ReasonTooLarge)
+			return nil
+		}
+		x.abort(fmt.Sprintf("expands beyond %d bytes", x.limits.MaxTotalSize))
+		x.skip(name, ReasonAbortedRemain)
+		return nil
+	}
+
+	x.seen[rel] = true
+	x.result.TotalSize += n
+	x.result.Entries = append(x.result.Entries, entities.ArchiveEntry{Path: rel, DiskPath: target, Size: n})
+	return nil
+}
+
+func (x *extractor) extractZip(ctx context.Context, archivePath string) error {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	for _, entry := range reader.File {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if x.result.Aborted != "" {
+			break
+		}
+		mode := entry.Mode()
+		switch {
+		case mode.IsDir():
+			continue
+		case mode&os.ModeSymlink != 0:
+			x.skip(entry.Name, ReasonLink)
+			continue
+		case !mode.IsRegular():
+			x.skip(entry.Name, ReasonSpecialFile)
+			continue
+		case entry.Flags&0x1 != 0:
+			x.skip(entry.Name, ReasonEncrypted)
+			continue
+		}
+
+		rel := x.admit(entry.Name)
+		if rel == "" {
+			continue
+		}
+		// ヘッダーで分かる zip bomb は読む前に除外する
+		if entry.UncompressedSize64 > uint64(x.limits.MaxFileSize) {
+			x.skip(entry.Name, ReasonTooLarge)
+			continue
+		}
+		if entry.UncompressedSize64 > ratioCheckSize && entry.CompressedSize64 > 0 &&
+			entry.UncompressedSize64/entry.CompressedSize64 > uint64(x.limits.MaxRatio) {
+			x.skip(entry.Name, ReasonRatio)
+			continue
+		}
+
+		rc, err := entry.Open()
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", entry.Name, err)
+		}
+		err = x.write(entry.Name, rel, rc)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *extractor) extractTarFile(ctx context.Context, archivePath, format string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var stream io.Reader = bufio.NewReader(f)
+	switch format {
+	case FormatTarGz:
+		gz, err := gzip.NewReader(stream)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		stream = gz
+	case FormatTarZst:
+		decoder, err := zstd.NewReader(stream, zstd.WithDecoderMaxMemory(256<<20))
+		if err != nil {
+			return err
+		}
+		defer decoder.Close()
+		stream = decoder
+	}
+	// 読み飛ばすエントリーも含め、展開後のデータ量全体を制限する
+	stream = &boundedReader{r: stream, remaining: 2 * x.limits.MaxTotalSize}
+
+	tr := tar.NewReader(stream)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if x.result.Aborted != "" {
+			return nil
+		}
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if errors.Is(err, errExpandsTooMuch) {
+			x.abort(err.Error())
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("invalid tar archive: %w", err)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeReg, tar.TypeRegA:
+		case tar.TypeDir, tar.TypeXGlobalHeader, tar.TypeXHeader, tar.TypeGNULongName, tar.TypeGNULongLink:
+			continue
+		case tar.TypeSymlink, tar.TypeLink:
+			x.skip(header.Name, ReasonLink)
+			continue
+		default:
+			x.skip(header.Name, ReasonSpecialFile)
+			continue
+		}
+
+		rel := x.admit(header.Name)
+		if rel == "" {
+			continue
+		}
+		if header.Size > x.limits.MaxFileSize {
+			x.skip(header.Name, ReasonTooLarge)
+			continue
+		}
+		if err := x.write(header.Name, rel, tr); err != nil {
+			if errors.Is(err, errExpandsTooMuch) {
+				x.abort(errExpandsTooMuch.Error())
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+var errExpandsTooMuch = errors.New("archive expands beyond the size limit")
+
+// boundedReader remaining を超えて読むとエラーを返す
+type boundedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (b *boundedReader) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, errExpandsTooMuch
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.r.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
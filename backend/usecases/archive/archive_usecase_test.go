@@ -0,0 +1,314 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+type testEntry struct {
+	name string
+	body string
+	mode os.FileMode
+	link bool // tar のハードリンク
+}
+
+func writeZip(t *testing.T, entries []testEntry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		mode := entry.mode
+		if mode == 0 {
+			mode = 0644
+		}
+		header.SetMode(mode)
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(entry.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archivePath := filepath.Join(t.TempDir(), "upload.zip")
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+func writeTarGz(t *testing.T, entries []testEntry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		header := &tar.Header{Name: entry.name, Mode: 0644, Size: int64(len(entry.body)), Typeflag: tar.TypeReg}
+		switch {
+		case entry.link:
+			header.Typeflag, header.Linkname, header.Size = tar.TypeLink, entry.body, 0
+		case entry.mode&os.ModeSymlink != 0:
+			header.Typeflag, header.Linkname, header.Size = tar.TypeSymlink, entry.body, 0
+		case entry.mode&os.ModeNamedPipe != 0:
+			header.Typeflag, header.Size = tar.TypeFifo, 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Size > 0 {
+			if _, err := tw.Write([]byte(entry.body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archivePath := filepath.Join(t.TempDir(), "upload.tar.gz")
+	if err := os.WriteFile(archivePath, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+func TestCleanEntryPath(t *testing.T) {
+	tests := []struct {
+		name   string
+		rel    string
+		reason string
+	}{
+		{name: "src/main.go", rel: "src/main.go"},
+		{name: "./src//main.go", rel: "src/main.go"},
+		{name: "src/util/../main.go", reason: ReasonTraversal},
+		{name: `src\win\main.go`, rel: "src/win/main.go"},
+		{name: "/etc/passwd", reason: ReasonAbsolutePath},
+		{name: `\etc\passwd`, reason: ReasonAbsolutePath},
+		{name: "C:/Windows/system.ini", reason: ReasonAbsolutePath},
+		{name: "c:evil.txt", reason: ReasonAbsolutePath},
+		{name: "../evil.sh", reason: ReasonTraversal},
+		{name: "src/../../evil.sh", reason: ReasonTraversal},
+		{name: `src\..\..\evil.sh`, reason: ReasonTraversal},
+		{name: "src/evil\n.go", reason: ReasonInvalidName},
+		{name: "./", reason: ReasonInvalidName},
+		{name: "__MACOSX/._main.go", reason: ReasonMetadata},
+		{name: "repo/.git/config", reason: ReasonMetadata},
+		{name: "src/.DS_Store", reason: ReasonMetadata},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rel, reason := CleanEntryPath(tt.name)
+			if rel != tt.rel || reason != tt.reason {
+				t.Errorf("CleanEntryPath(%q) = (%q, %q), want (%q, %q)", tt.name, rel, reason, tt.rel, tt.reason)
+			}
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	// tar はヘッダーも読み込み量に含まれるため、合計サイズの上限を大きくする
+	zipLimits := Limits{MaxEntries: 3, MaxFileSize: 16, MaxTotalSize: 24, MaxRatio: 200}
+	tarLimits := Limits{MaxEntries: 3, MaxFileSize: 16, MaxTotalSize: 1 << 20, MaxRatio: 200}
+
+	tests := []struct {
+		name    string
+		limits  Limits
+		write   func(*testing.T, []testEntry) string
+		entries []testEntry
+		want    []string          // 展開されたファイル
+		skipped map[string]string // エントリー名 → 理由
+		aborted bool
+	}{
+		{
+			name:   "zip slip and unsafe entries are skipped",
+			limits: zipLimits,
+			write:  writeZip,
+			entries: []testEntry{
+				{name: "src/main.go", body: "package main"},
+				{name: "../../evil.sh", body: "rm -rf /"},
+				{name: "/etc/cron.d/evil", body: "x"},
+				{name: "link", body: "/etc/passwd", mode: os.ModeSymlink | 0777},
+				{name: "__MACOSX/._main.go", body: "x"},
+				{name: "src/main.go", body: "again"},
+			},
+			want: []string{"src/main.go"},
+			skipped: map[string]string{
+				"../../evil.sh":      ReasonTraversal,
+				"/etc/cron.d/evil":   ReasonAbsolutePath,
+				"link":               ReasonLink,
+				"__MACOSX/._main.go": ReasonMetadata,
+				"src/main.go":        ReasonDuplicate,
+			},
+		},
+		{
+			name:   "tar slip and links are skipped",
+			limits: tarLimits,
+			write:  writeTarGz,
+			entries: []testEntry{
+				{name: "a.txt", body: "a"},
+				{name: "../evil.sh", body: "x"},
+				{name: "passwd", body: "/etc/passwd", mode: os.ModeSymlink},
+				{name: "hard", body: "a.txt", link: true},
+				{name: "pipe", mode: os.ModeNamedPipe},
+			},
+			want: []string{"a.txt"},
+			skipped: map[string]string{
+				"../evil.sh": ReasonTraversal,
+				"passwd":     ReasonLink,
+				"hard":       ReasonLink,
+				"pipe":       ReasonSpecialFile,
+			},
+		},
+		{
+			name:   "zip file over the size limit",
+			limits: zipLimits,
+			write:  writeZip,
+			entries: []testEntry{
+				{name: "big.bin", body: strings.Repeat("x", 17)},
+				{name: "small.txt", body: "ok"},
+			},
+			want:    []string{"small.txt"},
+			skipped: map[string]string{"big.bin": ReasonTooLarge},
+		},
+		{
+			name:   "tar file over the size limit",
+			limits: tarLimits,
+			write:  writeTarGz,
+			entries: []testEntry{
+				{name: "big.bin", body: strings.Repeat("x", 17)},
+				{name: "small.txt", body: "ok"},
+			},
+			want:    []string{"small.txt"},
+			skipped: map[string]string{"big.bin": ReasonTooLarge},
+		},
+		{
+			name:   "total size aborts the extraction",
+			limits: zipLimits,
+			write:  writeZip,
+			entries: []testEntry{
+				{name: "a", body: strings.Repeat("a", 16)},
+				{name: "b", body: strings.Repeat("b", 16)},
+				{name: "c", body: "c"},
+			},
+			want:    []string{"a"},
+			skipped: map[string]string{"b": ReasonAbortedRemain},
+			aborted: true,
+		},
+		{
+			name:   "entry count aborts the extraction",
+			limits: tarLimits,
+			write:  writeTarGz,
+			entries: []testEntry{
+				{name: "1", body: "1"},
+				{name: "2", body: "2"},
+				{name: "3", body: "3"},
+				{name: "4", body: "4"},
+				{name: "5", body: "5"},
+			},
+			want:    []string{"1", "2", "3"},
+			skipped: map[string]string{"4": ReasonAbortedRemain},
+			aborted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			destDir := t.TempDir()
+			result, err := NewArchiveExtractUseCase(tt.limits).Execute(context.Background(), tt.write(t, tt.entries), destDir)
+			if err != nil {
+				t.Fatalf("Execute() returned error: %v", err)
+			}
+
+			var got []string
+			for _, entry := range result.Entries {
+				got = append(got, entry.Path)
+				if _, err := os.Stat(entry.DiskPath); err != nil {
+					t.Errorf("extracted file %s is missing: %v", entry.Path, err)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extracted %v, want %v", got, tt.want)
+			}
+			skipped := make(map[string]string)
+			for _, entry := range result.Skipped {
+				skipped[entry.Path] = entry.Reason
+			}
+			if !reflect.DeepEqual(skipped, tt.skipped) {
+				t.Errorf("skipped %v, want %v", skipped, tt.skipped)
+			}
+			if (result.Aborted != "") != tt.aborted {
+				t.Errorf("Aborted = %q, want aborted %v", result.Aborted, tt.aborted)
+			}
+			assertWithin(t, destDir)
+		})
+	}
+}
+
+func TestExecuteRejectsHighCompressionRatio(t *testing.T) {
+	// 2 MiB のゼロは deflate で 200 倍を大きく超えて圧縮される
+	archivePath := writeZip(t, []testEntry{
+		{name: "bomb.bin", body: strings.Repeat("\x00", 2<<20)},
+		{name: "ok.txt", body: "ok"},
+	})
+	result, err := NewArchiveExtractUseCase(DefaultLimits()).Execute(context.Background(), archivePath, t.TempDir())
+	if err != nil {
+		t.Fatalf("Execute() returned error: %v", err)
+	}
+	want := []entities.SkippedEntry{{Path: "bomb.bin", Reason: ReasonRatio}}
+	if !reflect.DeepEqual(result.Skipped, want) {
+		t.Errorf("skipped %v, want %v", result.Skipped, want)
+	}
+	if len(result.Entries) != 1 || result.Entries[0].Path != "ok.txt" {
+		t.Errorf("entries = %v, want only ok.txt", result.Entries)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	plain := filepath.Join(t.TempDir(), "plain.zip")
+	if err := os.WriteFile(plain, []byte("not an archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{name: "zip", path: writeZip(t, []testEntry{{name: "a", body: "a"}}), want: FormatZip},
+		{name: "tar.gz", path: writeTarGz(t, []testEntry{{name: "a", body: "a"}}), want: FormatTarGz},
+		{name: "extension is not trusted", path: plain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DetectFormat(tt.path)
+			if got != tt.want || (err != nil) != (tt.want == "") {
+				t.Errorf("DetectFormat() = (%q, %v), want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+// assertWithin destDir の外に何も書き込まれていないことを確認する
+func assertWithin(t *testing.T, destDir string) {
+	t.Helper()
+	for _, name := range []string{"evil.sh", "../evil.sh", "../../evil.sh"} {
+		if _, err := os.Stat(filepath.Join(destDir, name)); err == nil {
+			t.Errorf("%s was written outside the destination", name)
+		}
+	}
+}
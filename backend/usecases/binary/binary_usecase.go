@@ -0,0 +1,155 @@
+package binary
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// DetectFormat が認識する実行ファイルの形式
+const (
+	FormatELF   = "elf"
+	FormatPE    = "pe"
+	FormatMachO = "macho"
+)
+
+// maxSymbols バイナリごとに保持するインポートとエクスポートの上限。合計数は報告する
+const maxSymbols = 2000
+
+// DetectFormat ファイルの先頭バイトから実行ファイルの形式を判定
+func DetectFormat(header []byte) string {
+	if len(header) < 8 {
+		return ""
+	}
+	switch {
+	case bytes.HasPrefix(header, []byte("\x7fELF")):
+		return FormatELF
+	case bytes.HasPrefix(header, []byte("MZ")):
+		return FormatPE
+	}
+
+	switch binary.BigEndian.Uint32(header) {
+	case 0xfeedface, 0xfeedfacf, 0xcefaedfe, 0xcffaedfe:
+		return FormatMachO
+	case 0xcafebabe:
+		// Java の class ファイルと同じマジック。fat ヘッダはアーキテクチャ数、class はバージョン（45 以上）が続く
+		if binary.BigEndian.Uint32(header[4:]) < 20 {
+			return FormatMachO
+		}
+	}
+	return ""
+}
+
+// DetectFileFormat path のファイルのヘッダーを読み、形式を判定
+func DetectFileFormat(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	header := make([]byte, 8)
+	n, err := io.ReadFull(f, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return "", nil
+	}
+	return DetectFormat(header[:n]), nil
+}
+
+// BinaryInspectionUseCase 実行ファイルの構造とセキュリティフラグを報告する
+type BinaryInspectionUseCase struct{}
+
+// NewBinaryInspectionUseCase バイナリ解析のユースケースを作成
+func NewBinaryInspectionUseCase() *BinaryInspectionUseCase {
+	return &BinaryInspectionUseCase{}
+}
+
+// Execute path に保存された実行ファイルを解析
+func (uc *BinaryInspectionUseCase) Execute(ctx context.Context, path string) (*entities.BinaryInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	format, err := DetectFileFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var info *entities.BinaryInfo
+	switch format {
+	case FormatELF:
+		info, err = inspectELF(path)
+	case FormatPE:
+		info, err = inspectPE(path)
+	case FormatMachO:
+		info, err = inspectMachO(path)
+	default:
+		return nil, fmt.Errorf("not an ELF, PE or Mach-O executable")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", format, err)
+	}
+
+	info.Format = format
+	info.ImportsTotal = len(info.Imports)
+	info.ExportsTotal = len(info.Exports)
+	info.Imports = capSymbols(info.Imports, &info.SymbolsCapped)
+	info.Exports = capSymbols(info.Exports, &info.SymbolsCapped)
+	if info.Libraries == nil {
+		info.Libraries = []string{}
+	}
+	return info, nil
+}
+
+// capSymbols シンボルをライブラリと名前で並べ、最大 maxSymbols 件だけ残す
+func capSymbols(symbols []entities.BinarySymbol, capped *bool) []entities.BinarySymbol {
+	sort.SliceStable(symbols, func(i, j int) bool {
+		if symbols[i].Library != symbols[j].Library {
+			return symbols[i].Library < symbols[j].Library
+		}
+		return symbols[i].Name < symbols[j].Name
+	})
+	if len(symbols) > maxSymbols {
+		*capped = true
+		return symbols[:maxSymbols]
+	}
+	if symbols == nil {
+		return []entities.BinarySymbol{}
+	}
+	return symbols
+}
+
+// permissions 読み書き実行のフラグを "rwx" 形式にする
+func permissions(r, w, x bool) string {
+	p := []byte("---")
+	if r {
+		p[0] = 'r'
+	}
+	if w {
+		p[1] = 'w'
+	}
+	if x {
+		p[2] = 'x'
+	}
+	return string(p)
+}
+
+// canaryHints インポートした名前からスタック保護と FORTIFY_SOURCE の補助関数を探す
+func canaryHints(imports []entities.BinarySymbol) (canary, fortify bool) {
+	for _, symbol := range imports {
+		switch symbol.Name {
+		case "__stack_chk_fail", "__stack_chk_guard", "___stack_chk_fail", "___stack_chk_guard", "__security_check_cookie":
+			canary = true
+		}
+		if len(symbol.Name) > 6 && symbol.Name[:2] == "__" && symbol.Name[len(symbol.Name)-4:] == "_chk" && symbol.Name != "__stack_chk_fail" {
+			fortify = true
+		}
+	}
+	return canary, fortify
+}
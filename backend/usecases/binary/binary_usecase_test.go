@@ -0,0 +1,162 @@
+package binary
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testExecutable テストを実行しているバイナリ（ELF）を読み込む
+func testExecutable(t testing.TB) []byte {
+	t.Helper()
+	path, err := os.Executable()
+	if err != nil {
+		t.Fatalf("os.Executable returned error: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read test executable: %v", err)
+	}
+	if DetectFormat(data) != FormatELF {
+		t.Skip("test executable is not an ELF file")
+	}
+	return data
+}
+
+func writeTestFile(t testing.TB, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sample.bin")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	return path
+}
+
+// peHeader e_lfanew の位置に PE シグネチャと COFF ヘッダーを置いた MZ ファイル
+func peHeader(lfanew uint32, sections uint16, optionalSize uint16) []byte {
+	data := make([]byte, 0x80)
+	copy(data, "MZ")
+	binary.LittleEndian.PutUint32(data[0x3c:], lfanew)
+	if int(lfanew)+24 <= len(data) {
+		copy(data[lfanew:], "PE\x00\x00")
+		binary.LittleEndian.PutUint16(data[lfanew+4:], 0x8664)
+		binary.LittleEndian.PutUint16(data[lfanew+6:], sections)
+		binary.LittleEndian.PutUint16(data[lfanew+20:], optionalSize)
+	}
+	return data
+}
+
+// machoHeader 64 ビットの Mach-O ヘッダー。ロードコマンドの本体は付けない
+func machoHeader(ncmds, sizeofcmds uint32) []byte {
+	data := make([]byte, 32)
+	binary.LittleEndian.PutUint32(data[0:], 0xfeedfacf)
+	binary.LittleEndian.PutUint32(data[4:], 0x01000007)
+	binary.LittleEndian.PutUint32(data[12:], 2)
+	binary.LittleEndian.PutUint32(data[16:], ncmds)
+	binary.LittleEndian.PutUint32(data[20:], sizeofcmds)
+	return data
+}
+
+// fatHeader アーキテクチャが 1 つの fat ヘッダー。offset と size はファイルの外を指してよい
+func fatHeader(offset, size uint32) []byte {
+	data := make([]byte, 28)
+	binary.BigEndian.PutUint32(data[0:], 0xcafebabe)
+	binary.BigEndian.PutUint32(data[4:], 1)
+	binary.BigEndian.PutUint32(data[8:], 0x01000007)
+	binary.BigEndian.PutUint32(data[16:], offset)
+	binary.BigEndian.PutUint32(data[20:], size)
+	return data
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []byte
+		want   string
+	}{
+		{"elf", []byte("\x7fELF\x02\x01\x01\x00"), FormatELF},
+		{"pe", peHeader(0x40, 1, 0), FormatPE},
+		{"macho 64", machoHeader(0, 0), FormatMachO},
+		{"macho 32 big endian", []byte{0xfe, 0xed, 0xfa, 0xce, 0, 0, 0, 7}, FormatMachO},
+		{"fat", fatHeader(0x1000, 0x1000), FormatMachO},
+		{"java class", []byte{0xca, 0xfe, 0xba, 0xbe, 0, 0, 0, 52}, ""},
+		{"short", []byte("\x7fELF"), ""},
+		{"text", []byte("#!/bin/sh\necho hi\n"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectFormat(tt.header); got != tt.want {
+				t.Errorf("DetectFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBinaryInspectionExecute(t *testing.T) {
+	data := testExecutable(t)
+
+	info, err := NewBinaryInspectionUseCase().Execute(context.Background(), writeTestFile(t, data))
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if info.Format != FormatELF || len(info.Sections) == 0 {
+		t.Errorf("Execute() = format %q with %d sections, want elf with sections", info.Format, len(info.Sections))
+	}
+}
+
+func TestBinaryInspectionMalformed(t *testing.T) {
+	data := testExecutable(t)
+
+	// セクションヘッダーテーブルの位置と数を壊した ELF
+	badSections := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint64(badSections[0x28:], uint64(len(data))*2)
+	badCount := append([]byte(nil), data...)
+	binary.LittleEndian.PutUint16(badCount[0x3c:], 0xffff)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"elf magic only", data[:8]},
+		{"elf header", data[:64]},
+		{"elf truncated to 4 KiB", data[:4096]},
+		{"elf truncated to half", data[:len(data)/2]},
+		{"elf missing last bytes", data[:len(data)-16]},
+		{"elf section headers out of range", badSections},
+		{"elf section count out of range", badCount},
+		{"pe lfanew outside file", peHeader(0x7fffffff, 1, 0)},
+		{"pe without optional header", peHeader(0x40, 0, 0)},
+		{"pe sections outside file", peHeader(0x40, 0xffff, 0xf0)},
+		{"pe truncated signature", peHeader(0x40, 1, 0)[:0x42]},
+		{"macho header only", machoHeader(0, 0)[:16]},
+		{"macho load commands outside file", machoHeader(0xffff, 0xffffff)},
+		{"fat architecture outside file", fatHeader(0xffff0000, 0x1000)},
+		{"fat truncated", fatHeader(0x1000, 0x1000)[:12]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := NewBinaryInspectionUseCase().Execute(context.Background(), writeTestFile(t, tt.data))
+			if err == nil {
+				t.Errorf("Execute() = %+v, want error", info)
+			}
+		})
+	}
+}
+
+func FuzzBinaryInspection(f *testing.F) {
+	data := testExecutable(f)
+	f.Add(data[:4096])
+	f.Add(peHeader(0x40, 1, 0xf0))
+	f.Add(machoHeader(1, 72))
+	f.Add(fatHeader(0x1c, 0x20))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// 不正な入力でもエラーを返し、panic しない
+		NewBinaryInspectionUseCase().Execute(context.Background(), writeTestFile(t, data))
+	})
+}
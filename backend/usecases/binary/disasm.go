@@ -0,0 +1,275 @@
+package binary
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+
+	"golang.org/x/arch/arm64/arm64asm"
+	"golang.org/x/arch/x86/x86asm"
+)
+
+var (
+	// ErrSymbolNotFound 指定したシンボルに一致する関数がない
+	ErrSymbolNotFound = errors.New("symbol not found")
+	// ErrUnsupportedArchitecture デコーダーのないアーキテクチャ
+	ErrUnsupportedArchitecture = errors.New("unsupported architecture")
+)
+
+// maxDisasmBytes 1 関数でデコードするコードの上限
+const maxDisasmBytes = 64 * 1024
+
+// DisassemblyUseCase x86、x86-64、ARM64 実行ファイルの関数を逆アセンブルする
+type DisassemblyUseCase struct{}
+
+// NewDisassemblyUseCase 逆アセンブルのユースケースを作成
+func NewDisassemblyUseCase() *DisassemblyUseCase {
+	return &DisassemblyUseCase{}
+}
+
+// Execute path の実行ファイルにある symbol という名前の関数を逆アセンブル
+// シンボルはシンボルテーブル、エクスポート、strip された Go 実行ファイルでは pclntab から
+// 探す。16 進数のアドレス（"0x401000"）も指定できる
+func (uc *DisassemblyUseCase) Execute(ctx context.Context, path, symbol string) (*entities.Disassembly, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	img, err := openImage(path)
+	if err != nil {
+		return nil, err
+	}
+	defer img.Close()
+
+	if img.arch != "x86_64" && img.arch != "x86" && img.arch != "arm64" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedArchitecture, img.arch)
+	}
+
+	symbols := functionSymbols(img)
+	fn, err := findFunction(img, symbols, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entities.Disassembly{
+		Symbol:       fn.name,
+		Address:      fn.addr,
+		Size:         fn.size,
+		Arch:         img.arch,
+		Instructions: []entities.Instruction{},
+	}
+	size := fn.size
+	if size > maxDisasmBytes {
+		size = maxDisasmBytes
+		result.Truncated = true
+	}
+	code, err := img.read(fn.addr, size)
+	if err != nil {
+		return nil, err
+	}
+
+	lookup := symbolLookup(symbols)
+	if img.arch == "arm64" {
+		result.Syntax = "gnu"
+		result.Instructions = decodeARM64(code, fn.addr, lookup)
+	} else {
+		mode := 64
+		if img.arch == "x86" {
+			mode = 32
+		}
+		result.Syntax = "intel"
+		result.Instructions = decodeX86(code, fn.addr, mode, lookup)
+	}
+	return result, nil
+}
+
+// FormatDisassembly 逆アセンブル結果を objdump のような一覧にする
+func FormatDisassembly(d *entities.Disassembly) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "; %s (%s, 0x%x bytes)\n", d.Symbol, d.Arch, d.Size)
+	for _, inst := range d.Instructions {
+		fmt.Fprintf(&b, "%08x:  %-24s %s", inst.Address, inst.Bytes, inst.Text)
+		if inst.Target != "" && !strings.Contains(inst.Text, inst.Target) {
+			fmt.Fprintf(&b, "  ; %s", inst.Target)
+		}
+		b.WriteString("\n")
+	}
+	if d.Truncated {
+		b.WriteString("; ... truncated\n")
+	}
+	return b.String()
+}
+
+// functionSymbols シンボルテーブルと Go の pclntab をまとめ、アドレス順に並べる
+func functionSymbols(img *image) []imageSymbol {
+	symbols := append([]imageSymbol{}, img.symbols...)
+	if table, _, err := goSymbolTable(img); err == nil {
+		for _, fn := range table.Funcs {
+			symbols = append(symbols, imageSymbol{name: fn.Name, addr: fn.Entry, size: fn.End - fn.Entry})
+		}
+	}
+
+	sort.SliceStable(symbols, func(i, j int) bool {
+		if symbols[i].addr != symbols[j].addr {
+			return symbols[i].addr < symbols[j].addr
+		}
+		// 同じアドレスではサイズの分かっているものを優先する
+		return symbols[i].size > symbols[j].size
+	})
+	return symbols
+}
+
+// findFunction シンボル名またはアドレスから、サイズの分かる関数を求める
+func findFunction(img *image, symbols []imageSymbol, symbol string) (imageSymbol, error) {
+	var fn imageSymbol
+	found := false
+	if strings.HasPrefix(symbol, "0x") {
+		addr, err := strconv.ParseUint(symbol[2:], 16, 64)
+		if err != nil {
+			return fn, fmt.Errorf("%w: invalid address %s", ErrSymbolNotFound, symbol)
+		}
+		fn, found = imageSymbol{name: symbol, addr: addr}, true
+		for _, s := range symbols {
+			if s.addr == addr {
+				fn = s
+				break
+			}
+		}
+	} else {
+		// Mach-O の C シンボルは先頭に "_" が付く
+		for _, name := range []string{symbol, "_" + symbol} {
+			for _, s := range symbols {
+				if s.name == name {
+					fn, found = s, true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+	}
+	if !found {
+		return fn, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+
+	if fn.size == 0 {
+		fn.size = inferSize(img, symbols, fn.addr)
+	}
+	if fn.size == 0 {
+		return fn, fmt.Errorf("%w: %s is not inside a code section", ErrSymbolNotFound, symbol)
+	}
+	return fn, nil
+}
+
+// inferSize 次のシンボルかセクションの終わりを関数の終わりとする
+func inferSize(img *image, symbols []imageSymbol, addr uint64) uint64 {
+	var section *imageSection
+	for i := range img.sections {
+		s := &img.sections[i]
+		if s.exec && addr >= s.addr && addr < s.addr+s.size {
+			section = s
+			break
+		}
+	}
+	if section == nil {
+		return 0
+	}
+
+	end := section.addr + section.size
+	i := sort.Search(len(symbols), func(i int) bool { return symbols[i].addr > addr })
+	if i < len(symbols) && symbols[i].addr < end {
+		end = symbols[i].addr
+	}
+	return end - addr
+}
+
+// symbolLookup 既知のシンボル内のアドレスに対して "name" または "name+0x10" を返す
+func symbolLookup(symbols []imageSymbol) func(uint64) (string, uint64) {
+	return func(addr uint64) (string, uint64) {
+		i := sort.Search(len(symbols), func(i int) bool { return symbols[i].addr > addr }) - 1
+		if i < 0 {
+			return "", 0
+		}
+		s := symbols[i]
+		if s.size > 0 && addr >= s.addr+s.size {
+			return "", 0
+		}
+		return s.name, s.addr
+	}
+}
+
+func targetName(lookup func(uint64) (string, uint64), addr uint64) string {
+	name, base := lookup(addr)
+	if name == "" {
+		return fmt.Sprintf("0x%x", addr)
+	}
+	if addr == base {
+		return name
+	}
+	return fmt.Sprintf("%s+0x%x", name, addr-base)
+}
+
+func decodeX86(code []byte, pc uint64, mode int, lookup func(uint64) (string, uint64)) []entities.Instruction {
+	var instructions []entities.Instruction
+	for offset := 0; offset < len(code); {
+		addr := pc + uint64(offset)
+		inst, err := x86asm.Decode(code[offset:], mode)
+		if err != nil || inst.Len == 0 {
+			// 解釈できないバイトは 1 バイトずつ読み飛ばす
+			instructions = append(instructions, entities.Instruction{Address: addr, Bytes: hex.EncodeToString(code[offset : offset+1]), Text: "(bad)"})
+			offset++
+			continue
+		}
+
+		instruction := entities.Instruction{
+			Address: addr,
+			Bytes:   hex.EncodeToString(code[offset : offset+inst.Len]),
+			Text:    x86asm.IntelSyntax(inst, addr, lookup),
+		}
+		for _, arg := range inst.Args {
+			if rel, ok := arg.(x86asm.Rel); ok {
+				instruction.Target = targetName(lookup, uint64(int64(addr)+int64(inst.Len)+int64(rel)))
+			}
+		}
+		instructions = append(instructions, instruction)
+		offset += inst.Len
+	}
+	return instructions
+}
+
+func decodeARM64(code []byte, pc uint64, lookup func(uint64) (string, uint64)) []entities.Instruction {
+	var instructions []entities.Instruction
+	for offset := 0; offset+4 <= len(code); offset += 4 {
+		addr := pc + uint64(offset)
+		instruction := entities.Instruction{Address: addr, Bytes: hex.EncodeToString(code[offset : offset+4])}
+
+		inst, err := arm64asm.Decode(code[offset : offset+4])
+		if err != nil {
+			instruction.Text = "(bad)"
+			instructions = append(instructions, instruction)
+			continue
+		}
+		instruction.Text = arm64asm.GNUSyntax(inst)
+		for _, arg := range inst.Args {
+			rel, ok := arg.(arm64asm.PCRel)
+			if !ok {
+				continue
+			}
+			// ADRP は 4KB ページ単位の相対アドレス
+			base := addr
+			if inst.Op == arm64asm.ADRP {
+				base &^= 0xfff
+			}
+			instruction.Target = targetName(lookup, uint64(int64(base)+int64(rel)))
+		}
+		instructions = append(instructions, instruction)
+	}
+	return instructions
+}
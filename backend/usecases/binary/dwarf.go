@@ -0,0 +1,467 @@
+package binary
+
+import (
+	"context"
+	"debug/dwarf"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// ErrNoDebugInfo 実行ファイルに DWARF セクションがない
+var ErrNoDebugInfo = errors.New("no DWARF debug information")
+
+const (
+	// maxDebugTypes と maxDebugFunctions は結果に保存する件数の上限
+	maxDebugTypes     = 5000
+	maxDebugFunctions = 10000
+	// maxDebugDocuments は 1 つの実行ファイルから RAG に登録する文書数の上限
+	maxDebugDocuments = 500
+	// debugFunctionsPerDocument は関数シグネチャを 1 文書にまとめる件数
+	debugFunctionsPerDocument = 40
+)
+
+var dwarfLanguages = map[int64]string{
+	0x01:   "C89",
+	0x02:   "C",
+	0x04:   "C++",
+	0x0c:   "C99",
+	0x0d:   "Ada",
+	0x10:   "ObjC",
+	0x11:   "ObjC++",
+	0x16:   "Go",
+	0x1a:   "C++11",
+	0x1c:   "Rust",
+	0x1d:   "C11",
+	0x1e:   "Swift",
+	0x21:   "C++14",
+	0x2b:   "C17",
+	0x8001: "Mips Assembler",
+}
+
+// DebugInfoUseCase 実行ファイルの DWARF デバッグ情報からコンパイル単位、ソースファイル、
+// 型、関数のシグネチャを再構成する
+type DebugInfoUseCase struct{}
+
+// NewDebugInfoUseCase デバッグ情報のユースケースを作成
+func NewDebugInfoUseCase() *DebugInfoUseCase {
+	return &DebugInfoUseCase{}
+}
+
+// Execute path の実行ファイルの DWARF セクションを読み込む
+func (uc *DebugInfoUseCase) Execute(ctx context.Context, path string) (*entities.DebugInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	img, err := openImage(path)
+	if err != nil {
+		return nil, err
+	}
+	defer img.Close()
+
+	data, err := img.dwarf()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoDebugInfo, err)
+	}
+	return readDebugInfo(ctx, data)
+}
+
+func readDebugInfo(ctx context.Context, data *dwarf.Data) (*entities.DebugInfo, error) {
+	info := &entities.DebugInfo{
+		CompileUnits: []entities.CompileUnit{},
+		Types:        []entities.DebugType{},
+		Functions:    []entities.DebugFunction{},
+	}
+	seenTypes := make(map[string]bool)
+	seenFunctions := make(map[string]bool)
+
+	var unitName string
+	var files []*dwarf.LineFile
+	r := data.Reader()
+	for {
+		entry, err := r.Next()
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			break
+		}
+
+		switch entry.Tag {
+		case dwarf.TagCompileUnit:
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			unit := compileUnit(data, entry, &files)
+			info.CompileUnits = append(info.CompileUnits, unit)
+			unitName = unit.Name
+
+		case dwarf.TagStructType, dwarf.TagUnionType, dwarf.TagClassType, dwarf.TagEnumerationType, dwarf.TagTypedef:
+			name, _ := entry.Val(dwarf.AttrName).(string)
+			if name == "" || isDeclaration(entry) {
+				continue
+			}
+			// ヘッダーの型は取り込んだコンパイル単位の数だけ重複する
+			t, err := data.Type(entry.Offset)
+			if err != nil {
+				continue
+			}
+			key := fmt.Sprintf("%s|%s|%d", entry.Tag, name, t.Size())
+			if seenTypes[key] {
+				continue
+			}
+			seenTypes[key] = true
+			info.TypesTotal++
+			if len(info.Types) >= maxDebugTypes {
+				info.Capped = true
+				continue
+			}
+			debugType := newDebugType(t, name)
+			debugType.DeclFile, debugType.DeclLine = declLocation(entry, files)
+			info.Types = append(info.Types, debugType)
+
+		case dwarf.TagSubprogram:
+			fn := newDebugFunction(r, data, entry, unitName)
+			fn.DeclFile, fn.DeclLine = declLocation(entry, files)
+			if fn.Name == "" || isDeclaration(entry) && fn.LowPC == 0 {
+				continue
+			}
+			key := fmt.Sprintf("%s|%x", fn.Name, fn.LowPC)
+			if seenFunctions[key] {
+				continue
+			}
+			seenFunctions[key] = true
+			info.FunctionsTotal++
+			if len(info.Functions) >= maxDebugFunctions {
+				info.Capped = true
+				continue
+			}
+			info.Functions = append(info.Functions, fn)
+		}
+	}
+
+	if len(info.CompileUnits) == 0 {
+		return nil, ErrNoDebugInfo
+	}
+
+	sort.Slice(info.Types, func(i, j int) bool {
+		if info.Types[i].Name != info.Types[j].Name {
+			return info.Types[i].Name < info.Types[j].Name
+		}
+		return info.Types[i].Kind < info.Types[j].Kind
+	})
+	sort.Slice(info.Functions, func(i, j int) bool {
+		if info.Functions[i].Name != info.Functions[j].Name {
+			return info.Functions[i].Name < info.Functions[j].Name
+		}
+		return info.Functions[i].LowPC < info.Functions[j].LowPC
+	})
+	info.SourceTree = sourceTree(info.CompileUnits)
+	return info, nil
+}
+
+// compileUnit コンパイル単位と、後続のエントリーの decl_file 属性が参照するファイルテーブルを読む
+func compileUnit(data *dwarf.Data, entry *dwarf.Entry, files *[]*dwarf.LineFile) entities.CompileUnit {
+	unit := entities.CompileUnit{Files: []string{}}
+	unit.Name, _ = entry.Val(dwarf.AttrName).(string)
+	unit.Producer, _ = entry.Val(dwarf.AttrProducer).(string)
+	unit.CompDir, _ = entry.Val(dwarf.AttrCompDir).(string)
+	if language, ok := entry.Val(dwarf.AttrLanguage).(int64); ok {
+		unit.Language = dwarfLanguages[language]
+		if unit.Language == "" {
+			unit.Language = fmt.Sprintf("0x%x", language)
+		}
+	}
+
+	*files = nil
+	if lr, err := data.LineReader(entry); err == nil && lr != nil {
+		*files = lr.Files()
+		seen := make(map[string]bool)
+		for _, file := range *files {
+			if file != nil && file.Name != "" && !seen[file.Name] {
+				seen[file.Name] = true
+				unit.Files = append(unit.Files, file.Name)
+			}
+		}
+	}
+	return unit
+}
+
+func isDeclaration(entry *dwarf.Entry) bool {
+	declaration, _ := entry.Val(dwarf.AttrDeclaration).(bool)
+	return declaration
+}
+
+func declLocation(entry *dwarf.Entry, files []*dwarf.LineFile) (string, int64) {
+	line, _ := entry.Val(dwarf.AttrDeclLine).(int64)
+	index, ok := entry.Val(dwarf.AttrDeclFile).(int64)
+	if !ok || index < 0 || int(index) >= len(files) || files[index] == nil {
+		return "", line
+	}
+	return files[index].Name, line
+}
+
+func newDebugType(t dwarf.Type, name string) entities.DebugType {
+	debugType := entities.DebugType{Name: name, Size: t.Size()}
+	switch t := t.(type) {
+	case *dwarf.StructType:
+		debugType.Kind = t.Kind
+		for _, field := range t.Field {
+			member := entities.DebugMember{
+				Name:    field.Name,
+				Type:    field.Type.String(),
+				Offset:  field.ByteOffset,
+				BitSize: field.BitSize,
+			}
+			// DWARF 5 のビットフィールドは構造体先頭からのビット位置で記録される
+			if field.BitSize > 0 && field.DataBitOffset > 0 {
+				member.Offset = field.DataBitOffset / 8
+				member.BitOffset = field.DataBitOffset % 8
+			}
+			debugType.Members = append(debugType.Members, member)
+		}
+	case *dwarf.EnumType:
+		debugType.Kind = "enum"
+		for _, value := range t.Val {
+			debugType.Enumerators = append(debugType.Enumerators, entities.DebugEnumerator{Name: value.Name, Value: value.Val})
+		}
+	case *dwarf.TypedefType:
+		debugType.Kind = "typedef"
+		debugType.Underlying = t.Type.String()
+	default:
+		debugType.Kind = "type"
+	}
+	return debugType
+}
+
+// newDebugFunction サブプログラムとその引数を読む
+// ネストしたスコープ（レキシカルブロック、インライン展開された呼び出し）は飛ばし、
+// リーダーをサブプログラムの後に進める
+func newDebugFunction(r *dwarf.Reader, data *dwarf.Data, entry *dwarf.Entry, unitName string) entities.DebugFunction {
+	fn := entities.DebugFunction{Params: []entities.DebugParam{}}
+	fn.Name, _ = entry.Val(dwarf.AttrName).(string)
+	fn.LinkageName, _ = entry.Val(dwarf.AttrLinkageName).(string)
+	fn.External, _ = entry.Val(dwarf.AttrExternal).(bool)
+	fn.LowPC, _ = entry.Val(dwarf.AttrLowpc).(uint64)
+	if field := entry.AttrField(dwarf.AttrHighpc); field != nil {
+		switch value := field.Val.(type) {
+		case uint64:
+			fn.HighPC = value
+		case int64:
+			// DWARF 4 以降は low_pc からのオフセット
+			fn.HighPC = fn.LowPC + uint64(value)
+		}
+	}
+	fn.CompileUnit = unitName
+	fn.ReturnType = typeName(data, entry)
+
+	// Go は戻り値を variable_parameter 付きの引数として記録する
+	var results []string
+	if entry.Children {
+		for {
+			child, err := r.Next()
+			if err != nil || child == nil || child.Tag == 0 {
+				break
+			}
+			switch child.Tag {
+			case dwarf.TagFormalParameter:
+				name, _ := child.Val(dwarf.AttrName).(string)
+				if result, _ := child.Val(dwarf.AttrVarParam).(bool); result {
+					results = append(results, typeName(data, child))
+					break
+				}
+				fn.Params = append(fn.Params, entities.DebugParam{Name: name, Type: typeName(data, child)})
+			case dwarf.TagUnspecifiedParameters:
+				fn.Variadic = true
+			}
+			if child.Children {
+				r.SkipChildren()
+			}
+		}
+	}
+	switch {
+	case len(results) == 1:
+		fn.ReturnType = results[0]
+	case len(results) > 1:
+		fn.ReturnType = "(" + strings.Join(results, ", ") + ")"
+	}
+	return fn
+}
+
+func typeName(data *dwarf.Data, entry *dwarf.Entry) string {
+	offset, ok := entry.Val(dwarf.AttrType).(dwarf.Offset)
+	if !ok {
+		return "void"
+	}
+	t, err := data.Type(offset)
+	if err != nil {
+		return "?"
+	}
+	return t.String()
+}
+
+// sourceTree すべてのコンパイル単位のソースファイルをディレクトリツリーにまとめる
+func sourceTree(units []entities.CompileUnit) *entities.SourceNode {
+	root := &entities.SourceNode{Name: "/", Path: "/", Dir: true}
+	nodes := map[string]*entities.SourceNode{"/": root}
+
+	add := func(file string) {
+		file = path.Clean("/" + strings.ReplaceAll(file, "\\", "/"))
+		parent := root
+		parts := strings.Split(strings.TrimPrefix(file, "/"), "/")
+		for i, part := range parts {
+			current := "/" + strings.Join(parts[:i+1], "/")
+			node, ok := nodes[current]
+			if !ok {
+				node = &entities.SourceNode{Name: part, Path: current, Dir: i < len(parts)-1}
+				nodes[current] = node
+				parent.Children = append(parent.Children, node)
+			} else if i < len(parts)-1 {
+				node.Dir = true
+			}
+			parent = node
+		}
+	}
+	for _, unit := range units {
+		for _, file := range unit.Files {
+			add(file)
+		}
+	}
+
+	var sortNode func(node *entities.SourceNode)
+	sortNode = func(node *entities.SourceNode) {
+		sort.Slice(node.Children, func(i, j int) bool {
+			a, b := node.Children[i], node.Children[j]
+			if a.Dir != b.Dir {
+				return a.Dir
+			}
+			return a.Name < b.Name
+		})
+		for _, child := range node.Children {
+			sortNode(child)
+		}
+	}
+	sortNode(root)
+	return root
+}
+
+// FormatDebugType 型をメンバーのオフセット付きの C 風の宣言にする
+func FormatDebugType(t entities.DebugType) string {
+	var b strings.Builder
+	switch t.Kind {
+	case "typedef":
+		fmt.Fprintf(&b, "typedef %s %s;", t.Underlying, t.Name)
+	case "enum":
+		fmt.Fprintf(&b, "enum %s {  // size %d\n", t.Name, t.Size)
+		for _, e := range t.Enumerators {
+			fmt.Fprintf(&b, "    %s = %d,\n", e.Name, e.Value)
+		}
+		b.WriteString("};")
+	default:
+		fmt.Fprintf(&b, "%s %s {  // size %d\n", t.Kind, t.Name, t.Size)
+		for _, m := range t.Members {
+			if m.BitSize > 0 {
+				fmt.Fprintf(&b, "    %s %s : %d;  // offset %d, bit %d\n", m.Type, m.Name, m.BitSize, m.Offset, m.BitOffset)
+			} else {
+				fmt.Fprintf(&b, "    %s %s;  // offset %d\n", m.Type, m.Name, m.Offset)
+			}
+		}
+		b.WriteString("};")
+	}
+	if t.DeclFile != "" {
+		fmt.Fprintf(&b, "\n// declared at %s:%d", t.DeclFile, t.DeclLine)
+	}
+	return b.String()
+}
+
+// FormatDebugFunction 関数のシグネチャを文字列にする
+func FormatDebugFunction(fn entities.DebugFunction) string {
+	params := make([]string, 0, len(fn.Params)+1)
+	for _, p := range fn.Params {
+		params = append(params, strings.TrimSpace(p.Type+" "+p.Name))
+	}
+	if fn.Variadic {
+		params = append(params, "...")
+	}
+	signature := fmt.Sprintf("%s %s(%s)", fn.ReturnType, fn.Name, strings.Join(params, ", "))
+	if fn.DeclFile != "" {
+		signature += fmt.Sprintf("  // %s:%d", fn.DeclFile, fn.DeclLine)
+	}
+	return signature
+}
+
+// DebugInfoDocuments 実行ファイルの型と関数のシグネチャからナレッジベースのドキュメントを作成
+// ソースコードがなくても「構造体 X の中身は」のような質問に答えられるようにする
+// 型は 1 つずつ、シグネチャは宣言したファイルごとに 1 ドキュメントにまとめる
+func DebugInfoDocuments(info *entities.DebugInfo, idPrefix, fileName string, metadata map[string]interface{}) []entities.Document {
+	var documents []entities.Document
+	newDocument := func(id, title, content, kind string) entities.Document {
+		docMetadata := map[string]interface{}{
+			"title":    title,
+			"category": "debug_info",
+			"kind":     kind,
+			"binary":   fileName,
+		}
+		for key, value := range metadata {
+			docMetadata[key] = value
+		}
+		return entities.Document{
+			ID:       idPrefix + "_" + id,
+			Content:  fmt.Sprintf("タイトル: %s\n\n%s", title, content),
+			Metadata: docMetadata,
+		}
+	}
+
+	for i, t := range info.Types {
+		if len(documents) >= maxDebugDocuments {
+			return documents
+		}
+		// メンバーを持たない型（前方宣言由来・空の typedef）は質問の対象になりにくい
+		if t.Kind != "typedef" && len(t.Members) == 0 && len(t.Enumerators) == 0 {
+			continue
+		}
+		title := fmt.Sprintf("%s %s (%s)", t.Kind, t.Name, fileName)
+		documents = append(documents, newDocument(fmt.Sprintf("type_%d", i), title, FormatDebugType(t), "type"))
+	}
+
+	byFile := make(map[string][]entities.DebugFunction)
+	var order []string
+	for _, fn := range info.Functions {
+		if fn.LowPC == 0 {
+			continue
+		}
+		file := fn.DeclFile
+		if file == "" {
+			file = fn.CompileUnit
+		}
+		if _, ok := byFile[file]; !ok {
+			order = append(order, file)
+		}
+		byFile[file] = append(byFile[file], fn)
+	}
+	sort.Strings(order)
+	for i, file := range order {
+		functions := byFile[file]
+		for start := 0; start < len(functions); start += debugFunctionsPerDocument {
+			if len(documents) >= maxDebugDocuments {
+				return documents
+			}
+			end := start + debugFunctionsPerDocument
+			if end > len(functions) {
+				end = len(functions)
+			}
+			lines := make([]string, 0, end-start)
+			for _, fn := range functions[start:end] {
+				lines = append(lines, FormatDebugFunction(fn))
+			}
+			title := fmt.Sprintf("functions in %s (%s)", file, fileName)
+			documents = append(documents, newDocument(fmt.Sprintf("functions_%d_%d", i, start), title, strings.Join(lines, "\n"), "functions"))
+		}
+	}
+	return documents
+}
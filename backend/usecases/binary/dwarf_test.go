@@ -0,0 +1,177 @@
+package binary
+
+import (
+	"bytes"
+	"context"
+	"debug/dwarf"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// testDWARF 構造体 1 つと関数 1 つを持つ C のコンパイル単位（DWARF 4）を組み立てる
+//
+//	struct point { int x; int y; };
+//	int add(int a, int b);
+func testDWARF() (abbrev, info []byte) {
+	abbrev = []byte{
+		1, 0x11, 1, 0x03, 0x08, 0x13, 0x0b, 0, 0, // compile_unit: name, language
+		2, 0x24, 0, 0x03, 0x08, 0x0b, 0x0b, 0x3e, 0x0b, 0, 0, // base_type: name, byte_size, encoding
+		3, 0x13, 1, 0x03, 0x08, 0x0b, 0x0b, 0, 0, // structure_type: name, byte_size
+		4, 0x0d, 0, 0x03, 0x08, 0x49, 0x13, 0x38, 0x0b, 0, 0, // member: name, type, data_member_location
+		5, 0x2e, 1, 0x03, 0x08, 0x11, 0x01, 0x12, 0x06, 0x49, 0x13, 0, 0, // subprogram: name, low_pc, high_pc, type
+		6, 0x05, 0, 0x03, 0x08, 0x49, 0x13, 0, 0, // formal_parameter: name, type
+		0,
+	}
+
+	var b bytes.Buffer
+	b.Write([]byte{0, 0, 0, 0, 4, 0, 0, 0, 0, 0, 8}) // unit_length は最後に埋める
+	cstring := func(s string) { b.WriteString(s); b.WriteByte(0) }
+	u32 := func(v uint32) { binary.Write(&b, binary.LittleEndian, v) }
+
+	b.WriteByte(1)
+	cstring("main.c")
+	b.WriteByte(0x0c)
+
+	intOffset := uint32(b.Len())
+	b.WriteByte(2)
+	cstring("int")
+	b.Write([]byte{4, 0x05})
+
+	b.WriteByte(3)
+	cstring("point")
+	b.WriteByte(8)
+	for i, name := range []string{"x", "y"} {
+		b.WriteByte(4)
+		cstring(name)
+		u32(intOffset)
+		b.WriteByte(byte(i * 4))
+	}
+	b.WriteByte(0)
+
+	b.WriteByte(5)
+	cstring("add")
+	binary.Write(&b, binary.LittleEndian, uint64(0x401000))
+	u32(0x20)
+	u32(intOffset)
+	for _, name := range []string{"a", "b"} {
+		b.WriteByte(6)
+		cstring(name)
+		u32(intOffset)
+	}
+	b.WriteByte(0)
+	b.WriteByte(0)
+
+	info = b.Bytes()
+	binary.LittleEndian.PutUint32(info, uint32(len(info)-4))
+	return abbrev, info
+}
+
+func readTestDWARF(abbrev, info []byte) (*entities.DebugInfo, error) {
+	data, err := dwarf.New(abbrev, nil, nil, info, nil, nil, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return readDebugInfo(context.Background(), data)
+}
+
+func TestReadDebugInfo(t *testing.T) {
+	got, err := readTestDWARF(testDWARF())
+	if err != nil {
+		t.Fatalf("readDebugInfo returned error: %v", err)
+	}
+
+	if len(got.CompileUnits) != 1 || got.CompileUnits[0].Name != "main.c" || got.CompileUnits[0].Language != "C99" {
+		t.Errorf("CompileUnits = %+v, want main.c (C99)", got.CompileUnits)
+	}
+	if len(got.Types) != 1 {
+		t.Fatalf("Types = %+v, want struct point", got.Types)
+	}
+	wantType := "struct point {  // size 8\n    int x;  // offset 0\n    int y;  // offset 4\n};"
+	if s := FormatDebugType(got.Types[0]); s != wantType {
+		t.Errorf("FormatDebugType() = %q, want %q", s, wantType)
+	}
+	if len(got.Functions) != 1 {
+		t.Fatalf("Functions = %+v, want add", got.Functions)
+	}
+	fn := got.Functions[0]
+	if s := FormatDebugFunction(fn); s != "int add(int a, int b)" {
+		t.Errorf("FormatDebugFunction() = %q, want %q", s, "int add(int a, int b)")
+	}
+	if fn.LowPC != 0x401000 || fn.HighPC != 0x401020 {
+		t.Errorf("add pc range = [0x%x, 0x%x), want [0x401000, 0x401020)", fn.LowPC, fn.HighPC)
+	}
+}
+
+func TestReadDebugInfoMalformed(t *testing.T) {
+	abbrev, info := testDWARF()
+	corrupt := func(offset int, value ...byte) []byte {
+		data := append([]byte(nil), info...)
+		copy(data[offset:], value)
+		return data
+	}
+
+	tests := []struct {
+		name   string
+		abbrev []byte
+		info   []byte
+	}{
+		{"empty info", abbrev, nil},
+		{"header only", abbrev, info[:11]},
+		{"truncated in compile unit", abbrev, info[:14]},
+		{"truncated in function", abbrev, info[:len(info)-12]},
+		{"unit length past end", abbrev, corrupt(0, 0xff, 0xff, 0, 0)},
+		{"unsupported version", abbrev, corrupt(4, 9, 0)},
+		{"abbrev offset out of range", abbrev, corrupt(6, 0xff, 0xff, 0, 0)},
+		{"bad address size", abbrev, corrupt(10, 3)},
+		{"unknown abbrev code", abbrev, corrupt(11, 0x7f)},
+		{"empty abbrev", nil, info},
+		{"truncated abbrev", abbrev[:12], info},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readTestDWARF(tt.abbrev, tt.info)
+			if err == nil {
+				t.Errorf("readDebugInfo() = %+v, want error", got)
+			}
+		})
+	}
+}
+
+func TestReadDebugInfoBadTypeReference(t *testing.T) {
+	abbrev, info := testDWARF()
+	// add の戻り値の型をコンパイル単位の外に向ける
+	offset := bytes.Index(info, []byte("add\x00")) + 4 + 8 + 4
+	binary.LittleEndian.PutUint32(info[offset:], 0xffff)
+
+	got, err := readTestDWARF(abbrev, info)
+	if err != nil {
+		t.Fatalf("readDebugInfo returned error: %v", err)
+	}
+	if len(got.Functions) != 1 || got.Functions[0].ReturnType != "?" {
+		t.Errorf("Functions = %+v, want add with unknown return type", got.Functions)
+	}
+}
+
+func TestDebugInfoExecuteWithoutDWARF(t *testing.T) {
+	// go test のバイナリはデバッグ情報を含まない（-w でリンクされる）
+	path := writeTestFile(t, testExecutable(t))
+	_, err := NewDebugInfoUseCase().Execute(context.Background(), path)
+	if !errors.Is(err, ErrNoDebugInfo) {
+		t.Errorf("Execute() error = %v, want %v", err, ErrNoDebugInfo)
+	}
+}
+
+func FuzzReadDebugInfo(f *testing.F) {
+	abbrev, info := testDWARF()
+	f.Add(abbrev, info)
+	f.Add(abbrev, info[:len(info)/2])
+
+	f.Fuzz(func(t *testing.T, abbrev, info []byte) {
+		// 壊れた DWARF でもエラーを返し、panic しない
+		readTestDWARF(abbrev, info)
+	})
+}
@@ -0,0 +1,152 @@
+package binary
+
+import (
+	"debug/elf"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+var elfArchitectures = map[elf.Machine]string{
+	elf.EM_X86_64:  "x86_64",
+	elf.EM_386:     "x86",
+	elf.EM_AARCH64: "arm64",
+	elf.EM_ARM:     "arm",
+	elf.EM_RISCV:   "riscv",
+	elf.EM_MIPS:    "mips",
+	elf.EM_PPC64:   "ppc64",
+	elf.EM_PPC:     "ppc",
+	elf.EM_S390:    "s390x",
+}
+
+func inspectELF(path string) (*entities.BinaryInfo, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info := &entities.BinaryInfo{
+		Architecture: elfArchitectures[f.Machine],
+		Bits:         32,
+		Endianness:   "little",
+		EntryPoint:   f.Entry,
+	}
+	if info.Architecture == "" {
+		info.Architecture = strings.ToLower(strings.TrimPrefix(f.Machine.String(), "EM_"))
+	}
+	if f.Class == elf.ELFCLASS64 {
+		info.Bits = 64
+	}
+	if f.Data == elf.ELFDATA2MSB {
+		info.Endianness = "big"
+	}
+
+	var hasStack, execStack, hasRelro bool
+	for _, prog := range f.Progs {
+		switch prog.Type {
+		case elf.PT_INTERP:
+			data := make([]byte, prog.Filesz)
+			if _, err := prog.ReadAt(data, 0); err == nil {
+				info.Interpreter = strings.TrimRight(string(data), "\x00")
+			}
+		case elf.PT_GNU_STACK:
+			hasStack = true
+			execStack = prog.Flags&elf.PF_X != 0
+		case elf.PT_GNU_RELRO:
+			hasRelro = true
+		}
+	}
+
+	switch f.Type {
+	case elf.ET_EXEC:
+		info.Type = "executable"
+	case elf.ET_DYN:
+		info.Type = "shared_object"
+		if info.Interpreter != "" || hasDynFlag(f, elf.DT_FLAGS_1, uint64(elf.DF_1_PIE)) {
+			info.Type = "pie_executable"
+		}
+	case elf.ET_REL:
+		info.Type = "relocatable"
+	case elf.ET_CORE:
+		info.Type = "core"
+	default:
+		info.Type = strings.ToLower(strings.TrimPrefix(f.Type.String(), "ET_"))
+	}
+
+	for _, section := range f.Sections {
+		if section.Type == elf.SHT_NULL {
+			continue
+		}
+		info.Sections = append(info.Sections, entities.BinarySection{
+			Name:        section.Name,
+			Address:     section.Addr,
+			Offset:      section.Offset,
+			Size:        section.Size,
+			Permissions: permissions(section.Flags&elf.SHF_ALLOC != 0, section.Flags&elf.SHF_WRITE != 0, section.Flags&elf.SHF_EXECINSTR != 0),
+		})
+	}
+	info.Stripped = f.Section(".symtab") == nil
+
+	info.Libraries, _ = f.ImportedLibraries()
+	if imported, err := f.ImportedSymbols(); err == nil {
+		for _, symbol := range imported {
+			info.Imports = append(info.Imports, entities.BinarySymbol{Name: symbol.Name, Library: symbol.Library})
+		}
+	}
+	if dynamic, err := f.DynamicSymbols(); err == nil {
+		for _, symbol := range dynamic {
+			bind := elf.ST_BIND(symbol.Info)
+			kind := elf.ST_TYPE(symbol.Info)
+			if symbol.Section == elf.SHN_UNDEF || symbol.Name == "" || (bind != elf.STB_GLOBAL && bind != elf.STB_WEAK) {
+				continue
+			}
+			if kind != elf.STT_FUNC && kind != elf.STT_OBJECT {
+				continue
+			}
+			info.Exports = append(info.Exports, entities.BinarySymbol{Name: symbol.Name, Address: symbol.Value})
+		}
+	}
+
+	canary, fortify := canaryHints(info.Imports)
+	if !canary && !info.Stripped {
+		// 静的リンクの場合は .symtab の定義済みシンボルから判定する
+		if symbols, err := f.Symbols(); err == nil {
+			for _, symbol := range symbols {
+				if symbol.Name == "__stack_chk_fail" || symbol.Name == "__stack_chk_guard" {
+					canary = true
+					break
+				}
+			}
+		}
+	}
+
+	info.Security = entities.BinarySecurity{
+		PIE:         info.Type == "pie_executable",
+		NX:          hasStack && !execStack,
+		RELRO:       "none",
+		StackCanary: canary,
+		Fortify:     fortify,
+	}
+	if hasRelro {
+		info.Security.RELRO = "partial"
+		if hasDynFlag(f, elf.DT_FLAGS, uint64(elf.DF_BIND_NOW)) || hasDynFlag(f, elf.DT_FLAGS_1, uint64(elf.DF_1_NOW)) {
+			info.Security.RELRO = "full"
+		}
+	}
+	return info, nil
+}
+
+// hasDynFlag dynamic セクションのフラグのエントリーに flag が立っているかを判定
+func hasDynFlag(f *elf.File, tag elf.DynTag, flag uint64) bool {
+	values, err := f.DynValue(tag)
+	if err != nil {
+		return false
+	}
+	for _, value := range values {
+		if value&flag != 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+package binary
+
+import (
+	"context"
+	"debug/buildinfo"
+	"debug/gosym"
+	"encoding/binary"
+	"fmt"
+	"runtime/debug"
+	"sort"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// maxGoFunctions 解析結果に保存する関数の一覧の上限
+const maxGoFunctions = 20000
+
+// pclntab のマジック（Go 1.2, 1.16, 1.18, 1.20 以降）
+var pclntabMagics = []uint32{0xfffffffb, 0xfffffffa, 0xfffffff0, 0xfffffff1}
+
+// GoBinaryUseCase ランタイム自身のメタデータから、strip されたものも含めて Go 実行ファイルの
+// ビルド情報と関数名を復元する
+type GoBinaryUseCase struct{}
+
+// NewGoBinaryUseCase Go バイナリ解析のユースケースを作成
+func NewGoBinaryUseCase() *GoBinaryUseCase {
+	return &GoBinaryUseCase{}
+}
+
+// IsGoBinary path の実行ファイルに Go のビルド情報があるかを判定
+func IsGoBinary(path string) bool {
+	_, err := buildinfo.ReadFile(path)
+	return err == nil
+}
+
+// Execute path の実行ファイルのビルド情報と pclntab を読み込む
+func (uc *GoBinaryUseCase) Execute(ctx context.Context, path string) (*entities.GoBinaryInfo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	bi, err := buildinfo.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("not a Go executable: %w", err)
+	}
+
+	info := &entities.GoBinaryInfo{
+		GoVersion: bi.GoVersion,
+		Path:      bi.Path,
+		Main:      goModule(&bi.Main),
+		Deps:      []entities.GoModule{},
+		Settings:  []entities.GoBuildSetting{},
+		Packages:  []entities.GoPackage{},
+		Functions: []entities.GoFunction{},
+	}
+	for _, dep := range bi.Deps {
+		info.Deps = append(info.Deps, goModule(dep))
+	}
+	for _, setting := range bi.Settings {
+		info.Settings = append(info.Settings, entities.GoBuildSetting{Key: setting.Key, Value: setting.Value})
+	}
+
+	// 関数名が取れなくてもビルド情報だけで結果とする
+	img, err := openImage(path)
+	if err != nil {
+		info.SymbolsError = err.Error()
+		return info, nil
+	}
+	defer img.Close()
+
+	table, source, err := goSymbolTable(img)
+	if err != nil {
+		info.SymbolsError = err.Error()
+		return info, nil
+	}
+	info.SymbolsSource = source
+
+	packages := make(map[string]int)
+	for _, fn := range table.Funcs {
+		info.FunctionsTotal++
+		packages[fn.PackageName()]++
+		if len(info.Functions) >= maxGoFunctions {
+			continue
+		}
+		file, line, _ := table.PCToLine(fn.Entry)
+		info.Functions = append(info.Functions, entities.GoFunction{
+			Name:    fn.Name,
+			Package: fn.PackageName(),
+			Entry:   fn.Entry,
+			End:     fn.End,
+			File:    file,
+			Line:    line,
+		})
+	}
+	for name, count := range packages {
+		info.Packages = append(info.Packages, entities.GoPackage{Name: name, Functions: count})
+	}
+	sort.Slice(info.Packages, func(i, j int) bool {
+		return info.Packages[i].Name < info.Packages[j].Name
+	})
+	return info, nil
+}
+
+func goModule(m *debug.Module) entities.GoModule {
+	module := entities.GoModule{Path: m.Path, Version: m.Version, Sum: m.Sum}
+	if m.Replace != nil {
+		replace := goModule(m.Replace)
+		module.Replace = &replace
+	}
+	return module
+}
+
+// GoBuildInfoManifest 依存関係のユースケースが埋め込まれたモジュール一覧を go.mod と同じように
+// 扱えるよう、Go 実行ファイルのビルド情報を "<name>.buildinfo" という疑似マニフェストにする
+func GoBuildInfoManifest(path, name string) (entities.FileInfo, bool) {
+	bi, err := buildinfo.ReadFile(path)
+	if err != nil || len(bi.Deps) == 0 && bi.Main.Path == "" {
+		return entities.FileInfo{}, false
+	}
+	return entities.FileInfo{
+		Name:     name + ".buildinfo",
+		Language: "go",
+		Content:  bi.String(),
+	}, true
+}
+
+// goSymbolTable Go 実行ファイルの pclntab を探して解析する
+// スタックトレースのためにランタイムが必要とするので strip しても残っている。名前付きの
+// セクションがない場合は、ロードされるセクションからヘッダーを探す
+func goSymbolTable(img *image) (*gosym.Table, string, error) {
+	for _, name := range []string{".gopclntab", "__gopclntab"} {
+		if section := img.section(name); section != nil {
+			data, err := section.data()
+			if err != nil {
+				return nil, "", err
+			}
+			table, err := parsePclntab(img, data)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to parse %s: %w", name, err)
+			}
+			return table, "section:" + name, nil
+		}
+	}
+
+	// PE は runtime.pclntab シンボルが残っていればその位置から読む
+	if symbol, ok := img.symbol("runtime.pclntab"); ok {
+		if end, ok := img.symbol("runtime.epclntab"); ok && end.addr > symbol.addr {
+			if data, err := img.read(symbol.addr, end.addr-symbol.addr); err == nil {
+				if table, err := parsePclntab(img, data); err == nil {
+					return table, "symbol:runtime.pclntab", nil
+				}
+			}
+		}
+	}
+
+	for i := range img.sections {
+		section := &img.sections[i]
+		if section.exec || section.reader == nil {
+			continue
+		}
+		data, err := section.data()
+		if err != nil {
+			continue
+		}
+		for offset := 0; offset+16 <= len(data); offset += 4 {
+			if !isPclntabHeader(data[offset:]) {
+				continue
+			}
+			if table, err := parsePclntab(img, data[offset:]); err == nil && len(table.Funcs) > 0 {
+				return table, fmt.Sprintf("scan:%s+0x%x", section.name, offset), nil
+			}
+		}
+	}
+	return nil, "", fmt.Errorf("pclntab not found")
+}
+
+// isPclntabHeader マジック、パディング、命令の単位、ポインターサイズを確認
+func isPclntabHeader(data []byte) bool {
+	if len(data) < 8 || data[4] != 0 || data[5] != 0 {
+		return false
+	}
+	if quantum := data[6]; quantum != 1 && quantum != 2 && quantum != 4 {
+		return false
+	}
+	if ptrSize := data[7]; ptrSize != 4 && ptrSize != 8 {
+		return false
+	}
+	le := binary.LittleEndian.Uint32(data)
+	be := binary.BigEndian.Uint32(data)
+	for _, magic := range pclntabMagics {
+		if le == magic || be == magic {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePclntab pclntab のデータからシンボルテーブルを作成
+// gosym は不正なテーブルで panic することがあり、候補を探す間はそれも想定内
+func parsePclntab(img *image, data []byte) (table *gosym.Table, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			table, err = nil, fmt.Errorf("malformed pclntab: %v", r)
+		}
+	}()
+
+	table, err = gosym.NewTable(nil, gosym.NewLineTable(data, goTextStart(img, data)))
+	if err != nil {
+		return nil, err
+	}
+	return table, nil
+}
+
+// goTextStart runtime.text のアドレスを返す。Go 1.18 以降のテーブルの関数の開始位置はこれからの相対値
+func goTextStart(img *image, data []byte) uint64 {
+	if symbol, ok := img.symbol("runtime.text"); ok {
+		return symbol.addr
+	}
+
+	// 1.18 以降のヘッダーは magic, pad, quantum, ptrsize, nfunc, nfiles, textStart の順
+	if isPclntabHeader(data) && (data[0]&0xfe == 0xf0 || data[3]&0xfe == 0xf0) {
+		ptrSize := int(data[7])
+		offset := 8 + 2*ptrSize
+		if len(data) >= offset+ptrSize {
+			// リトルエンディアンではマジックの先頭バイトが 0xff にならない
+			order := binary.ByteOrder(binary.LittleEndian)
+			if data[0] == 0xff {
+				order = binary.BigEndian
+			}
+			var start uint64
+			if ptrSize == 8 {
+				start = order.Uint64(data[offset:])
+			} else {
+				start = uint64(order.Uint32(data[offset:]))
+			}
+			if start != 0 {
+				return start
+			}
+		}
+	}
+
+	if section := img.section(".text", "__text"); section != nil {
+		return section.addr
+	}
+	return 0
+}
@@ -0,0 +1,245 @@
+package binary
+
+import (
+	"debug/dwarf"
+	"debug/elf"
+	"debug/pe"
+	"fmt"
+	"io"
+)
+
+// image シンボルの復元と逆アセンブルで使う、形式に依存しない実行ファイルの表現
+// ロードされるセクションを仮想アドレスで参照でき、strip されていなければシンボル
+// テーブルで定義されたシンボルも持つ
+type image struct {
+	format   string
+	arch     string
+	sections []imageSection
+	symbols  []imageSymbol
+	dwarf    func() (*dwarf.Data, error)
+	closer   io.Closer
+}
+
+type imageSection struct {
+	name   string
+	addr   uint64
+	offset uint64 // ファイル上の位置
+	size   uint64
+	exec   bool
+	reader io.ReaderAt
+}
+
+type imageSymbol struct {
+	name string
+	addr uint64
+	size uint64 // 0 のときは不明
+}
+
+func (s *imageSection) data() ([]byte, error) {
+	if s.reader == nil {
+		return nil, fmt.Errorf("section %s has no data in the file", s.name)
+	}
+	data := make([]byte, s.size)
+	n, err := s.reader.ReadAt(data, 0)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func openImage(path string) (*image, error) {
+	format, err := DetectFileFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatELF:
+		return openELFImage(path)
+	case FormatPE:
+		return openPEImage(path)
+	case FormatMachO:
+		return openMachOImage(path)
+	}
+	return nil, fmt.Errorf("not an ELF, PE or Mach-O executable")
+}
+
+func (img *image) Close() error {
+	return img.closer.Close()
+}
+
+// section 指定した名前のいずれかを持つ最初のセクションを返す
+func (img *image) section(names ...string) *imageSection {
+	for _, name := range names {
+		for i := range img.sections {
+			if img.sections[i].name == name {
+				return &img.sections[i]
+			}
+		}
+	}
+	return nil
+}
+
+// symbol 定義されたシンボルのアドレスを返す
+func (img *image) symbol(name string) (imageSymbol, bool) {
+	for _, symbol := range img.symbols {
+		if symbol.name == name {
+			return symbol, true
+		}
+	}
+	return imageSymbol{}, false
+}
+
+// read 仮想アドレスから size バイトを返す
+func (img *image) read(addr, size uint64) ([]byte, error) {
+	for i := range img.sections {
+		s := &img.sections[i]
+		if s.reader == nil || addr < s.addr || addr >= s.addr+s.size {
+			continue
+		}
+		if addr+size > s.addr+s.size {
+			size = s.addr + s.size - addr
+		}
+		data := make([]byte, size)
+		n, err := s.reader.ReadAt(data, int64(addr-s.addr))
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+		return data[:n], nil
+	}
+	return nil, fmt.Errorf("address 0x%x is not inside a loaded section", addr)
+}
+
+func openELFImage(path string) (*image, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	img := &image{format: FormatELF, arch: elfArchitectures[f.Machine], dwarf: f.DWARF, closer: f}
+	for _, s := range f.Sections {
+		if s.Flags&elf.SHF_ALLOC == 0 {
+			continue
+		}
+		section := imageSection{name: s.Name, addr: s.Addr, offset: s.Offset, size: s.Size, exec: s.Flags&elf.SHF_EXECINSTR != 0}
+		if s.Type != elf.SHT_NOBITS {
+			section.reader = s
+		}
+		img.sections = append(img.sections, section)
+	}
+
+	symbols, _ := f.Symbols()
+	for _, s := range symbols {
+		kind := elf.ST_TYPE(s.Info)
+		if s.Section == elf.SHN_UNDEF || s.Name == "" || (kind != elf.STT_FUNC && kind != elf.STT_OBJECT && kind != elf.STT_NOTYPE) {
+			continue
+		}
+		img.symbols = append(img.symbols, imageSymbol{name: s.Name, addr: s.Value, size: s.Size})
+	}
+	// 動的シンボルしか残っていない共有ライブラリ
+	if len(img.symbols) == 0 {
+		dynamic, _ := f.DynamicSymbols()
+		for _, s := range dynamic {
+			if s.Section != elf.SHN_UNDEF && s.Name != "" && elf.ST_TYPE(s.Info) == elf.STT_FUNC {
+				img.symbols = append(img.symbols, imageSymbol{name: s.Name, addr: s.Value, size: s.Size})
+			}
+		}
+	}
+	return img, nil
+}
+
+func openPEImage(path string) (*image, error) {
+	f, err := pe.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var imageBase uint64
+	switch header := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		imageBase = uint64(header.ImageBase)
+	case *pe.OptionalHeader64:
+		imageBase = header.ImageBase
+	}
+
+	img := &image{format: FormatPE, arch: peArchitectures[f.Machine], dwarf: f.DWARF, closer: f}
+	for _, s := range f.Sections {
+		section := imageSection{
+			name:   s.Name,
+			addr:   imageBase + uint64(s.VirtualAddress),
+			offset: uint64(s.Offset),
+			size:   uint64(s.VirtualSize),
+			exec:   s.Characteristics&peSectionExecute != 0,
+		}
+		if s.Size > 0 {
+			section.reader = s
+			if uint64(s.Size) < section.size {
+				section.size = uint64(s.Size)
+			}
+		}
+		img.sections = append(img.sections, section)
+	}
+
+	// COFF シンボルの値はセクション先頭からのオフセット
+	for _, s := range f.Symbols {
+		if s.SectionNumber <= 0 || int(s.SectionNumber) > len(f.Sections) || s.Name == "" {
+			continue
+		}
+		base := imageBase + uint64(f.Sections[s.SectionNumber-1].VirtualAddress)
+		img.symbols = append(img.symbols, imageSymbol{name: s.Name, addr: base + uint64(s.Value)})
+	}
+	if exports := peExportsFromImage(f, imageBase); len(exports) > 0 {
+		img.symbols = append(img.symbols, exports...)
+	}
+	return img, nil
+}
+
+func peExportsFromImage(f *pe.File, imageBase uint64) []imageSymbol {
+	var directories []pe.DataDirectory
+	switch header := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		directories = header.DataDirectory[:header.NumberOfRvaAndSizes]
+	case *pe.OptionalHeader64:
+		directories = header.DataDirectory[:header.NumberOfRvaAndSizes]
+	}
+	var symbols []imageSymbol
+	for _, export := range peExports(f, directories, imageBase) {
+		symbols = append(symbols, imageSymbol{name: export.Name, addr: export.Address})
+	}
+	return symbols
+}
+
+func openMachOImage(path string) (*image, error) {
+	f, _, closeFile, err := openMachO(path)
+	if err != nil {
+		return nil, err
+	}
+
+	img := &image{format: FormatMachO, arch: machoArchitecture(f.Cpu), dwarf: f.DWARF, closer: closerFunc(closeFile)}
+	for _, s := range f.Sections {
+		section := imageSection{name: s.Name, addr: s.Addr, offset: uint64(s.Offset), size: s.Size}
+		if segment := f.Segment(s.Seg); segment != nil {
+			section.exec = segment.Prot&4 != 0
+		}
+		// zerofill セクション（__bss など）はファイル上にデータを持たない
+		if s.Offset != 0 {
+			section.reader = s
+		}
+		img.sections = append(img.sections, section)
+	}
+
+	if f.Symtab != nil {
+		for _, s := range f.Symtab.Syms {
+			if s.Type&machoNTypeMask == machoNTypeSection && s.Name != "" {
+				img.symbols = append(img.symbols, imageSymbol{name: s.Name, addr: s.Value})
+			}
+		}
+	}
+	return img, nil
+}
+
+type closerFunc func()
+
+func (f closerFunc) Close() error {
+	f()
+	return nil
+}
@@ -0,0 +1,162 @@
+package binary
+
+import (
+	"debug/macho"
+	"encoding/binary"
+	"fmt"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+const (
+	machoFlagAllowStackExecution = 0x20000
+	machoFlagPIE                 = 0x200000
+	machoLoadMain                = 0x80000028
+	machoLoadCodeSignature       = 0x1d
+	machoNTypeExternal           = 0x01
+	machoNTypeMask               = 0x0e
+	machoNTypeSection            = 0x0e
+)
+
+var machoArchitectures = map[macho.Cpu]string{
+	macho.CpuAmd64: "x86_64",
+	macho.Cpu386:   "x86",
+	macho.CpuArm64: "arm64",
+	macho.CpuArm:   "arm",
+	macho.CpuPpc64: "ppc64",
+	macho.CpuPpc:   "ppc",
+}
+
+func machoArchitecture(cpu macho.Cpu) string {
+	if arch, ok := machoArchitectures[cpu]; ok {
+		return arch
+	}
+	return fmt.Sprintf("cpu_%d", cpu)
+}
+
+// openMachO 単一アーキテクチャの Mach-O ファイル、またはユニバーサルバイナリの優先するスライスを開く
+func openMachO(path string) (*macho.File, []string, func(), error) {
+	fat, err := macho.OpenFat(path)
+	if err == nil {
+		var universal []string
+		chosen := fat.Arches[0].File
+		for _, arch := range fat.Arches {
+			universal = append(universal, machoArchitecture(arch.Cpu))
+			// 解析対象は arm64 / x86_64 を優先する
+			if arch.Cpu == macho.CpuArm64 || (arch.Cpu == macho.CpuAmd64 && chosen.Cpu != macho.CpuArm64) {
+				chosen = arch.File
+			}
+		}
+		return chosen, universal, func() { fat.Close() }, nil
+	}
+	if err != macho.ErrNotFat {
+		return nil, nil, nil, err
+	}
+
+	f, err := macho.Open(path)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return f, nil, func() { f.Close() }, nil
+}
+
+func inspectMachO(path string) (*entities.BinaryInfo, error) {
+	f, universal, closeFile, err := openMachO(path)
+	if err != nil {
+		return nil, err
+	}
+	defer closeFile()
+
+	info := &entities.BinaryInfo{
+		Architecture: machoArchitecture(f.Cpu),
+		Bits:         32,
+		Endianness:   "little",
+		Universal:    universal,
+	}
+	if f.Magic == macho.Magic64 {
+		info.Bits = 64
+	}
+	if f.ByteOrder == binary.BigEndian {
+		info.Endianness = "big"
+	}
+
+	switch f.Type {
+	case macho.TypeExec:
+		info.Type = "executable"
+	case macho.TypeDylib:
+		info.Type = "dylib"
+	case macho.TypeBundle:
+		info.Type = "bundle"
+	case macho.TypeObj:
+		info.Type = "relocatable"
+	default:
+		info.Type = f.Type.String()
+	}
+
+	var textBase uint64
+	signed := false
+	for _, load := range f.Loads {
+		raw := load.Raw()
+		if len(raw) < 8 {
+			continue
+		}
+		switch f.ByteOrder.Uint32(raw) {
+		case machoLoadMain:
+			if len(raw) >= 16 {
+				info.EntryPoint = f.ByteOrder.Uint64(raw[8:])
+			}
+		case machoLoadCodeSignature:
+			signed = true
+		}
+		if segment, ok := load.(*macho.Segment); ok && segment.Name == "__TEXT" {
+			textBase = segment.Addr
+		}
+	}
+	if info.EntryPoint != 0 {
+		// LC_MAIN の entryoff は __TEXT からのオフセット
+		info.EntryPoint += textBase
+	}
+
+	for _, section := range f.Sections {
+		perms := "---"
+		if segment := f.Segment(section.Seg); segment != nil {
+			perms = permissions(segment.Prot&1 != 0, segment.Prot&2 != 0, segment.Prot&4 != 0)
+		}
+		info.Sections = append(info.Sections, entities.BinarySection{
+			Name:        section.Seg + "," + section.Name,
+			Address:     section.Addr,
+			Offset:      uint64(section.Offset),
+			Size:        section.Size,
+			Permissions: perms,
+		})
+	}
+
+	info.Libraries, _ = f.ImportedLibraries()
+	if imported, err := f.ImportedSymbols(); err == nil {
+		for _, name := range imported {
+			info.Imports = append(info.Imports, entities.BinarySymbol{Name: name})
+		}
+	}
+	info.Stripped = true
+	if f.Symtab != nil {
+		for _, symbol := range f.Symtab.Syms {
+			if symbol.Type&machoNTypeMask == machoNTypeSection {
+				info.Stripped = false
+				if symbol.Type&machoNTypeExternal != 0 {
+					info.Exports = append(info.Exports, entities.BinarySymbol{Name: symbol.Name, Address: symbol.Value})
+				}
+			}
+		}
+	}
+
+	canary, fortify := canaryHints(info.Imports)
+	info.Security = entities.BinarySecurity{
+		PIE:         f.Flags&machoFlagPIE != 0 || info.Type == "dylib",
+		NX:          f.Flags&machoFlagAllowStackExecution == 0,
+		RELRO:       "n/a",
+		StackCanary: canary,
+		Fortify:     fortify,
+		Signed:      signed,
+	}
+	return info, nil
+}
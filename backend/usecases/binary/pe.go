@@ -0,0 +1,189 @@
+package binary
+
+import (
+	"bytes"
+	"debug/pe"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+const (
+	peDirectoryExport     = 0
+	peDirectorySecurity   = 4
+	peDirectoryLoadConfig = 10
+
+	peSectionExecute = 0x20000000
+	peSectionRead    = 0x40000000
+	peSectionWrite   = 0x80000000
+)
+
+var peArchitectures = map[uint16]string{
+	pe.IMAGE_FILE_MACHINE_AMD64: "x86_64",
+	pe.IMAGE_FILE_MACHINE_I386:  "x86",
+	pe.IMAGE_FILE_MACHINE_ARM64: "arm64",
+	pe.IMAGE_FILE_MACHINE_ARMNT: "arm",
+	pe.IMAGE_FILE_MACHINE_ARM:   "arm",
+}
+
+func inspectPE(path string) (*entities.BinaryInfo, error) {
+	f, err := pe.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info := &entities.BinaryInfo{
+		Architecture: peArchitectures[f.Machine],
+		Endianness:   "little",
+		Type:         "executable",
+	}
+	if info.Architecture == "" {
+		info.Architecture = fmt.Sprintf("machine_0x%04x", f.Machine)
+	}
+	if f.Characteristics&pe.IMAGE_FILE_DLL != 0 {
+		info.Type = "dll"
+	}
+
+	var imageBase uint64
+	var dllCharacteristics uint16
+	var directories []pe.DataDirectory
+	switch header := f.OptionalHeader.(type) {
+	case *pe.OptionalHeader32:
+		info.Bits = 32
+		imageBase = uint64(header.ImageBase)
+		info.EntryPoint = imageBase + uint64(header.AddressOfEntryPoint)
+		dllCharacteristics = header.DllCharacteristics
+		directories = header.DataDirectory[:header.NumberOfRvaAndSizes]
+	case *pe.OptionalHeader64:
+		info.Bits = 64
+		imageBase = header.ImageBase
+		info.EntryPoint = imageBase + uint64(header.AddressOfEntryPoint)
+		dllCharacteristics = header.DllCharacteristics
+		directories = header.DataDirectory[:header.NumberOfRvaAndSizes]
+	default:
+		return nil, fmt.Errorf("missing optional header")
+	}
+
+	for _, section := range f.Sections {
+		info.Sections = append(info.Sections, entities.BinarySection{
+			Name:        section.Name,
+			Address:     imageBase + uint64(section.VirtualAddress),
+			Offset:      uint64(section.Offset),
+			Size:        uint64(section.VirtualSize),
+			Permissions: permissions(section.Characteristics&peSectionRead != 0, section.Characteristics&peSectionWrite != 0, section.Characteristics&peSectionExecute != 0),
+		})
+	}
+	info.Stripped = len(f.Symbols) == 0
+
+	info.Libraries, _ = f.ImportedLibraries()
+	if imported, err := f.ImportedSymbols(); err == nil {
+		// "関数名:DLL名" の形式
+		for _, entry := range imported {
+			name, library, _ := strings.Cut(entry, ":")
+			info.Imports = append(info.Imports, entities.BinarySymbol{Name: name, Library: library})
+		}
+	}
+	info.Exports = peExports(f, directories, imageBase)
+
+	canary, _ := canaryHints(info.Imports)
+	if !canary {
+		// /GS のクッキーはインポートされないため Load Config の SecurityCookie で判定する
+		canary = peSecurityCookie(f, directories, info.Bits) != 0
+	}
+
+	info.Security = entities.BinarySecurity{
+		PIE:         dllCharacteristics&pe.IMAGE_DLLCHARACTERISTICS_DYNAMIC_BASE != 0,
+		NX:          dllCharacteristics&pe.IMAGE_DLLCHARACTERISTICS_NX_COMPAT != 0,
+		RELRO:       "n/a",
+		StackCanary: canary,
+		CFG:         dllCharacteristics&pe.IMAGE_DLLCHARACTERISTICS_GUARD_CF != 0,
+		Signed:      len(directories) > peDirectorySecurity && directories[peDirectorySecurity].Size > 0,
+	}
+	return info, nil
+}
+
+// peReadRVA 相対仮想アドレスから size バイトを読む
+func peReadRVA(f *pe.File, rva, size uint32) []byte {
+	for _, section := range f.Sections {
+		if rva < section.VirtualAddress || rva >= section.VirtualAddress+section.Size {
+			continue
+		}
+		offset := rva - section.VirtualAddress
+		if offset+size > section.Size {
+			size = section.Size - offset
+		}
+		data := make([]byte, size)
+		if _, err := section.ReadAt(data, int64(offset)); err != nil {
+			return nil
+		}
+		return data
+	}
+	return nil
+}
+
+// peString RVA にある NUL 終端の文字列を読む
+func peString(f *pe.File, rva uint32) string {
+	data := peReadRVA(f, rva, 512)
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = data[:i]
+	}
+	return string(data)
+}
+
+// peExports エクスポートディレクトリをたどる。debug/pe はこれを解析しない
+func peExports(f *pe.File, directories []pe.DataDirectory, imageBase uint64) []entities.BinarySymbol {
+	if len(directories) <= peDirectoryExport || directories[peDirectoryExport].Size == 0 {
+		return nil
+	}
+	dir := peReadRVA(f, directories[peDirectoryExport].VirtualAddress, 40)
+	if len(dir) < 40 {
+		return nil
+	}
+
+	le := binary.LittleEndian
+	numberOfNames := le.Uint32(dir[24:])
+	functions := le.Uint32(dir[28:])
+	names := le.Uint32(dir[32:])
+	ordinals := le.Uint32(dir[36:])
+	if numberOfNames > 1<<16 {
+		return nil
+	}
+
+	nameTable := peReadRVA(f, names, numberOfNames*4)
+	ordinalTable := peReadRVA(f, ordinals, numberOfNames*2)
+	if uint32(len(nameTable)) < numberOfNames*4 || uint32(len(ordinalTable)) < numberOfNames*2 {
+		return nil
+	}
+
+	exports := make([]entities.BinarySymbol, 0, numberOfNames)
+	for i := uint32(0); i < numberOfNames; i++ {
+		symbol := entities.BinarySymbol{Name: peString(f, le.Uint32(nameTable[i*4:]))}
+		ordinal := uint32(le.Uint16(ordinalTable[i*2:]))
+		if address := peReadRVA(f, functions+ordinal*4, 4); len(address) == 4 {
+			symbol.Address = imageBase + uint64(le.Uint32(address))
+		}
+		exports = append(exports, symbol)
+	}
+	return exports
+}
+
+// peSecurityCookie ロード構成ディレクトリから /GS クッキーのアドレスを返す
+func peSecurityCookie(f *pe.File, directories []pe.DataDirectory, bits int) uint64 {
+	if len(directories) <= peDirectoryLoadConfig || directories[peDirectoryLoadConfig].Size == 0 {
+		return 0
+	}
+	data := peReadRVA(f, directories[peDirectoryLoadConfig].VirtualAddress, directories[peDirectoryLoadConfig].Size)
+	if bits == 64 {
+		if len(data) >= 0x60 {
+			return binary.LittleEndian.Uint64(data[0x58:])
+		}
+		return 0
+	}
+	if len(data) >= 0x40 {
+		return uint64(binary.LittleEndian.Uint32(data[0x3c:]))
+	}
+	return 0
+}
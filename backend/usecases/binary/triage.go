@@ -0,0 +1,330 @@
+package binary
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+const (
+	// minStringLength は抽出する文字列の最小文字数
+	minStringLength = 4
+	// maxTriageStrings と maxTriageIOCs は 1 ファイルあたりの保存件数の上限
+	maxTriageStrings = 200000
+	maxTriageIOCs    = 5000
+	// maxTriageSize を超えるファイルは読み込まない
+	maxTriageSize = 512 << 20
+	// entropyBlockSize は実行形式でないファイルのエントロピーを計測する単位
+	entropyBlockSize = 64 << 10
+	// highEntropyThreshold 以上の領域は圧縮・暗号化されている可能性が高い
+	highEntropyThreshold = 7.2
+)
+
+// packerSections パッカーやプロテクターが残すセクション名
+var packerSections = map[string]string{
+	"UPX0":     "UPX",
+	"UPX1":     "UPX",
+	"UPX2":     "UPX",
+	".upx":     "UPX",
+	".aspack":  "ASPack",
+	".adata":   "ASPack",
+	".MPRESS1": "MPRESS",
+	".MPRESS2": "MPRESS",
+	".themida": "Themida",
+	".winlice": "WinLicense",
+	".vmp0":    "VMProtect",
+	".vmp1":    "VMProtect",
+	".vmp2":    "VMProtect",
+	"PEC2":     "PECompact",
+	"pec1":     "PECompact",
+	"PEC2TO":   "PECompact",
+	".petite":  "Petite",
+	".nsp0":    "NsPack",
+	".nsp1":    "NsPack",
+	"nsp0":     "NsPack",
+	"nsp1":     "NsPack",
+	".enigma1": "Enigma Protector",
+	".enigma2": "Enigma Protector",
+	"MEW":      "MEW",
+	".packed":  "RLPack",
+	".RLPack":  "RLPack",
+}
+
+// packerSignatures パッカーのスタブが埋め込むバイト列
+var packerSignatures = []struct {
+	name      string
+	signature []byte
+}{
+	{"UPX", []byte("UPX!")},
+	{"UPX", []byte("$Info: This file is packed with the UPX")},
+	{"ASPack", []byte("ASPack")},
+	{"MPRESS", []byte("MPRESS")},
+	{"PECompact", []byte("PECompact2")},
+	{"Themida", []byte("Themida")},
+	{"Enigma Protector", []byte("Enigma protector")},
+	{"FSG", []byte("FSG!")},
+}
+
+var (
+	urlPattern      = regexp.MustCompile(`(?i)\b(?:https?|ftp|wss?)://[A-Za-z0-9\-._~:/?#\[\]@!$&'()*+,;=%]+`)
+	ipPattern       = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	emailPattern    = regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`)
+	winPathPattern  = regexp.MustCompile(`(?i)(?:\b[A-Z]:\\|\\\\[A-Za-z0-9.$_-]+\\|%[A-Z_]+%\\)[^\s"*?<>|]+`)
+	unixPathPattern = regexp.MustCompile(`(?:^|[\s"'=:(])(/(?:etc|tmp|var|usr|bin|sbin|home|root|dev|proc|opt|Library|Users|System|Applications|private)/[A-Za-z0-9._\-/]+)`)
+	registryPattern = regexp.MustCompile(`(?i)\b(?:HKEY_[A-Z_]+|HKLM|HKCU|HKCR)\\[^\s"*?<>|]+`)
+)
+
+// BinaryTriageUseCase バイナリファイルの一次トリアージを行う
+// 文字列、エントロピー、パッカーのシグネチャ、侵害の痕跡を調べる
+type BinaryTriageUseCase struct{}
+
+// NewBinaryTriageUseCase バイナリトリアージのユースケースを作成
+func NewBinaryTriageUseCase() *BinaryTriageUseCase {
+	return &BinaryTriageUseCase{}
+}
+
+// Execute path に保存されたファイルをトリアージする
+// 文字列は概要とは別に保存してページングするため、分けて返す
+func (uc *BinaryTriageUseCase) Execute(ctx context.Context, path string) (*entities.BinaryTriage, []entities.ExtractedString, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stat.Size() > maxTriageSize {
+		return nil, nil, fmt.Errorf("file is larger than %d bytes", maxTriageSize)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	triage := &entities.BinaryTriage{
+		Size:     int64(len(data)),
+		Entropy:  ShannonEntropy(data),
+		Sections: []entities.SectionEntropy{},
+		Packers:  []entities.PackerMatch{},
+		IOCs:     []entities.IOC{},
+	}
+
+	var sectionNames []string
+	if img, err := openImage(path); err == nil {
+		triage.Format = img.format
+		for _, section := range img.sections {
+			sectionNames = append(sectionNames, section.name)
+			if section.reader == nil || section.size == 0 {
+				continue
+			}
+			content, err := section.data()
+			if err != nil {
+				continue
+			}
+			triage.Sections = append(triage.Sections, sectionEntropy(section.name, int64(section.offset), content))
+			// 実行可能な領域のエントロピーが高いのはパッカーで圧縮されたコードの典型
+			if section.exec && triage.Sections[len(triage.Sections)-1].HighEntropy {
+				triage.Packers = appendPacker(triage.Packers, "unknown packer", fmt.Sprintf("executable section %s has high entropy", section.name))
+			}
+		}
+		img.Close()
+	} else {
+		for offset := 0; offset < len(data); offset += entropyBlockSize {
+			end := offset + entropyBlockSize
+			if end > len(data) {
+				end = len(data)
+			}
+			name := fmt.Sprintf("0x%x-0x%x", offset, end)
+			triage.Sections = append(triage.Sections, sectionEntropy(name, int64(offset), data[offset:end]))
+		}
+	}
+
+	for _, name := range sectionNames {
+		if packer, ok := packerSections[name]; ok {
+			triage.Packers = appendPacker(triage.Packers, packer, "section "+name)
+		}
+	}
+	for _, sig := range packerSignatures {
+		if i := bytes.Index(data, sig.signature); i >= 0 {
+			triage.Packers = appendPacker(triage.Packers, sig.name, fmt.Sprintf("signature %q at 0x%x", sig.signature, i))
+		}
+	}
+	// 既知のパッカーが見つかった場合は汎用の判定を残さない
+	if len(triage.Packers) > 1 {
+		known := triage.Packers[:0]
+		for _, packer := range triage.Packers {
+			if packer.Name != "unknown packer" {
+				known = append(known, packer)
+			}
+		}
+		triage.Packers = known
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	strs := ExtractStrings(data, minStringLength)
+	triage.StringsTotal = len(strs)
+	if len(strs) > maxTriageStrings {
+		strs = strs[:maxTriageStrings]
+		triage.StringsCapped = true
+	}
+	triage.IOCs = ExtractIOCs(strs)
+	return triage, strs, nil
+}
+
+// ShannonEntropy data の 1 バイトあたりのエントロピー（0〜8 ビット）を返す
+func ShannonEntropy(data []byte) float64 {
+	if len(data) == 0 {
+		return 0
+	}
+	var counts [256]int
+	for _, b := range data {
+		counts[b]++
+	}
+	entropy := 0.0
+	size := float64(len(data))
+	for _, count := range counts {
+		if count == 0 {
+			continue
+		}
+		p := float64(count) / size
+		entropy -= p * math.Log2(p)
+	}
+	return math.Round(entropy*1000) / 1000
+}
+
+func sectionEntropy(name string, offset int64, data []byte) entities.SectionEntropy {
+	entropy := ShannonEntropy(data)
+	return entities.SectionEntropy{
+		Name:    name,
+		Offset:  offset,
+		Size:    int64(len(data)),
+		Entropy: entropy,
+		// 小さな領域はバイトの種類が少なくエントロピーが安定しない
+		HighEntropy: entropy >= highEntropyThreshold && len(data) >= 512,
+	}
+}
+
+func appendPacker(packers []entities.PackerMatch, name, evidence string) []entities.PackerMatch {
+	for _, packer := range packers {
+		if packer.Name == name {
+			return packers
+		}
+	}
+	return append(packers, entities.PackerMatch{Name: name, Evidence: evidence})
+}
+
+// ExtractStrings minLength 文字以上の印字可能な ASCII と UTF-16LE の文字列をオフセット順に探す
+func ExtractStrings(data []byte, minLength int) []entities.ExtractedString {
+	var result []entities.ExtractedString
+
+	start := -1
+	for i := 0; i <= len(data); i++ {
+		if i < len(data) && isPrintable(data[i]) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 && i-start >= minLength {
+			result = append(result, entities.ExtractedString{Offset: int64(start), Encoding: "ascii", Value: string(data[start:i])})
+		}
+		start = -1
+	}
+
+	// UTF-16LE は印字可能文字と 0x00 の組が続く箇所（奇数位置から始まるものも拾う）
+	for i := 0; i+1 < len(data); {
+		if !isPrintable(data[i]) || data[i+1] != 0 {
+			i++
+			continue
+		}
+		j := i
+		var b strings.Builder
+		for j+1 < len(data) && isPrintable(data[j]) && data[j+1] == 0 {
+			b.WriteByte(data[j])
+			j += 2
+		}
+		if b.Len() >= minLength {
+			result = append(result, entities.ExtractedString{Offset: int64(i), Encoding: "utf16le", Value: b.String()})
+		}
+		i = j
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Offset < result[j].Offset
+	})
+	return result
+}
+
+func isPrintable(b byte) bool {
+	return b >= 0x20 && b < 0x7f || b == '\t'
+}
+
+// ExtractIOCs 抽出した文字列から URL、IP アドレス、メールアドレス、ファイルパス、
+// レジストリキーを探す。同じ値は 1 回だけ数える
+func ExtractIOCs(strs []entities.ExtractedString) []entities.IOC {
+	var iocs []entities.IOC
+	index := make(map[string]int)
+	add := func(kind, value string, offset int64) {
+		key := kind + "|" + value
+		if i, ok := index[key]; ok {
+			iocs[i].Count++
+			return
+		}
+		if len(iocs) >= maxTriageIOCs {
+			return
+		}
+		index[key] = len(iocs)
+		iocs = append(iocs, entities.IOC{Kind: kind, Value: value, Offset: offset, Count: 1})
+	}
+
+	for _, s := range strs {
+		value := s.Value
+		for _, m := range urlPattern.FindAllString(value, -1) {
+			add("url", strings.TrimRight(m, ".,;)'\""), s.Offset)
+		}
+		// URL の一部として現れる IP・パスは URL として数える
+		rest := urlPattern.ReplaceAllString(value, " ")
+		for _, m := range ipPattern.FindAllString(rest, -1) {
+			if isIOCAddress(m) {
+				add("ip", m, s.Offset)
+			}
+		}
+		for _, m := range emailPattern.FindAllString(rest, -1) {
+			add("email", m, s.Offset)
+		}
+		for _, m := range registryPattern.FindAllString(rest, -1) {
+			add("registry", m, s.Offset)
+		}
+		for _, m := range winPathPattern.FindAllString(rest, -1) {
+			add("path", strings.TrimRight(m, ".,;)'\""), s.Offset)
+		}
+		for _, m := range unixPathPattern.FindAllStringSubmatch(rest, -1) {
+			add("path", strings.TrimRight(m[1], ".,;)"), s.Offset)
+		}
+	}
+	if iocs == nil {
+		iocs = []entities.IOC{}
+	}
+	return iocs
+}
+
+// isIOCAddress 不正、未指定、ループバック、ブロードキャストのアドレスを除く
+// これらはほとんどがバージョン番号や定数
+func isIOCAddress(candidate string) bool {
+	ip := net.ParseIP(candidate)
+	if ip == nil {
+		return false
+	}
+	return !ip.IsUnspecified() && !ip.IsLoopback() && !ip.Equal(net.IPv4bcast)
+}
@@ -0,0 +1,373 @@
+package callgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+var (
+	// ErrEntryNotFound 指定したエントリーポイントに一致する関数がない
+	ErrEntryNotFound = errors.New("entry point not found")
+	// ErrAmbiguousEntry 指定したエントリーポイントに複数の関数が一致する
+	ErrAmbiguousEntry = errors.New("entry point is ambiguous")
+)
+
+const (
+	defaultDepth = 5
+	maxDepth     = 15
+	maxNodes     = 500
+)
+
+// CallGraphUseCase アップロードされた Go ソースから静的なコールグラフを作成する
+type CallGraphUseCase struct{}
+
+// NewCallGraphUseCase コールグラフのユースケースを作成
+func NewCallGraphUseCase() *CallGraphUseCase {
+	return &CallGraphUseCase{}
+}
+
+// Execute entry から到達できるコールグラフを作成する
+// entry は "Func"、"Type.Method"、"pkg.Type.Method" のいずれかで指定する
+// インターフェース値の呼び出しは、そのインターフェースを実装するプロジェクトのすべての型に展開する
+func (uc *CallGraphUseCase) Execute(ctx context.Context, files []entities.FileInfo, entry string, depth int, includeExternal bool) (*entities.CallGraph, error) {
+	if depth <= 0 {
+		depth = defaultDepth
+	}
+	if depth > maxDepth {
+		depth = maxDepth
+	}
+
+	program := loadGoProgram(files)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	root, err := findEntry(program, entry)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &graphBuilder{
+		program:         program,
+		includeExternal: includeExternal,
+		nodes:           make(map[string]*entities.CallNode),
+		graph: &entities.CallGraph{
+			Entry: funcID(root),
+			Depth: depth,
+		},
+	}
+	b.addFunc(root)
+
+	type item struct {
+		fn    *types.Func
+		depth int
+	}
+	visited := map[*types.Func]bool{root: true}
+	queue := []item{{fn: root, depth: 0}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current.depth >= depth {
+			continue
+		}
+		for _, callee := range b.expand(current.fn) {
+			if visited[callee] {
+				continue
+			}
+			visited[callee] = true
+			if len(b.nodes) >= maxNodes {
+				b.graph.Truncated = true
+				continue
+			}
+			queue = append(queue, item{fn: callee, depth: current.depth + 1})
+		}
+	}
+
+	for _, node := range b.nodes {
+		b.graph.Nodes = append(b.graph.Nodes, *node)
+	}
+	sort.Slice(b.graph.Nodes, func(i, j int) bool { return b.graph.Nodes[i].ID < b.graph.Nodes[j].ID })
+
+	return b.graph, nil
+}
+
+// findEntry エントリーポイントの名前を宣言された関数に解決
+func findEntry(program *goProgram, entry string) (*types.Func, error) {
+	entry = strings.TrimSpace(entry)
+	var matches []*types.Func
+	for fn := range program.decls {
+		id := funcID(fn)
+		if id == entry || displayName(fn) == entry {
+			matches = append(matches, fn)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, entry)
+	case 1:
+		return matches[0], nil
+	}
+
+	candidates := make([]string, 0, len(matches))
+	for _, fn := range matches {
+		candidates = append(candidates, funcID(fn))
+	}
+	sort.Strings(candidates)
+	return nil, fmt.Errorf("%w: %s matches %s", ErrAmbiguousEntry, entry, strings.Join(candidates, ", "))
+}
+
+type graphBuilder struct {
+	program         *goProgram
+	includeExternal bool
+	nodes           map[string]*entities.CallNode
+	graph           *entities.CallGraph
+}
+
+// expand fn の呼び出し箇所を記録し、呼び出すプロジェクトの関数を返す
+func (b *graphBuilder) expand(fn *types.Func) []*types.Func {
+	decl := b.program.decls[fn]
+	if decl == nil || decl.Body == nil {
+		return nil
+	}
+	from := funcID(fn)
+
+	var calls []*ast.CallExpr
+	kinds := make(map[*ast.CallExpr]string)
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.GoStmt:
+			kinds[node.Call] = "go"
+		case *ast.DeferStmt:
+			kinds[node.Call] = "defer"
+		case *ast.CallExpr:
+			calls = append(calls, node)
+		}
+		return true
+	})
+
+	// 引数やメソッドチェーンの内側の呼び出しが先に評価されるよう、閉じ括弧の位置で並べる
+	sort.SliceStable(calls, func(i, j int) bool { return calls[i].Rparen < calls[j].Rparen })
+
+	var callees []*types.Func
+	for _, call := range calls {
+		kind := kinds[call]
+		if kind == "" {
+			kind = "call"
+		}
+		line := b.program.fset.Position(call.Lparen).Line
+		callees = append(callees, b.callSite(from, call, kind, line)...)
+	}
+	return callees
+}
+
+// callSite 1 つの呼び出し式をグラフのエッジに解決
+func (b *graphBuilder) callSite(from string, call *ast.CallExpr, kind string, line int) []*types.Func {
+	info := b.program.info
+
+	// 型変換や組み込み関数は呼び出しとして扱わない
+	if tv, ok := info.Types[call.Fun]; ok && tv.IsType() {
+		return nil
+	}
+
+	var callee *types.Func
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		switch obj := info.Uses[fun].(type) {
+		case *types.Func:
+			callee = obj
+		case *types.Builtin, *types.TypeName:
+			return nil
+		}
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[fun]; ok {
+			callee, _ = sel.Obj().(*types.Func)
+		} else if obj, ok := info.Uses[fun.Sel].(*types.Func); ok {
+			callee = obj
+		}
+		if callee == nil {
+			b.addExternal(from, fun, kind, line)
+			return nil
+		}
+	default:
+		return nil
+	}
+	if callee == nil {
+		return nil
+	}
+
+	if b.program.decls[callee] != nil {
+		b.addFunc(callee)
+		b.addEdge(from, funcID(callee), kind, line)
+		return []*types.Func{callee}
+	}
+
+	// インターフェースのメソッド呼び出しはプロジェクト内の実装に展開する
+	if impls := b.program.implementations(callee); len(impls) > 0 {
+		for _, impl := range impls {
+			b.addFunc(impl)
+			b.addEdge(from, funcID(impl), "dynamic", line)
+		}
+		return impls
+	}
+
+	if b.includeExternal {
+		id := funcID(callee)
+		if _, exists := b.nodes[id]; !exists {
+			b.nodes[id] = &entities.CallNode{
+				ID:          id,
+				Name:        displayName(callee),
+				Package:     packageName(callee),
+				Receiver:    receiverName(callee),
+				Participant: participantName(callee),
+				External:    true,
+			}
+		}
+		b.addEdge(from, id, kind, line)
+	}
+	return nil
+}
+
+// addExternal サードパーティの型のメソッドのように、型の分からない呼び出しを
+// レシーバーの式を名前にして記録する
+func (b *graphBuilder) addExternal(from string, fun *ast.SelectorExpr, kind string, line int) {
+	if !b.includeExternal {
+		return
+	}
+
+	participant := b.externalParticipant(fun.X)
+	id := participant + "." + fun.Sel.Name
+	if _, exists := b.nodes[id]; !exists {
+		b.nodes[id] = &entities.CallNode{
+			ID:          id,
+			Name:        fun.Sel.Name,
+			Package:     participant,
+			Participant: participant,
+			External:    true,
+		}
+	}
+	b.addEdge(from, id, kind, line)
+}
+
+// externalParticipant 型の分からない呼び出しのレシーバーの名前を返す
+// パッケージ名、既知の型名、または ac.db のような呼び出しチェーンの基底の式
+func (b *graphBuilder) externalParticipant(x ast.Expr) string {
+	info := b.program.info
+	for {
+		x = ast.Unparen(x)
+		if call, ok := x.(*ast.CallExpr); ok {
+			if sel, ok := ast.Unparen(call.Fun).(*ast.SelectorExpr); ok {
+				x = sel.X
+				continue
+			}
+		}
+		break
+	}
+
+	var obj types.Object
+	switch e := x.(type) {
+	case *ast.Ident:
+		if pkgName, ok := info.Uses[e].(*types.PkgName); ok {
+			return pkgName.Imported().Name()
+		}
+		obj = info.Uses[e]
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[e]; ok {
+			obj = sel.Obj()
+		}
+	}
+	if tv, ok := info.Types[x]; ok && tv.Type != nil {
+		if named := namedType(tv.Type); named != nil {
+			return named.Obj().Name()
+		}
+	}
+	// 外部パッケージの型は解決できないため、宣言に書かれた型名（gin.Context など）を使う
+	if obj != nil {
+		if typeExpr, ok := b.program.declTypes[obj.Pos()]; ok {
+			return strings.TrimLeft(types.ExprString(typeExpr), "*")
+		}
+	}
+	return types.ExprString(x)
+}
+
+func (b *graphBuilder) addFunc(fn *types.Func) {
+	id := funcID(fn)
+	if _, exists := b.nodes[id]; exists {
+		return
+	}
+	node := &entities.CallNode{
+		ID:          id,
+		Name:        displayName(fn),
+		Package:     packageName(fn),
+		Receiver:    receiverName(fn),
+		Participant: participantName(fn),
+	}
+	if decl := b.program.decls[fn]; decl != nil {
+		node.Path, node.Line = b.program.position(decl)
+	}
+	b.nodes[id] = node
+}
+
+func (b *graphBuilder) addEdge(from, to, kind string, line int) {
+	b.graph.Edges = append(b.graph.Edges, entities.CallEdge{
+		From: from,
+		To:   to,
+		Line: line,
+		Kind: kind,
+	})
+}
+
+// funcID "pkg.Func" または "pkg.Type.Method" を返す
+func funcID(fn *types.Func) string {
+	return packageName(fn) + "." + displayName(fn)
+}
+
+// displayName "Func" または "Type.Method" を返す
+func displayName(fn *types.Func) string {
+	if recv := receiverName(fn); recv != "" {
+		return recv + "." + fn.Name()
+	}
+	return fn.Name()
+}
+
+func packageName(fn *types.Func) string {
+	if fn.Pkg() == nil {
+		return ""
+	}
+	return fn.Pkg().Name()
+}
+
+func receiverName(fn *types.Func) string {
+	sig, ok := fn.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return ""
+	}
+	if named := namedType(sig.Recv().Type()); named != nil {
+		return named.Obj().Name()
+	}
+	return ""
+}
+
+// participantName シーケンス図で関数が属するライフライン
+// メソッドはレシーバーの型、通常の関数はパッケージ
+func participantName(fn *types.Func) string {
+	if recv := receiverName(fn); recv != "" {
+		return recv
+	}
+	return packageName(fn)
+}
+
+func namedType(t types.Type) *types.Named {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, _ := t.(*types.Named)
+	return named
+}
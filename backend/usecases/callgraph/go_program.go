@@ -0,0 +1,199 @@
+package callgraph
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// goProgram まとめて型チェックしたアップロード済みの Go パッケージの集合
+// プロジェクトのパッケージのインポートはパスの最後の要素で解決し、それ以外（標準ライブラリと
+// サードパーティのモジュール）は空のパッケージに置き換える。そのため、それらの型の式は型が
+// 付かないままになるが、実行時に Go ツールチェーンがなくても型チェックを続けられる
+type goProgram struct {
+	fset     *token.FileSet
+	info     *types.Info
+	files    map[string][]*ast.File
+	packages map[string]*types.Package
+	checking map[string]bool
+	// decls 宣言されたすべての関数・メソッドとその構文の対応
+	decls map[*types.Func]*ast.FuncDecl
+	// named インターフェースの解決に使う、プロジェクトで宣言された名前付きの型
+	named []*types.Named
+	// declTypes 宣言されたすべての変数、引数、フィールドの位置と型の式の対応
+	// 型の付かないサードパーティの型でも名前を出せるようにする
+	declTypes map[token.Pos]ast.Expr
+}
+
+func loadGoProgram(files []entities.FileInfo) *goProgram {
+	p := &goProgram{
+		fset: token.NewFileSet(),
+		info: &types.Info{
+			Types:      make(map[ast.Expr]types.TypeAndValue),
+			Defs:       make(map[*ast.Ident]types.Object),
+			Uses:       make(map[*ast.Ident]types.Object),
+			Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		},
+		files:     make(map[string][]*ast.File),
+		packages:  make(map[string]*types.Package),
+		checking:  make(map[string]bool),
+		decls:     make(map[*types.Func]*ast.FuncDecl),
+		declTypes: make(map[token.Pos]ast.Expr),
+	}
+
+	for _, file := range files {
+		if file.Language != "go" || strings.HasSuffix(file.Name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(p.fset, file.Name, file.Content, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		p.files[f.Name.Name] = append(p.files[f.Name.Name], f)
+	}
+
+	for name := range p.files {
+		p.check(name)
+	}
+
+	for _, pkgFiles := range p.files {
+		for _, f := range pkgFiles {
+			for _, decl := range f.Decls {
+				fd, ok := decl.(*ast.FuncDecl)
+				if !ok {
+					continue
+				}
+				if fn, ok := p.info.Defs[fd.Name].(*types.Func); ok {
+					p.decls[fn] = fd
+				}
+			}
+			p.collectDeclTypes(f)
+		}
+	}
+
+	for _, pkg := range p.packages {
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			if tn, ok := scope.Lookup(name).(*types.TypeName); ok {
+				if named, ok := tn.Type().(*types.Named); ok {
+					p.named = append(p.named, named)
+				}
+			}
+		}
+	}
+
+	return p
+}
+
+func (p *goProgram) collectDeclTypes(f *ast.File) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.Field:
+			for _, name := range node.Names {
+				p.declTypes[name.Pos()] = node.Type
+			}
+		case *ast.ValueSpec:
+			if node.Type != nil {
+				for _, name := range node.Names {
+					p.declTypes[name.Pos()] = node.Type
+				}
+			}
+		}
+		return true
+	})
+}
+
+// check 指定した名前のプロジェクトのパッケージを型チェック
+func (p *goProgram) check(name string) *types.Package {
+	if pkg, ok := p.packages[name]; ok {
+		return pkg
+	}
+	if p.checking[name] {
+		// 循環インポートは空のパッケージで打ち切る
+		return types.NewPackage(name, name)
+	}
+	p.checking[name] = true
+
+	conf := types.Config{
+		Importer: importerFunc(p.importPackage),
+		// 外部パッケージの型が解決できないエラーは無視して検査を続ける
+		Error: func(error) {},
+	}
+	pkg, _ := conf.Check(name, p.fset, p.files[name], p.info)
+	p.packages[name] = pkg
+	return pkg
+}
+
+func (p *goProgram) importPackage(importPath string) (*types.Package, error) {
+	name := path.Base(importPath)
+	if _, ok := p.files[name]; ok && name != "main" {
+		return p.check(name), nil
+	}
+	pkg := types.NewPackage(importPath, guessPackageName(importPath))
+	pkg.MarkComplete()
+	return pkg, nil
+}
+
+// guessPackageName github.com/go-redis/redis/v8 や gopkg.in/yaml.v3 のようなインポートパスからパッケージ名を推測
+func guessPackageName(importPath string) string {
+	parts := strings.Split(importPath, "/")
+	name := parts[len(parts)-1]
+	if len(parts) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = parts[len(parts)-2]
+	}
+	if i := strings.Index(name, ".v"); i > 0 {
+		name = name[:i]
+	}
+	name = strings.TrimPrefix(name, "go-")
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
+
+// implementations インターフェースのメソッドを通じて呼ばれる可能性のあるプロジェクトのメソッドを返す
+func (p *goProgram) implementations(method *types.Func) []*types.Func {
+	sig, ok := method.Type().(*types.Signature)
+	if !ok || sig.Recv() == nil {
+		return nil
+	}
+	iface, ok := sig.Recv().Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil
+	}
+
+	var impls []*types.Func
+	for _, named := range p.named {
+		if types.IsInterface(named) {
+			continue
+		}
+		var typ types.Type = named
+		if !types.Implements(typ, iface) {
+			typ = types.NewPointer(named)
+			if !types.Implements(typ, iface) {
+				continue
+			}
+		}
+		sel := types.NewMethodSet(typ).Lookup(method.Pkg(), method.Name())
+		if sel == nil {
+			continue
+		}
+		if fn, ok := sel.Obj().(*types.Func); ok && p.decls[fn] != nil {
+			impls = append(impls, fn)
+		}
+	}
+	return impls
+}
+
+func (p *goProgram) position(fd *ast.FuncDecl) (string, int) {
+	pos := p.fset.Position(fd.Pos())
+	return pos.Filename, pos.Line
+}
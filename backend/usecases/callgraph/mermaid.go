@@ -0,0 +1,98 @@
+package callgraph
+
+import (
+	"fmt"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// RenderSequenceDiagram グラフの呼び出しの流れを Mermaid のシーケンス図にする
+// 呼び出しを評価順に深さ優先でたどり、各関数は最初の呼び出しで展開し、
+// 2 回目以降は 1 つのメッセージとして描く
+func RenderSequenceDiagram(graph *entities.CallGraph) string {
+	nodes := make(map[string]entities.CallNode, len(graph.Nodes))
+	for _, node := range graph.Nodes {
+		nodes[node.ID] = node
+	}
+	outgoing := make(map[string][]entities.CallEdge)
+	for _, edge := range graph.Edges {
+		outgoing[edge.From] = append(outgoing[edge.From], edge)
+	}
+
+	r := &sequenceRenderer{
+		nodes:    nodes,
+		outgoing: outgoing,
+		aliases:  make(map[string]string),
+		expanded: make(map[string]bool),
+	}
+
+	entry, ok := nodes[graph.Entry]
+	if !ok {
+		return "sequenceDiagram\n"
+	}
+	r.participant(entry.Participant)
+	r.walk(entry.ID, 0, graph.Depth)
+
+	var b strings.Builder
+	b.WriteString("sequenceDiagram\n")
+	for _, name := range r.order {
+		fmt.Fprintf(&b, "    participant %s as %s\n", r.aliases[name], name)
+	}
+	for _, line := range r.lines {
+		b.WriteString("    " + line + "\n")
+	}
+	return b.String()
+}
+
+type sequenceRenderer struct {
+	nodes    map[string]entities.CallNode
+	outgoing map[string][]entities.CallEdge
+	aliases  map[string]string
+	order    []string
+	expanded map[string]bool
+	lines    []string
+}
+
+// participant ライフライン名から Mermaid で使える別名を返す
+func (r *sequenceRenderer) participant(name string) string {
+	if alias, ok := r.aliases[name]; ok {
+		return alias
+	}
+	alias := fmt.Sprintf("P%d", len(r.order))
+	r.aliases[name] = alias
+	r.order = append(r.order, name)
+	return alias
+}
+
+func (r *sequenceRenderer) walk(id string, depth, maxDepth int) {
+	if depth >= maxDepth {
+		return
+	}
+	r.expanded[id] = true
+
+	caller := r.participant(r.nodes[id].Participant)
+	for _, edge := range r.outgoing[id] {
+		callee, ok := r.nodes[edge.To]
+		if !ok {
+			continue
+		}
+		target := r.participant(callee.Participant)
+		label := escapeMermaid(callee.Name[strings.LastIndex(callee.Name, ".")+1:])
+
+		switch {
+		case edge.Kind == "go":
+			r.lines = append(r.lines, fmt.Sprintf("%s-)%s: go %s()", caller, target, label))
+		case callee.External || r.expanded[edge.To] || len(r.outgoing[edge.To]) == 0:
+			r.lines = append(r.lines, fmt.Sprintf("%s->>%s: %s()", caller, target, label))
+		default:
+			r.lines = append(r.lines, fmt.Sprintf("%s->>+%s: %s()", caller, target, label))
+			r.walk(edge.To, depth+1, maxDepth)
+			r.lines = append(r.lines, fmt.Sprintf("%s-->>-%s: return", target, caller))
+		}
+	}
+}
+
+func escapeMermaid(s string) string {
+	return strings.NewReplacer(";", "#59;", "#", "#35;", ":", "#58;").Replace(s)
+}
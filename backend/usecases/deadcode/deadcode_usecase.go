@@ -0,0 +1,73 @@
+package deadcode
+
+import (
+	"context"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// DeadCodeUseCase 参照されていない宣言と使われていないエクスポートを検出する
+type DeadCodeUseCase struct{}
+
+// NewDeadCodeUseCase 未使用コード検出のユースケースを作成
+func NewDeadCodeUseCase() *DeadCodeUseCase {
+	return &DeadCodeUseCase{}
+}
+
+// Execute Go と JavaScript/TypeScript のファイルからファイル横断の参照インデックスを作成し、
+// エントリーポイントから到達できない宣言をすべて報告する
+func (uc *DeadCodeUseCase) Execute(ctx context.Context, files []entities.FileInfo) (*entities.DeadCodeReport, error) {
+	var goFiles, tsFiles []entities.FileInfo
+	for _, file := range files {
+		switch file.Language {
+		case "go":
+			goFiles = append(goFiles, file)
+		case "javascript", "typescript":
+			tsFiles = append(tsFiles, file)
+		}
+	}
+
+	report := &entities.DeadCodeReport{}
+
+	if len(goFiles) > 0 {
+		ix := buildGoIndex(goFiles)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// Go の公開はパッケージ単位のため、複数パッケージがある場合のみ未使用エクスポートを報告する。
+		// 型とメソッドはコンストラクタやインターフェース経由で暗黙に使われるため対象外とする
+		multiPackage := countUnits(ix) > 1
+		uc.merge(report, ix, func(n *symbolNode) bool {
+			return multiPackage && n.method == "" && n.symbol.Kind != "type"
+		})
+	}
+
+	if len(tsFiles) > 0 {
+		ix := buildTSIndex(tsFiles)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		uc.merge(report, ix, func(n *symbolNode) bool { return true })
+	}
+
+	return report, nil
+}
+
+func (uc *DeadCodeUseCase) merge(report *entities.DeadCodeReport, ix *referenceIndex, checkExport func(n *symbolNode) bool) {
+	entryPoints, findings := ix.analyze(checkExport)
+	for _, n := range ix.nodes {
+		if !n.pseudo {
+			report.Symbols++
+		}
+	}
+	report.EntryPoints = append(report.EntryPoints, entryPoints...)
+	report.Findings = append(report.Findings, findings...)
+}
+
+func countUnits(ix *referenceIndex) int {
+	units := make(map[string]bool)
+	for _, n := range ix.nodes {
+		units[n.unit] = true
+	}
+	return len(units)
+}
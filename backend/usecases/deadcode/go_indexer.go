@@ -0,0 +1,444 @@
+package deadcode
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// goRouteMethods 末尾の引数が HTTP ハンドラーになるルーターのメソッド（gin、net/http、echo、chi）
+var goRouteMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true,
+	"HEAD": true, "OPTIONS": true, "Any": true, "Handle": true, "HandleFunc": true,
+	"Get": true, "Post": true, "Put": true, "Patch": true, "Delete": true,
+}
+
+// goWellKnownMethods 標準ライブラリのインターフェースを通じて暗黙に呼ばれるメソッド
+var goWellKnownMethods = map[string]bool{
+	"String": true, "Error": true, "Unwrap": true, "Is": true, "As": true,
+	"ServeHTTP": true, "MarshalJSON": true, "UnmarshalJSON": true,
+	"MarshalText": true, "UnmarshalText": true, "MarshalYAML": true, "UnmarshalYAML": true,
+	"Len": true, "Less": true, "Swap": true, "Push": true, "Pop": true,
+	"Read": true, "Write": true, "Close": true, "Format": true, "GoString": true,
+	"Scan": true, "Value": true, "TableName": true, "BeforeCreate": true, "AfterCreate": true,
+	"BeforeSave": true, "AfterSave": true, "BeforeUpdate": true, "AfterUpdate": true,
+	"BeforeDelete": true, "AfterDelete": true, "AfterFind": true,
+}
+
+// buildGoIndex Go ファイルを解析して参照インデックスを作成
+func buildGoIndex(files []entities.FileInfo) *referenceIndex {
+	ix := newReferenceIndex()
+	fset := token.NewFileSet()
+
+	var parsed []*ast.File
+	var paths []string
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file.Name, file.Content, parser.SkipObjectResolution)
+		if err != nil {
+			continue
+		}
+		parsed = append(parsed, f)
+		paths = append(paths, file.Name)
+	}
+
+	interfaceMethods := make(map[string]bool)
+	for name := range goWellKnownMethods {
+		interfaceMethods[name] = true
+	}
+
+	// コンストラクタ（NewXxx など）の戻り値の型と、パッケージ変数の型
+	constructors := make(map[string]string)
+	globals := make(map[string]string)
+
+	for i, f := range parsed {
+		path := paths[i]
+		pkg := f.Name.Name
+		isTest := strings.HasSuffix(path, "_test.go")
+
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.FuncDecl:
+				ix.add(goFuncNode(fset, path, pkg, isTest, d))
+				if d.Recv == nil && d.Type.Results != nil && len(d.Type.Results.List) > 0 {
+					if name := goTypeName(d.Type.Results.List[0].Type); name != "" {
+						constructors[d.Name.Name] = name
+					}
+				}
+			case *ast.GenDecl:
+				for _, n := range goGenDeclNodes(fset, path, pkg, d) {
+					ix.add(n)
+				}
+			}
+		}
+
+		ast.Inspect(f, func(n ast.Node) bool {
+			if node, ok := n.(*ast.InterfaceType); ok {
+				for _, m := range node.Methods.List {
+					for _, name := range m.Names {
+						interfaceMethods[name.Name] = true
+					}
+				}
+			}
+			return true
+		})
+	}
+	for _, f := range parsed {
+		for _, decl := range f.Decls {
+			if d, ok := decl.(*ast.GenDecl); ok && d.Tok == token.VAR {
+				collectGoVarTypes(globals, constructors, d)
+			}
+		}
+	}
+
+	// ルーターに登録されたハンドラーは、メソッドであればレシーバの型まで特定してエントリポイントにする
+	var handlers []goHandlerRef
+	for _, f := range parsed {
+		imports := goImportNames(f)
+		for _, decl := range f.Decls {
+			locals := make(map[string]string)
+			if d, ok := decl.(*ast.FuncDecl); ok {
+				if d.Recv != nil {
+					collectGoFieldTypes(locals, d.Recv)
+				}
+				collectGoFieldTypes(locals, d.Type.Params)
+			}
+			ast.Inspect(decl, func(n ast.Node) bool {
+				switch node := n.(type) {
+				case *ast.AssignStmt:
+					collectGoAssignTypes(locals, constructors, node)
+				case *ast.DeclStmt:
+					if d, ok := node.Decl.(*ast.GenDecl); ok && d.Tok == token.VAR {
+						collectGoVarTypes(locals, constructors, d)
+					}
+				case *ast.FuncLit:
+					collectGoFieldTypes(locals, node.Type.Params)
+				case *ast.CallExpr:
+					handlers = append(handlers, goRouteHandlers(node, locals, globals, imports)...)
+				}
+				return true
+			})
+		}
+	}
+
+	for _, h := range handlers {
+		if h.receiver != "" && ix.markMethodRoots(h.receiver, h.name, "http_handler") {
+			continue
+		}
+		// 型が分かってもそのメソッドが見つからない場合（インターフェース型の変数など）は名前だけで判定する
+		ix.markRoots(h.name, "http_handler", h.methods || h.receiver != "")
+	}
+
+	// インターフェース経由で呼ばれうるメソッドは、レシーバ型が生きていれば生きているとみなす
+	for id, n := range ix.nodes {
+		if n.method == "" || !interfaceMethods[n.method] {
+			continue
+		}
+		for _, typeID := range ix.byName[n.receiver] {
+			if ix.nodes[typeID].symbol.Kind == "type" {
+				ix.nodes[typeID].implied = append(ix.nodes[typeID].implied, id)
+			}
+		}
+	}
+
+	// エントリポイントがない場合はライブラリとみなし、公開シンボルを起点にする
+	if !ix.hasRoots() {
+		for _, n := range ix.nodes {
+			if n.symbol.Exported {
+				n.root = "exported_api"
+			}
+		}
+	}
+
+	return ix
+}
+
+// goHandlerRef ルーターに渡されたハンドラー
+// receiver はメソッドハンドラーを取り出した値の型で、解決できない場合は空
+type goHandlerRef struct {
+	name     string
+	receiver string
+	methods  bool
+}
+
+// goRouteHandlers r.GET("/path", c.Handler) のようなルーターの呼び出しで登録されたハンドラーを返す
+func goRouteHandlers(call *ast.CallExpr, locals, globals map[string]string, imports map[string]bool) []goHandlerRef {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !goRouteMethods[sel.Sel.Name] || len(call.Args) < 2 {
+		return nil
+	}
+	if lit, ok := call.Args[0].(*ast.BasicLit); !ok || lit.Kind != token.STRING {
+		return nil
+	}
+	var handlers []goHandlerRef
+	for _, arg := range call.Args[1:] {
+		switch a := arg.(type) {
+		case *ast.Ident:
+			handlers = append(handlers, goHandlerRef{name: a.Name})
+		case *ast.SelectorExpr:
+			x, ok := a.X.(*ast.Ident)
+			switch {
+			case ok && locals[x.Name] != "":
+				handlers = append(handlers, goHandlerRef{name: a.Sel.Name, receiver: locals[x.Name]})
+			case ok && globals[x.Name] != "":
+				handlers = append(handlers, goHandlerRef{name: a.Sel.Name, receiver: globals[x.Name]})
+			case ok && imports[x.Name]:
+				// パッケージの関数（handlers.Index など）
+				handlers = append(handlers, goHandlerRef{name: a.Sel.Name})
+			default:
+				handlers = append(handlers, goHandlerRef{name: a.Sel.Name, methods: true})
+			}
+		}
+	}
+	return handlers
+}
+
+// goImportNames ファイルがインポートしたパッケージを参照するときの名前を返す
+func goImportNames(f *ast.File) map[string]bool {
+	names := make(map[string]bool)
+	for _, spec := range f.Imports {
+		if spec.Name != nil {
+			names[spec.Name.Name] = true
+			continue
+		}
+		importPath := strings.Trim(spec.Path.Value, "`\"")
+		names[importPath[strings.LastIndex(importPath, "/")+1:]] = true
+	}
+	return names
+}
+
+// collectGoFieldTypes 名前付きの引数またはレシーバーの型名を記録
+func collectGoFieldTypes(types map[string]string, fields *ast.FieldList) {
+	if fields == nil {
+		return
+	}
+	for _, field := range fields.List {
+		name := goTypeName(field.Type)
+		if name == "" {
+			continue
+		}
+		for _, ident := range field.Names {
+			types[ident.Name] = name
+		}
+	}
+}
+
+// collectGoVarTypes var で宣言された変数の型を記録
+func collectGoVarTypes(types, constructors map[string]string, d *ast.GenDecl) {
+	for _, spec := range d.Specs {
+		s, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		for i, ident := range s.Names {
+			name := ""
+			if s.Type != nil {
+				name = goTypeName(s.Type)
+			} else if len(s.Values) == len(s.Names) {
+				name = goExprType(s.Values[i], constructors)
+			}
+			if name != "" {
+				types[ident.Name] = name
+			}
+		}
+	}
+}
+
+// collectGoAssignTypes コンストラクターの呼び出しや複合リテラルから代入された変数の型を記録
+func collectGoAssignTypes(types, constructors map[string]string, a *ast.AssignStmt) {
+	if len(a.Lhs) != len(a.Rhs) {
+		// c, err := NewController() のように戻り値が複数の場合は最初の値だけを見る
+		if len(a.Rhs) != 1 || len(a.Lhs) == 0 {
+			return
+		}
+		if ident, ok := a.Lhs[0].(*ast.Ident); ok {
+			if name := goExprType(a.Rhs[0], constructors); name != "" {
+				types[ident.Name] = name
+			}
+		}
+		return
+	}
+	for i, lhs := range a.Lhs {
+		ident, ok := lhs.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		if name := goExprType(a.Rhs[i], constructors); name != "" {
+			types[ident.Name] = name
+		}
+	}
+}
+
+// goExprType 構文から明らかな場合に式の型名を推測する
+// T{}、&T{}、new(T)、T を返す関数の呼び出しが対象
+func goExprType(expr ast.Expr, constructors map[string]string) string {
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		return goTypeName(e.Type)
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			return goExprType(e.X, constructors)
+		}
+	case *ast.CallExpr:
+		switch fn := e.Fun.(type) {
+		case *ast.Ident:
+			if fn.Name == "new" && len(e.Args) == 1 {
+				return goTypeName(e.Args[0])
+			}
+			return constructors[fn.Name]
+		case *ast.SelectorExpr:
+			return constructors[fn.Sel.Name]
+		}
+	}
+	return ""
+}
+
+// goTypeName *pkg.T や T[U] のような名前付きの型の式から型名だけを返す
+func goTypeName(expr ast.Expr) string {
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		return sel.Sel.Name
+	}
+	if star, ok := expr.(*ast.StarExpr); ok {
+		return goTypeName(star.X)
+	}
+	return goReceiverName(expr)
+}
+
+func goFuncNode(fset *token.FileSet, path, pkg string, isTest bool, d *ast.FuncDecl) *symbolNode {
+	n := &symbolNode{
+		symbol: entities.Symbol{
+			Name:     d.Name.Name,
+			Kind:     "function",
+			Path:     path,
+			Line:     fset.Position(d.Pos()).Line,
+			Language: "go",
+			Exported: d.Name.IsExported(),
+		},
+		unit: pkg,
+	}
+
+	if d.Recv != nil && len(d.Recv.List) > 0 {
+		n.receiver = goReceiverName(d.Recv.List[0].Type)
+		n.method = d.Name.Name
+		n.symbol.Kind = "method"
+		n.symbol.Name = n.receiver + "." + d.Name.Name
+		collectGoRefs(n, d.Recv)
+	} else {
+		switch {
+		case pkg == "main" && d.Name.Name == "main":
+			n.root = "main"
+		case d.Name.Name == "init":
+			n.root = "init"
+		case isTest && isGoTestFunc(d.Name.Name):
+			n.root = "test"
+		}
+	}
+
+	collectGoRefs(n, d.Type)
+	if d.Body != nil {
+		collectGoRefs(n, d.Body)
+	}
+	return n
+}
+
+func goGenDeclNodes(fset *token.FileSet, path, pkg string, d *ast.GenDecl) []*symbolNode {
+	var nodes []*symbolNode
+	for _, spec := range d.Specs {
+		switch s := spec.(type) {
+		case *ast.TypeSpec:
+			n := &symbolNode{
+				symbol: entities.Symbol{
+					Name:     s.Name.Name,
+					Kind:     "type",
+					Path:     path,
+					Line:     fset.Position(s.Pos()).Line,
+					Language: "go",
+					Exported: s.Name.IsExported(),
+				},
+				unit: pkg,
+			}
+			if s.TypeParams != nil {
+				collectGoRefs(n, s.TypeParams)
+			}
+			collectGoRefs(n, s.Type)
+			nodes = append(nodes, n)
+		case *ast.ValueSpec:
+			kind := "var"
+			if d.Tok == token.CONST {
+				kind = "const"
+			}
+			for _, name := range s.Names {
+				n := &symbolNode{
+					symbol: entities.Symbol{
+						Name:     name.Name,
+						Kind:     kind,
+						Path:     path,
+						Line:     fset.Position(name.Pos()).Line,
+						Language: "go",
+						Exported: name.IsExported(),
+					},
+					unit: pkg,
+				}
+				// var _ Interface = (*T)(nil) のような宣言はパッケージ初期化時に評価される
+				if name.Name == "_" {
+					n.pseudo = true
+					n.root = "module_init"
+				}
+				if s.Type != nil {
+					collectGoRefs(n, s.Type)
+				}
+				for _, value := range s.Values {
+					collectGoRefs(n, value)
+				}
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	return nodes
+}
+
+// collectGoRefs node の下で使われている識別子とセレクターの名前を記録
+func collectGoRefs(n *symbolNode, node ast.Node) {
+	ast.Inspect(node, func(x ast.Node) bool {
+		switch e := x.(type) {
+		case *ast.SelectorExpr:
+			n.selectors = append(n.selectors, e.Sel.Name)
+			collectGoRefs(n, e.X)
+			return false
+		case *ast.Ident:
+			n.idents = append(n.idents, e.Name)
+		}
+		return true
+	})
+}
+
+func goReceiverName(expr ast.Expr) string {
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		case *ast.Ident:
+			return t.Name
+		default:
+			return ""
+		}
+	}
+}
+
+func isGoTestFunc(name string) bool {
+	if name == "TestMain" {
+		return true
+	}
+	for _, prefix := range []string{"Test", "Benchmark", "Example", "Fuzz"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,194 @@
+package deadcode
+
+import (
+	"sort"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// symbolNode 宣言と、その本体が参照する名前
+type symbolNode struct {
+	symbol entities.Symbol
+	// unit エクスポートが越える範囲。Go はパッケージ、TypeScript はファイル
+	unit      string
+	idents    []string
+	selectors []string
+	// receiver Go のメソッドのレシーバーの型名
+	receiver string
+	// method Go のメソッドのメソッド名のみ
+	method string
+	// root エントリーポイントである理由。通常のシンボルは空
+	root string
+	// pseudo モジュールレベルの文のような合成ノードで、報告しない
+	pseudo bool
+	// implied このノードが生きている間は生きているとみなすノード（型のインターフェースメソッドなど）
+	implied []int
+}
+
+// referenceIndex 名前に基づくファイル横断の参照グラフ
+// 完全な型情報がないため、名前への参照はその名前を持つすべての宣言に解決する
+// （コードを残す側に倒す）
+type referenceIndex struct {
+	nodes    []*symbolNode
+	byName   map[string][]int
+	byMethod map[string][]int
+}
+
+func newReferenceIndex() *referenceIndex {
+	return &referenceIndex{
+		byName:   make(map[string][]int),
+		byMethod: make(map[string][]int),
+	}
+}
+
+func (ix *referenceIndex) add(n *symbolNode) int {
+	id := len(ix.nodes)
+	ix.nodes = append(ix.nodes, n)
+	if n.pseudo {
+		return id
+	}
+	if n.method != "" {
+		ix.byMethod[n.method] = append(ix.byMethod[n.method], id)
+	} else {
+		ix.byName[n.symbol.Name] = append(ix.byName[n.symbol.Name], id)
+	}
+	return id
+}
+
+// targets ノードの参照をノード ID に解決
+func (ix *referenceIndex) targets(n *symbolNode) []int {
+	var ids []int
+	for _, name := range n.idents {
+		ids = append(ids, ix.byName[name]...)
+	}
+	for _, name := range n.selectors {
+		ids = append(ids, ix.byMethod[name]...)
+		ids = append(ids, ix.byName[name]...)
+	}
+	return append(ids, n.implied...)
+}
+
+// markRoots name という名前のメソッドでないシンボル（methods が true ならその名前の
+// メソッドも）をすべてエントリーポイントにする
+func (ix *referenceIndex) markRoots(name, reason string, methods bool) {
+	for _, id := range ix.byName[name] {
+		if ix.nodes[id].root == "" {
+			ix.nodes[id].root = reason
+		}
+	}
+	if !methods {
+		return
+	}
+	for _, id := range ix.byMethod[name] {
+		if ix.nodes[id].root == "" {
+			ix.nodes[id].root = reason
+		}
+	}
+}
+
+// markMethodRoots レシーバーの型の name メソッドをエントリーポイントにし、
+// その型にメソッドがあったかを返す
+func (ix *referenceIndex) markMethodRoots(receiver, name, reason string) bool {
+	found := false
+	for _, id := range ix.byMethod[name] {
+		if ix.nodes[id].receiver != receiver {
+			continue
+		}
+		found = true
+		if ix.nodes[id].root == "" {
+			ix.nodes[id].root = reason
+		}
+	}
+	return found
+}
+
+func (ix *referenceIndex) hasRoots() bool {
+	for _, n := range ix.nodes {
+		if n.root != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// analyze エントリーポイントからグラフをたどり、到達できない宣言を分類する
+// 到達できるエクスポートされたシンボルのうち、checkExport が受け付け、他の unit から
+// 参照されていないものは使われていないエクスポートとして報告する
+func (ix *referenceIndex) analyze(checkExport func(n *symbolNode) bool) ([]entities.EntryPoint, []entities.DeadSymbol) {
+	refs := make([]int, len(ix.nodes))
+	external := make([]int, len(ix.nodes))
+	edges := make([][]int, len(ix.nodes))
+
+	for id, n := range ix.nodes {
+		seen := make(map[int]bool)
+		for _, target := range ix.targets(n) {
+			if target == id || seen[target] {
+				continue
+			}
+			seen[target] = true
+			edges[id] = append(edges[id], target)
+			refs[target]++
+			if ix.nodes[target].unit != n.unit {
+				external[target]++
+			}
+		}
+	}
+
+	reachable := make([]bool, len(ix.nodes))
+	var queue []int
+	var entryPoints []entities.EntryPoint
+	for id, n := range ix.nodes {
+		if n.root == "" {
+			continue
+		}
+		reachable[id] = true
+		queue = append(queue, id)
+		if !n.pseudo {
+			entryPoints = append(entryPoints, entities.EntryPoint{Symbol: n.symbol, Reason: n.root})
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, target := range edges[id] {
+			if !reachable[target] {
+				reachable[target] = true
+				queue = append(queue, target)
+			}
+		}
+	}
+
+	var findings []entities.DeadSymbol
+	for id, n := range ix.nodes {
+		if n.pseudo || n.root != "" {
+			continue
+		}
+
+		reason := ""
+		switch {
+		case !reachable[id] && refs[id] == 0:
+			reason = "unreferenced"
+		case !reachable[id]:
+			reason = "unreachable"
+		case n.symbol.Exported && external[id] == 0 && checkExport(n):
+			reason = "unused_export"
+		default:
+			continue
+		}
+
+		findings = append(findings, entities.DeadSymbol{
+			Symbol:     n.symbol,
+			Reason:     reason,
+			References: refs[id],
+		})
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return findings[i].Path < findings[j].Path
+		}
+		return findings[i].Line < findings[j].Line
+	})
+
+	return entryPoints, findings
+}
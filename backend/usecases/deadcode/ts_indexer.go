@@ -0,0 +1,280 @@
+package deadcode
+
+import (
+	"regexp"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/utils"
+)
+
+var tsTokenPattern = regexp.MustCompile("[A-Za-z_$][A-Za-z0-9_$]*|=>|[{}()\\[\\];,.=*'\"`]")
+
+// tsRouteMethods 末尾の引数がハンドラーになる express/koa のルーターのメソッド
+var tsRouteMethods = map[string]bool{
+	"get": true, "post": true, "put": true, "patch": true, "delete": true,
+	"head": true, "options": true, "all": true,
+}
+
+// tsModifiers 宣言のキーワードの前に付く修飾子
+var tsModifiers = map[string]bool{
+	"export": true, "default": true, "async": true, "declare": true, "abstract": true,
+}
+
+// tsDeclKinds 宣言のキーワードとシンボルの種類の対応
+var tsDeclKinds = map[string]string{
+	"function":  "function",
+	"class":     "class",
+	"interface": "interface",
+	"type":      "type",
+	"enum":      "enum",
+	"const":     "const",
+	"let":       "var",
+	"var":       "var",
+}
+
+type tsToken struct {
+	text string
+	line int
+}
+
+// buildTSIndex JavaScript/TypeScript のファイルをトークンに分割し、参照インデックスを作成する
+// トップレベルの宣言は波括弧の深さ 0 で認識する。宣言でない文はインポート時に実行されるため、
+// モジュールレベルのエントリーポイントとして扱う
+func buildTSIndex(files []entities.FileInfo) *referenceIndex {
+	ix := newReferenceIndex()
+
+	var handlerNames []string
+	for _, file := range files {
+		tokens := tsTokenize(file.Content, file.Language)
+		isTest := isTSTestFile(file.Name)
+		handlerNames = append(handlerNames, tsRouteHandlers(tokens)...)
+
+		module := &symbolNode{
+			symbol: entities.Symbol{Name: "<module>", Kind: "module", Path: file.Name, Language: file.Language},
+			unit:   file.Name,
+			root:   "module_init",
+			pseudo: true,
+		}
+		ix.add(module)
+
+		var declared []*symbolNode
+		exportedNames := make(map[string]bool)
+		defaultExports := make(map[string]bool)
+
+		owner := module
+		depth := 0
+		lastLine := 0
+		for i := 0; i < len(tokens); i++ {
+			tok := tokens[i]
+
+			// 深さ0で行頭のトークンから新しい文・宣言が始まる
+			if depth == 0 && tok.line != lastLine {
+				switch {
+				case tok.text == "import":
+					i = tsSkipStatement(tokens, i, func(t tsToken) {
+						// import した名前は他ファイルのシンボルへの参照として数える
+						module.idents = append(module.idents, t.text)
+					})
+					lastLine = tokens[i].line
+					continue
+				case tok.text == "export" && i+1 < len(tokens) && tokens[i+1].text == "{":
+					i = tsSkipStatement(tokens, i, func(t tsToken) {
+						exportedNames[t.text] = true
+						module.idents = append(module.idents, t.text)
+					})
+					lastLine = tokens[i].line
+					continue
+				case tok.text == "export" && i+2 < len(tokens) && tokens[i+1].text == "default" &&
+					tsDeclKinds[tokens[i+2].text] == "" && !tsModifiers[tokens[i+2].text]:
+					defaultExports[tokens[i+2].text] = true
+				}
+
+				if n, next := tsDeclaration(tokens, i, file); n != nil {
+					if isTest {
+						n.root = "test"
+					}
+					ix.add(n)
+					declared = append(declared, n)
+					owner = n
+					i = next
+					lastLine = tok.line
+					continue
+				}
+				owner = module
+			}
+			lastLine = tok.line
+
+			switch tok.text {
+			case "{":
+				depth++
+			case "}":
+				if depth > 0 {
+					depth--
+				}
+			default:
+				if isTSIdent(tok.text) {
+					owner.idents = append(owner.idents, tok.text)
+				}
+			}
+		}
+
+		for _, n := range declared {
+			if exportedNames[n.symbol.Name] {
+				n.symbol.Exported = true
+			}
+			if defaultExports[n.symbol.Name] && n.root == "" {
+				n.root = "module_default"
+			}
+		}
+	}
+
+	for _, name := range handlerNames {
+		ix.markRoots(name, "http_handler", false)
+	}
+
+	return ix
+}
+
+// tsDeclaration i から始まる "[export] [default] [async] function|class|... name" を認識する
+func tsDeclaration(tokens []tsToken, i int, file entities.FileInfo) (*symbolNode, int) {
+	start := i
+	exported := false
+	isDefault := false
+	for i < len(tokens) && tsModifiers[tokens[i].text] {
+		switch tokens[i].text {
+		case "export":
+			exported = true
+		case "default":
+			isDefault = true
+		}
+		i++
+	}
+	if i >= len(tokens) {
+		return nil, start
+	}
+
+	kind, ok := tsDeclKinds[tokens[i].text]
+	if !ok {
+		return nil, start
+	}
+	// const enum 宣言
+	if tokens[i].text == "const" && i+1 < len(tokens) && tokens[i+1].text == "enum" {
+		kind = "enum"
+		i++
+	}
+	// function* のジェネレーター
+	if i+1 < len(tokens) && tokens[i+1].text == "*" {
+		i++
+	}
+
+	n := &symbolNode{
+		symbol: entities.Symbol{
+			Kind:     kind,
+			Path:     file.Name,
+			Line:     tokens[start].line,
+			Language: file.Language,
+			Exported: exported,
+		},
+		unit: file.Name,
+	}
+
+	if i+1 < len(tokens) && isTSIdent(tokens[i+1].text) {
+		n.symbol.Name = tokens[i+1].text
+		i++
+	} else {
+		// export default function () {} のような無名の宣言
+		n.symbol.Name = "default"
+		n.pseudo = true
+	}
+	if isDefault {
+		n.root = "module_default"
+	}
+
+	return n, i
+}
+
+// tsSkipStatement 終端の ";" または深さ 0 での最終行の終わりまで文を読み進め、すべての識別子を報告する
+func tsSkipStatement(tokens []tsToken, i int, visit func(tsToken)) int {
+	depth := 0
+	for j := i; j < len(tokens); j++ {
+		t := tokens[j]
+		switch t.text {
+		case "{", "(":
+			depth++
+		case "}", ")":
+			depth--
+		case ";":
+			if depth == 0 {
+				return j
+			}
+		default:
+			if j > i && isTSIdent(t.text) && !tsImportKeywords[t.text] {
+				visit(t)
+			}
+		}
+		if depth <= 0 && j+1 < len(tokens) && tokens[j+1].line != t.line && t.text != "," && t.text != "{" {
+			return j
+		}
+	}
+	return len(tokens) - 1
+}
+
+var tsImportKeywords = map[string]bool{
+	"import": true, "export": true, "from": true, "as": true, "type": true, "default": true,
+}
+
+// tsRouteHandlers app.get('/path', handler) 形式の呼び出しでハンドラーとして渡された識別子を返す
+func tsRouteHandlers(tokens []tsToken) []string {
+	var names []string
+	for i := 0; i+4 < len(tokens); i++ {
+		if tokens[i].text != "." || !tsRouteMethods[tokens[i+1].text] || tokens[i+2].text != "(" {
+			continue
+		}
+		if q := tokens[i+3].text; q != "'" && q != "\"" && q != "`" {
+			continue
+		}
+
+		depth := 0
+		for j := i + 2; j < len(tokens); j++ {
+			t := tokens[j].text
+			if t == "(" {
+				depth++
+			} else if t == ")" {
+				depth--
+				if depth == 0 {
+					break
+				}
+			} else if depth == 1 && isTSIdent(t) {
+				names = append(names, t)
+			}
+		}
+	}
+	return names
+}
+
+func tsTokenize(content, language string) []tsToken {
+	lines, _, _ := utils.StripComments(strings.Split(content, "\n"), language)
+
+	var tokens []tsToken
+	for i, line := range lines {
+		for _, text := range tsTokenPattern.FindAllString(line, -1) {
+			tokens = append(tokens, tsToken{text: text, line: i + 1})
+		}
+	}
+	return tokens
+}
+
+func isTSIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	c := s[0]
+	return c == '_' || c == '$' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z')
+}
+
+func isTSTestFile(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.Contains(lower, ".test.") || strings.Contains(lower, ".spec.") ||
+		strings.Contains(lower, "__tests__/")
+}
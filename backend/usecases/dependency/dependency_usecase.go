@@ -0,0 +1,238 @@
+package dependency
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// manifestParser 1 つのマニフェストファイルから依存関係を取り出す
+type manifestParser func(name, content string) ([]entities.Dependency, error)
+
+type manifestFormat struct {
+	parse manifestParser
+	// resolved はロックファイルなど、実際に解決されたバージョンを記録する形式かどうか
+	resolved bool
+	// priority は同じパッケージが複数の解決済みマニフェストに現れたときの優先度（小さいほど優先）
+	priority int
+}
+
+var manifestFormats = map[string]manifestFormat{
+	"go.mod":            {parse: parseGoMod, resolved: true, priority: 0},
+	"go.sum":            {parse: parseGoSum, resolved: true, priority: 1},
+	".buildinfo":        {parse: parseGoBuildInfo, resolved: true, priority: 0},
+	"package.json":      {parse: parsePackageJSON},
+	"package-lock.json": {parse: parsePackageLock, resolved: true},
+	"requirements.txt":  {parse: parseRequirements},
+	"Pipfile.lock":      {parse: parsePipfileLock, resolved: true},
+	"pom.xml":           {parse: parsePom},
+	"Cargo.toml":        {parse: parseCargoToml},
+	"Cargo.lock":        {parse: parseCargoLock, resolved: true},
+	"composer.json":     {parse: parseComposerJSON},
+	"composer.lock":     {parse: parseComposerLock, resolved: true},
+}
+
+// DependencyUseCase マニフェストファイルからサードパーティの依存関係の一覧を作成する
+type DependencyUseCase struct{}
+
+// NewDependencyUseCase 依存関係の一覧のユースケースを作成
+func NewDependencyUseCase() *DependencyUseCase {
+	return &DependencyUseCase{}
+}
+
+// IsManifest ファイル名が対応している依存関係のマニフェストかを判定
+func IsManifest(name string) bool {
+	_, ok := lookupManifest(name)
+	return ok
+}
+
+// Execute 対応しているすべてのマニフェストを解析して結果をまとめる
+// ロックファイルで解決されたバージョンはマニフェストで宣言された範囲より優先し、
+// マニフェストの宣言で解決済みのパッケージが直接の依存関係かを判定する
+func (uc *DependencyUseCase) Execute(ctx context.Context, files []entities.FileInfo) (*entities.DependencyInventory, error) {
+	inventory := &entities.DependencyInventory{
+		Manifests:    []string{},
+		Dependencies: []entities.Dependency{},
+	}
+
+	type resolvedEntry struct {
+		dep      entities.Dependency
+		priority int
+	}
+	resolved := make(map[string][]resolvedEntry)
+	declared := make(map[string][]entities.Dependency)
+	var order []string
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		format, ok := lookupManifest(file.Name)
+		if !ok {
+			continue
+		}
+		deps, err := format.parse(file.Name, file.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", file.Name, err)
+		}
+		inventory.Manifests = append(inventory.Manifests, file.Name)
+
+		for _, dep := range deps {
+			if dep.Name == "" {
+				continue
+			}
+			key := packageKey(dep.Ecosystem, dep.Name)
+			if _, seen := resolved[key]; !seen {
+				if _, seen := declared[key]; !seen {
+					order = append(order, key)
+				}
+			}
+			if format.resolved {
+				resolved[key] = append(resolved[key], resolvedEntry{dep: dep, priority: format.priority})
+			} else {
+				declared[key] = append(declared[key], dep)
+			}
+		}
+	}
+
+	for _, key := range order {
+		entries := resolved[key]
+		if len(entries) == 0 {
+			inventory.Dependencies = append(inventory.Dependencies, uniqueDependencies(declared[key])...)
+			continue
+		}
+
+		// 最も優先度の高いマニフェストの解決結果だけを採用する（go.mod と go.sum の重複など）
+		best := entries[0].priority
+		for _, entry := range entries {
+			if entry.priority < best {
+				best = entry.priority
+			}
+		}
+		var deps []entities.Dependency
+		for _, entry := range entries {
+			if entry.priority == best {
+				deps = append(deps, entry.dep)
+			}
+		}
+		deps = uniqueDependencies(deps)
+
+		if decl := declared[key]; len(decl) > 0 {
+			markDirect(deps, decl)
+		}
+		inventory.Dependencies = append(inventory.Dependencies, deps...)
+	}
+
+	sort.SliceStable(inventory.Dependencies, func(i, j int) bool {
+		a, b := inventory.Dependencies[i], inventory.Dependencies[j]
+		if a.Ecosystem != b.Ecosystem {
+			return a.Ecosystem < b.Ecosystem
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+
+	return inventory, nil
+}
+
+// markDirect マニフェストが宣言している解決済みのパッケージに印を付ける
+// ロックファイルがどのコピーが直接の依存関係かを記録していればそれを使い、
+// なければ最初の（トップレベルの）コピーを使う
+func markDirect(deps []entities.Dependency, declared []entities.Dependency) {
+	for _, dep := range deps {
+		if dep.Direct {
+			return
+		}
+	}
+	deps[0].Direct = true
+	if deps[0].Scope == "runtime" {
+		deps[0].Scope = declared[0].Scope
+	}
+}
+
+// uniqueDependencies 同じバージョンのエントリーをまとめ、フラグとメタデータを結合する
+func uniqueDependencies(deps []entities.Dependency) []entities.Dependency {
+	var result []entities.Dependency
+	index := make(map[string]int)
+	for _, dep := range deps {
+		i, ok := index[dep.Version]
+		if !ok {
+			index[dep.Version] = len(result)
+			result = append(result, dep)
+			continue
+		}
+		merged := &result[i]
+		merged.Direct = merged.Direct || dep.Direct
+		if len(merged.Licenses) == 0 {
+			merged.Licenses = dep.Licenses
+		}
+		merged.DependsOn = mergeStrings(merged.DependsOn, dep.DependsOn)
+	}
+	return result
+}
+
+func mergeStrings(a, b []string) []string {
+	seen := make(map[string]bool, len(a))
+	for _, s := range a {
+		seen[s] = true
+	}
+	for _, s := range b {
+		if !seen[s] {
+			seen[s] = true
+			a = append(a, s)
+		}
+	}
+	return a
+}
+
+func lookupManifest(name string) (manifestFormat, bool) {
+	base := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if format, ok := manifestFormats[base]; ok {
+		return format, true
+	}
+	// requirements-dev.txt や requirements/base.txt のような分割された要件ファイル
+	if strings.HasPrefix(base, "requirements") && strings.HasSuffix(base, ".txt") {
+		return manifestFormats["requirements.txt"], true
+	}
+	// Go 実行ファイルから復元したビルド情報（<ファイル名>.buildinfo）
+	if strings.HasSuffix(base, ".buildinfo") {
+		return manifestFormats[".buildinfo"], true
+	}
+	return manifestFormat{}, false
+}
+
+// NormalizePackageName エコシステムが名前を比較する方法でパッケージ名を正規化
+func NormalizePackageName(ecosystem, name string) string {
+	switch ecosystem {
+	case "PyPI":
+		return strings.NewReplacer("_", "-", ".", "-").Replace(strings.ToLower(name))
+	case "Packagist":
+		return strings.ToLower(name)
+	}
+	return name
+}
+
+func packageKey(ecosystem, name string) string {
+	return ecosystem + "|" + NormalizePackageName(ecosystem, name)
+}
+
+func newDependency(ecosystem, name, version, manifest string, direct bool, scope string) entities.Dependency {
+	name = strings.TrimSpace(name)
+	version = strings.TrimSpace(version)
+	return entities.Dependency{
+		Name:      name,
+		Version:   version,
+		Ecosystem: ecosystem,
+		PURL:      PackageURL(ecosystem, name, version),
+		Direct:    direct,
+		Scope:     scope,
+		Manifest:  manifest,
+	}
+}
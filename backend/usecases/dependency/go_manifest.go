@@ -0,0 +1,140 @@
+package dependency
+
+import (
+	"bufio"
+	"runtime/debug"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// parseGoMod go.mod から require されたモジュールを取り出す。"// indirect" は推移的な依存関係を表す
+func parseGoMod(name, content string) ([]entities.Dependency, error) {
+	var deps []entities.Dependency
+	replaced := make(map[string]string)
+
+	inRequire := false
+	inReplace := false
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		indirect := strings.Contains(line, "// indirect")
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+
+		switch {
+		case line == "require (":
+			inRequire = true
+			continue
+		case line == "replace (":
+			inReplace = true
+			continue
+		case line == ")":
+			inRequire, inReplace = false, false
+			continue
+		case strings.HasPrefix(line, "require "):
+			line = strings.TrimSpace(strings.TrimPrefix(line, "require "))
+			inRequire = false
+			deps = appendGoRequire(deps, name, line, indirect)
+			continue
+		case strings.HasPrefix(line, "replace "):
+			parseGoReplace(strings.TrimPrefix(line, "replace "), replaced)
+			continue
+		}
+
+		if inRequire {
+			deps = appendGoRequire(deps, name, line, indirect)
+		} else if inReplace {
+			parseGoReplace(line, replaced)
+		}
+	}
+
+	// replace ディレクティブで差し替えられたバージョンを反映する
+	for i, dep := range deps {
+		if version, ok := replaced[dep.Name]; ok {
+			deps[i].Version = version
+			deps[i].PURL = PackageURL(dep.Ecosystem, dep.Name, version)
+		}
+	}
+
+	return deps, scanner.Err()
+}
+
+func appendGoRequire(deps []entities.Dependency, manifest, line string, indirect bool) []entities.Dependency {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return deps
+	}
+	return append(deps, newDependency("Go", fields[0], fields[1], manifest, !indirect, "runtime"))
+}
+
+// parseGoReplace バージョンを固定する "old [v] => new v" の置き換えを記録
+func parseGoReplace(line string, replaced map[string]string) {
+	parts := strings.SplitN(line, "=>", 2)
+	if len(parts) != 2 {
+		return
+	}
+	from := strings.Fields(parts[0])
+	to := strings.Fields(parts[1])
+	if len(from) == 0 || len(to) != 2 {
+		return
+	}
+	replaced[from[0]] = to[1]
+}
+
+// parseGoSum ソースをダウンロードしたモジュールをすべて列挙する
+// 同じモジュールの複数のバージョンがある場合は、MVS が選ぶ最も高いバージョンを使う
+func parseGoSum(name, content string) ([]entities.Dependency, error) {
+	selected := make(map[string]string)
+	var order []string
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		module, version := fields[0], fields[1]
+		// go.mod ファイルだけのハッシュはビルドに使われないモジュールも含むため除外する
+		if strings.HasSuffix(version, "/go.mod") {
+			continue
+		}
+		current, exists := selected[module]
+		if !exists {
+			order = append(order, module)
+		}
+		if !exists || CompareSemver(version, current) > 0 {
+			selected[module] = version
+		}
+	}
+
+	deps := make([]entities.Dependency, 0, len(order))
+	for _, module := range order {
+		deps = append(deps, newDependency("Go", module, selected[module], name, false, "runtime"))
+	}
+	return deps, scanner.Err()
+}
+
+// parseGoBuildInfo Go 実行ファイルに埋め込まれたビルド情報（debug.BuildInfo のテキスト形式）から
+// リンクされたモジュールを列挙する。置き換えを適用した実際にコンパイルされたバージョンだが、
+// 直接 require されたかどうかは記録されていない
+func parseGoBuildInfo(name, content string) ([]entities.Dependency, error) {
+	bi, err := debug.ParseBuildInfo(content)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := make([]entities.Dependency, 0, len(bi.Deps))
+	for _, module := range bi.Deps {
+		version := module.Version
+		if module.Replace != nil && module.Replace.Version != "" {
+			version = module.Replace.Version
+		}
+		deps = append(deps, newDependency("Go", module.Path, version, name, false, "runtime"))
+	}
+	return deps, nil
+}
@@ -0,0 +1,184 @@
+package dependency
+
+import (
+	"bufio"
+	"encoding/json"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// parseCargoLock Cargo.lock の [[package]] テーブルを読む
+// source のないパッケージはワークスペースのメンバーなので飛ばす
+func parseCargoLock(name, content string) ([]entities.Dependency, error) {
+	var deps []entities.Dependency
+	var current map[string]string
+	var dependsOn []string
+	inDependencies := false
+
+	flush := func() {
+		if current != nil && current["source"] != "" {
+			dep := newDependency("crates.io", current["name"], current["version"], name, false, "runtime")
+			dep.DependsOn = dependsOn
+			deps = append(deps, dep)
+		}
+		current, dependsOn, inDependencies = nil, nil, false
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "[[package]]":
+			flush()
+			current = make(map[string]string)
+			continue
+		case strings.HasPrefix(line, "["):
+			flush()
+			continue
+		case current == nil || line == "" || strings.HasPrefix(line, "#"):
+			continue
+		}
+
+		if inDependencies {
+			if line == "]" {
+				inDependencies = false
+				continue
+			}
+			// "serde 1.0.0" のようにバージョン付きで書かれる場合は名前のみ使う
+			entry := strings.Fields(strings.Trim(line, `",`))
+			if len(entry) > 0 {
+				dependsOn = append(dependsOn, entry[0])
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		if key == "dependencies" {
+			if value == "[" {
+				inDependencies = true
+			}
+			continue
+		}
+		current[key] = strings.Trim(value, `"`)
+	}
+	flush()
+
+	return deps, scanner.Err()
+}
+
+type composerLock struct {
+	Packages    []composerPackage `json:"packages"`
+	PackagesDev []composerPackage `json:"packages-dev"`
+}
+
+type composerPackage struct {
+	Name    string            `json:"name"`
+	Version string            `json:"version"`
+	License []string          `json:"license"`
+	Require map[string]string `json:"require"`
+}
+
+// parseComposerLock composer.lock から解決済みのパッケージとライセンスを取り出す
+func parseComposerLock(name, content string) ([]entities.Dependency, error) {
+	var lock composerLock
+	if err := json.Unmarshal([]byte(content), &lock); err != nil {
+		return nil, err
+	}
+
+	var deps []entities.Dependency
+	appendPackages := func(packages []composerPackage, scope string) {
+		for _, pkg := range packages {
+			dep := newDependency("Packagist", pkg.Name, strings.TrimPrefix(pkg.Version, "v"), name, false, scope)
+			dep.Licenses = pkg.License
+			for _, required := range sortedKeys(pkg.Require) {
+				// php 本体や ext-* 拡張はパッケージではない
+				if strings.Contains(required, "/") {
+					dep.DependsOn = append(dep.DependsOn, required)
+				}
+			}
+			deps = append(deps, dep)
+		}
+	}
+	appendPackages(lock.Packages, "runtime")
+	appendPackages(lock.PackagesDev, "development")
+	return deps, nil
+}
+
+type composerJSON struct {
+	Require    map[string]string `json:"require"`
+	RequireDev map[string]string `json:"require-dev"`
+	License    json.RawMessage   `json:"license"`
+}
+
+// parseComposerJSON composer.json から宣言された依存関係を取り出す
+func parseComposerJSON(name, content string) ([]entities.Dependency, error) {
+	var manifest composerJSON
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		return nil, err
+	}
+
+	var deps []entities.Dependency
+	for _, pkg := range sortedKeys(manifest.Require) {
+		if strings.Contains(pkg, "/") {
+			deps = append(deps, newDependency("Packagist", pkg, manifest.Require[pkg], name, true, "runtime"))
+		}
+	}
+	for _, pkg := range sortedKeys(manifest.RequireDev) {
+		if strings.Contains(pkg, "/") {
+			deps = append(deps, newDependency("Packagist", pkg, manifest.RequireDev[pkg], name, true, "development"))
+		}
+	}
+	return deps, nil
+}
+
+// parseCargoToml Cargo.toml の [dependencies] 形式のテーブルで宣言されたクレート名を取り出す
+func parseCargoToml(name, content string) ([]entities.Dependency, error) {
+	var deps []entities.Dependency
+	scope := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			switch strings.Trim(line, "[] ") {
+			case "dependencies", "build-dependencies":
+				scope = "runtime"
+			case "dev-dependencies":
+				scope = "development"
+			default:
+				scope = ""
+			}
+			continue
+		}
+		if scope == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		crate := strings.Trim(strings.TrimSpace(key), `"`)
+		value = strings.TrimSpace(value)
+		version := strings.Trim(value, `"`)
+		if strings.HasPrefix(value, "{") {
+			version = ""
+			if _, rest, found := strings.Cut(value, "version"); found {
+				if _, v, found := strings.Cut(rest, `"`); found {
+					version, _, _ = strings.Cut(v, `"`)
+				}
+			}
+		}
+		deps = append(deps, newDependency("crates.io", crate, version, name, true, scope))
+	}
+	return deps, scanner.Err()
+}
@@ -0,0 +1,114 @@
+package dependency
+
+import (
+	"encoding/xml"
+	"regexp"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+type pomProject struct {
+	GroupID string `xml:"groupId"`
+	Version string `xml:"version"`
+	Parent  struct {
+		GroupID string `xml:"groupId"`
+		Version string `xml:"version"`
+	} `xml:"parent"`
+	Properties struct {
+		Entries []pomProperty `xml:",any"`
+	} `xml:"properties"`
+	Licenses struct {
+		License []struct {
+			Name string `xml:"name"`
+		} `xml:"license"`
+	} `xml:"licenses"`
+	Dependencies struct {
+		Dependency []pomDependency `xml:"dependency"`
+	} `xml:"dependencies"`
+	DependencyManagement struct {
+		Dependencies struct {
+			Dependency []pomDependency `xml:"dependency"`
+		} `xml:"dependencies"`
+	} `xml:"dependencyManagement"`
+}
+
+type pomProperty struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+type pomDependency struct {
+	GroupID    string `xml:"groupId"`
+	ArtifactID string `xml:"artifactId"`
+	Version    string `xml:"version"`
+	Scope      string `xml:"scope"`
+	Optional   string `xml:"optional"`
+}
+
+var pomPropertyPattern = regexp.MustCompile(`\$\{([^}]+)\}`)
+
+// parsePom pom.xml で宣言された依存関係を取り出す
+// dependencyManagement で管理されたバージョンとファイル内の ${property} の参照は解決するが、
+// 継承元の親 POM は参照できないため未解決のまま残す
+func parsePom(name, content string) ([]entities.Dependency, error) {
+	var pom pomProject
+	if err := xml.Unmarshal([]byte(content), &pom); err != nil {
+		return nil, err
+	}
+
+	properties := map[string]string{
+		"project.version":        firstNonEmpty(pom.Version, pom.Parent.Version),
+		"project.groupId":        firstNonEmpty(pom.GroupID, pom.Parent.GroupID),
+		"project.parent.version": pom.Parent.Version,
+	}
+	for _, prop := range pom.Properties.Entries {
+		properties[prop.XMLName.Local] = strings.TrimSpace(prop.Value)
+	}
+	resolve := func(value string) string {
+		return pomPropertyPattern.ReplaceAllStringFunc(strings.TrimSpace(value), func(ref string) string {
+			if resolved, ok := properties[ref[2:len(ref)-1]]; ok {
+				return resolved
+			}
+			return ref
+		})
+	}
+
+	managed := make(map[string]string)
+	for _, dep := range pom.DependencyManagement.Dependencies.Dependency {
+		managed[resolve(dep.GroupID)+":"+resolve(dep.ArtifactID)] = resolve(dep.Version)
+	}
+
+	var deps []entities.Dependency
+	for _, dep := range pom.Dependencies.Dependency {
+		coordinate := resolve(dep.GroupID) + ":" + resolve(dep.ArtifactID)
+		version := resolve(dep.Version)
+		if version == "" {
+			version = managed[coordinate]
+		}
+		deps = append(deps, newDependency("Maven", coordinate, version, name, true, mavenScope(dep.Scope, dep.Optional)))
+	}
+	return deps, nil
+}
+
+func mavenScope(scope, optional string) string {
+	if strings.TrimSpace(optional) == "true" {
+		return "optional"
+	}
+	switch strings.TrimSpace(scope) {
+	case "test":
+		return "test"
+	case "provided", "system":
+		return "optional"
+	}
+	return "runtime"
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
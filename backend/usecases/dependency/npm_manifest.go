@@ -0,0 +1,180 @@
+package dependency
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+type packageJSON struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// parsePackageJSON 宣言された依存関係を取り出す。バージョンは解決済みのものではなく範囲
+func parsePackageJSON(name, content string) ([]entities.Dependency, error) {
+	var manifest packageJSON
+	if err := json.Unmarshal([]byte(content), &manifest); err != nil {
+		return nil, err
+	}
+
+	var deps []entities.Dependency
+	groups := []struct {
+		entries map[string]string
+		scope   string
+	}{
+		{manifest.Dependencies, "runtime"},
+		{manifest.PeerDependencies, "runtime"},
+		{manifest.OptionalDependencies, "optional"},
+		{manifest.DevDependencies, "development"},
+	}
+	for _, group := range groups {
+		for _, pkg := range sortedKeys(group.entries) {
+			deps = append(deps, newDependency("npm", pkg, group.entries[pkg], name, true, group.scope))
+		}
+	}
+	return deps, nil
+}
+
+type packageLock struct {
+	LockfileVersion int                       `json:"lockfileVersion"`
+	Packages        map[string]lockPackage    `json:"packages"`
+	Dependencies    map[string]lockDependency `json:"dependencies"`
+}
+
+type lockPackage struct {
+	Name         string            `json:"name"`
+	Version      string            `json:"version"`
+	License      json.RawMessage   `json:"license"`
+	Dev          bool              `json:"dev"`
+	Optional     bool              `json:"optional"`
+	Dependencies map[string]string `json:"dependencies"`
+	DevDeps      map[string]string `json:"devDependencies"`
+}
+
+type lockDependency struct {
+	Version      string                    `json:"version"`
+	Dev          bool                      `json:"dev"`
+	Optional     bool                      `json:"optional"`
+	Requires     map[string]string         `json:"requires"`
+	Dependencies map[string]lockDependency `json:"dependencies"`
+}
+
+// parsePackageLock package-lock.json（v1、v2、v3）から解決済みの依存関係を取り出す
+func parsePackageLock(name, content string) ([]entities.Dependency, error) {
+	var lock packageLock
+	if err := json.Unmarshal([]byte(content), &lock); err != nil {
+		return nil, err
+	}
+
+	if len(lock.Packages) > 0 {
+		return parseLockPackages(name, lock.Packages), nil
+	}
+
+	// v1 はネストした依存を持つため、トップレベル（ホイストされたもの）から順に幅優先で辿る
+	var deps []entities.Dependency
+	queue := []map[string]lockDependency{lock.Dependencies}
+	for len(queue) > 0 {
+		entries := queue[0]
+		queue = queue[1:]
+		for _, pkg := range sortedKeys(entries) {
+			entry := entries[pkg]
+			dep := newDependency("npm", pkg, entry.Version, name, false, npmScope(entry.Dev, entry.Optional))
+			dep.DependsOn = sortedKeys(entry.Requires)
+			deps = append(deps, dep)
+			if len(entry.Dependencies) > 0 {
+				queue = append(queue, entry.Dependencies)
+			}
+		}
+	}
+	return deps, nil
+}
+
+// parseLockPackages ロックファイル v2/v3 の "packages" を読む
+// ルートのパッケージ（""）が直接の依存関係を列挙している
+func parseLockPackages(name string, packages map[string]lockPackage) []entities.Dependency {
+	direct := make(map[string]bool)
+	if root, ok := packages[""]; ok {
+		for pkg := range root.Dependencies {
+			direct[pkg] = true
+		}
+		for pkg := range root.DevDeps {
+			direct[pkg] = true
+		}
+	}
+
+	var deps []entities.Dependency
+	for _, path := range sortedKeys(packages) {
+		if path == "" || !strings.Contains(path, "node_modules/") {
+			continue
+		}
+		entry := packages[path]
+		pkg := entry.Name
+		if pkg == "" {
+			pkg = path[strings.LastIndex(path, "node_modules/")+len("node_modules/"):]
+		}
+		// ネストされていない node_modules 直下のものだけが直接依存になりうる
+		isDirect := direct[pkg] && path == "node_modules/"+pkg
+
+		dep := newDependency("npm", pkg, entry.Version, name, isDirect, npmScope(entry.Dev, entry.Optional))
+		dep.Licenses = parseLicenseField(entry.License)
+		dep.DependsOn = sortedKeys(entry.Dependencies)
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+func npmScope(dev, optional bool) string {
+	switch {
+	case dev:
+		return "development"
+	case optional:
+		return "optional"
+	}
+	return "runtime"
+}
+
+// parseLicenseField "MIT"、{"type": "MIT"}、またはそれらのリストを受け付ける
+func parseLicenseField(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		if single == "" {
+			return nil
+		}
+		return []string{single}
+	}
+
+	var object struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &object); err == nil && object.Type != "" {
+		return []string{object.Type}
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(raw, &list); err == nil {
+		var licenses []string
+		for _, item := range list {
+			licenses = append(licenses, parseLicenseField(item)...)
+		}
+		return licenses
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
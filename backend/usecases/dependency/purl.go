@@ -0,0 +1,56 @@
+package dependency
+
+import (
+	"net/url"
+	"strings"
+)
+
+// PackageURL 依存関係の package URL（purl）を作成
+// バージョンの範囲は purl のバージョンとして不正なため、固定されていなければバージョンを省く
+func PackageURL(ecosystem, name, version string) string {
+	var purlType, path string
+	switch ecosystem {
+	case "Go":
+		purlType, path = "golang", escapeSegments(strings.Split(name, "/"))
+	case "npm":
+		// スコープ付きパッケージの "@" は purl では %40 にエンコードする
+		purlType, path = "npm", strings.Replace(escapeSegments(strings.SplitN(name, "/", 2)), "@", "%40", 1)
+	case "PyPI":
+		purlType = "pypi"
+		path = url.PathEscape(strings.ReplaceAll(strings.ToLower(name), "_", "-"))
+	case "Maven":
+		purlType, path = "maven", escapeSegments(strings.SplitN(name, ":", 2))
+	case "crates.io":
+		purlType, path = "cargo", url.PathEscape(name)
+	case "Packagist":
+		purlType, path = "composer", escapeSegments(strings.SplitN(name, "/", 2))
+	default:
+		purlType, path = "generic", url.PathEscape(name)
+	}
+
+	purl := "pkg:" + purlType + "/" + path
+	if IsPinnedVersion(version) {
+		purl += "@" + url.PathEscape(version)
+	}
+	return purl
+}
+
+// IsPinnedVersion version が範囲ではなく 1 つのリリースを指すかを判定
+func IsPinnedVersion(version string) bool {
+	version = strings.TrimSpace(version)
+	if version == "" || strings.ContainsAny(version, "^~<>=*|, ${}") {
+		return false
+	}
+	if strings.HasSuffix(version, ".x") || strings.HasSuffix(version, ".X") {
+		return false
+	}
+	first := strings.TrimPrefix(version, "v")
+	return first != "" && first[0] >= '0' && first[0] <= '9'
+}
+
+func escapeSegments(segments []string) string {
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,70 @@
+package dependency
+
+import (
+	"bufio"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// requirementPattern requirements.txt の "name[extras] <op> version" にマッチする
+var requirementPattern = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)\s*(?:\[[^\]]*\])?\s*(?:(===|==|~=|>=|<=|!=|>|<)\s*([^\s,;]+))?`)
+
+// parseRequirements 依存関係を取り出す。"==" で固定されたものだけが正確なバージョンになる
+func parseRequirements(name, content string) ([]entities.Dependency, error) {
+	var deps []entities.Dependency
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		// コメント・オプション行（-r, -e, --index-url など）・URL 指定は対象外
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") || strings.Contains(line, "://") {
+			continue
+		}
+
+		match := requirementPattern.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		version := match[3]
+		if match[2] != "==" && match[2] != "===" {
+			version = strings.TrimSpace(match[2] + match[3])
+		}
+		deps = append(deps, newDependency("PyPI", match[1], version, name, true, "runtime"))
+	}
+	return deps, scanner.Err()
+}
+
+type pipfileLock struct {
+	Default map[string]pipfilePackage `json:"default"`
+	Develop map[string]pipfilePackage `json:"develop"`
+}
+
+type pipfilePackage struct {
+	Version string `json:"version"`
+}
+
+// parsePipfileLock 解決済みのパッケージを取り出す
+// Pipfile.lock は直接要求されたパッケージを記録していないため、すべて推移的な依存関係とする
+func parsePipfileLock(name, content string) ([]entities.Dependency, error) {
+	var lock pipfileLock
+	if err := json.Unmarshal([]byte(content), &lock); err != nil {
+		return nil, err
+	}
+
+	var deps []entities.Dependency
+	for _, pkg := range sortedKeys(lock.Default) {
+		version := strings.TrimPrefix(lock.Default[pkg].Version, "==")
+		deps = append(deps, newDependency("PyPI", pkg, version, name, false, "runtime"))
+	}
+	for _, pkg := range sortedKeys(lock.Develop) {
+		version := strings.TrimPrefix(lock.Develop[pkg].Version, "==")
+		deps = append(deps, newDependency("PyPI", pkg, version, name, false, "development"))
+	}
+	return deps, nil
+}
@@ -0,0 +1,357 @@
+package dependency
+
+import (
+	"crypto/rand"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+const sbomToolName = "reverse-engineering-backend"
+
+// SBOMSubject SBOM が記述するソフトウェア
+type SBOMSubject struct {
+	Name    string
+	Version string
+}
+
+// CycloneDX 1.5 JSON の構造
+
+type cycloneDXBOM struct {
+	BOMFormat    string                `json:"bomFormat"`
+	SpecVersion  string                `json:"specVersion"`
+	SerialNumber string                `json:"serialNumber"`
+	Version      int                   `json:"version"`
+	Metadata     cycloneDXMetadata     `json:"metadata"`
+	Components   []cycloneDXComponent  `json:"components"`
+	Dependencies []cycloneDXDependency `json:"dependencies"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string             `json:"timestamp"`
+	Tools     cycloneDXTools     `json:"tools"`
+	Component cycloneDXComponent `json:"component"`
+}
+
+type cycloneDXTools struct {
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	BOMRef     string              `json:"bom-ref,omitempty"`
+	Type       string              `json:"type"`
+	Group      string              `json:"group,omitempty"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	Scope      string              `json:"scope,omitempty"`
+	PURL       string              `json:"purl,omitempty"`
+	Licenses   []cycloneDXLicense  `json:"licenses,omitempty"`
+	Properties []cycloneDXProperty `json:"properties,omitempty"`
+}
+
+type cycloneDXLicense struct {
+	License    *cycloneDXLicenseID `json:"license,omitempty"`
+	Expression string              `json:"expression,omitempty"`
+}
+
+type cycloneDXLicenseID struct {
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name,omitempty"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cycloneDXDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// BuildCycloneDX 依存関係の一覧を CycloneDX 1.5 の JSON ドキュメントとして出力
+func BuildCycloneDX(subject SBOMSubject, inventory *entities.DependencyInventory) interface{} {
+	rootRef := "root:" + subject.Name
+	refs := dependencyRefs(inventory.Dependencies)
+
+	components := make([]cycloneDXComponent, 0, len(inventory.Dependencies))
+	graph := []cycloneDXDependency{{Ref: rootRef, DependsOn: []string{}}}
+	for i, dep := range inventory.Dependencies {
+		component := cycloneDXComponent{
+			BOMRef:   refs.byIndex[i],
+			Type:     "library",
+			Name:     dep.Name,
+			Version:  dep.Version,
+			Scope:    cycloneDXScope(dep.Scope),
+			PURL:     dep.PURL,
+			Licenses: cycloneDXLicenses(dep.Licenses),
+			Properties: []cycloneDXProperty{
+				{Name: "ecosystem", Value: dep.Ecosystem},
+				{Name: "manifest", Value: dep.Manifest},
+				{Name: "direct", Value: fmt.Sprintf("%t", dep.Direct)},
+			},
+		}
+		if dep.Ecosystem == "Maven" {
+			if group, artifact, ok := strings.Cut(dep.Name, ":"); ok {
+				component.Group, component.Name = group, artifact
+			}
+		}
+		components = append(components, component)
+
+		if dep.Direct {
+			graph[0].DependsOn = append(graph[0].DependsOn, refs.byIndex[i])
+		}
+		graph = append(graph, cycloneDXDependency{
+			Ref:       refs.byIndex[i],
+			DependsOn: refs.resolve(dep.Ecosystem, dep.DependsOn),
+		})
+	}
+
+	return cycloneDXBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Tools: cycloneDXTools{
+				Components: []cycloneDXComponent{{Type: "application", Name: sbomToolName}},
+			},
+			Component: cycloneDXComponent{
+				BOMRef:  rootRef,
+				Type:    "application",
+				Name:    subject.Name,
+				Version: subject.Version,
+			},
+		},
+		Components:   components,
+		Dependencies: graph,
+	}
+}
+
+func cycloneDXScope(scope string) string {
+	switch scope {
+	case "runtime":
+		return "required"
+	case "optional":
+		return "optional"
+	case "development", "test":
+		return "excluded"
+	}
+	return ""
+}
+
+func cycloneDXLicenses(licenses []string) []cycloneDXLicense {
+	var result []cycloneDXLicense
+	for _, license := range licenses {
+		switch {
+		case strings.ContainsAny(license, " ()"):
+			// "MIT OR Apache-2.0" のような SPDX 式は expression として出力する
+			return []cycloneDXLicense{{Expression: strings.Join(licenses, " AND ")}}
+		case spdxIDPattern.MatchString(license):
+			result = append(result, cycloneDXLicense{License: &cycloneDXLicenseID{ID: license}})
+		default:
+			result = append(result, cycloneDXLicense{License: &cycloneDXLicenseID{Name: license}})
+		}
+	}
+	return result
+}
+
+// SPDX 2.3 JSON の構造
+
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseConcluded string            `json:"licenseConcluded"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	CopyrightText    string            `json:"copyrightText"`
+	Comment          string            `json:"comment,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+var (
+	spdxIDPattern     = regexp.MustCompile(`^[A-Za-z0-9.+-]+$`)
+	spdxUnsafePattern = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+)
+
+// BuildSPDX 依存関係の一覧を SPDX 2.3 の JSON ドキュメントとして出力
+func BuildSPDX(subject SBOMSubject, inventory *entities.DependencyInventory) interface{} {
+	rootID := "SPDXRef-Package-" + spdxUnsafePattern.ReplaceAllString(subject.Name, "-")
+	refs := dependencyRefs(inventory.Dependencies)
+	spdxIDs := make(map[string]string, len(refs.byIndex))
+	for i, ref := range refs.byIndex {
+		spdxIDs[ref] = fmt.Sprintf("SPDXRef-Package-%d-%s", i+1, spdxUnsafePattern.ReplaceAllString(inventory.Dependencies[i].Name, "-"))
+	}
+
+	packages := []spdxPackage{{
+		SPDXID:           rootID,
+		Name:             subject.Name,
+		VersionInfo:      subject.Version,
+		DownloadLocation: "NOASSERTION",
+		LicenseConcluded: "NOASSERTION",
+		LicenseDeclared:  "NOASSERTION",
+		CopyrightText:    "NOASSERTION",
+	}}
+	relationships := []spdxRelationship{{
+		SPDXElementID:      "SPDXRef-DOCUMENT",
+		RelationshipType:   "DESCRIBES",
+		RelatedSPDXElement: rootID,
+	}}
+
+	for i, dep := range inventory.Dependencies {
+		id := spdxIDs[refs.byIndex[i]]
+		pkg := spdxPackage{
+			SPDXID:           id,
+			Name:             dep.Name,
+			VersionInfo:      dep.Version,
+			DownloadLocation: "NOASSERTION",
+			LicenseConcluded: "NOASSERTION",
+			LicenseDeclared:  spdxLicense(dep.Licenses),
+			CopyrightText:    "NOASSERTION",
+			Comment:          fmt.Sprintf("ecosystem=%s manifest=%s scope=%s", dep.Ecosystem, dep.Manifest, dep.Scope),
+		}
+		if dep.PURL != "" {
+			pkg.ExternalRefs = []spdxExternalRef{{
+				ReferenceCategory: "PACKAGE-MANAGER",
+				ReferenceType:     "purl",
+				ReferenceLocator:  dep.PURL,
+			}}
+		}
+		packages = append(packages, pkg)
+
+		relationType := "DEPENDS_ON"
+		if dep.Scope == "development" || dep.Scope == "test" {
+			relationType = "DEV_DEPENDENCY_OF"
+		}
+		if dep.Direct {
+			if relationType == "DEPENDS_ON" {
+				relationships = append(relationships, spdxRelationship{SPDXElementID: rootID, RelationshipType: relationType, RelatedSPDXElement: id})
+			} else {
+				relationships = append(relationships, spdxRelationship{SPDXElementID: id, RelationshipType: relationType, RelatedSPDXElement: rootID})
+			}
+		}
+		for _, target := range refs.resolve(dep.Ecosystem, dep.DependsOn) {
+			relationships = append(relationships, spdxRelationship{SPDXElementID: id, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: spdxIDs[target]})
+		}
+	}
+
+	now := time.Now().UTC()
+	return spdxDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              subject.Name,
+		DocumentNamespace: fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s", spdxUnsafePattern.ReplaceAllString(subject.Name, "-"), newUUID()),
+		CreationInfo: spdxCreationInfo{
+			Created:  now.Format(time.RFC3339),
+			Creators: []string{"Tool: " + sbomToolName},
+		},
+		Packages:      packages,
+		Relationships: relationships,
+	}
+}
+
+func spdxLicense(licenses []string) string {
+	if len(licenses) == 0 {
+		return "NOASSERTION"
+	}
+	var ids []string
+	for _, license := range licenses {
+		if !spdxIDPattern.MatchString(license) && !strings.ContainsAny(license, "()") {
+			// SPDX 識別子でないライセンス名は式として表現できない
+			return "NOASSERTION"
+		}
+		ids = append(ids, license)
+	}
+	if len(ids) == 1 {
+		return ids[0]
+	}
+	return "(" + strings.Join(ids, " AND ") + ")"
+}
+
+// refTable 依存関係に安定した参照を割り当て、DependsOn の名前をその参照に解決する
+type refTable struct {
+	byIndex []string
+	byName  map[string]string
+}
+
+func dependencyRefs(deps []entities.Dependency) refTable {
+	table := refTable{
+		byIndex: make([]string, len(deps)),
+		byName:  make(map[string]string),
+	}
+	used := make(map[string]int)
+	for i, dep := range deps {
+		ref := dep.PURL
+		if ref == "" || !IsPinnedVersion(dep.Version) {
+			ref = dep.Ecosystem + ":" + dep.Name + "@" + dep.Version
+		}
+		// 同じ purl が複数のマニフェストに現れても bom-ref は一意でなければならない
+		if n := used[ref]; n > 0 {
+			used[ref] = n + 1
+			ref = fmt.Sprintf("%s#%d", ref, n+1)
+		} else {
+			used[ref] = 1
+		}
+		table.byIndex[i] = ref
+
+		key := packageKey(dep.Ecosystem, dep.Name)
+		if _, ok := table.byName[key]; !ok {
+			table.byName[key] = ref
+		}
+	}
+	return table
+}
+
+func (t refTable) resolve(ecosystem string, names []string) []string {
+	refs := []string{}
+	for _, name := range names {
+		if ref, ok := t.byName[packageKey(ecosystem, name)]; ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs
+}
+
+// newUUID ランダムな RFC 4122 バージョン 4 の UUID を返す
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,79 @@
+package dependency
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareSemver 2 つのセマンティックバージョンを比較し、-1、0、1 を返す
+// 先頭の "v" と minor/patch の省略を受け付ける。プレリリースは対応するリリースより前に
+// 並び、ビルドメタデータは無視する
+func CompareSemver(a, b string) int {
+	aCore, aPre := splitSemver(a)
+	bCore, bPre := splitSemver(b)
+
+	for i := 0; i < 3; i++ {
+		if c := compareInts(aCore[i], bCore[i]); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case aPre == "" && bPre == "":
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+
+	aParts := strings.Split(aPre, ".")
+	bParts := strings.Split(bPre, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNum, aErr := strconv.Atoi(aParts[i])
+		bNum, bErr := strconv.Atoi(bParts[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if c := compareInts(aNum, bNum); c != 0 {
+				return c
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(aParts[i], bParts[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return compareInts(len(aParts), len(bParts))
+}
+
+func splitSemver(v string) ([3]int, string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexByte(v, '+'); i >= 0 {
+		v = v[:i]
+	}
+	pre := ""
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+
+	var core [3]int
+	for i, part := range strings.SplitN(v, ".", 3) {
+		n, _ := strconv.Atoi(part)
+		core[i] = n
+	}
+	return core, pre
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,93 @@
+package filetree
+
+import (
+	"context"
+	"errors"
+	"path"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/domain/repositories"
+)
+
+// ErrNotFound 指定したディレクトリの下にファイルがない
+var ErrNotFound = errors.New("directory not found")
+
+// FileTreeUseCase ファイルのスラッシュ区切りの相対パスから、プロジェクトのディレクトリツリーの
+// 1 階層を作成する
+type FileTreeUseCase struct {
+	treeRepo repositories.FileTreeRepository
+}
+
+// NewFileTreeUseCase ファイルツリーのユースケースを作成
+func NewFileTreeUseCase(treeRepo repositories.FileTreeRepository) *FileTreeUseCase {
+	return &FileTreeUseCase{
+		treeRepo: treeRepo,
+	}
+}
+
+// CleanPath クライアントから指定されたディレクトリのパスを正規化
+// プロジェクトのルートは空文字列。ルートの外に出るパスは拒否する
+func CleanPath(dir string) (string, error) {
+	dir = strings.ReplaceAll(dir, "\\", "/")
+	for _, part := range strings.Split(dir, "/") {
+		if part == ".." {
+			return "", errors.New("invalid path")
+		}
+	}
+	dir = strings.Trim(path.Clean("/"+dir), "/")
+	return dir, nil
+}
+
+// Execute dir の直下のファイルとサブディレクトリを一覧にする
+// サブディレクトリには配下のすべてのファイルの数、合計サイズ、言語を付ける。
+// 配下のファイルは読み込まず、リポジトリが集計した値を使う
+func (uc *FileTreeUseCase) Execute(ctx context.Context, projectID uint, commit, dir string) (*entities.FileTree, error) {
+	dir, err := CleanPath(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := uc.treeRepo.FindFiles(ctx, projectID, commit, dir)
+	if err != nil {
+		return nil, err
+	}
+	directories, err := uc.treeRepo.FindDirectories(ctx, projectID, commit, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &entities.FileTree{
+		Path:        dir,
+		Directories: directories,
+		Files:       files,
+		Languages:   make(map[string]int),
+	}
+	if tree.Directories == nil {
+		tree.Directories = []entities.TreeDirectory{}
+	}
+	if tree.Files == nil {
+		tree.Files = []entities.TreeFile{}
+	}
+	for _, file := range files {
+		language := file.Language
+		if language == "" {
+			language = "unknown"
+		}
+		tree.FileCount++
+		tree.Size += file.Size
+		tree.Languages[language]++
+	}
+	for _, directory := range directories {
+		tree.FileCount += directory.FileCount
+		tree.Size += directory.Size
+		for language, count := range directory.Languages {
+			tree.Languages[language] += count
+		}
+	}
+
+	if tree.FileCount == 0 && dir != "" {
+		return nil, ErrNotFound
+	}
+	return tree, nil
+}
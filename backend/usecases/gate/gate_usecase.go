@@ -0,0 +1,318 @@
+package gate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// 判定結果と JUnit のテストケースに出力する条件名
+const (
+	ConditionMaxHighIssues   = "max_high_issues"
+	ConditionMaxComplexity   = "max_complexity"
+	ConditionNoNewVulnerable = "no_new_vulnerable_dependencies"
+	ConditionNoSecrets       = "no_secrets"
+)
+
+const (
+	defaultTimeoutSeconds = 600
+	maxTimeoutSeconds     = 3600
+	defaultMaxHighIssues  = 0
+	defaultMaxComplexity  = 20
+	maxConditionDetails   = 20
+)
+
+// gatingSources max_high_issues で数える決定的な検出元
+var gatingSources = map[string]bool{"sast": true, "secret": true, "osv": true}
+
+// ComplexFunction 循環的複雑度を計測した関数
+type ComplexFunction struct {
+	Path       string
+	Function   string
+	StartLine  int
+	Cyclomatic int
+}
+
+// Evidence ゲートで評価する解析の結果
+type Evidence struct {
+	Findings []entities.Finding
+	// Functions は code_metrics の関数メトリクス（複雑度の高い順）
+	Functions []ComplexFunction
+	// NewVulnerabilities は前回の vulnerability_scan になかった脆弱な依存関係
+	NewVulnerabilities []string
+	HasBaseline        bool
+	// Failed は失敗した解析タイプとエラー、Incomplete はタイムアウトまでに終わらなかった解析タイプ
+	Failed     map[string]string
+	Incomplete []string
+}
+
+// DefaultQualityGate ゲートが設定されていないプロジェクトに適用する
+func DefaultQualityGate() entities.QualityGate {
+	maxHigh := defaultMaxHighIssues
+	maxComplexity := defaultMaxComplexity
+	return entities.QualityGate{
+		MaxHighIssues:               &maxHigh,
+		MaxComplexity:               &maxComplexity,
+		NoNewVulnerableDependencies: true,
+		NoSecrets:                   true,
+		TimeoutSeconds:              defaultTimeoutSeconds,
+	}
+}
+
+// Validate ゲートの設定が使えるかを判定
+// analysisTypes はワーカーが実行できる解析の種類。それ以外の追加解析はタイムアウト
+// まで待ち続けることになるため拒否する
+func Validate(gate entities.QualityGate, analysisTypes []string) error {
+	if gate.MaxHighIssues != nil && *gate.MaxHighIssues < 0 {
+		return fmt.Errorf("max_high_issues must not be negative")
+	}
+	if gate.MaxComplexity != nil && *gate.MaxComplexity < 1 {
+		return fmt.Errorf("max_complexity must be at least 1")
+	}
+	if gate.TimeoutSeconds < 0 || gate.TimeoutSeconds > maxTimeoutSeconds {
+		return fmt.Errorf("timeout_seconds must be between 0 and %d", maxTimeoutSeconds)
+	}
+	known := make(map[string]bool, len(analysisTypes))
+	for _, analysisType := range analysisTypes {
+		known[analysisType] = true
+	}
+	for _, analysisType := range gate.Analyses {
+		if !known[analysisType] {
+			return fmt.Errorf("unknown analysis type: %s", analysisType)
+		}
+	}
+	return nil
+}
+
+// Timeout 設定されたタイムアウト（秒）、または既定値を返す
+func Timeout(gate entities.QualityGate) int {
+	if gate.TimeoutSeconds <= 0 {
+		return defaultTimeoutSeconds
+	}
+	return gate.TimeoutSeconds
+}
+
+// RequiredAnalyses 有効な条件が依存する解析の種類と、その後にゲートに設定された
+// 追加の解析を返す
+func RequiredAnalyses(gate entities.QualityGate) []string {
+	seen := make(map[string]bool)
+	var types []string
+	add := func(analysisType string) {
+		if analysisType != "" && !seen[analysisType] {
+			seen[analysisType] = true
+			types = append(types, analysisType)
+		}
+	}
+
+	if gate.MaxHighIssues != nil {
+		add("sast")
+		add("vulnerability_scan")
+	}
+	if gate.MaxComplexity != nil {
+		add("code_metrics")
+	}
+	if gate.NoNewVulnerableDependencies {
+		add("vulnerability_scan")
+	}
+	// シークレットはアップロード時に検査済みのため解析は不要
+	for _, analysisType := range gate.Analyses {
+		add(analysisType)
+	}
+	return types
+}
+
+// Evaluate 有効なすべての条件を解析結果と照らし合わせる
+func Evaluate(gate entities.QualityGate, evidence Evidence) entities.GateVerdict {
+	var conditions []entities.GateCondition
+	if gate.MaxHighIssues != nil {
+		conditions = append(conditions, evaluateHighIssues(*gate.MaxHighIssues, evidence))
+	}
+	if gate.MaxComplexity != nil {
+		conditions = append(conditions, evaluateComplexity(*gate.MaxComplexity, evidence))
+	}
+	if gate.NoNewVulnerableDependencies {
+		conditions = append(conditions, evaluateNewVulnerabilities(evidence))
+	}
+	if gate.NoSecrets {
+		conditions = append(conditions, evaluateSecrets(evidence))
+	}
+
+	verdict := entities.GateVerdict{Status: "passed", Passed: true, Conditions: conditions}
+	for _, condition := range conditions {
+		if condition.Status != "passed" {
+			verdict.Passed = false
+			verdict.Status = "failed"
+		}
+	}
+	if len(evidence.Incomplete) > 0 {
+		verdict.Passed = false
+		verdict.Status = "timeout"
+	}
+	return verdict
+}
+
+// unavailable 依存する解析が成功しなかったため評価できない条件を返す
+func unavailable(name, threshold string, evidence Evidence, analysisTypes ...string) (entities.GateCondition, bool) {
+	for _, analysisType := range analysisTypes {
+		if cause, ok := evidence.Failed[analysisType]; ok {
+			return entities.GateCondition{
+				Name: name, Status: "error", Threshold: threshold, Actual: "unknown",
+				Reason: fmt.Sprintf("%s analysis failed: %s", analysisType, cause),
+			}, true
+		}
+		for _, incomplete := range evidence.Incomplete {
+			if incomplete == analysisType {
+				return entities.GateCondition{
+					Name: name, Status: "error", Threshold: threshold, Actual: "unknown",
+					Reason: fmt.Sprintf("%s analysis did not finish before the timeout", analysisType),
+				}, true
+			}
+		}
+	}
+	return entities.GateCondition{}, false
+}
+
+func evaluateHighIssues(max int, evidence Evidence) entities.GateCondition {
+	threshold := "<= " + strconv.Itoa(max)
+	if condition, ok := unavailable(ConditionMaxHighIssues, threshold, evidence, "sast", "vulnerability_scan"); ok {
+		return condition
+	}
+
+	var details []string
+	count := 0
+	for _, finding := range evidence.Findings {
+		if !gatingSources[finding.Source] || (finding.Severity != "critical" && finding.Severity != "high") {
+			continue
+		}
+		count++
+		details = appendDetail(details, fmt.Sprintf("[%s] %s %s: %s", finding.Severity, finding.Source, location(finding.Path, finding.StartLine), finding.RuleID))
+	}
+
+	condition := entities.GateCondition{
+		Name:      ConditionMaxHighIssues,
+		Threshold: threshold,
+		Actual:    strconv.Itoa(count),
+		Details:   details,
+	}
+	if count > max {
+		condition.Status = "failed"
+		condition.Reason = fmt.Sprintf("%d critical/high issue(s) found, at most %d allowed", count, max)
+	} else {
+		condition.Status = "passed"
+		condition.Reason = fmt.Sprintf("%d critical/high issue(s) found", count)
+	}
+	return condition
+}
+
+func evaluateComplexity(max int, evidence Evidence) entities.GateCondition {
+	threshold := "<= " + strconv.Itoa(max)
+	if condition, ok := unavailable(ConditionMaxComplexity, threshold, evidence, "code_metrics"); ok {
+		return condition
+	}
+
+	functions := make([]ComplexFunction, len(evidence.Functions))
+	copy(functions, evidence.Functions)
+	sort.SliceStable(functions, func(i, j int) bool { return functions[i].Cyclomatic > functions[j].Cyclomatic })
+
+	highest := 0
+	var details []string
+	for _, fn := range functions {
+		if fn.Cyclomatic > highest {
+			highest = fn.Cyclomatic
+		}
+		if fn.Cyclomatic > max {
+			details = appendDetail(details, fmt.Sprintf("%s %s: cyclomatic complexity %d", location(fn.Path, fn.StartLine), fn.Function, fn.Cyclomatic))
+		}
+	}
+
+	condition := entities.GateCondition{
+		Name:      ConditionMaxComplexity,
+		Threshold: threshold,
+		Actual:    strconv.Itoa(highest),
+		Details:   details,
+	}
+	if highest > max {
+		condition.Status = "failed"
+		condition.Reason = fmt.Sprintf("%s has cyclomatic complexity %d, at most %d allowed", functions[0].Function, highest, max)
+	} else {
+		condition.Status = "passed"
+		condition.Reason = fmt.Sprintf("highest cyclomatic complexity is %d", highest)
+	}
+	return condition
+}
+
+func evaluateNewVulnerabilities(evidence Evidence) entities.GateCondition {
+	if condition, ok := unavailable(ConditionNoNewVulnerable, "0", evidence, "vulnerability_scan"); ok {
+		return condition
+	}
+
+	count := len(evidence.NewVulnerabilities)
+	condition := entities.GateCondition{
+		Name:      ConditionNoNewVulnerable,
+		Threshold: "0",
+		Actual:    strconv.Itoa(count),
+	}
+	for _, vulnerability := range evidence.NewVulnerabilities {
+		condition.Details = appendDetail(condition.Details, vulnerability)
+	}
+
+	baseline := "compared with the previous vulnerability scan"
+	if !evidence.HasBaseline {
+		// 比較対象がない初回はすべての脆弱な依存関係を新規とみなす
+		baseline = "no previous vulnerability scan, every vulnerable dependency counts as new"
+	}
+	if count > 0 {
+		condition.Status = "failed"
+		condition.Reason = fmt.Sprintf("%d new vulnerable dependency finding(s) (%s)", count, baseline)
+	} else {
+		condition.Status = "passed"
+		condition.Reason = "no new vulnerable dependencies (" + baseline + ")"
+	}
+	return condition
+}
+
+func evaluateSecrets(evidence Evidence) entities.GateCondition {
+	var details []string
+	count := 0
+	for _, finding := range evidence.Findings {
+		if finding.Source != "secret" {
+			continue
+		}
+		count++
+		details = appendDetail(details, fmt.Sprintf("%s: %s", location(finding.Path, finding.StartLine), finding.RuleID))
+	}
+
+	condition := entities.GateCondition{
+		Name:      ConditionNoSecrets,
+		Threshold: "0",
+		Actual:    strconv.Itoa(count),
+		Details:   details,
+	}
+	if count > 0 {
+		condition.Status = "failed"
+		condition.Reason = fmt.Sprintf("%d secret(s) found in uploaded files", count)
+	} else {
+		condition.Status = "passed"
+		condition.Reason = "no secrets found"
+	}
+	return condition
+}
+
+func appendDetail(details []string, detail string) []string {
+	if len(details) == maxConditionDetails {
+		return append(details, "...")
+	}
+	if len(details) > maxConditionDetails {
+		return details
+	}
+	return append(details, detail)
+}
+
+func location(path string, line int) string {
+	if line > 0 {
+		return fmt.Sprintf("%s:%d", path, line)
+	}
+	return path
+}
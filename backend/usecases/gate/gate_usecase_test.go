@@ -0,0 +1,203 @@
+package gate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+func intPtr(n int) *int {
+	return &n
+}
+
+func TestEvaluate(t *testing.T) {
+	high := func(source string) entities.Finding {
+		return entities.Finding{Source: source, RuleID: source + "-rule", Severity: "high", Path: "main.go", StartLine: 1}
+	}
+
+	tests := []struct {
+		name       string
+		gate       entities.QualityGate
+		evidence   Evidence
+		status     string
+		conditions map[string]string // 条件名 → status
+	}{
+		{
+			name:     "default gate passes without findings",
+			gate:     DefaultQualityGate(),
+			evidence: Evidence{HasBaseline: true},
+			status:   "passed",
+			conditions: map[string]string{
+				ConditionMaxHighIssues: "passed", ConditionMaxComplexity: "passed",
+				ConditionNoNewVulnerable: "passed", ConditionNoSecrets: "passed",
+			},
+		},
+		{
+			name:       "high issues within the limit",
+			gate:       entities.QualityGate{MaxHighIssues: intPtr(2)},
+			evidence:   Evidence{Findings: []entities.Finding{high("sast"), high("osv")}},
+			status:     "passed",
+			conditions: map[string]string{ConditionMaxHighIssues: "passed"},
+		},
+		{
+			name:       "high issues over the limit",
+			gate:       entities.QualityGate{MaxHighIssues: intPtr(1)},
+			evidence:   Evidence{Findings: []entities.Finding{high("sast"), high("secret")}},
+			status:     "failed",
+			conditions: map[string]string{ConditionMaxHighIssues: "failed"},
+		},
+		{
+			name: "llm and medium findings are not counted",
+			gate: entities.QualityGate{MaxHighIssues: intPtr(0)},
+			evidence: Evidence{Findings: []entities.Finding{
+				high("llm"),
+				{Source: "sast", RuleID: "r", Severity: "medium"},
+			}},
+			status:     "passed",
+			conditions: map[string]string{ConditionMaxHighIssues: "passed"},
+		},
+		{
+			name: "complexity over the limit",
+			gate: entities.QualityGate{MaxComplexity: intPtr(10)},
+			evidence: Evidence{Functions: []ComplexFunction{
+				{Path: "a.go", Function: "small", Cyclomatic: 3},
+				{Path: "b.go", Function: "big", Cyclomatic: 12},
+			}},
+			status:     "failed",
+			conditions: map[string]string{ConditionMaxComplexity: "failed"},
+		},
+		{
+			name:       "complexity at the limit",
+			gate:       entities.QualityGate{MaxComplexity: intPtr(12)},
+			evidence:   Evidence{Functions: []ComplexFunction{{Function: "big", Cyclomatic: 12}}},
+			status:     "passed",
+			conditions: map[string]string{ConditionMaxComplexity: "passed"},
+		},
+		{
+			name:       "new vulnerable dependency",
+			gate:       entities.QualityGate{NoNewVulnerableDependencies: true},
+			evidence:   Evidence{NewVulnerabilities: []string{"GHSA-1 lodash"}},
+			status:     "failed",
+			conditions: map[string]string{ConditionNoNewVulnerable: "failed"},
+		},
+		{
+			name:       "secret found",
+			gate:       entities.QualityGate{NoSecrets: true},
+			evidence:   Evidence{Findings: []entities.Finding{{Source: "secret", RuleID: "aws", Severity: "low"}}},
+			status:     "failed",
+			conditions: map[string]string{ConditionNoSecrets: "failed"},
+		},
+		{
+			name:       "failed analysis makes its conditions error",
+			gate:       entities.QualityGate{MaxHighIssues: intPtr(0), NoSecrets: true},
+			evidence:   Evidence{Failed: map[string]string{"sast": "boom"}},
+			status:     "failed",
+			conditions: map[string]string{ConditionMaxHighIssues: "error", ConditionNoSecrets: "passed"},
+		},
+		{
+			name:       "incomplete analysis times out",
+			gate:       entities.QualityGate{MaxComplexity: intPtr(10), NoSecrets: true},
+			evidence:   Evidence{Incomplete: []string{"code_metrics"}},
+			status:     "timeout",
+			conditions: map[string]string{ConditionMaxComplexity: "error", ConditionNoSecrets: "passed"},
+		},
+		{
+			name:     "no conditions",
+			gate:     entities.QualityGate{},
+			evidence: Evidence{Findings: []entities.Finding{high("secret")}},
+			status:   "passed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict := Evaluate(tt.gate, tt.evidence)
+			if verdict.Status != tt.status || verdict.Passed != (tt.status == "passed") {
+				t.Errorf("Evaluate() status = %s (passed %v), want %s", verdict.Status, verdict.Passed, tt.status)
+			}
+			got := make(map[string]string)
+			for _, condition := range verdict.Conditions {
+				got[condition.Name] = condition.Status
+			}
+			want := tt.conditions
+			if want == nil {
+				want = map[string]string{}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Evaluate() conditions = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEvaluateDetailsAreCapped(t *testing.T) {
+	var findings []entities.Finding
+	for i := 0; i < maxConditionDetails+5; i++ {
+		findings = append(findings, entities.Finding{Source: "secret", RuleID: "r", Path: "f", StartLine: i + 1})
+	}
+	verdict := Evaluate(entities.QualityGate{NoSecrets: true}, Evidence{Findings: findings})
+	details := verdict.Conditions[0].Details
+	if len(details) != maxConditionDetails+1 || details[len(details)-1] != "..." {
+		t.Errorf("got %d details ending with %q, want %d ending with \"...\"", len(details), details[len(details)-1], maxConditionDetails+1)
+	}
+	if verdict.Conditions[0].Actual != "25" {
+		t.Errorf("Actual = %s, want 25", verdict.Conditions[0].Actual)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	analysisTypes := []string{"code_metrics", "dead_code", "sast", "vulnerability_scan"}
+
+	tests := []struct {
+		name string
+		gate entities.QualityGate
+		err  string
+	}{
+		{name: "default", gate: DefaultQualityGate()},
+		{name: "empty", gate: entities.QualityGate{}},
+		{name: "known extra analysis", gate: entities.QualityGate{Analyses: []string{"dead_code"}}},
+		{name: "unknown extra analysis", gate: entities.QualityGate{Analyses: []string{"dead_code", "fuzzing"}}, err: "unknown analysis type: fuzzing"},
+		{name: "negative high issues", gate: entities.QualityGate{MaxHighIssues: intPtr(-1)}, err: "max_high_issues"},
+		{name: "zero high issues", gate: entities.QualityGate{MaxHighIssues: intPtr(0)}},
+		{name: "zero complexity", gate: entities.QualityGate{MaxComplexity: intPtr(0)}, err: "max_complexity"},
+		{name: "negative timeout", gate: entities.QualityGate{TimeoutSeconds: -1}, err: "timeout_seconds"},
+		{name: "maximum timeout", gate: entities.QualityGate{TimeoutSeconds: maxTimeoutSeconds}},
+		{name: "timeout too long", gate: entities.QualityGate{TimeoutSeconds: maxTimeoutSeconds + 1}, err: "timeout_seconds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.gate, analysisTypes)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("Validate() returned error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestRequiredAnalyses(t *testing.T) {
+	tests := []struct {
+		name string
+		gate entities.QualityGate
+		want []string
+	}{
+		{name: "default", gate: DefaultQualityGate(), want: []string{"sast", "vulnerability_scan", "code_metrics"}},
+		{name: "secrets only", gate: entities.QualityGate{NoSecrets: true}},
+		{name: "vulnerabilities only", gate: entities.QualityGate{NoNewVulnerableDependencies: true}, want: []string{"vulnerability_scan"}},
+		{
+			name: "extra analyses are appended once",
+			gate: entities.QualityGate{MaxComplexity: intPtr(5), Analyses: []string{"dead_code", "code_metrics", "dead_code"}},
+			want: []string{"code_metrics", "dead_code"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RequiredAnalyses(tt.gate); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("RequiredAnalyses() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
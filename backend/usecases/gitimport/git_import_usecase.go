@@ -0,0 +1,270 @@
+package gitimport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/usecases/archive"
+)
+
+// RepositoryDir チェックアウトしたツリーと並べて置く bare ミラーの名前
+const RepositoryDir = "repository.git"
+
+// DefaultMaxCommits ref の履歴から取り込むコミット数
+const DefaultMaxCommits = 5000
+
+// ファイル変更の種類（git の raw 形式のステータス）
+var changeStatuses = map[byte]string{
+	'A': "added",
+	'M': "modified",
+	'D': "deleted",
+	'R': "renamed",
+	'C': "copied",
+	'T': "type_changed",
+}
+
+// GitSource 取り込み元のローカルリポジトリまたはバンドルファイル
+type GitSource struct {
+	RepositoryPath string
+	BundlePath     string
+	Ref            string // 省略時は HEAD
+}
+
+// GitImportUseCase git コマンドで git リポジトリまたはバンドルを ref の時点で取り込む
+// bare ミラーを保持し、コミットのツリーをチェックアウトし、各コミットで変更された
+// ファイルとともにコミット履歴を読み込む
+type GitImportUseCase struct {
+	limits     archive.Limits
+	maxCommits int
+}
+
+// NewGitImportUseCase git 取り込みのユースケースを作成
+func NewGitImportUseCase() *GitImportUseCase {
+	return &GitImportUseCase{
+		limits:     archive.DefaultLimits(),
+		maxCommits: DefaultMaxCommits,
+	}
+}
+
+// Execute 取り込み元を destDir にミラーし、ref を解決して、そのツリーをコミット名の
+// ディレクトリにチェックアウトする
+func (uc *GitImportUseCase) Execute(ctx context.Context, source GitSource, destDir string) (*entities.GitImport, error) {
+	from := source.RepositoryPath
+	if source.BundlePath != "" {
+		from = source.BundlePath
+	}
+	if from == "" {
+		return nil, errors.New("repository path or bundle is required")
+	}
+	if !filepath.IsAbs(from) {
+		return nil, errors.New("repository path must be absolute")
+	}
+
+	// ローカルのパス以外（ネットワーク越しの取得など）は許可しない
+	repoDir := filepath.Join(destDir, RepositoryDir)
+	_, err := runGit(ctx, "", "-c", "protocol.allow=never", "-c", "protocol.file.allow=always",
+		"clone", "--mirror", "--no-hardlinks", "--quiet", "--", from, repoDir)
+	if err != nil {
+		return nil, err
+	}
+
+	ref := source.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	commit, err := uc.ResolveCommit(ctx, repoDir, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := uc.Checkout(ctx, repoDir, commit, filepath.Join(destDir, TreeDir(commit)))
+	if err != nil {
+		return nil, err
+	}
+
+	commits, truncated, err := uc.History(ctx, repoDir, commit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.GitImport{
+		Ref:              ref,
+		Commit:           commit,
+		Tree:             tree,
+		Commits:          commits,
+		CommitsTruncated: truncated,
+	}, nil
+}
+
+// TreeDir コミットのツリーをチェックアウトするディレクトリ名を返す
+func TreeDir(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+// ResolveCommit ブランチ、タグ、短縮ハッシュを完全なコミットハッシュに解決
+func (uc *GitImportUseCase) ResolveCommit(ctx context.Context, repoDir, rev string) (string, error) {
+	// オプションとして解釈される名前や範囲指定は受け付けない
+	if rev == "" || strings.HasPrefix(rev, "-") || strings.Contains(rev, "..") || strings.ContainsAny(rev, " \t\n:") {
+		return "", fmt.Errorf("invalid ref: %q", rev)
+	}
+	out, err := runGit(ctx, repoDir, "rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return "", fmt.Errorf("ref not found: %s", rev)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Checkout コミットのツリーを destDir に書き出す
+// ツリーは git archive でエクスポートし、アーカイブのアップロードと同じ上限で展開するため、
+// シンボリックリンクや大きすぎるファイルは同じように飛ばす
+func (uc *GitImportUseCase) Checkout(ctx context.Context, repoDir, commit, destDir string) (*entities.ArchiveExtraction, error) {
+	tmp, err := os.CreateTemp("", "git-tree-*.tar")
+	if err != nil {
+		return nil, err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if _, err := runGit(ctx, repoDir, "archive", "--format=tar", "--output="+tmp.Name(), commit); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return nil, err
+	}
+	return archive.NewArchiveExtractUseCase(uc.limits).Execute(ctx, tmp.Name(), destDir)
+}
+
+// History commit から到達できるコミットを新しい順に、各コミットで変更されたファイルとともに返す
+// 古いコミットを打ち切ったかどうかも返す
+func (uc *GitImportUseCase) History(ctx context.Context, repoDir, commit string) ([]entities.GitCommit, bool, error) {
+	// 区切り文字: \x1e がコミット、\x1f がフィールド
+	format := "--format=%x1e%H%x1f%P%x1f%an%x1f%ae%x1f%aI%x1f%cn%x1f%ce%x1f%cI%x1f%B%x1f"
+	out, err := runGit(ctx, repoDir, "log", format, "--raw", "--numstat", "-z", "-M",
+		"--max-count="+strconv.Itoa(uc.maxCommits+1), commit, "--")
+	if err != nil {
+		return nil, false, err
+	}
+
+	commits := []entities.GitCommit{}
+	for _, record := range strings.Split(string(out), "\x1e") {
+		if record == "" {
+			continue
+		}
+		commit, err := parseCommit(record)
+		if err != nil {
+			return nil, false, err
+		}
+		commits = append(commits, commit)
+	}
+	if len(commits) > uc.maxCommits {
+		return commits[:uc.maxCommits], true, nil
+	}
+	return commits, false, nil
+}
+
+// parseCommit git log の出力の 1 コミットを解析
+func parseCommit(record string) (entities.GitCommit, error) {
+	fields := strings.SplitN(record, "\x1f", 10)
+	if len(fields) != 10 {
+		return entities.GitCommit{}, errors.New("unexpected git log output")
+	}
+	commit := entities.GitCommit{
+		Hash:           fields[0],
+		Parents:        strings.Fields(fields[1]),
+		AuthorName:     fields[2],
+		AuthorEmail:    fields[3],
+		CommitterName:  fields[5],
+		CommitterEmail: fields[6],
+		Message:        strings.TrimRight(fields[8], "\n"),
+		Files:          []entities.GitFileChange{},
+	}
+	commit.AuthoredAt, _ = time.Parse(time.RFC3339, fields[4])
+	commit.CommittedAt, _ = time.Parse(time.RFC3339, fields[7])
+	if commit.Parents == nil {
+		commit.Parents = []string{}
+	}
+
+	// --raw の行で変更の種類を、--numstat の行で行数を得る（-z ではパスは NUL 区切り）
+	tokens := strings.Split(fields[9], "\x00")
+	byPath := make(map[string]int)
+	for i := 0; i < len(tokens); i++ {
+		token := strings.TrimLeft(tokens[i], "\n")
+		switch {
+		case token == "":
+		case strings.HasPrefix(token, ":"):
+			meta := strings.Fields(token)
+			if len(meta) == 0 || i+1 >= len(tokens) {
+				continue
+			}
+			code := meta[len(meta)-1]
+			change := entities.GitFileChange{Path: tokens[i+1], Status: changeStatuses[code[0]]}
+			if change.Status == "" {
+				change.Status = "modified"
+			}
+			i++
+			if (code[0] == 'R' || code[0] == 'C') && i+1 < len(tokens) {
+				change.OldPath = change.Path
+				change.Path = tokens[i+1]
+				i++
+			}
+			byPath[change.Path] = len(commit.Files)
+			commit.Files = append(commit.Files, change)
+		default:
+			parts := strings.SplitN(token, "\t", 3)
+			if len(parts) != 3 {
+				continue
+			}
+			path := parts[2]
+			if path == "" && i+2 < len(tokens) {
+				// リネームは「追加\t削除\t」の後に元のパスと新しいパスが続く
+				path = tokens[i+2]
+				i += 2
+			}
+			index, ok := byPath[path]
+			if !ok {
+				continue
+			}
+			file := &commit.Files[index]
+			if parts[0] == "-" {
+				file.Binary = true
+				continue
+			}
+			file.Additions, _ = strconv.Atoi(parts[0])
+			file.Deletions, _ = strconv.Atoi(parts[1])
+		}
+	}
+	return commit, nil
+}
+
+// runGit 認証情報の入力を求めず、ユーザーとシステムの設定を読まずに git を実行し、標準出力を返す
+func runGit(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_CONFIG_NOSYSTEM=1",
+		"GIT_CONFIG_GLOBAL="+os.DevNull,
+	)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], message)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return stdout.Bytes(), nil
+}
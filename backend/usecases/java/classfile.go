@@ -0,0 +1,471 @@
+package java
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// ClassMagic すべての class ファイルの先頭 4 バイト
+const ClassMagic = 0xCAFEBABE
+
+// 定数プールのタグ
+const (
+	constantUtf8               = 1
+	constantInteger            = 3
+	constantFloat              = 4
+	constantLong               = 5
+	constantDouble             = 6
+	constantClass              = 7
+	constantString             = 8
+	constantFieldref           = 9
+	constantMethodref          = 10
+	constantInterfaceMethodref = 11
+	constantNameAndType        = 12
+	constantMethodHandle       = 15
+	constantMethodType         = 16
+	constantDynamic            = 17
+	constantInvokeDynamic      = 18
+	constantModule             = 19
+	constantPackage            = 20
+)
+
+var errTruncated = errors.New("truncated class file")
+
+type accessFlag struct {
+	mask uint16
+	name string
+}
+
+var (
+	classAccessFlags = []accessFlag{
+		{0x0001, "public"}, {0x0010, "final"}, {0x0400, "abstract"}, {0x1000, "synthetic"},
+	}
+	fieldAccessFlags = []accessFlag{
+		{0x0001, "public"}, {0x0002, "private"}, {0x0004, "protected"}, {0x0008, "static"},
+		{0x0010, "final"}, {0x0040, "volatile"}, {0x0080, "transient"}, {0x1000, "synthetic"},
+	}
+	methodAccessFlags = []accessFlag{
+		{0x0001, "public"}, {0x0002, "private"}, {0x0004, "protected"}, {0x0008, "static"},
+		{0x0010, "final"}, {0x0020, "synchronized"}, {0x0040, "bridge"}, {0x0080, "varargs"},
+		{0x0100, "native"}, {0x0400, "abstract"}, {0x0800, "strictfp"}, {0x1000, "synthetic"},
+	}
+)
+
+// 宣言の表示に含めるアクセス修飾子（bridge・synthetic などはコンパイラ生成の印）
+var declarationModifiers = map[string]bool{
+	"public": true, "private": true, "protected": true, "static": true, "final": true,
+	"abstract": true, "native": true, "synchronized": true, "volatile": true, "transient": true,
+}
+
+type constant struct {
+	tag   byte
+	utf8  string
+	index [2]uint16
+}
+
+// classReader ビッグエンディアンの値を読み、最初のエラーを記録する
+type classReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *classReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.data) {
+		r.err = errTruncated
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *classReader) u1() byte {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *classReader) u2() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *classReader) u4() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+// ParseClass class ファイルを解析する。source は読み込み元を表す
+func ParseClass(data []byte, source string) (*entities.JavaClass, error) {
+	r := &classReader{data: data}
+	if r.u4() != ClassMagic {
+		return nil, fmt.Errorf("not a class file")
+	}
+	minor := r.u2()
+	major := r.u2()
+
+	count := int(r.u2())
+	pool := make([]constant, count)
+	for i := 1; i < count; i++ {
+		tag := r.u1()
+		c := constant{tag: tag}
+		switch tag {
+		case constantUtf8:
+			c.utf8 = decodeModifiedUTF8(r.bytes(int(r.u2())))
+		case constantInteger, constantFloat:
+			r.u4()
+		case constantLong, constantDouble:
+			r.u4()
+			r.u4()
+			// 8 バイトの定数は 2 つの番号を占める
+			pool[i] = c
+			i++
+			continue
+		case constantClass, constantString, constantMethodType, constantModule, constantPackage:
+			c.index[0] = r.u2()
+		case constantFieldref, constantMethodref, constantInterfaceMethodref, constantNameAndType, constantDynamic, constantInvokeDynamic:
+			c.index[0] = r.u2()
+			c.index[1] = r.u2()
+		case constantMethodHandle:
+			r.u1()
+			c.index[0] = r.u2()
+		default:
+			if r.err == nil {
+				return nil, fmt.Errorf("unknown constant pool tag %d at index %d", tag, i)
+			}
+		}
+		if r.err != nil {
+			return nil, r.err
+		}
+		pool[i] = c
+	}
+
+	utf8At := func(index uint16) string {
+		if int(index) < len(pool) && pool[index].tag == constantUtf8 {
+			return pool[index].utf8
+		}
+		return ""
+	}
+	classAt := func(index uint16) string {
+		if int(index) < len(pool) && pool[index].tag == constantClass {
+			return utf8At(pool[index].index[0])
+		}
+		return ""
+	}
+
+	access := r.u2()
+	thisClass := classAt(r.u2())
+	superClass := classAt(r.u2())
+	if r.err != nil {
+		return nil, r.err
+	}
+	if thisClass == "" {
+		return nil, fmt.Errorf("invalid this_class index")
+	}
+
+	class := &entities.JavaClass{
+		Name:       javaName(thisClass),
+		Source:     source,
+		Kind:       classKind(access),
+		Access:     flagNames(access, classAccessFlags),
+		Version:    classVersion(major, minor),
+		SuperClass: javaName(superClass),
+		Interfaces: []string{},
+		Fields:     []entities.JavaMember{},
+		Methods:    []entities.JavaMember{},
+	}
+	class.Package = packageOf(class.Name)
+
+	references := make(map[string]bool)
+	addDescriptor := func(descriptor string) {
+		for _, name := range descriptorClasses(descriptor) {
+			references[name] = true
+		}
+	}
+
+	for n := int(r.u2()); n > 0 && r.err == nil; n-- {
+		class.Interfaces = append(class.Interfaces, javaName(classAt(r.u2())))
+	}
+
+	readMembers := func(flags []accessFlag, method bool) []entities.JavaMember {
+		members := []entities.JavaMember{}
+		for n := int(r.u2()); n > 0 && r.err == nil; n-- {
+			memberAccess := r.u2()
+			name := utf8At(r.u2())
+			descriptor := utf8At(r.u2())
+			skipAttributes(r)
+
+			member := entities.JavaMember{
+				Name:       name,
+				Descriptor: descriptor,
+				Access:     flagNames(memberAccess, flags),
+			}
+			if method {
+				member.Declaration = methodDeclaration(class.Name, name, descriptor, member.Access)
+			} else {
+				member.Declaration = fieldDeclaration(name, descriptor, member.Access)
+			}
+			addDescriptor(descriptor)
+			members = append(members, member)
+		}
+		return members
+	}
+	class.Fields = readMembers(fieldAccessFlags, false)
+	class.Methods = readMembers(methodAccessFlags, true)
+
+	for n := int(r.u2()); n > 0 && r.err == nil; n-- {
+		name := utf8At(r.u2())
+		body := r.bytes(int(r.u4()))
+		if name == "SourceFile" && len(body) == 2 {
+			class.SourceFile = utf8At(binary.BigEndian.Uint16(body))
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	// 定数プールのクラス参照と、メソッド・フィールド参照の記述子を集める
+	for _, c := range pool {
+		switch c.tag {
+		case constantClass:
+			name := utf8At(c.index[0])
+			if strings.HasPrefix(name, "[") {
+				addDescriptor(name)
+			} else if name != "" {
+				references[javaName(name)] = true
+			}
+		case constantNameAndType, constantMethodType:
+			index := c.index[0]
+			if c.tag == constantNameAndType {
+				index = c.index[1]
+			}
+			addDescriptor(utf8At(index))
+		}
+	}
+	delete(references, class.Name)
+	class.References = sortedKeys(references)
+	return class, nil
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func skipAttributes(r *classReader) {
+	for n := int(r.u2()); n > 0 && r.err == nil; n-- {
+		r.u2()
+		r.bytes(int(r.u4()))
+	}
+}
+
+// decodeModifiedUTF8 JVM の修正 UTF-8 をデコード
+// NUL は 2 バイト、補助文字はサロゲートペアでエンコードされている
+func decodeModifiedUTF8(b []byte) string {
+	units := make([]uint16, 0, len(b))
+	for i := 0; i < len(b); {
+		c := b[i]
+		switch {
+		case c < 0x80:
+			units = append(units, uint16(c))
+			i++
+		case c&0xe0 == 0xc0 && i+1 < len(b):
+			units = append(units, uint16(c&0x1f)<<6|uint16(b[i+1]&0x3f))
+			i += 2
+		case c&0xf0 == 0xe0 && i+2 < len(b):
+			units = append(units, uint16(c&0x0f)<<12|uint16(b[i+1]&0x3f)<<6|uint16(b[i+2]&0x3f))
+			i += 3
+		default:
+			units = append(units, 0xfffd)
+			i++
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+func flagNames(access uint16, flags []accessFlag) []string {
+	names := []string{}
+	for _, flag := range flags {
+		if access&flag.mask != 0 {
+			names = append(names, flag.name)
+		}
+	}
+	return names
+}
+
+func classKind(access uint16) string {
+	switch {
+	case access&0x8000 != 0:
+		return "module"
+	case access&0x2000 != 0:
+		return "annotation"
+	case access&0x0200 != 0:
+		return "interface"
+	case access&0x4000 != 0:
+		return "enum"
+	}
+	return "class"
+}
+
+// classVersion class ファイルのバージョンを対応する Java のリリース付きで返す
+func classVersion(major, minor uint16) string {
+	release := ""
+	switch {
+	case major >= 49:
+		release = fmt.Sprintf("Java %d", major-44)
+	case major >= 45:
+		release = fmt.Sprintf("Java 1.%d", major-44)
+	}
+	if minor == 0xffff {
+		release += " preview"
+	}
+	if release == "" {
+		return fmt.Sprintf("%d.%d", major, minor)
+	}
+	return fmt.Sprintf("%d.%d (%s)", major, minor, release)
+}
+
+// javaName 内部名（java/lang/String）をバイナリ名（java.lang.String）に変換
+func javaName(internal string) string {
+	return strings.ReplaceAll(internal, "/", ".")
+}
+
+func packageOf(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// parseFieldType ディスクリプターからフィールド型を 1 つ読み、Java での表記、
+// 参照するクラス（あれば）、残りのディスクリプターを返す
+func parseFieldType(descriptor string) (string, string, string) {
+	dims := 0
+	for strings.HasPrefix(descriptor, "[") {
+		dims++
+		descriptor = descriptor[1:]
+	}
+	if descriptor == "" {
+		return "", "", ""
+	}
+
+	var name, class string
+	rest := descriptor[1:]
+	switch descriptor[0] {
+	case 'B':
+		name = "byte"
+	case 'C':
+		name = "char"
+	case 'D':
+		name = "double"
+	case 'F':
+		name = "float"
+	case 'I':
+		name = "int"
+	case 'J':
+		name = "long"
+	case 'S':
+		name = "short"
+	case 'Z':
+		name = "boolean"
+	case 'V':
+		name = "void"
+	case 'L':
+		end := strings.IndexByte(descriptor, ';')
+		if end < 0 {
+			return "", "", ""
+		}
+		class = javaName(descriptor[1:end])
+		name = class
+		rest = descriptor[end+1:]
+	default:
+		return "", "", ""
+	}
+	return name + strings.Repeat("[]", dims), class, rest
+}
+
+// descriptorClasses フィールドまたはメソッドのディスクリプターに現れるクラスを返す
+func descriptorClasses(descriptor string) []string {
+	var classes []string
+	descriptor = strings.NewReplacer("(", "", ")", "").Replace(descriptor)
+	for descriptor != "" {
+		_, class, rest := parseFieldType(descriptor)
+		if rest == descriptor {
+			break
+		}
+		if class != "" {
+			classes = append(classes, class)
+		}
+		descriptor = rest
+	}
+	return classes
+}
+
+func declarationPrefix(access []string) string {
+	var modifiers []string
+	for _, name := range access {
+		if declarationModifiers[name] {
+			modifiers = append(modifiers, name)
+		}
+	}
+	if len(modifiers) == 0 {
+		return ""
+	}
+	return strings.Join(modifiers, " ") + " "
+}
+
+func fieldDeclaration(name, descriptor string, access []string) string {
+	typ, _, _ := parseFieldType(descriptor)
+	return declarationPrefix(access) + typ + " " + name
+}
+
+// methodDeclaration メソッドをソースでの宣言の形にする
+// コンストラクターと静的初期化子はクラス名を使う
+func methodDeclaration(className, name, descriptor string, access []string) string {
+	if !strings.HasPrefix(descriptor, "(") {
+		return declarationPrefix(access) + name
+	}
+	end := strings.IndexByte(descriptor, ')')
+	if end < 0 {
+		return declarationPrefix(access) + name
+	}
+
+	var params []string
+	for rest := descriptor[1:end]; rest != ""; {
+		typ, _, next := parseFieldType(rest)
+		if next == rest || typ == "" {
+			break
+		}
+		params = append(params, typ)
+		rest = next
+	}
+	returnType, _, _ := parseFieldType(descriptor[end+1:])
+
+	simpleName := className[strings.LastIndex(className, ".")+1:]
+	switch name {
+	case "<init>":
+		return fmt.Sprintf("%s%s(%s)", declarationPrefix(access), simpleName, strings.Join(params, ", "))
+	case "<clinit>":
+		return "static {}"
+	}
+	return fmt.Sprintf("%s%s %s(%s)", declarationPrefix(access), returnType, name, strings.Join(params, ", "))
+}
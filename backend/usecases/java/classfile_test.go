@@ -0,0 +1,219 @@
+package java
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// classBuilder テスト用の class ファイルをビッグエンディアンで組み立てる
+type classBuilder struct {
+	bytes.Buffer
+}
+
+func (b *classBuilder) u1(v byte)   { b.WriteByte(v) }
+func (b *classBuilder) u2(v uint16) { binary.Write(b, binary.BigEndian, v) }
+func (b *classBuilder) u4(v uint32) { binary.Write(b, binary.BigEndian, v) }
+func (b *classBuilder) utf8(s string) {
+	b.u1(constantUtf8)
+	b.u2(uint16(len(s)))
+	b.WriteString(s)
+}
+
+// testClass 次のクラスを Java 17 の class ファイルにする
+//
+//	public class com.example.Hello extends java.lang.Object implements java.lang.Runnable {
+//	    private int count;
+//	    public static void main(java.lang.String[] args);
+//	}
+func testClass() []byte {
+	var b classBuilder
+	b.u4(ClassMagic)
+	b.u2(0)
+	b.u2(61)
+
+	b.u2(15)
+	b.utf8("com/example/Hello") // #1
+	b.u1(constantClass)         // #2
+	b.u2(1)
+	b.utf8("java/lang/Object") // #3
+	b.u1(constantClass)        // #4
+	b.u2(3)
+	b.utf8("java/lang/Runnable") // #5
+	b.u1(constantClass)          // #6
+	b.u2(5)
+	b.utf8("count")                  // #7
+	b.utf8("I")                      // #8
+	b.utf8("main")                   // #9
+	b.utf8("([Ljava/lang/String;)V") // #10
+	b.utf8("SourceFile")             // #11
+	b.utf8("Hello.java")             // #12
+	b.u1(constantLong)               // #13, #14
+	b.u4(0)
+	b.u4(42)
+
+	b.u2(0x0021)
+	b.u2(2)
+	b.u2(4)
+	b.u2(1)
+	b.u2(6)
+
+	b.u2(1)
+	b.u2(0x0002)
+	b.u2(7)
+	b.u2(8)
+	b.u2(0)
+
+	b.u2(1)
+	b.u2(0x0009)
+	b.u2(9)
+	b.u2(10)
+	b.u2(1)
+	b.u2(12)
+	b.u4(3)
+	b.Write([]byte{0, 0, 0})
+
+	b.u2(1)
+	b.u2(11)
+	b.u4(2)
+	b.u2(12)
+	return b.Bytes()
+}
+
+func TestParseClass(t *testing.T) {
+	class, err := ParseClass(testClass(), "Hello.class")
+	if err != nil {
+		t.Fatalf("ParseClass returned error: %v", err)
+	}
+
+	if class.Name != "com.example.Hello" || class.Package != "com.example" || class.Kind != "class" {
+		t.Errorf("ParseClass() = %s in %s (%s), want class com.example.Hello", class.Name, class.Package, class.Kind)
+	}
+	if class.Version != "61.0 (Java 17)" {
+		t.Errorf("Version = %q, want %q", class.Version, "61.0 (Java 17)")
+	}
+	if class.SuperClass != "java.lang.Object" || !reflect.DeepEqual(class.Interfaces, []string{"java.lang.Runnable"}) {
+		t.Errorf("SuperClass = %q, Interfaces = %v, want java.lang.Object and [java.lang.Runnable]", class.SuperClass, class.Interfaces)
+	}
+	if class.SourceFile != "Hello.java" {
+		t.Errorf("SourceFile = %q, want %q", class.SourceFile, "Hello.java")
+	}
+	if len(class.Fields) != 1 || class.Fields[0].Declaration != "private int count" {
+		t.Errorf("Fields = %+v, want private int count", class.Fields)
+	}
+	if len(class.Methods) != 1 || class.Methods[0].Declaration != "public static void main(java.lang.String[])" {
+		t.Errorf("Methods = %+v, want public static void main(java.lang.String[])", class.Methods)
+	}
+	want := []string{"java.lang.Object", "java.lang.Runnable", "java.lang.String"}
+	if !reflect.DeepEqual(class.References, want) {
+		t.Errorf("References = %v, want %v", class.References, want)
+	}
+}
+
+func TestParseClassTruncated(t *testing.T) {
+	data := testClass()
+	// class ファイルは最後まで読むため、どこで切れてもエラーになる
+	for n := 0; n < len(data); n++ {
+		if class, err := ParseClass(data[:n], "Hello.class"); err == nil {
+			t.Errorf("ParseClass(%d of %d bytes) = %+v, want error", n, len(data), class)
+		}
+	}
+}
+
+func TestParseClassMalformed(t *testing.T) {
+	data := testClass()
+	corrupt := func(offset int, value ...byte) []byte {
+		corrupted := append([]byte(nil), data...)
+		copy(corrupted[offset:], value)
+		return corrupted
+	}
+	thisClass := bytes.LastIndex(data, []byte{0x00, 0x21}) + 2
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"bad magic", corrupt(0, 0xca, 0xfe, 0xba, 0xbf)},
+		{"constant pool count past end", corrupt(8, 0xff, 0xff)},
+		{"unknown constant tag", corrupt(10, 0x7f)},
+		{"utf8 length past end", corrupt(11, 0xff, 0xff)},
+		{"this_class out of range", corrupt(thisClass, 0xff, 0xff)},
+		{"this_class is not a class", corrupt(thisClass, 0, 1)},
+		{"attribute length past end", corrupt(len(data)-6, 0xff, 0xff, 0xff, 0xff)},
+		{"negative attribute length", corrupt(len(data)-6, 0x80, 0, 0, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if class, err := ParseClass(tt.data, "Hello.class"); err == nil {
+				t.Errorf("ParseClass() = %+v, want error", class)
+			}
+		})
+	}
+}
+
+func TestJavaInventoryMalformedArchive(t *testing.T) {
+	data := testClass()
+
+	var jar bytes.Buffer
+	zw := zip.NewWriter(&jar)
+	for name, content := range map[string][]byte{
+		"com/example/Hello.class":  data,
+		"com/example/Broken.class": data[:len(data)/2],
+		"lib/broken.jar":           []byte("PK\x03\x04 not a zip"),
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("failed to create jar entry: %v", err)
+		}
+		w.Write(content)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to write jar: %v", err)
+	}
+
+	dir := t.TempDir()
+	write := func(name string, content []byte) InputFile {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return InputFile{Name: name, Path: path}
+	}
+	files := []InputFile{
+		write("app.jar", jar.Bytes()),
+		write("truncated.jar", jar.Bytes()[:jar.Len()/2]),
+		write("Truncated.class", data[:20]),
+	}
+
+	inventory, err := NewJavaInventoryUseCase().Execute(context.Background(), files)
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	// 壊れた入力はエラーとして記録し、読めたクラスは残す
+	if len(inventory.Classes) != 1 || inventory.Classes[0].Name != "com.example.Hello" {
+		t.Errorf("Classes = %+v, want com.example.Hello", inventory.Classes)
+	}
+	sources := make(map[string]bool)
+	for _, e := range inventory.Errors {
+		sources[e.Source] = true
+	}
+	for _, source := range []string{"app.jar!/com/example/Broken.class", "app.jar!/lib/broken.jar", "truncated.jar", "Truncated.class"} {
+		if !sources[source] {
+			t.Errorf("Errors = %+v, want an error for %s", inventory.Errors, source)
+		}
+	}
+}
+
+func FuzzParseClass(f *testing.F) {
+	f.Add(testClass())
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// 壊れた class ファイルでもエラーを返し、panic しない
+		ParseClass(data, "Fuzz.class")
+	})
+}
@@ -0,0 +1,318 @@
+package java
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// DexMagic すべての Dalvik 実行ファイルの先頭。この後にバージョンが続く
+var DexMagic = []byte("dex\n")
+
+// dexNoIndex スーパークラスやソースファイルがないことを表す
+const dexNoIndex = 0xffffffff
+
+// dexHeaderSize header_item のサイズ
+const dexHeaderSize = 0x70
+
+type dexFile struct {
+	data    []byte
+	strings []uint32
+	types   []uint32
+	protos  []byte
+	fields  []byte
+	methods []byte
+}
+
+func (d *dexFile) u32(offset uint32) (uint32, error) {
+	if uint64(offset)+4 > uint64(len(d.data)) {
+		return 0, errTruncated
+	}
+	return binary.LittleEndian.Uint32(d.data[offset:]), nil
+}
+
+// table offset から entrySize バイトのエントリーを size 個返す
+func (d *dexFile) table(offset, size uint32, entrySize int) ([]byte, error) {
+	end := uint64(offset) + uint64(size)*uint64(entrySize)
+	if end > uint64(len(d.data)) {
+		return nil, errTruncated
+	}
+	return d.data[offset:end], nil
+}
+
+func (d *dexFile) string(index uint32) string {
+	if int(index) >= len(d.strings) {
+		return ""
+	}
+	offset := int(d.strings[index])
+	if offset >= len(d.data) {
+		return ""
+	}
+	// 先頭の ULEB128 は UTF-16 の長さ。本体は NUL 終端の修正 UTF-8
+	_, n := readULEB128(d.data[offset:])
+	start := offset + n
+	end := bytes.IndexByte(d.data[start:], 0)
+	if end < 0 {
+		return ""
+	}
+	return decodeModifiedUTF8(d.data[start : start+end])
+}
+
+func (d *dexFile) typeDescriptor(index uint32) string {
+	if int(index) >= len(d.types) {
+		return ""
+	}
+	return d.string(d.types[index])
+}
+
+// protoDescriptor proto_id_item を JVM のメソッドディスクリプターにする
+func (d *dexFile) protoDescriptor(index uint32) string {
+	if int(index)*12+12 > len(d.protos) {
+		return ""
+	}
+	item := d.protos[index*12:]
+	returnType := d.typeDescriptor(binary.LittleEndian.Uint32(item[4:]))
+	paramsOffset := binary.LittleEndian.Uint32(item[8:])
+
+	var params strings.Builder
+	if paramsOffset != 0 {
+		if size, err := d.u32(paramsOffset); err == nil {
+			list, err := d.table(paramsOffset+4, size, 2)
+			if err == nil {
+				for i := 0; i < len(list); i += 2 {
+					params.WriteString(d.typeDescriptor(uint32(binary.LittleEndian.Uint16(list[i:]))))
+				}
+			}
+		}
+	}
+	return "(" + params.String() + ")" + returnType
+}
+
+// ParseDex Dalvik 実行ファイル（classes.dex）を class ファイルと同じクラスの表現に解析する
+// Version は DEX 形式のバージョン
+func ParseDex(data []byte, source string) ([]entities.JavaClass, *entities.DexFile, error) {
+	if len(data) < dexHeaderSize || !bytes.HasPrefix(data, DexMagic) {
+		return nil, nil, errors.New("not a dex file")
+	}
+	version := strings.TrimRight(string(data[4:8]), "\x00")
+	if endian := binary.LittleEndian.Uint32(data[0x28:]); endian != 0x12345678 {
+		return nil, nil, fmt.Errorf("unsupported endian tag 0x%08x", endian)
+	}
+
+	header := func(offset int) uint32 {
+		return binary.LittleEndian.Uint32(data[offset:])
+	}
+	d := &dexFile{data: data}
+
+	stringIDs, err := d.table(header(0x3c), header(0x38), 4)
+	if err != nil {
+		return nil, nil, fmt.Errorf("string_ids: %w", err)
+	}
+	d.strings = make([]uint32, header(0x38))
+	for i := range d.strings {
+		d.strings[i] = binary.LittleEndian.Uint32(stringIDs[i*4:])
+	}
+
+	typeIDs, err := d.table(header(0x44), header(0x40), 4)
+	if err != nil {
+		return nil, nil, fmt.Errorf("type_ids: %w", err)
+	}
+	d.types = make([]uint32, header(0x40))
+	for i := range d.types {
+		d.types[i] = binary.LittleEndian.Uint32(typeIDs[i*4:])
+	}
+
+	if d.protos, err = d.table(header(0x4c), header(0x48), 12); err != nil {
+		return nil, nil, fmt.Errorf("proto_ids: %w", err)
+	}
+	if d.fields, err = d.table(header(0x54), header(0x50), 8); err != nil {
+		return nil, nil, fmt.Errorf("field_ids: %w", err)
+	}
+	if d.methods, err = d.table(header(0x5c), header(0x58), 8); err != nil {
+		return nil, nil, fmt.Errorf("method_ids: %w", err)
+	}
+	classDefs, err := d.table(header(0x64), header(0x60), 32)
+	if err != nil {
+		return nil, nil, fmt.Errorf("class_defs: %w", err)
+	}
+
+	summary := &entities.DexFile{
+		Name:    source,
+		Version: version,
+		Strings: len(d.strings),
+		Classes: int(header(0x60)),
+	}
+
+	classes := make([]entities.JavaClass, 0, summary.Classes)
+	for i := 0; i < len(classDefs); i += 32 {
+		class, err := d.classDef(classDefs[i:i+32], version, source)
+		if err != nil {
+			return classes, summary, err
+		}
+		classes = append(classes, *class)
+		summary.Methods += len(class.Methods)
+	}
+	return classes, summary, nil
+}
+
+func (d *dexFile) classDef(def []byte, version, source string) (*entities.JavaClass, error) {
+	field := func(i int) uint32 {
+		return binary.LittleEndian.Uint32(def[i*4:])
+	}
+	access := field(1)
+	name := javaName(descriptorName(d.typeDescriptor(field(0))))
+	class := &entities.JavaClass{
+		Name:       name,
+		Package:    packageOf(name),
+		Source:     source,
+		Kind:       classKind(uint16(access)),
+		Access:     flagNames(uint16(access), classAccessFlags),
+		Version:    "dex " + version,
+		Interfaces: []string{},
+		Fields:     []entities.JavaMember{},
+		Methods:    []entities.JavaMember{},
+	}
+	if super := field(2); super != dexNoIndex {
+		class.SuperClass = javaName(descriptorName(d.typeDescriptor(super)))
+	}
+	if sourceFile := field(4); sourceFile != dexNoIndex {
+		class.SourceFile = d.string(sourceFile)
+	}
+
+	references := make(map[string]bool)
+	addDescriptor := func(descriptor string) {
+		for _, name := range descriptorClasses(descriptor) {
+			references[name] = true
+		}
+	}
+	if class.SuperClass != "" {
+		references[class.SuperClass] = true
+	}
+
+	if offset := field(3); offset != 0 {
+		size, err := d.u32(offset)
+		if err != nil {
+			return nil, err
+		}
+		list, err := d.table(offset+4, size, 2)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < len(list); i += 2 {
+			iface := javaName(descriptorName(d.typeDescriptor(uint32(binary.LittleEndian.Uint16(list[i:])))))
+			class.Interfaces = append(class.Interfaces, iface)
+			references[iface] = true
+		}
+	}
+
+	if offset := field(6); offset != 0 {
+		if int(offset) >= len(d.data) {
+			return nil, errTruncated
+		}
+		r := &dexReader{data: d.data[offset:]}
+		staticFields, instanceFields := r.uleb(), r.uleb()
+		directMethods, virtualMethods := r.uleb(), r.uleb()
+
+		readFields := func(count uint32) {
+			var index uint32
+			for ; count > 0 && r.err == nil; count-- {
+				index += r.uleb()
+				fieldAccess := r.uleb()
+				if int(index)*8+8 > len(d.fields) {
+					r.err = errTruncated
+					return
+				}
+				item := d.fields[index*8:]
+				descriptor := d.typeDescriptor(uint32(binary.LittleEndian.Uint16(item[2:])))
+				fieldName := d.string(binary.LittleEndian.Uint32(item[4:]))
+				flags := flagNames(uint16(fieldAccess), fieldAccessFlags)
+				class.Fields = append(class.Fields, entities.JavaMember{
+					Name:        fieldName,
+					Descriptor:  descriptor,
+					Declaration: fieldDeclaration(fieldName, descriptor, flags),
+					Access:      flags,
+				})
+				addDescriptor(descriptor)
+			}
+		}
+		readMethods := func(count uint32) {
+			var index uint32
+			for ; count > 0 && r.err == nil; count-- {
+				index += r.uleb()
+				methodAccess := r.uleb()
+				r.uleb() // code_off（命令列は読まない）
+				if int(index)*8+8 > len(d.methods) {
+					r.err = errTruncated
+					return
+				}
+				item := d.methods[index*8:]
+				descriptor := d.protoDescriptor(uint32(binary.LittleEndian.Uint16(item[2:])))
+				methodName := d.string(binary.LittleEndian.Uint32(item[4:]))
+				flags := flagNames(uint16(methodAccess), methodAccessFlags)
+				class.Methods = append(class.Methods, entities.JavaMember{
+					Name:        methodName,
+					Descriptor:  descriptor,
+					Declaration: methodDeclaration(class.Name, methodName, descriptor, flags),
+					Access:      flags,
+				})
+				addDescriptor(descriptor)
+			}
+		}
+		readFields(staticFields)
+		readFields(instanceFields)
+		readMethods(directMethods)
+		readMethods(virtualMethods)
+		if r.err != nil {
+			return nil, fmt.Errorf("class %s: %w", class.Name, r.err)
+		}
+	}
+
+	delete(references, class.Name)
+	class.References = sortedKeys(references)
+	return class, nil
+}
+
+// descriptorName クラスディスクリプター（Lcom/example/Foo;）を内部名に変換
+func descriptorName(descriptor string) string {
+	if strings.HasPrefix(descriptor, "L") && strings.HasSuffix(descriptor, ";") {
+		return descriptor[1 : len(descriptor)-1]
+	}
+	return descriptor
+}
+
+// dexReader class_data_item の ULEB128 の値を読む
+type dexReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *dexReader) uleb() uint32 {
+	if r.err != nil {
+		return 0
+	}
+	value, n := readULEB128(r.data[r.pos:])
+	if n == 0 {
+		r.err = errTruncated
+		return 0
+	}
+	r.pos += n
+	return value
+}
+
+// readULEB128 最大 5 バイトの符号なし LEB128 の値をデコードし、読んだバイト数を返す
+// （入力が途中で切れている場合は 0）
+func readULEB128(data []byte) (uint32, int) {
+	var result uint32
+	for i := 0; i < 5 && i < len(data); i++ {
+		result |= uint32(data[i]&0x7f) << (7 * i)
+		if data[i]&0x80 == 0 {
+			return result, i + 1
+		}
+	}
+	return 0, 0
+}
@@ -0,0 +1,171 @@
+package java
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+// testDex 次のクラスを 1 つ持つ classes.dex を組み立てる
+//
+//	public class com.example.Main extends java.lang.Object {
+//	    private int count;
+//	    public static void main(java.lang.String[] args);
+//	}
+//
+// ID テーブル、class_defs、文字列データ、type_list、class_data の順に並べるため、
+// どこで切れてもクラスの読み込みがエラーになる
+func testDex() []byte {
+	strs := []string{"Lcom/example/Main;", "Ljava/lang/Object;", "V", "I", "main", "count", "Main.java", "[Ljava/lang/String;"}
+	types := []uint32{0, 1, 2, 3, 7}
+
+	le := binary.LittleEndian
+	header := make([]byte, dexHeaderSize)
+	copy(header, "dex\n035\x00")
+	le.PutUint32(header[0x28:], 0x12345678)
+
+	stringIDs := dexHeaderSize
+	typeIDs := stringIDs + len(strs)*4
+	protoIDs := typeIDs + len(types)*4
+	fieldIDs := protoIDs + 12
+	methodIDs := fieldIDs + 8
+	classDefs := methodIDs + 8
+	stringData := classDefs + 32
+
+	var strData bytes.Buffer
+	offsets := make([]uint32, len(strs))
+	for i, s := range strs {
+		offsets[i] = uint32(stringData + strData.Len())
+		strData.WriteByte(byte(len(s)))
+		strData.WriteString(s)
+		strData.WriteByte(0)
+	}
+	typeList := stringData + strData.Len()
+	classData := typeList + 8
+
+	for _, v := range [][2]int{
+		{0x38, len(strs)}, {0x3c, stringIDs},
+		{0x40, len(types)}, {0x44, typeIDs},
+		{0x48, 1}, {0x4c, protoIDs},
+		{0x50, 1}, {0x54, fieldIDs},
+		{0x58, 1}, {0x5c, methodIDs},
+		{0x60, 1}, {0x64, classDefs},
+	} {
+		le.PutUint32(header[v[0]:], uint32(v[1]))
+	}
+
+	var b bytes.Buffer
+	b.Write(header)
+	for _, offset := range offsets {
+		binary.Write(&b, le, offset)
+	}
+	binary.Write(&b, le, types)
+	binary.Write(&b, le, []uint32{2, 2, uint32(typeList)})                      // proto: shorty, return V, params
+	binary.Write(&b, le, []uint16{0, 3})                                        // field: Main, I
+	binary.Write(&b, le, uint32(5))                                             // field: count
+	binary.Write(&b, le, []uint16{0, 0})                                        // method: Main, proto 0
+	binary.Write(&b, le, uint32(4))                                             // method: main
+	binary.Write(&b, le, []uint32{0, 0x0001, 1, 0, 6, 0, uint32(classData), 0}) // class_def
+	b.Write(strData.Bytes())
+	binary.Write(&b, le, []uint32{1})
+	binary.Write(&b, le, []uint16{4, 0})
+	b.Write([]byte{0, 1, 1, 0, 0, 0x02, 0, 0x09, 0})
+	return b.Bytes()
+}
+
+func TestParseDex(t *testing.T) {
+	classes, dex, err := ParseDex(testDex(), "classes.dex")
+	if err != nil {
+		t.Fatalf("ParseDex returned error: %v", err)
+	}
+
+	if dex.Version != "035" || dex.Strings != 8 || dex.Classes != 1 || dex.Methods != 1 {
+		t.Errorf("ParseDex() summary = %+v, want version 035 with 8 strings, 1 class and 1 method", dex)
+	}
+	if len(classes) != 1 {
+		t.Fatalf("ParseDex() classes = %+v, want com.example.Main", classes)
+	}
+	class := classes[0]
+	if class.Name != "com.example.Main" || class.SuperClass != "java.lang.Object" || class.SourceFile != "Main.java" {
+		t.Errorf("class = %s extends %s (%s), want com.example.Main extends java.lang.Object (Main.java)", class.Name, class.SuperClass, class.SourceFile)
+	}
+	if len(class.Fields) != 1 || class.Fields[0].Declaration != "private int count" {
+		t.Errorf("Fields = %+v, want private int count", class.Fields)
+	}
+	if len(class.Methods) != 1 || class.Methods[0].Descriptor != "([Ljava/lang/String;)V" ||
+		class.Methods[0].Declaration != "public static void main(java.lang.String[])" {
+		t.Errorf("Methods = %+v, want public static void main(java.lang.String[])", class.Methods)
+	}
+}
+
+func TestParseDexTruncated(t *testing.T) {
+	data := testDex()
+	for n := 0; n < len(data); n++ {
+		if classes, _, err := ParseDex(data[:n], "classes.dex"); err == nil {
+			t.Errorf("ParseDex(%d of %d bytes) = %+v, want error", n, len(data), classes)
+		}
+	}
+}
+
+func TestParseDexMalformed(t *testing.T) {
+	data := testDex()
+	classDef := int(binary.LittleEndian.Uint32(data[0x64:]))
+	classData := int(binary.LittleEndian.Uint32(data[classDef+24:]))
+	corrupt := func(offset int, value ...byte) []byte {
+		corrupted := append([]byte(nil), data...)
+		copy(corrupted[offset:], value)
+		return corrupted
+	}
+	u32 := func(v uint32) []byte {
+		return binary.LittleEndian.AppendUint32(nil, v)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"bad magic", corrupt(0, 'D')},
+		{"big endian", corrupt(0x28, u32(0x78563412)...)},
+		{"string_ids past end", corrupt(0x38, u32(0x40000000)...)},
+		{"type_ids offset past end", corrupt(0x44, u32(0xfffffff0)...)},
+		{"class_defs past end", corrupt(0x60, u32(0xffffffff)...)},
+		{"interfaces past end", corrupt(classDef+12, u32(uint32(len(data)))...)},
+		{"class_data past end", corrupt(classDef+24, u32(uint32(len(data)))...)},
+		{"field index out of range", corrupt(classData+4, 0x05)},
+		{"method index out of range", corrupt(classData+6, 0x05)},
+		{"class_data counts past end", corrupt(classData, 0x7f, 0x7f)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if classes, _, err := ParseDex(tt.data, "classes.dex"); err == nil {
+				t.Errorf("ParseDex() = %+v, want error", classes)
+			}
+		})
+	}
+}
+
+func TestParseDexBadStringReferences(t *testing.T) {
+	// 文字列や型の参照が壊れていても、名前を空にして読み進める
+	data := testDex()
+	stringIDs := int(binary.LittleEndian.Uint32(data[0x3c:]))
+	binary.LittleEndian.PutUint32(data[stringIDs+6*4:], 0xffffffff)
+	classDef := int(binary.LittleEndian.Uint32(data[0x64:]))
+	binary.LittleEndian.PutUint32(data[classDef+8:], 0x1000)
+
+	classes, _, err := ParseDex(data, "classes.dex")
+	if err != nil {
+		t.Fatalf("ParseDex returned error: %v", err)
+	}
+	if len(classes) != 1 || classes[0].SourceFile != "" || classes[0].SuperClass != "" {
+		t.Errorf("ParseDex() = %+v, want a class without source file and super class", classes)
+	}
+}
+
+func FuzzParseDex(f *testing.F) {
+	f.Add(testDex())
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// 壊れた DEX でもエラーを返し、panic しない
+		ParseDex(data, "classes.dex")
+	})
+}
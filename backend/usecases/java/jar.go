@@ -0,0 +1,136 @@
+package java
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+const (
+	// maxClassSize を超えるクラスファイルは読み込まない
+	maxClassSize = 16 << 20
+	// maxNestedJarSize を超える同梱 JAR は展開しない（メモリ上で開くため）
+	maxNestedJarSize = 64 << 20
+)
+
+// archiveVisitor アーカイブで見つかったクラスを 1 つずつ受け取る
+type archiveVisitor struct {
+	onClass func(class *entities.JavaClass)
+	onError func(source, message string)
+}
+
+// readArchive JAR（または WAR/EAR）と、その直下にネストした JAR をたどる
+// depth はネストしたアーカイブを開く深さの上限
+func readArchive(reader *zip.Reader, name string, depth int, visitor archiveVisitor) []entities.JavaArchive {
+	archive := entities.JavaArchive{Name: name}
+	var nested []entities.JavaArchive
+
+	for _, entry := range reader.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		archive.Entries++
+		source := name + "!/" + entry.Name
+		lower := strings.ToLower(entry.Name)
+
+		switch {
+		case entry.Name == "META-INF/MANIFEST.MF":
+			data, err := readEntry(entry, maxClassSize)
+			if err != nil {
+				visitor.onError(source, err.Error())
+				continue
+			}
+			archive.Manifest = parseManifest(data)
+		case strings.HasSuffix(lower, ".class"):
+			// マルチリリース JAR のバージョン別クラスは重複するため除外する
+			if strings.HasPrefix(entry.Name, "META-INF/versions/") || path.Base(entry.Name) == "module-info.class" {
+				continue
+			}
+			data, err := readEntry(entry, maxClassSize)
+			if err != nil {
+				visitor.onError(source, err.Error())
+				continue
+			}
+			class, err := ParseClass(data, source)
+			if err != nil {
+				visitor.onError(source, err.Error())
+				continue
+			}
+			archive.Classes++
+			visitor.onClass(class)
+		case strings.HasSuffix(lower, ".jar") || strings.HasSuffix(lower, ".war"):
+			archive.Nested = append(archive.Nested, entry.Name)
+			if depth <= 0 {
+				continue
+			}
+			data, err := readEntry(entry, maxNestedJarSize)
+			if err != nil {
+				visitor.onError(source, err.Error())
+				continue
+			}
+			inner, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				visitor.onError(source, err.Error())
+				continue
+			}
+			nested = append(nested, readArchive(inner, source, depth-1, visitor)...)
+		}
+	}
+	return append([]entities.JavaArchive{archive}, nested...)
+}
+
+// readEntry zip のエントリーを読む。展開後のサイズが limit を超えるエントリーは拒否する
+func readEntry(entry *zip.File, limit int64) ([]byte, error) {
+	if entry.UncompressedSize64 > uint64(limit) {
+		return nil, fmt.Errorf("entry is larger than %d bytes", limit)
+	}
+	rc, err := entry.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	// ヘッダーのサイズは偽装できるため、実際に読む量も制限する
+	data, err := io.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("entry is larger than %d bytes", limit)
+	}
+	return data, nil
+}
+
+// parseManifest META-INF/MANIFEST.MF のメインセクションを読み、継続行（1 つの空白で
+// 始まる行）をつなげる
+func parseManifest(data []byte) map[string]string {
+	manifest := make(map[string]string)
+	var lastKey string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			// 空行以降はエントリーごとのセクション
+			break
+		}
+		if strings.HasPrefix(line, " ") {
+			if lastKey != "" {
+				manifest[lastKey] += line[1:]
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		lastKey = strings.TrimSpace(key)
+		manifest[lastKey] = strings.TrimSpace(value)
+	}
+	return manifest
+}
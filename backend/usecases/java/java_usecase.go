@@ -0,0 +1,170 @@
+package java
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+const (
+	// maxJavaClasses を超えるクラスは一覧に含めない（件数だけ数える）
+	maxJavaClasses = 20000
+	// maxJavaEdges は依存グラフの辺の上限
+	maxJavaEdges = 100000
+	// nestedJarDepth は同梱 JAR を開く深さ（Spring Boot の BOOT-INF/lib など）
+	nestedJarDepth = 1
+)
+
+// InputFile ディスクに保存された、アップロードされた JAR、WAR、EAR、class ファイル
+type InputFile struct {
+	Name string
+	Path string
+}
+
+// JavaInventoryUseCase Java のアーカイブと class ファイルから、パッケージとクラスの一覧と
+// クラスの依存グラフを作成する
+type JavaInventoryUseCase struct{}
+
+// NewJavaInventoryUseCase Java インベントリーのユースケースを作成
+func NewJavaInventoryUseCase() *JavaInventoryUseCase {
+	return &JavaInventoryUseCase{}
+}
+
+// IsJavaFile class ファイル、または Java アーカイブの拡張子を持つ zip アーカイブかを判定
+func IsJavaFile(name, path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	magic := make([]byte, 4)
+	if _, err := f.Read(magic); err != nil {
+		return false
+	}
+	if binary.BigEndian.Uint32(magic) == ClassMagic {
+		return true
+	}
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".jar", ".war", ".ear":
+		return bytes.Equal(magic, []byte("PK\x03\x04"))
+	}
+	return false
+}
+
+// Execute すべての入力を解析し、クラスを依存グラフでつなぐ
+func (uc *JavaInventoryUseCase) Execute(ctx context.Context, files []InputFile) (*entities.JavaInventory, error) {
+	inventory := &entities.JavaInventory{
+		Archives: []entities.JavaArchive{},
+		Packages: []entities.JavaPackage{},
+		Classes:  []entities.JavaClass{},
+		Graph:    entities.JavaClassGraph{Nodes: []entities.JavaClassNode{}, Edges: []entities.JavaClassEdge{}},
+		Errors:   []entities.JavaParseError{},
+	}
+
+	seen := make(map[string]bool)
+	visitor := archiveVisitor{
+		onClass: func(class *entities.JavaClass) {
+			inventory.ClassesTotal++
+			// 同じクラスが複数の JAR に含まれる場合は最初のものを採用する
+			if seen[class.Name] {
+				return
+			}
+			seen[class.Name] = true
+			if len(inventory.Classes) >= maxJavaClasses {
+				inventory.Capped = true
+				return
+			}
+			inventory.Classes = append(inventory.Classes, *class)
+		},
+		onError: func(source, message string) {
+			inventory.Errors = append(inventory.Errors, entities.JavaParseError{Source: source, Error: message})
+		},
+	}
+
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := readInput(file, visitor, inventory); err != nil {
+			visitor.onError(file.Name, err.Error())
+		}
+	}
+
+	inventory.Packages = Packages(inventory.Classes)
+	inventory.Graph, inventory.Capped = classGraph(inventory.Classes, inventory.Capped)
+	return inventory, nil
+}
+
+func readInput(file InputFile, visitor archiveVisitor, inventory *entities.JavaInventory) error {
+	data, err := os.ReadFile(file.Path)
+	if err != nil {
+		return err
+	}
+	if len(data) >= 4 && binary.BigEndian.Uint32(data) == ClassMagic {
+		class, err := ParseClass(data, file.Name)
+		if err != nil {
+			return err
+		}
+		visitor.onClass(class)
+		return nil
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	inventory.Archives = append(inventory.Archives, readArchive(reader, file.Name, nestedJarDepth, visitor)...)
+	return nil
+}
+
+// Packages パッケージごとのクラス数をパッケージ名順に数える
+func Packages(classes []entities.JavaClass) []entities.JavaPackage {
+	counts := make(map[string]int)
+	for _, class := range classes {
+		counts[class.Package]++
+	}
+	result := make([]entities.JavaPackage, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, entities.JavaPackage{Name: name, Classes: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// classGraph 各クラスを参照先のクラスとつなぐ
+// アップロードされていない参照先のクラスは外部ノードにする
+func classGraph(classes []entities.JavaClass, capped bool) (entities.JavaClassGraph, bool) {
+	graph := entities.JavaClassGraph{Nodes: []entities.JavaClassNode{}, Edges: []entities.JavaClassEdge{}}
+
+	internal := make(map[string]bool, len(classes))
+	for _, class := range classes {
+		internal[class.Name] = true
+		graph.Nodes = append(graph.Nodes, entities.JavaClassNode{ID: class.Name, Package: class.Package})
+	}
+
+	external := make(map[string]bool)
+	for _, class := range classes {
+		for _, ref := range class.References {
+			if len(graph.Edges) >= maxJavaEdges {
+				capped = true
+				break
+			}
+			graph.Edges = append(graph.Edges, entities.JavaClassEdge{From: class.Name, To: ref})
+			if !internal[ref] && !external[ref] {
+				external[ref] = true
+				graph.Nodes = append(graph.Nodes, entities.JavaClassNode{ID: ref, Package: packageOf(ref), External: true})
+			}
+		}
+	}
+	return graph, capped
+}
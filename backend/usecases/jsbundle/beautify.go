@@ -0,0 +1,301 @@
+package jsbundle
+
+import "strings"
+
+// spacedKeywords 括弧の前でも後ろに空白を入れるキーワード
+var spacedKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true, "with": true,
+	"return": true, "typeof": true, "void": true, "delete": true, "new": true, "throw": true,
+	"case": true, "in": true, "of": true, "instanceof": true, "await": true, "yield": true,
+	"else": true, "do": true, "try": true, "finally": true, "var": true, "let": true, "const": true,
+	"export": true, "import": true, "from": true, "extends": true, "async": true,
+}
+
+// operandKeywords 後ろの + と - が単項演算子になるキーワード
+var operandKeywords = map[string]bool{
+	"return": true, "typeof": true, "void": true, "delete": true, "throw": true, "case": true,
+	"in": true, "of": true, "instanceof": true, "await": true, "yield": true, "new": true,
+}
+
+// frame 整形中の開いている括弧
+type frame struct {
+	open    string
+	ternary int  // 閉じていない ? の数
+	label   bool // case / default のラベル待ち
+	inCase  bool // case の本体をインデントしている
+}
+
+type formatter struct {
+	b         strings.Builder
+	indent    int
+	lineStart bool
+	space     bool
+	prev      *token
+	stack     []frame
+}
+
+// formatTokens トークン列を 2 スペースのインデントで整形
+func formatTokens(tokens []token) string {
+	f := &formatter{lineStart: true}
+	for i := range tokens {
+		var next *token
+		if i+1 < len(tokens) {
+			next = &tokens[i+1]
+		}
+		f.token(&tokens[i], next)
+	}
+	out := strings.TrimRight(f.b.String(), " \n")
+	if out == "" {
+		return ""
+	}
+	return out + "\n"
+}
+
+func (f *formatter) top() *frame {
+	if len(f.stack) == 0 {
+		return nil
+	}
+	return &f.stack[len(f.stack)-1]
+}
+
+func (f *formatter) write(text string) {
+	if f.lineStart {
+		f.b.WriteString(strings.Repeat("  ", f.indent))
+		f.lineStart = false
+	} else if f.space {
+		f.b.WriteByte(' ')
+	}
+	f.space = false
+	f.b.WriteString(text)
+}
+
+// dedent インデントを 1 段戻す。括弧の対応が崩れたコードでも負にはしない
+func (f *formatter) dedent() {
+	if f.indent > 0 {
+		f.indent--
+	}
+}
+
+func (f *formatter) newline() {
+	if !f.lineStart {
+		f.b.WriteByte('\n')
+		f.lineStart = true
+	}
+	f.space = false
+}
+
+// prevIsOperand 直前のトークンが式の終わりかどうかを判定
+// 単項演算子と二項演算子の区別に使う
+func (f *formatter) prevIsOperand() bool {
+	if f.prev == nil {
+		return false
+	}
+	switch f.prev.kind {
+	case tokenWord:
+		return !operandKeywords[f.prev.text]
+	case tokenNumber, tokenString, tokenTemplate, tokenRegex:
+		return true
+	case tokenPunct:
+		return f.prev.text == ")" || f.prev.text == "]" || f.prev.text == "}" || f.prev.text == "++" || f.prev.text == "--"
+	}
+	return false
+}
+
+func (f *formatter) token(t, next *token) {
+	// 改行で区切られた文（セミコロンの自動挿入）は改行を保つ
+	if t.newline && f.prev != nil && (f.top() == nil || f.top().open == "{") && f.prevIsOperand() && t.kind != tokenPunct {
+		f.newline()
+	}
+
+	switch t.kind {
+	case tokenLineComment:
+		if !f.lineStart {
+			f.space = true
+		}
+		f.write(t.text)
+		f.newline()
+		return
+	case tokenBlockComment:
+		if t.newline || strings.HasPrefix(t.text, "/**") {
+			f.newline()
+		} else if !f.lineStart {
+			f.space = true
+		}
+		f.write(t.text)
+		if strings.HasPrefix(t.text, "/**") || strings.Contains(t.text, "\n") {
+			f.newline()
+		} else {
+			f.space = true
+		}
+		return
+	case tokenWord:
+		f.word(t, next)
+	case tokenPunct:
+		f.punct(t, next)
+	default:
+		if f.prevIsOperand() || f.prev != nil && f.prev.kind == tokenWord {
+			f.space = true
+		}
+		f.write(t.text)
+	}
+	f.prev = t
+}
+
+func (f *formatter) word(t, next *token) {
+	if f.prev != nil {
+		switch f.prev.kind {
+		case tokenWord, tokenNumber, tokenString, tokenTemplate, tokenRegex:
+			f.space = true
+		case tokenPunct:
+			if f.prev.text == ")" || f.prev.text == "]" || f.prev.text == "}" {
+				f.space = true
+			}
+		}
+	}
+	if f.prev != nil && f.prev.kind == tokenPunct && (f.prev.text == "." || f.prev.text == "?.") {
+		f.space = false
+	}
+	label := (t.text == "case" || t.text == "default" && next != nil && next.text == ":") && f.top() != nil
+	if label && f.top().inCase {
+		// 前の case の本体を閉じる
+		f.top().inCase = false
+		f.dedent()
+	}
+	f.write(t.text)
+
+	if label {
+		f.top().label = true
+	}
+	if spacedKeywords[t.text] && !(f.prev != nil && (f.prev.text == "." || f.prev.text == "?.")) {
+		f.space = true
+	}
+}
+
+func (f *formatter) punct(t, next *token) {
+	switch t.text {
+	case "{":
+		if f.prev != nil && f.prev.text != "(" && f.prev.text != "[" && f.prev.text != "!" && f.prev.text != "..." {
+			f.space = true
+		}
+		f.write("{")
+		if next != nil && next.text == "}" {
+			// 空のブロックは {} のまま。対応する } は自身で処理される
+			f.stack = append(f.stack, frame{open: "{"})
+			return
+		}
+		f.stack = append(f.stack, frame{open: "{"})
+		f.indent++
+		f.newline()
+	case "}":
+		empty := f.prev != nil && f.prev.text == "{"
+		if top := f.top(); top != nil {
+			if top.inCase {
+				f.dedent()
+			}
+			f.stack = f.stack[:len(f.stack)-1]
+		}
+		if !empty {
+			f.dedent()
+			f.newline()
+		}
+		f.write("}")
+		if next == nil {
+			return
+		}
+		switch next.text {
+		case ")", "]", ",", ";", ".", "?.", "(", ":", "`":
+			return
+		case "else", "catch", "finally", "while":
+			f.space = true
+			return
+		}
+		f.newline()
+	case "(", "[":
+		if f.prev != nil {
+			switch {
+			case f.prev.kind == tokenWord && spacedKeywords[f.prev.text]:
+				f.space = true
+			case f.prev.kind == tokenPunct && !f.prevIsOperand() && f.prev.text != "(" && f.prev.text != "[" &&
+				f.prev.text != "." && f.prev.text != "?." && f.prev.text != "!" && f.prev.text != "~" && f.prev.text != "...":
+				f.space = true
+			}
+		}
+		f.write(t.text)
+		f.stack = append(f.stack, frame{open: t.text})
+	case ")", "]":
+		if len(f.stack) > 0 {
+			f.stack = f.stack[:len(f.stack)-1]
+		}
+		f.write(t.text)
+	case ";":
+		f.write(";")
+		if top := f.top(); top != nil && top.open == "(" {
+			f.space = true
+			return
+		}
+		f.newline()
+	case ",":
+		f.write(",")
+		if top := f.top(); top != nil && top.open == "{" {
+			f.newline()
+			return
+		}
+		f.space = true
+	case ".", "?.":
+		f.write(t.text)
+	case "?":
+		if top := f.top(); top != nil {
+			top.ternary++
+		}
+		f.space = true
+		f.write("?")
+		f.space = true
+	case ":":
+		top := f.top()
+		switch {
+		case top != nil && top.ternary > 0:
+			top.ternary--
+			f.space = true
+			f.write(":")
+			f.space = true
+		case top != nil && top.label:
+			top.label = false
+			top.inCase = true
+			f.write(":")
+			f.indent++
+			f.newline()
+		default:
+			f.write(":")
+			f.space = true
+		}
+	case "++", "--":
+		f.write(t.text)
+	case "!", "~":
+		if f.prevIsOperand() || f.prev != nil && f.prev.kind == tokenWord {
+			f.space = true
+		}
+		f.write(t.text)
+	case "+", "-":
+		if f.prevIsOperand() {
+			f.space = true
+			f.write(t.text)
+			f.space = true
+			return
+		}
+		// 単項演算子は直後のトークンに付ける
+		if f.prev != nil && f.prev.kind == tokenWord {
+			f.space = true
+		}
+		f.write(t.text)
+	case "...", "#", "@":
+		if f.prev != nil && (f.prev.text == "," || f.prev.kind == tokenWord) {
+			f.space = true
+		}
+		f.write(t.text)
+	default:
+		// 二項演算子・代入・アロー
+		f.space = true
+		f.write(t.text)
+		f.space = true
+	}
+}
@@ -0,0 +1,87 @@
+package jsbundle
+
+import "testing"
+
+func TestFormatTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{
+			name: "function",
+			code: "function a(b){if(b){return-1}else{return b+1}}",
+			want: "function a(b) {\n  if (b) {\n    return -1\n  } else {\n    return b + 1\n  }\n}\n",
+		},
+		{
+			name: "switch",
+			code: "switch(a){case 1:b();break;default:c()}",
+			want: "switch (a) {\n  case 1:\n    b();\n    break;\n  default:\n    c()\n}\n",
+		},
+		{
+			name: "object literal",
+			code: "var e={x:1,y:[1,2]};",
+			want: "var e = {\n  x: 1,\n  y: [1, 2]\n};\n",
+		},
+		{
+			name: "template and regex",
+			code: "const s=`a${`b${c}`}`;const r=/[/]+/g.test(s)",
+			want: "const s = `a${`b${c}`}`;\nconst r = /[/]+/g.test(s)\n",
+		},
+		{
+			name: "automatic semicolon insertion",
+			code: "a=b\nc=d",
+			want: "a = b\nc = d\n",
+		},
+		{
+			name: "for loop",
+			code: "for(var i=0;i<n;i++){}",
+			want: "for (var i = 0; i < n; i++) {}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatTokens(tokenize(tt.code)); got != tt.want {
+				t.Errorf("formatTokens() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatTokensMalformed(t *testing.T) {
+	// 括弧やリテラルが閉じていないコードでも panic せずに整形する
+	tests := []string{
+		"(case 1:[}case 2:x",
+		"}}}{{{case:default:",
+		"switch(a){case 1:)]}case 2:",
+		"var s='unterminated",
+		"var t=`${`${",
+		"var r=/[unterminated",
+		"/* unterminated comment",
+		"a\\",
+		"\xff\xfe\x00",
+	}
+
+	for _, code := range tests {
+		formatTokens(tokenize(code))
+		s := newSplitter(tokenize(code))
+		s.webpackModules()
+		s.statements(0, len(s.tokens))
+	}
+}
+
+func FuzzFormatTokens(f *testing.F) {
+	f.Add("switch(a){case 1:b();break;default:c()}")
+	f.Add("(case 1:[}case 2:x")
+	f.Add("!function(e){var t={};}({1:function(e,t){t.a=1},\"./src/b.js\":function(e){}});")
+	f.Fuzz(func(t *testing.T, code string) {
+		tokens := tokenize(code)
+		formatTokens(tokens)
+		s := newSplitter(tokens)
+		for _, m := range s.webpackModules() {
+			formatTokens(tokens[m.start:m.end])
+		}
+		splitSections(s, markerSection)
+	})
+}
@@ -0,0 +1,328 @@
+package jsbundle
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// minModules は webpack のモジュールテーブルとみなす最小のエントリー数
+const minModules = 2
+
+// sectionMarkerPattern esbuild が入力ファイルごとのコードの前に付ける "// src/foo.ts" コメントにマッチする
+var sectionMarkerPattern = regexp.MustCompile(`^//\s*([\w@.\-]+/)*[\w@.\-]+\.(js|jsx|mjs|cjs|ts|tsx|vue|svelte)$`)
+
+// module webpack のモジュールテーブル内の関数
+type module struct {
+	key        string // モジュール ID（数値またはパス）
+	start, end int    // 関数式のトークン範囲 [start, end)
+}
+
+// bracketPairs 開き括弧のインデックスと対応する閉じ括弧の対応
+// 対応の取れていない括弧は含めない
+func bracketPairs(tokens []token) map[int]int {
+	pairs := make(map[int]int)
+	var stack []int
+	for i, t := range tokens {
+		if t.kind != tokenPunct {
+			continue
+		}
+		switch t.text {
+		case "(", "[", "{":
+			stack = append(stack, i)
+		case ")", "]", "}":
+			open := map[string]string{")": "(", "]": "[", "}": "{"}[t.text]
+			// 対応しない括弧は読み飛ばす
+			for len(stack) > 0 {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				if tokens[top].text == open {
+					pairs[top] = i
+					break
+				}
+			}
+		}
+	}
+	return pairs
+}
+
+// splitter トークン列から構造を探す
+type splitter struct {
+	tokens []token
+	pairs  map[int]int
+}
+
+func newSplitter(tokens []token) *splitter {
+	return &splitter{tokens: tokens, pairs: bracketPairs(tokens)}
+}
+
+// next i 以降でコメントでない最初のトークンのインデックスを返す（なければ len(tokens)）
+func (s *splitter) next(i int) int {
+	for i < len(s.tokens) && (s.tokens[i].kind == tokenLineComment || s.tokens[i].kind == tokenBlockComment) {
+		i++
+	}
+	return i
+}
+
+func (s *splitter) is(i int, text string) bool {
+	return i < len(s.tokens) && s.tokens[i].kind == tokenPunct && s.tokens[i].text == text
+}
+
+func (s *splitter) isWord(i int, text string) bool {
+	return i < len(s.tokens) && s.tokens[i].kind == tokenWord && s.tokens[i].text == text
+}
+
+// closing i の括弧を閉じる括弧のインデックスを返す（なければ -1）
+func (s *splitter) closing(i int) int {
+	if end, ok := s.pairs[i]; ok {
+		return end
+	}
+	return -1
+}
+
+// functionEnd i から始まる関数式の直後のインデックスを返す（関数式でなければ -1）
+// 関数式、ブロック本体のアロー関数、およびそれらを括弧で囲んだものを受け付ける
+func (s *splitter) functionEnd(i int) int {
+	i = s.next(i)
+	switch {
+	case s.isWord(i, "function"):
+		j := s.next(i + 1)
+		if s.is(j, "*") {
+			j = s.next(j + 1)
+		}
+		if j < len(s.tokens) && s.tokens[j].kind == tokenWord {
+			j = s.next(j + 1)
+		}
+		return s.blockAfterParams(j)
+	case s.isWord(i, "async"):
+		return s.functionEnd(i + 1)
+	case i < len(s.tokens) && s.tokens[i].kind == tokenWord:
+		// e=>{...}
+		j := s.next(i + 1)
+		if !s.is(j, "=>") {
+			return -1
+		}
+		return s.block(s.next(j + 1))
+	case s.is(i, "("):
+		end := s.closing(i)
+		if end < 0 {
+			return -1
+		}
+		if j := s.next(end + 1); s.is(j, "=>") {
+			return s.block(s.next(j + 1))
+		}
+		// (function(){...}) や ((e)=>{...}) のように括弧で囲まれた関数
+		if inner := s.functionEnd(i + 1); inner >= 0 && s.next(inner) == end {
+			return end + 1
+		}
+	}
+	return -1
+}
+
+// blockAfterParams i に引数リストとそれに続くブロックがあることを確認
+func (s *splitter) blockAfterParams(i int) int {
+	if !s.is(i, "(") {
+		return -1
+	}
+	end := s.closing(i)
+	if end < 0 {
+		return -1
+	}
+	return s.block(s.next(end + 1))
+}
+
+func (s *splitter) block(i int) int {
+	if !s.is(i, "{") {
+		return -1
+	}
+	end := s.closing(i)
+	if end < 0 {
+		return -1
+	}
+	return end + 1
+}
+
+// moduleTable i のオブジェクトまたは配列リテラルを webpack のモジュールテーブルとして読み、
+// モジュールを返す（テーブルでなければ nil）
+func (s *splitter) moduleTable(i int) []module {
+	end := s.closing(i)
+	if end < 0 {
+		return nil
+	}
+	var modules []module
+	j := s.next(i + 1)
+	index := 0
+	for j < end {
+		if s.tokens[i].text == "[" {
+			// 配列形式（webpack 4 以前）は添字がモジュール ID。空要素も許す
+			if s.is(j, ",") {
+				index++
+				j = s.next(j + 1)
+				continue
+			}
+			fnEnd := s.functionEnd(j)
+			if fnEnd < 0 {
+				return nil
+			}
+			modules = append(modules, module{key: fmt.Sprint(index), start: j, end: fnEnd})
+			j = s.next(fnEnd)
+		} else {
+			key := s.tokens[j]
+			// 通常のオブジェクトと区別するため、文字列のキーはモジュールのパスに限る
+			if key.kind != tokenNumber && !(key.kind == tokenString && strings.Contains(key.text, "/")) {
+				return nil
+			}
+			colon := s.next(j + 1)
+			if !s.is(colon, ":") {
+				return nil
+			}
+			start := s.next(colon + 1)
+			fnEnd := s.functionEnd(start)
+			if fnEnd < 0 {
+				return nil
+			}
+			modules = append(modules, module{key: unquote(key.text), start: start, end: fnEnd})
+			j = s.next(fnEnd)
+		}
+		if j >= end {
+			break
+		}
+		if !s.is(j, ",") {
+			return nil
+		}
+		index++
+		j = s.next(j + 1)
+	}
+	if len(modules) < minModules {
+		return nil
+	}
+	return modules
+}
+
+// webpackModules webpack のバンドルまたはチャンクのモジュールテーブルを探す
+// モジュール内にネストしたテーブルは無視する
+func (s *splitter) webpackModules() []module {
+	var modules []module
+	for i := 0; i < len(s.tokens); i++ {
+		if !s.is(i, "{") && !s.is(i, "[") {
+			continue
+		}
+		table := s.moduleTable(i)
+		if table == nil {
+			continue
+		}
+		modules = append(modules, table...)
+		i = s.closing(i)
+	}
+	return modules
+}
+
+// statements [start, end) のトークンをトップレベルの文に分割する
+// コードが 1 つのラッパー（IIFE や UMD）の場合は、その本体を分割する
+func (s *splitter) statements(start, end int) [][2]int {
+	for depth := 0; depth < 3; depth++ {
+		list := s.splitStatements(start, end)
+		if len(list) != 1 {
+			return list
+		}
+		body := s.largestBlock(list[0][0], list[0][1])
+		if body < 0 || s.closing(body)-body < (end-start)*9/10 {
+			return list
+		}
+		start, end = body+1, s.closing(body)
+	}
+	return s.splitStatements(start, end)
+}
+
+// largestBlock 範囲の直下にある最大の { } ブロックのインデックスを返す（なければ -1）
+func (s *splitter) largestBlock(start, end int) int {
+	best, size := -1, 0
+	for i := start; i < end; i++ {
+		closing := s.closing(i)
+		if closing < 0 {
+			continue
+		}
+		if s.is(i, "{") && closing-i > size {
+			best, size = i, closing-i
+		}
+		// (function(){...})() のように括弧の中の関数は見るが、関数本体の中は見ない
+		if s.is(i, "(") {
+			continue
+		}
+		i = closing
+	}
+	return best
+}
+
+func (s *splitter) splitStatements(start, end int) [][2]int {
+	var list [][2]int
+	begin := start
+	for i := start; i < end; i++ {
+		t := s.tokens[i]
+		if t.kind != tokenPunct {
+			continue
+		}
+		switch t.text {
+		case "(", "[", "{":
+			closing := s.closing(i)
+			if closing < 0 || closing >= end {
+				continue
+			}
+			i = closing
+			if t.text != "{" {
+				continue
+			}
+			// function a(){} や if(){} の後に別の文が続く
+			next := s.next(i + 1)
+			if next >= end {
+				continue
+			}
+			n := s.tokens[next]
+			if n.newline || n.kind == tokenWord && !continuationWords[n.text] {
+				list = append(list, [2]int{begin, i + 1})
+				begin = i + 1
+			}
+		case ";":
+			list = append(list, [2]int{begin, i + 1})
+			begin = i + 1
+		}
+	}
+	if s.next(begin) < end {
+		list = append(list, [2]int{begin, end})
+	}
+	return list
+}
+
+// continuationWords 閉じ波括弧の後で文を続けるキーワード
+var continuationWords = map[string]bool{
+	"else": true, "catch": true, "finally": true, "while": true, "in": true, "instanceof": true, "of": true,
+}
+
+// moduleName webpack のモジュール ID をファイル名にする
+// 数値の ID は module_<id>.js とする
+func moduleName(key string) string {
+	if key == "" || strings.Trim(key, "0123456789") == "" {
+		return "module_" + key + ".js"
+	}
+	name := cleanSourcePath(key)
+	if name == "" {
+		return ""
+	}
+	// "./src/a.js + 3 modules" のような連結モジュールの注記を除く
+	if i := strings.Index(name, " + "); i >= 0 {
+		name = name[:i]
+	}
+	if path.Ext(name) == "" {
+		name += ".js"
+	}
+	return name
+}
+
+// unquote 文字列トークンの引用符を取り除く（エスケープは解釈しない）
+func unquote(text string) string {
+	if len(text) >= 2 && (text[0] == '"' || text[0] == '\'') && text[len(text)-1] == text[0] {
+		return text[1 : len(text)-1]
+	}
+	return text
+}
@@ -0,0 +1,318 @@
+package jsbundle
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+const (
+	// maxBundleSize を超えるバンドルは処理しない
+	maxBundleSize = 16 << 20
+	// maxRecoveredFiles を超えるファイルは復元しない
+	maxRecoveredFiles = 2000
+	// minifiedLineLength 平均の行の長さがこれを超えるコードは圧縮済みとみなす
+	minifiedLineLength = 120
+	// preludeName は最初のソースより前にあるコード（バンドラーのランタイムなど）のファイル名
+	preludeName = "prelude.js"
+)
+
+// reservedWords ソースマップの名前で置き換えない予約語
+var reservedWords = map[string]bool{
+	"break": true, "case": true, "catch": true, "class": true, "const": true, "continue": true,
+	"debugger": true, "default": true, "delete": true, "do": true, "else": true, "export": true,
+	"extends": true, "finally": true, "for": true, "function": true, "if": true, "import": true,
+	"in": true, "instanceof": true, "new": true, "return": true, "super": true, "switch": true,
+	"this": true, "throw": true, "try": true, "typeof": true, "var": true, "void": true,
+	"while": true, "with": true, "yield": true, "let": true, "static": true, "await": true,
+	"async": true, "of": true, "null": true, "true": true, "false": true, "undefined": true,
+}
+
+// BundleInput アップロードされた JavaScript ファイルと、それに対応するソースマップ
+type BundleInput struct {
+	Name          string
+	Code          string
+	SourceMap     []byte // 見つからなければ nil
+	SourceMapName string
+}
+
+// JSBundleUseCase 圧縮された JavaScript を整形し、バンドルを元のソースファイルに分割する
+// ソースマップがあればそれを使う
+type JSBundleUseCase struct{}
+
+// NewJSBundleUseCase JavaScript バンドルのユースケースを作成
+func NewJSBundleUseCase() *JSBundleUseCase {
+	return &JSBundleUseCase{}
+}
+
+// IsJavaScriptFile ファイル名が JavaScript の拡張子を持つかを判定
+func IsJavaScriptFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".js", ".mjs", ".cjs":
+		return true
+	}
+	return false
+}
+
+// SourceMapURL コードの最後の sourceMappingURL コメントの URL を返す（なければ ""）
+func SourceMapURL(code string) string {
+	index := max(strings.LastIndex(code, "//# sourceMappingURL="), strings.LastIndex(code, "//@ sourceMappingURL="))
+	if index < 0 {
+		return ""
+	}
+	value := code[index+len("//# sourceMappingURL="):]
+	if end := strings.IndexAny(value, " \t\r\n"); end >= 0 {
+		value = value[:end]
+	}
+	return value
+}
+
+// Execute バンドルのソースファイルを復元する
+// ソースマップの内容、webpack のモジュールテーブル、ソースに対応付けられるかファイル
+// コメントで区切られたセクションの順に優先する。どれもなければ圧縮されたコード全体を整形する
+func (uc *JSBundleUseCase) Execute(ctx context.Context, input BundleInput) (*entities.JSBundle, error) {
+	if len(input.Code) > maxBundleSize {
+		return nil, fmt.Errorf("bundle is larger than %d bytes", maxBundleSize)
+	}
+	result := &entities.JSBundle{Files: []entities.RecoveredFile{}}
+	names := make(map[string]int)
+	add := func(name, origin, content string) {
+		if len(result.Files) >= maxRecoveredFiles {
+			result.Capped = true
+			return
+		}
+		// 同じ名前のファイルは name_2.js のように区別する
+		names[name]++
+		if n := names[name]; n > 1 {
+			ext := path.Ext(name)
+			name = fmt.Sprintf("%s_%d%s", strings.TrimSuffix(name, ext), n, ext)
+		}
+		result.Files = append(result.Files, entities.RecoveredFile{Name: name, Origin: origin, Size: len(content), Content: content})
+	}
+
+	sm := uc.loadSourceMap(input, result)
+
+	// 1. ソースマップに元のソースが含まれていればそれをそのまま使う
+	if sm != nil && len(sm.SourcesContent) > 0 {
+		for i, content := range sm.SourcesContent {
+			if content == nil {
+				continue
+			}
+			name := sm.sourcePath(i)
+			if name == "" {
+				result.Skipped++
+				continue
+			}
+			add(name, "source_map", *content)
+		}
+		if len(result.Files) > 0 {
+			result.Bundler = "source_map"
+			return result, nil
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	tokens := tokenize(input.Code)
+	if sm != nil {
+		result.IdentifiersRecovered = renameIdentifiers(tokens, sm)
+	}
+	s := newSplitter(tokens)
+
+	// 2. webpack のモジュールテーブル
+	if modules := s.webpackModules(); len(modules) > 0 {
+		result.Bundler = "webpack"
+		for _, m := range modules {
+			name := moduleName(m.key)
+			// 数値 ID のモジュールはソースマップから元のファイル名を探す
+			if sm != nil && strings.HasPrefix(name, "module_") {
+				first := tokens[s.next(m.start)]
+				if index := sm.sourceAt(first.line, first.column); index >= 0 {
+					name = sm.sourcePath(index)
+				}
+			}
+			if name == "" {
+				result.Skipped++
+				continue
+			}
+			add(name, "webpack_module", formatTokens(tokens[m.start:m.end]))
+		}
+		return result, nil
+	}
+
+	// 3. ソースマップまたはファイル名のコメントで区切られた文のまとまり
+	bundler := "esbuild"
+	sectionOf := markerSection
+	if sm != nil {
+		bundler = "source_map"
+		sectionOf = func(s *splitter, start, end int) (string, string, bool) {
+			first := s.tokens[s.next(start)]
+			index := sm.sourceAt(first.line, first.column)
+			if index < 0 {
+				return "", "", false
+			}
+			return sm.sourcePath(index), sm.Sources[index], true
+		}
+	}
+	if sections, skipped := splitSections(s, sectionOf); len(sections) >= 2 || sm != nil && len(sections) == 1 && sections[0].name != preludeName {
+		result.Bundler = bundler
+		result.Skipped = skipped
+		for _, section := range sections {
+			var content strings.Builder
+			for _, r := range section.ranges {
+				content.WriteString(formatTokens(tokens[r[0]:r[1]]))
+			}
+			add(section.name, "bundle_section", content.String())
+		}
+		return result, nil
+	}
+
+	// 4. 圧縮されたコード全体を整形する
+	if isMinified(input.Code) {
+		result.Bundler = "minified"
+		add(path.Base(filepath.ToSlash(input.Name)), "beautified", formatTokens(tokens))
+		return result, nil
+	}
+	result.Bundler = "none"
+	return result, nil
+}
+
+// loadSourceMap アップロードされたソースマップまたはインラインのソースマップを解析
+// エラーは結果に記録し、ソースマップなしでバンドルを処理する
+func (uc *JSBundleUseCase) loadSourceMap(input BundleInput, result *entities.JSBundle) *sourceMap {
+	data, name := input.SourceMap, input.SourceMapName
+	if data == nil {
+		mapURL := SourceMapURL(input.Code)
+		if !strings.HasPrefix(mapURL, "data:") {
+			return nil
+		}
+		// data:application/json;charset=utf-8;base64,... のインラインソースマップ
+		comma := strings.IndexByte(mapURL, ',')
+		if comma < 0 {
+			result.SourceMapError = "invalid inline source map"
+			return nil
+		}
+		var err error
+		if strings.HasSuffix(mapURL[:comma], ";base64") {
+			data, err = base64.StdEncoding.DecodeString(mapURL[comma+1:])
+		} else {
+			var text string
+			text, err = url.PathUnescape(mapURL[comma+1:])
+			data = []byte(text)
+		}
+		if err != nil {
+			result.SourceMapError = "invalid inline source map: " + err.Error()
+			return nil
+		}
+		name = "inline"
+	}
+	sm, err := parseSourceMap(data)
+	if err != nil {
+		result.SourceMapError = err.Error()
+		return nil
+	}
+	result.SourceMap = name
+	return sm
+}
+
+// renameIdentifiers 圧縮された識別子をソースマップに記録された元の名前に置き換え、
+// 置き換えた数を返す
+func renameIdentifiers(tokens []token, sm *sourceMap) int {
+	renamed := 0
+	previous := ""
+	for i := range tokens {
+		t := &tokens[i]
+		if t.kind == tokenLineComment || t.kind == tokenBlockComment {
+			continue
+		}
+		// プロパティ名（a.b の b）は圧縮されないため対象外
+		if t.kind == tokenWord && previous != "." && previous != "?." && !reservedWords[t.text] {
+			if name := sm.nameAt(t.line, t.column); name != "" && name != t.text && isIdentifier(name) && !reservedWords[name] {
+				t.text = name
+				renamed++
+			}
+		}
+		previous = t.text
+	}
+	return renamed
+}
+
+func isIdentifier(name string) bool {
+	for i, r := range name {
+		if r == '_' || r == '$' || unicode.IsLetter(r) || i > 0 && unicode.IsDigit(r) {
+			continue
+		}
+		return false
+	}
+	return name != ""
+}
+
+// section 1 ファイルとして復元する文のまとまり
+type section struct {
+	name   string
+	ranges [][2]int
+}
+
+// sectionFunc 文が属するファイルと、その導出元のソースを返す
+// 文が現在のファイルの続きの場合 ok は false。名前が空の場合はその文を捨てる
+type sectionFunc func(s *splitter, start, end int) (name, source string, ok bool)
+
+// markerSection "// src/foo.js" コメントごとに新しいファイルを始める
+func markerSection(s *splitter, start, end int) (string, string, bool) {
+	for i := start; i < end && i < len(s.tokens); i++ {
+		t := s.tokens[i]
+		if t.kind != tokenLineComment && t.kind != tokenBlockComment {
+			break
+		}
+		if sectionMarkerPattern.MatchString(strings.TrimSpace(t.text)) {
+			source := strings.TrimSpace(strings.TrimPrefix(t.text, "//"))
+			return cleanSourcePath(source), source, true
+		}
+	}
+	return "", "", false
+}
+
+// splitSections トップレベルの文をファイルごとにまとめる。ファイルは最初に現れた順に並べ、
+// 捨てたファイルの数も返す
+func splitSections(s *splitter, sectionOf sectionFunc) ([]section, int) {
+	var sections []section
+	index := make(map[string]int)
+	skipped := make(map[string]bool)
+	current := preludeName
+	for _, r := range s.statements(0, len(s.tokens)) {
+		if name, source, ok := sectionOf(s, r[0], r[1]); ok {
+			if name == "" {
+				// node_modules などのコードは捨てる
+				skipped[source] = true
+				current = ""
+				continue
+			}
+			current = name
+		}
+		if current == "" {
+			continue
+		}
+		i, exists := index[current]
+		if !exists {
+			i = len(sections)
+			index[current] = i
+			sections = append(sections, section{name: current})
+		}
+		sections[i].ranges = append(sections[i].ranges, r)
+	}
+	return sections, len(skipped)
+}
+
+// isMinified コードの行が平均して長いかを判定
+func isMinified(code string) bool {
+	lines := strings.Count(strings.TrimRight(code, "\n"), "\n") + 1
+	return len(code)/lines > minifiedLineLength
+}
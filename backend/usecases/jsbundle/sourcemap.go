@@ -0,0 +1,210 @@
+package jsbundle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// base64 の各文字が表す 6 ビットの値（VLQ の復号に使う）
+const base64Chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+
+// sourceMap 解析したリビジョン 3 のソースマップ
+type sourceMap struct {
+	Version        int       `json:"version"`
+	File           string    `json:"file"`
+	SourceRoot     string    `json:"sourceRoot"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent"`
+	Names          []string  `json:"names"`
+	Mappings       string    `json:"mappings"`
+	Sections       []any     `json:"sections"`
+
+	// lines は生成コードの行ごとのマッピング（列の昇順）
+	lines [][]segment
+}
+
+// segment 生成後の列と、元のソースの位置と名前の対応
+// segment に含まれない場合 source と name は -1
+type segment struct {
+	column int
+	source int
+	name   int
+}
+
+// parseSourceMap ソースマップとその VLQ のマッピングをデコード
+func parseSourceMap(data []byte) (*sourceMap, error) {
+	// XSSI 対策の接頭辞 )]} が付いている場合は 1 行目を捨てる
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, ")]}") {
+		if i := strings.IndexByte(trimmed, '\n'); i >= 0 {
+			data = []byte(trimmed[i+1:])
+		}
+	}
+	var sm sourceMap
+	if err := json.Unmarshal(data, &sm); err != nil {
+		return nil, fmt.Errorf("invalid source map: %w", err)
+	}
+	if len(sm.Sections) > 0 {
+		return nil, errors.New("indexed source maps are not supported")
+	}
+	if sm.Version != 3 {
+		return nil, fmt.Errorf("unsupported source map version %d", sm.Version)
+	}
+	if err := sm.decodeMappings(); err != nil {
+		return nil, err
+	}
+	return &sm, nil
+}
+
+func (sm *sourceMap) decodeMappings() error {
+	// source・name のインデックスと元の行・列はマップ全体で相対値、列は行ごとに相対値
+	source, originalLine, originalColumn, name := 0, 0, 0, 0
+	for _, line := range strings.Split(sm.Mappings, ";") {
+		var segments []segment
+		column := 0
+		for _, field := range strings.Split(line, ",") {
+			if field == "" {
+				continue
+			}
+			values, err := decodeVLQ(field)
+			if err != nil {
+				return err
+			}
+			column += values[0]
+			seg := segment{column: column, source: -1, name: -1}
+			if len(values) >= 4 {
+				source += values[1]
+				originalLine += values[2]
+				originalColumn += values[3]
+				seg.source = source
+			}
+			if len(values) >= 5 {
+				name += values[4]
+				seg.name = name
+			}
+			segments = append(segments, seg)
+		}
+		sm.lines = append(sm.lines, segments)
+	}
+	return nil
+}
+
+// decodeVLQ 1 つのマッピングセグメントの base64 VLQ の値をデコード
+func decodeVLQ(field string) ([]int, error) {
+	var values []int
+	value, shift := 0, 0
+	for i := 0; i < len(field); i++ {
+		digit := strings.IndexByte(base64Chars, field[i])
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid mapping character %q", field[i])
+		}
+		value |= (digit & 0x1f) << shift
+		if digit&0x20 != 0 {
+			shift += 5
+			if shift > 30 {
+				return nil, errors.New("mapping value overflows")
+			}
+			continue
+		}
+		// 最下位ビットが符号
+		if value&1 != 0 {
+			values = append(values, -(value >> 1))
+		} else {
+			values = append(values, value>>1)
+		}
+		value, shift = 0, 0
+	}
+	if shift != 0 || len(values) == 0 {
+		return nil, errors.New("truncated mapping segment")
+	}
+	return values, nil
+}
+
+// nameAt 生成後の位置からちょうど始まる識別子の元の名前を返す
+func (sm *sourceMap) nameAt(line, column int) string {
+	if line >= len(sm.lines) {
+		return ""
+	}
+	for _, seg := range sm.lines[line] {
+		if seg.column == column {
+			if seg.name >= 0 && seg.name < len(sm.Names) {
+				return sm.Names[seg.name]
+			}
+			return ""
+		}
+		if seg.column > column {
+			break
+		}
+	}
+	return ""
+}
+
+// sourceAt 生成後の位置を含む元のソースのインデックスを返す（なければ -1）
+func (sm *sourceMap) sourceAt(line, column int) int {
+	if line >= len(sm.lines) {
+		return -1
+	}
+	result := -1
+	for _, seg := range sm.lines[line] {
+		if seg.column > column {
+			break
+		}
+		result = seg.source
+	}
+	if result >= len(sm.Sources) {
+		return -1
+	}
+	return result
+}
+
+// sourcePath ソースの整理した相対パスを返す
+// 復元しないバンドラーのランタイムやサードパーティのコードは ""
+func (sm *sourceMap) sourcePath(index int) string {
+	if index < 0 || index >= len(sm.Sources) {
+		return ""
+	}
+	source := sm.Sources[index]
+	if sm.SourceRoot != "" && !strings.Contains(source, "://") {
+		source = strings.TrimSuffix(sm.SourceRoot, "/") + "/" + source
+	}
+	return cleanSourcePath(source)
+}
+
+// cleanSourcePath webpack://app/./src/index.js のようなソース URL を相対パス（src/index.js）にする
+// バンドラーのランタイムと node_modules は "" を返す
+func cleanSourcePath(source string) string {
+	if i := strings.Index(source, "://"); i >= 0 {
+		source = source[i+3:]
+	}
+	if i := strings.IndexAny(source, "?#"); i >= 0 {
+		source = source[:i]
+	}
+	// webpack://<プロジェクト名>/./src/... の ./ より前はプロジェクト名
+	if i := strings.Index(source, "/./"); i >= 0 {
+		source = source[i+3:]
+	}
+	source = path.Clean("/" + strings.ReplaceAll(source, "\\", "/"))
+	source = strings.TrimPrefix(source, "/")
+	if source == "" || source == "." {
+		return ""
+	}
+	if isExcludedSource(source) {
+		return ""
+	}
+	return source
+}
+
+// isExcludedSource ソースのパスがサードパーティまたはバンドラーのランタイムのコードかを判定
+func isExcludedSource(source string) bool {
+	if strings.HasPrefix(source, "node_modules/") || strings.Contains(source, "/node_modules/") {
+		return true
+	}
+	for _, prefix := range []string{"webpack/", "(webpack)/", "external ", "ignored|"} {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	return false
+}
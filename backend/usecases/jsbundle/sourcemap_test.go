@@ -0,0 +1,166 @@
+package jsbundle
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeVLQ(t *testing.T) {
+	tests := []struct {
+		field   string
+		want    []int
+		wantErr bool
+	}{
+		{field: "AAAA", want: []int{0, 0, 0, 0}},
+		{field: "AAgBC", want: []int{0, 0, 16, 1}},
+		{field: "D", want: []int{-1}},
+		{field: "2H", want: []int{123}},
+		{field: "", wantErr: true},
+		{field: "g", wantErr: true},
+		{field: "A!A", wantErr: true},
+		{field: "gggggggA", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := decodeVLQ(tt.field)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("decodeVLQ(%q) error = %v, wantErr %v", tt.field, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("decodeVLQ(%q) = %v, want %v", tt.field, got, tt.want)
+		}
+	}
+}
+
+func TestParseSourceMap(t *testing.T) {
+	// 1 行目の列 0 は a.js の name 0、列 4 は b.js（名前なし）、2 行目の列 0 は範囲外の source と name
+	data := `)]}'
+{"version":3,"sourceRoot":"webpack://app/","sources":["./src/a.js","./src/b.js"],"names":["handler"],"mappings":"AAAAA,ICAA;AEAAE"}`
+	sm, err := parseSourceMap([]byte(data))
+	if err != nil {
+		t.Fatalf("parseSourceMap returned error: %v", err)
+	}
+
+	if got := sm.nameAt(0, 0); got != "handler" {
+		t.Errorf("nameAt(0, 0) = %q, want %q", got, "handler")
+	}
+	if got := sm.nameAt(0, 4); got != "" {
+		t.Errorf("nameAt(0, 4) = %q, want no name", got)
+	}
+	if got := sm.sourcePath(sm.sourceAt(0, 6)); got != "src/b.js" {
+		t.Errorf("sourcePath(sourceAt(0, 6)) = %q, want %q", got, "src/b.js")
+	}
+	if got := sm.sourceAt(1, 0); got != -1 {
+		t.Errorf("sourceAt(1, 0) = %d, want -1 for a source out of range", got)
+	}
+	if got := sm.nameAt(1, 0); got != "" {
+		t.Errorf("nameAt(1, 0) = %q, want no name for a name out of range", got)
+	}
+	if got := sm.sourceAt(5, 0); got != -1 {
+		t.Errorf("sourceAt(5, 0) = %d, want -1 past the last line", got)
+	}
+}
+
+func TestParseSourceMapMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"empty", ""},
+		{"truncated json", `{"version":3,"sources":["a.js"],"mappings":"AAAA`},
+		{"version 2", `{"version":2,"mappings":""}`},
+		{"indexed map", `{"version":3,"sections":[{"offset":{"line":0,"column":0},"map":{}}]}`},
+		{"invalid mapping character", `{"version":3,"mappings":"AA*A"}`},
+		{"truncated mapping segment", `{"version":3,"mappings":"AAAA,g"}`},
+		{"overflowing mapping value", `{"version":3,"mappings":"gggggggggA"}`},
+		{"sources of the wrong type", `{"version":3,"sources":"a.js","mappings":""}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if sm, err := parseSourceMap([]byte(tt.data)); err == nil {
+				t.Errorf("parseSourceMap() = %+v, want error", sm)
+			}
+		})
+	}
+}
+
+func TestCleanSourcePath(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"webpack://app/./src/index.js", "src/index.js"},
+		{"webpack:///src/App.vue?a1b2", "src/App.vue"},
+		{"../../src/../../etc/passwd", "etc/passwd"},
+		{`src\win\path.ts`, "src/win/path.ts"},
+		{"webpack://app/./node_modules/react/index.js", ""},
+		{"webpack/bootstrap", ""},
+		{"external \"fs\"", ""},
+		{"webpack://", ""},
+	}
+
+	for _, tt := range tests {
+		if got := cleanSourcePath(tt.source); got != tt.want {
+			t.Errorf("cleanSourcePath(%q) = %q, want %q", tt.source, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteMalformedSourceMap(t *testing.T) {
+	code := "!function(){" + strings.Repeat("var a=1;", 40) + "}();"
+
+	tests := []struct {
+		name  string
+		input BundleInput
+	}{
+		{
+			name:  "uploaded map",
+			input: BundleInput{Name: "app.min.js", Code: code, SourceMap: []byte(`{"version":3,"mappings":"g"}`), SourceMapName: "app.min.js.map"},
+		},
+		{
+			name:  "inline map with invalid base64",
+			input: BundleInput{Name: "app.min.js", Code: code + "\n//# sourceMappingURL=data:application/json;base64,@@@"},
+		},
+		{
+			name:  "inline map without data",
+			input: BundleInput{Name: "app.min.js", Code: code + "\n//# sourceMappingURL=data:application/json"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 壊れたソースマップはエラーを記録し、ソースマップなしで整形する
+			result, err := NewJSBundleUseCase().Execute(context.Background(), tt.input)
+			if err != nil {
+				t.Fatalf("Execute returned error: %v", err)
+			}
+			if result.SourceMapError == "" || result.SourceMap != "" {
+				t.Errorf("Execute() source map = %q, error %q, want only an error", result.SourceMap, result.SourceMapError)
+			}
+			if result.Bundler != "minified" || len(result.Files) != 1 {
+				t.Errorf("Execute() = %s with %d files, want the beautified bundle", result.Bundler, len(result.Files))
+			}
+		})
+	}
+}
+
+func FuzzParseSourceMap(f *testing.F) {
+	f.Add(`{"version":3,"sources":["a.js"],"names":["x"],"mappings":"AAAAA,ICAA;AEAAE"}`, "a(b)")
+	f.Fuzz(func(t *testing.T, data, code string) {
+		// 壊れたソースマップでもエラーを返し、範囲外の source と name で panic しない
+		sm, err := parseSourceMap([]byte(data))
+		if err != nil {
+			return
+		}
+		renameIdentifiers(tokenize(code), sm)
+		for line := range sm.lines {
+			for _, seg := range sm.lines[line] {
+				sm.sourcePath(sm.sourceAt(line, seg.column))
+			}
+		}
+	})
+}
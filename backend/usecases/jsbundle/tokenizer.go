@@ -0,0 +1,272 @@
+package jsbundle
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota // 識別子・キーワード
+	tokenNumber
+	tokenString
+	tokenTemplate
+	tokenRegex
+	tokenPunct
+	tokenLineComment
+	tokenBlockComment
+)
+
+// token JavaScript ソースの字句トークン
+// line と column はソースマップと同じく 0 始まりのソース内の位置
+type token struct {
+	kind   tokenKind
+	text   string
+	line   int
+	column int
+	// newline は直前に改行があったことを示す（ASI の判定に使う）
+	newline bool
+}
+
+// regexAfterKeywords 後ろのスラッシュが除算ではなく正規表現の始まりになるキーワード
+var regexAfterKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true, "new": true,
+	"delete": true, "void": true, "throw": true, "case": true, "do": true, "else": true,
+	"yield": true, "await": true,
+}
+
+// punctuators 長いものから先にマッチするよう並べた区切り記号
+var punctuators = []string{
+	">>>=", "...", "===", "!==", "**=", "<<=", ">>=", ">>>", "&&=", "||=", "??=",
+	"=>", "==", "!=", "<=", ">=", "&&", "||", "??", "?.", "++", "--", "+=", "-=", "*=", "/=",
+	"%=", "&=", "|=", "^=", "<<", ">>", "**",
+	"{", "}", "(", ")", "[", "]", ";", ",", "<", ">", "+", "-", "*", "/", "%", "&", "|",
+	"^", "!", "~", "?", ":", "=", ".", "@", "#",
+}
+
+// tokenize JavaScript ソースをトークンに分割する
+// 寛容に処理し、未知の文字は 1 文字の区切り記号にする
+func tokenize(src string) []token {
+	var tokens []token
+	line, lineStart := 0, 0
+	newline := false
+	i := 0
+
+	emit := func(kind tokenKind, start, end int) {
+		tokens = append(tokens, token{kind: kind, text: src[start:end], line: line, column: utf16Column(src[lineStart:start]), newline: newline})
+		newline = false
+	}
+	// advanceLines は start から end までに含まれる改行で行番号を進める
+	advanceLines := func(start, end int) {
+		for j := start; j < end; j++ {
+			if src[j] == '\n' {
+				line++
+				lineStart = j + 1
+				newline = true
+			}
+		}
+	}
+
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+			lineStart = i
+			newline = true
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			i++
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			emit(tokenLineComment, i, i+end)
+			i += end
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				end = len(src) - i - 2
+			} else {
+				end += 2
+			}
+			emit(tokenBlockComment, i, i+2+end)
+			advanceLines(i, i+2+end)
+			i += 2 + end
+		case c == '"' || c == '\'':
+			end := scanString(src, i)
+			emit(tokenString, i, end)
+			advanceLines(i, end)
+			i = end
+		case c == '`':
+			end := scanTemplate(src, i)
+			emit(tokenTemplate, i, end)
+			advanceLines(i, end)
+			i = end
+		case c == '/' && regexAllowed(tokens):
+			end := scanRegex(src, i)
+			emit(tokenRegex, i, end)
+			i = end
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			end := i + 1
+			for end < len(src) && (isWordByte(src[end]) || src[end] == '.' ||
+				(src[end] == '+' || src[end] == '-') && (src[end-1] == 'e' || src[end-1] == 'E') && !strings.HasPrefix(src[i:], "0x")) {
+				end++
+			}
+			emit(tokenNumber, i, end)
+			i = end
+		case isWordStart(src, i):
+			end := i
+			for end < len(src) {
+				r, size := utf8.DecodeRuneInString(src[end:])
+				if r == '\\' || r == '$' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\u200c' || r == '\u200d' {
+					end += size
+					continue
+				}
+				break
+			}
+			emit(tokenWord, i, end)
+			i = end
+		default:
+			matched := false
+			for _, p := range punctuators {
+				if strings.HasPrefix(src[i:], p) {
+					// ?. の直後が数字の場合は三項演算子と小数（a?.5:b）
+					if p == "?." && i+2 < len(src) && src[i+2] >= '0' && src[i+2] <= '9' {
+						continue
+					}
+					emit(tokenPunct, i, i+len(p))
+					i += len(p)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				_, size := utf8.DecodeRuneInString(src[i:])
+				emit(tokenPunct, i, i+size)
+				i += size
+			}
+		}
+	}
+	return tokens
+}
+
+// utf16Column s の長さを UTF-16 のコードユニット数で返す（ソースマップの列の数え方）
+func utf16Column(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func isWordStart(src string, i int) bool {
+	r, _ := utf8.DecodeRuneInString(src[i:])
+	return r == '_' || r == '$' || r == '\\' || unicode.IsLetter(r)
+}
+
+func scanString(src string, start int) int {
+	quote := src[start]
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case quote, '\n':
+			return i + 1
+		}
+	}
+	return len(src)
+}
+
+// scanTemplate ネストした ${} の式を含むテンプレートリテラルを読み進める
+func scanTemplate(src string, start int) int {
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '`':
+			return i + 1
+		case '$':
+			if i+1 < len(src) && src[i+1] == '{' {
+				i = scanExpression(src, i+2) - 1
+			}
+		}
+	}
+	return len(src)
+}
+
+// scanExpression ${} の式を読み飛ばし、閉じ波括弧の直後のインデックスを返す
+func scanExpression(src string, start int) int {
+	depth := 1
+	for i := start; i < len(src); i++ {
+		switch src[i] {
+		case '"', '\'':
+			i = scanString(src, i) - 1
+		case '`':
+			i = scanTemplate(src, i) - 1
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(src)
+}
+
+func scanRegex(src string, start int) int {
+	inClass := false
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '\n':
+			return i
+		case '/':
+			if inClass {
+				continue
+			}
+			end := i + 1
+			for end < len(src) && isWordByte(src[end]) {
+				end++
+			}
+			return end
+		}
+	}
+	return len(src)
+}
+
+// regexAllowed 直前の意味のあるトークンから、スラッシュが正規表現の始まりかを判定
+func regexAllowed(tokens []token) bool {
+	for i := len(tokens) - 1; i >= 0; i-- {
+		t := tokens[i]
+		switch t.kind {
+		case tokenLineComment, tokenBlockComment:
+			continue
+		case tokenWord:
+			return regexAfterKeywords[t.text]
+		case tokenNumber, tokenString, tokenTemplate, tokenRegex:
+			return false
+		case tokenPunct:
+			return t.text != ")" && t.text != "]" && t.text != "}" && t.text != "++" && t.text != "--"
+		}
+	}
+	return true
+}
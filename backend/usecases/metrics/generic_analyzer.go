@@ -0,0 +1,373 @@
+package metrics
+
+import (
+	"regexp"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/utils"
+)
+
+// decisionKeywords 制御フローグラフに分岐を追加するトークン
+var decisionKeywords = map[string]bool{
+	"if": true, "elif": true, "for": true, "foreach": true, "while": true,
+	"case": true, "catch": true, "except": true, "when": true, "unless": true,
+	"until": true, "and": true, "or": true, "&&": true, "||": true, "?": true,
+}
+
+// nestingKeywords 認知的複雑度を増やす構造のトークン
+var nestingKeywords = map[string]bool{
+	"if": true, "for": true, "foreach": true, "while": true, "switch": true,
+	"catch": true, "except": true, "match": true, "unless": true, "until": true,
+}
+
+var (
+	tokenPattern = regexp.MustCompile(`&&|\|\||[A-Za-z_$][A-Za-z0-9_$]*|[{}()?:;,]`)
+
+	cFunctionPattern = regexp.MustCompile(
+		`(?:^|[\s*&])([A-Za-z_$][A-Za-z0-9_$]*)\s*(?:<[^<>]*>)?\s*\(([^()]*)\)[^;{}()]*\{\s*$`)
+	jsArrowPattern = regexp.MustCompile(
+		`([A-Za-z_$][A-Za-z0-9_$]*)\s*[:=]\s*(?:async\s*)?\(([^()]*)\)\s*(?::[^=]+)?=>\s*\{\s*$`)
+	pythonDefPattern = regexp.MustCompile(`^(\s*)(?:async\s+)?def\s+([A-Za-z_][A-Za-z0-9_]*)\s*\(([^)]*)\)?`)
+	rubyDefPattern   = regexp.MustCompile(`^(\s*)def\s+([A-Za-z_][A-Za-z0-9_?!.]*)\s*(?:\(([^)]*)\))?`)
+	shellFuncPattern = regexp.MustCompile(`^\s*(?:function\s+)?([A-Za-z_][A-Za-z0-9_]*)\s*(?:\(\s*\))?\s*\{\s*$`)
+)
+
+// controlWords 呼び出しのように見えるが制御ブロックを開く識別子
+var controlWords = map[string]bool{
+	"if": true, "for": true, "foreach": true, "while": true, "switch": true,
+	"catch": true, "return": true, "sizeof": true,
+	"synchronized": true, "using": true, "lock": true, "fixed": true,
+	"else": true, "do": true, "try": true, "with": true, "match": true,
+	"async": true, "function": true,
+}
+
+// analyzeGeneric 軽量なトークナイザーでおおよそのメトリクスを計算
+func analyzeGeneric(path, language, content string) *entities.FileMetrics {
+	result := &entities.FileMetrics{
+		Path:     path,
+		Language: language,
+	}
+
+	lines := strings.Split(content, "\n")
+	codeLines, code, comments := utils.StripComments(lines, language)
+	summarizeLines(result, lines, code, comments)
+	result.LogicalLOC = len(code)
+
+	switch language {
+	case "python":
+		result.Functions = indentedFunctions(codeLines, pythonDefPattern)
+	case "ruby":
+		result.Functions = rubyFunctions(codeLines)
+	case "shell":
+		result.Functions = braceFunctions(codeLines, shellFuncPattern, nil)
+	case "javascript", "typescript", "java", "c", "cpp", "csharp", "rust",
+		"swift", "kotlin", "scala", "php":
+		result.Functions = braceFunctions(codeLines, jsArrowPattern, cFunctionPattern)
+	}
+
+	return result
+}
+
+// braceFunctions 本体が波括弧で区切られた関数を探す
+func braceFunctions(lines []string, patterns ...*regexp.Regexp) []entities.FunctionMetrics {
+	var functions []entities.FunctionMetrics
+
+	for i := 0; i < len(lines); i++ {
+		name, params, ok := matchFunctionHeader(lines, i, patterns)
+		if !ok {
+			continue
+		}
+
+		end := matchingBrace(lines, i)
+		if end < 0 {
+			continue
+		}
+
+		body := lines[i : end+1]
+		fn := entities.FunctionMetrics{
+			Name:      name,
+			StartLine: i + 1,
+			EndLine:   end + 1,
+			LOC:       end - i + 1,
+			Params:    countParams(params),
+		}
+		fn.Cyclomatic, fn.Cognitive, fn.MaxNesting = braceComplexity(body)
+		// 入れ子の関数も個別に計測するため、次の行から探索を続ける
+		functions = append(functions, fn)
+	}
+
+	return functions
+}
+
+func matchFunctionHeader(lines []string, i int, patterns []*regexp.Regexp) (string, string, bool) {
+	line := lines[i]
+	// 引数リストが複数行にわたる場合は次の行と結合して判定する
+	candidates := []string{line}
+	if i+1 < len(lines) && !strings.Contains(line, "{") && strings.Contains(line, "(") {
+		candidates = append(candidates, line+" "+strings.TrimSpace(lines[i+1]))
+	}
+
+	for _, candidate := range candidates {
+		for _, pattern := range patterns {
+			if pattern == nil {
+				continue
+			}
+			m := pattern.FindStringSubmatch(candidate)
+			if m == nil {
+				continue
+			}
+			name := m[1]
+			if controlWords[name] {
+				continue
+			}
+			params := ""
+			if len(m) > 2 {
+				params = m[2]
+			}
+			return name, params, true
+		}
+	}
+	return "", "", false
+}
+
+// matchingBrace start 以降で最初に開いた波括弧を閉じる行のインデックスを返す
+func matchingBrace(lines []string, start int) int {
+	depth := 0
+	opened := false
+	for i := start; i < len(lines); i++ {
+		for _, ch := range lines[i] {
+			switch ch {
+			case '{':
+				depth++
+				opened = true
+			case '}':
+				depth--
+				if opened && depth == 0 {
+					return i
+				}
+			}
+		}
+	}
+	return -1
+}
+
+// braceComplexity 波括弧で区切られた本体の循環的複雑度、認知的複雑度、ネストの深さを計算
+func braceComplexity(body []string) (int, int, int) {
+	cyclomatic := 1
+	cognitive := 0
+	maxNesting := 0
+
+	depth := 0
+	// structural[d] は深さ d のブロックが制御構造によって開かれたかを示す
+	var structural []bool
+	nesting := 0
+	pendingStructure := false
+	var lastLogical, prev string
+
+	for _, line := range body {
+		for _, tok := range tokenPattern.FindAllString(line, -1) {
+			if decisionKeywords[tok] {
+				cyclomatic++
+			}
+
+			switch {
+			case tok == "if" && prev == "else":
+				// else if は else 側で加算済み
+				pendingStructure = true
+			case tok == "else":
+				cognitive++
+				lastLogical = ""
+			case nestingKeywords[tok]:
+				cognitive += 1 + nesting
+				pendingStructure = true
+				lastLogical = ""
+			case tok == "&&" || tok == "||":
+				if tok != lastLogical {
+					cognitive++
+				}
+				lastLogical = tok
+			case tok == "?":
+				cognitive += 1 + nesting
+			case tok == "{":
+				depth++
+				structural = append(structural, pendingStructure)
+				if pendingStructure {
+					nesting++
+					if nesting > maxNesting {
+						maxNesting = nesting
+					}
+				}
+				pendingStructure = false
+				lastLogical = ""
+			case tok == "}":
+				if depth > 0 {
+					depth--
+					if structural[len(structural)-1] {
+						nesting--
+					}
+					structural = structural[:len(structural)-1]
+				}
+				lastLogical = ""
+			case tok == ";":
+				lastLogical = ""
+			}
+			prev = tok
+		}
+	}
+
+	return cyclomatic, cognitive, maxNesting
+}
+
+// indentedFunctions 本体がインデントで区切られた関数を探す
+func indentedFunctions(lines []string, pattern *regexp.Regexp) []entities.FunctionMetrics {
+	var functions []entities.FunctionMetrics
+
+	for i, line := range lines {
+		m := pattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		indent := indentWidth(m[1])
+
+		end := i
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == "" {
+				continue
+			}
+			if indentWidth(lines[j]) <= indent {
+				break
+			}
+			end = j
+		}
+
+		body := lines[i : end+1]
+		fn := entities.FunctionMetrics{
+			Name:      m[2],
+			StartLine: i + 1,
+			EndLine:   end + 1,
+			LOC:       end - i + 1,
+			Params:    countParams(strings.TrimPrefix(strings.TrimPrefix(m[3], "self"), "cls")),
+		}
+		fn.Cyclomatic, fn.Cognitive, fn.MaxNesting = indentComplexity(body)
+		functions = append(functions, fn)
+	}
+
+	return functions
+}
+
+// indentComplexity インデントで区切られた本体のメトリクスを計算
+func indentComplexity(body []string) (int, int, int) {
+	cyclomatic := 1
+	cognitive := 0
+	maxNesting := 0
+
+	// 制御構造のインデント幅を積んで入れ子の深さを求める
+	var stack []int
+	for _, line := range body[1:] {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := indentWidth(line)
+		for len(stack) > 0 && indent <= stack[len(stack)-1] {
+			stack = stack[:len(stack)-1]
+		}
+		nesting := len(stack)
+
+		var lastLogical string
+		tokens := tokenPattern.FindAllString(line, -1)
+		for k, tok := range tokens {
+			if decisionKeywords[tok] && tok != "?" {
+				cyclomatic++
+			}
+			switch {
+			case tok == "elif" || tok == "else":
+				cognitive++
+			case k == 0 && nestingKeywords[tok]:
+				cognitive += 1 + nesting
+			case tok == "and" || tok == "or":
+				if tok != lastLogical {
+					cognitive++
+				}
+				lastLogical = tok
+			}
+		}
+
+		if len(tokens) > 0 && nestingKeywords[tokens[0]] {
+			stack = append(stack, indent)
+			if len(stack) > maxNesting {
+				maxNesting = len(stack)
+			}
+		}
+	}
+
+	return cyclomatic, cognitive, maxNesting
+}
+
+// rubyFunctions def ... end のブロックを探す
+func rubyFunctions(lines []string) []entities.FunctionMetrics {
+	var functions []entities.FunctionMetrics
+	for i, line := range lines {
+		m := rubyDefPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		indent := indentWidth(m[1])
+		end := i
+		for j := i + 1; j < len(lines); j++ {
+			if strings.TrimSpace(lines[j]) == "end" && indentWidth(lines[j]) == indent {
+				end = j
+				break
+			}
+		}
+		body := lines[i : end+1]
+		fn := entities.FunctionMetrics{
+			Name:      m[2],
+			StartLine: i + 1,
+			EndLine:   end + 1,
+			LOC:       end - i + 1,
+			Params:    countParams(m[3]),
+		}
+		fn.Cyclomatic, fn.Cognitive, fn.MaxNesting = indentComplexity(body)
+		functions = append(functions, fn)
+	}
+	return functions
+}
+
+func indentWidth(line string) int {
+	width := 0
+	for _, ch := range line {
+		switch ch {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
+
+// countParams カンマ区切りの引数を数える（ジェネリクスと既定値の中のカンマは無視する）
+func countParams(params string) int {
+	params = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(params), ","))
+	if params == "" || params == "void" {
+		return 0
+	}
+	count := 1
+	depth := 0
+	for _, ch := range params {
+		switch ch {
+		case '<', '[', '(', '{':
+			depth++
+		case '>', ']', ')', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				count++
+			}
+		}
+	}
+	return count
+}
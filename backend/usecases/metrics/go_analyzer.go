@@ -0,0 +1,383 @@
+package metrics
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// analyzeGo go/ast で Go ソースのメトリクスを計算
+func analyzeGo(path, content string) (*entities.FileMetrics, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, content, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entities.FileMetrics{
+		Path:     path,
+		Language: "go",
+	}
+
+	code, comments := goLineKinds(fset, content)
+	summarizeLines(result, strings.Split(content, "\n"), code, comments)
+
+	// 論理行数は文と宣言の数で数える
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n.(type) {
+		case *ast.BlockStmt:
+		case ast.Stmt, ast.Spec:
+			result.LogicalLOC++
+		case *ast.FuncDecl:
+			result.LogicalLOC++
+		}
+		return true
+	})
+
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Body == nil {
+			continue
+		}
+
+		start := fset.Position(fn.Pos()).Line
+		end := fset.Position(fn.End()).Line
+		result.Functions = append(result.Functions, entities.FunctionMetrics{
+			Name:       goFuncName(fn),
+			StartLine:  start,
+			EndLine:    end,
+			LOC:        end - start + 1,
+			Cyclomatic: goCyclomatic(fn.Body),
+			Cognitive:  goCognitive(fn.Body),
+			MaxNesting: goMaxNesting(fn.Body, 0),
+			Params:     goParamCount(fn.Type.Params),
+		})
+	}
+
+	return result, nil
+}
+
+// goLineKinds ソースを走査し、コードの行とコメントの行を記録する
+func goLineKinds(fset *token.FileSet, content string) (map[int]bool, map[int]bool) {
+	code := make(map[int]bool)
+	comments := make(map[int]bool)
+
+	src := []byte(content)
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	s.Init(file, src, nil, scanner.ScanComments)
+	for {
+		pos, tok, lit := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		// 自動挿入されたセミコロンは行の内容として扱わない
+		if tok == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+		start := fset.Position(pos).Line
+		end := start + strings.Count(lit, "\n")
+		for line := start; line <= end; line++ {
+			if tok == token.COMMENT {
+				comments[line] = true
+			} else {
+				code[line] = true
+			}
+		}
+	}
+	return code, comments
+}
+
+// goFuncName メソッドは "Type.Method"、関数はそのままの名前を返す
+func goFuncName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+
+	recv := fn.Recv.List[0].Type
+	for {
+		switch t := recv.(type) {
+		case *ast.StarExpr:
+			recv = t.X
+			continue
+		case *ast.IndexExpr:
+			recv = t.X
+			continue
+		case *ast.IndexListExpr:
+			recv = t.X
+			continue
+		case *ast.Ident:
+			return t.Name + "." + fn.Name.Name
+		}
+		return fn.Name.Name
+	}
+}
+
+func goParamCount(params *ast.FieldList) int {
+	if params == nil {
+		return 0
+	}
+	count := 0
+	for _, field := range params.List {
+		if len(field.Names) == 0 {
+			count++
+		} else {
+			count += len(field.Names)
+		}
+	}
+	return count
+}
+
+// goCyclomatic McCabe の複雑度（1 + 分岐点の数）を計算
+func goCyclomatic(body *ast.BlockStmt) int {
+	complexity := 1
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if node.List != nil {
+				complexity++
+			}
+		case *ast.CommClause:
+			if node.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if node.Op == token.LAND || node.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+	return complexity
+}
+
+// goCognitive SonarSource の規則で認知的複雑度を計算
+// 構造による加算はネストの深さで重み付けし、同じ論理演算子の連続は 1 つとして加算する
+func goCognitive(body *ast.BlockStmt) int {
+	c := &cognitiveVisitor{}
+	c.block(body, 0)
+	return c.total
+}
+
+type cognitiveVisitor struct {
+	total int
+}
+
+func (c *cognitiveVisitor) block(block *ast.BlockStmt, nesting int) {
+	if block == nil {
+		return
+	}
+	for _, stmt := range block.List {
+		c.stmt(stmt, nesting)
+	}
+}
+
+func (c *cognitiveVisitor) stmt(stmt ast.Stmt, nesting int) {
+	switch s := stmt.(type) {
+	case *ast.IfStmt:
+		c.total += 1 + nesting
+		c.ifChain(s, nesting)
+	case *ast.ForStmt:
+		c.total += 1 + nesting
+		c.expr(s.Cond, nesting)
+		c.block(s.Body, nesting+1)
+	case *ast.RangeStmt:
+		c.total += 1 + nesting
+		c.expr(s.X, nesting)
+		c.block(s.Body, nesting+1)
+	case *ast.SwitchStmt:
+		c.total += 1 + nesting
+		c.expr(s.Tag, nesting)
+		c.clauses(s.Body, nesting+1)
+	case *ast.TypeSwitchStmt:
+		c.total += 1 + nesting
+		c.clauses(s.Body, nesting+1)
+	case *ast.SelectStmt:
+		c.total += 1 + nesting
+		c.clauses(s.Body, nesting+1)
+	case *ast.BranchStmt:
+		if s.Label != nil || s.Tok == token.GOTO {
+			c.total++
+		}
+	case *ast.LabeledStmt:
+		c.stmt(s.Stmt, nesting)
+	case *ast.BlockStmt:
+		c.block(s, nesting)
+	case *ast.ExprStmt:
+		c.expr(s.X, nesting)
+	case *ast.AssignStmt:
+		for _, e := range s.Rhs {
+			c.expr(e, nesting)
+		}
+	case *ast.ReturnStmt:
+		for _, e := range s.Results {
+			c.expr(e, nesting)
+		}
+	case *ast.GoStmt:
+		c.expr(s.Call, nesting)
+	case *ast.DeferStmt:
+		c.expr(s.Call, nesting)
+	case *ast.DeclStmt:
+		ast.Inspect(s, func(n ast.Node) bool {
+			if e, ok := n.(ast.Expr); ok {
+				c.expr(e, nesting)
+				return false
+			}
+			return true
+		})
+	}
+}
+
+func (c *cognitiveVisitor) ifChain(s *ast.IfStmt, nesting int) {
+	c.expr(s.Cond, nesting)
+	c.block(s.Body, nesting+1)
+	switch e := s.Else.(type) {
+	case *ast.IfStmt:
+		// else if は入れ子による加算なしで +1
+		c.total++
+		c.ifChain(e, nesting)
+	case *ast.BlockStmt:
+		c.total++
+		c.block(e, nesting+1)
+	}
+}
+
+func (c *cognitiveVisitor) clauses(body *ast.BlockStmt, nesting int) {
+	for _, stmt := range body.List {
+		switch clause := stmt.(type) {
+		case *ast.CaseClause:
+			for _, s := range clause.Body {
+				c.stmt(s, nesting)
+			}
+		case *ast.CommClause:
+			for _, s := range clause.Body {
+				c.stmt(s, nesting)
+			}
+		}
+	}
+}
+
+// expr 論理演算子の連続を数え、関数リテラルの中にも降りる
+func (c *cognitiveVisitor) expr(e ast.Expr, nesting int) {
+	if e == nil {
+		return
+	}
+	ast.Inspect(e, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			c.block(node.Body, nesting+1)
+			return false
+		case *ast.BinaryExpr:
+			if node.Op == token.LAND || node.Op == token.LOR {
+				c.total += countOperatorSequences(node)
+				c.exprOperands(node, nesting)
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// exprOperands 論理式の論理演算子でないオペランドをたどる
+func (c *cognitiveVisitor) exprOperands(e ast.Expr, nesting int) {
+	if b, ok := unparen(e).(*ast.BinaryExpr); ok && (b.Op == token.LAND || b.Op == token.LOR) {
+		c.exprOperands(b.X, nesting)
+		c.exprOperands(b.Y, nesting)
+		return
+	}
+	c.expr(e, nesting)
+}
+
+// countOperatorSequences 論理式を平坦化し、演算子が切り替わる回数を数える
+func countOperatorSequences(e ast.Expr) int {
+	var ops []token.Token
+	var flatten func(ast.Expr)
+	flatten = func(e ast.Expr) {
+		b, ok := unparen(e).(*ast.BinaryExpr)
+		if !ok || (b.Op != token.LAND && b.Op != token.LOR) {
+			return
+		}
+		flatten(b.X)
+		ops = append(ops, b.Op)
+		flatten(b.Y)
+	}
+	flatten(e)
+
+	count := 0
+	for i, op := range ops {
+		if i == 0 || ops[i-1] != op {
+			count++
+		}
+	}
+	return count
+}
+
+func unparen(e ast.Expr) ast.Expr {
+	for {
+		p, ok := e.(*ast.ParenExpr)
+		if !ok {
+			return e
+		}
+		e = p.X
+	}
+}
+
+// goMaxNesting node 以下の制御構造の最も深いネストを返す
+func goMaxNesting(node ast.Node, depth int) int {
+	max := depth
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == node {
+			return true
+		}
+		if d, ok := goNestedDepth(n, depth); ok {
+			if d > max {
+				max = d
+			}
+			return false
+		}
+		return true
+	})
+	return max
+}
+
+func goNestedDepth(n ast.Node, depth int) (int, bool) {
+	switch s := n.(type) {
+	case *ast.IfStmt:
+		return goIfNesting(s, depth), true
+	case *ast.ForStmt:
+		return goMaxNesting(s.Body, depth+1), true
+	case *ast.RangeStmt:
+		return goMaxNesting(s.Body, depth+1), true
+	case *ast.SwitchStmt:
+		return goMaxNesting(s.Body, depth+1), true
+	case *ast.TypeSwitchStmt:
+		return goMaxNesting(s.Body, depth+1), true
+	case *ast.SelectStmt:
+		return goMaxNesting(s.Body, depth+1), true
+	case *ast.FuncLit:
+		return goMaxNesting(s.Body, depth+1), true
+	}
+	return 0, false
+}
+
+// goIfNesting else if の連鎖を同じネストの深さとして扱う
+func goIfNesting(s *ast.IfStmt, depth int) int {
+	max := goMaxNesting(s.Body, depth+1)
+	var d int
+	switch e := s.Else.(type) {
+	case *ast.IfStmt:
+		d = goIfNesting(e, depth)
+	case *ast.BlockStmt:
+		d = goMaxNesting(e, depth+1)
+	}
+	if d > max {
+		max = d
+	}
+	return max
+}
@@ -0,0 +1,84 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// CodeMetricsUseCase LLM を呼ばずに静的なコードメトリクスを計算する
+type CodeMetricsUseCase struct{}
+
+// NewCodeMetricsUseCase コードメトリクスのユースケースを作成
+func NewCodeMetricsUseCase() *CodeMetricsUseCase {
+	return &CodeMetricsUseCase{}
+}
+
+// Execute 指定したファイルのファイルごと・関数ごとのメトリクスを計算
+func (uc *CodeMetricsUseCase) Execute(ctx context.Context, files []entities.FileInfo) ([]entities.FileMetrics, error) {
+	results := make([]entities.FileMetrics, 0, len(files))
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if file.Content == "" {
+			continue
+		}
+		results = append(results, *uc.Analyze(file))
+	}
+	return results, nil
+}
+
+// Analyze 1 ファイルのメトリクスを計算する
+// Go のソースは go/ast で解析し、それ以外（解析に失敗した Go を含む）はトークナイザーに
+// よる解析にフォールバックする
+func (uc *CodeMetricsUseCase) Analyze(file entities.FileInfo) *entities.FileMetrics {
+	if file.Language == "go" {
+		if result, err := analyzeGo(file.Name, file.Content); err == nil {
+			return result
+		}
+	}
+	return analyzeGeneric(file.Name, file.Language, file.Content)
+}
+
+// TopFunctions ファイル内で最も複雑な n 個の関数を、循環的複雑度、認知的複雑度の順に並べて返す
+func TopFunctions(metrics *entities.FileMetrics, n int) []entities.FunctionMetrics {
+	functions := append([]entities.FunctionMetrics(nil), metrics.Functions...)
+	sort.SliceStable(functions, func(i, j int) bool {
+		if functions[i].Cyclomatic != functions[j].Cyclomatic {
+			return functions[i].Cyclomatic > functions[j].Cyclomatic
+		}
+		return functions[i].Cognitive > functions[j].Cognitive
+	})
+	if n > 0 && len(functions) > n {
+		functions = functions[:n]
+	}
+	return functions
+}
+
+// summarizeLines 行ごとのコード・コメントのフラグから行数のカウンターを埋める
+func summarizeLines(result *entities.FileMetrics, lines []string, code, comments map[int]bool) {
+	// 末尾の改行による空行は数えない
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	result.PhysicalLOC = len(lines)
+	for i, line := range lines {
+		lineNo := i + 1
+		switch {
+		case code[lineNo]:
+		case comments[lineNo]:
+			result.CommentLines++
+		case strings.TrimSpace(line) == "":
+			result.BlankLines++
+		}
+	}
+
+	nonBlank := result.PhysicalLOC - result.BlankLines
+	if nonBlank > 0 {
+		result.CommentDensity = float64(result.CommentLines) / float64(nonBlank)
+	}
+}
@@ -0,0 +1,265 @@
+package redaction
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/usecases/secrets"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`)
+	ipv4Pattern  = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	// IPv6 は候補を広めに拾い、前後の境界と net.ParseIP で確定する
+	ipv6Pattern        = regexp.MustCompile(`(?i)[0-9a-f:]*:[0-9a-f]*:[0-9a-f:.]*`)
+	placeholderPattern = regexp.MustCompile(`\[REDACTED_[A-Z]+_\d+\]`)
+	// 秘密鍵のルールは BEGIN 行にしか一致しないため、本文と END 行を含むブロック全体を拾う。
+	// END 行がない（途中で切れている）場合はテキストの末尾までとする
+	privateKeyPattern = regexp.MustCompile(`(?s)-----BEGIN [A-Z ]*PRIVATE KEY(?: BLOCK)?-----.*?(?:-----END [A-Z ]*PRIVATE KEY(?: BLOCK)?-----|\z)`)
+)
+
+// span テキスト中で見つかった機密情報
+type span struct {
+	start, end int
+	kind       string
+	ruleID     string
+}
+
+// Redactor ネットワークの外に出る前に置き換えるため、シークレット、メールアドレス、
+// IP アドレス、設定された識別子を検出する
+type Redactor struct {
+	scanner     *secrets.SecretScanUseCase
+	identifiers []*regexp.Regexp
+}
+
+// NewRedactor 組み込みのシークレットルールと指定された識別子を使う Redactor を作成
+func NewRedactor(identifiers []string) (*Redactor, error) {
+	scanner, err := secrets.NewSecretScanUseCase(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Redactor{scanner: scanner}
+	for _, identifier := range identifiers {
+		identifier = strings.TrimSpace(identifier)
+		if identifier == "" {
+			continue
+		}
+		r.identifiers = append(r.identifiers, regexp.MustCompile(`\b`+regexp.QuoteMeta(identifier)+`\b`))
+	}
+	return r, nil
+}
+
+// NewRedactorFromEnv 識別子をカンマ区切りの環境変数 REDACTION_IDENTIFIERS（顧客名、
+// 社内のホスト名、プロジェクトのコード名など）から読み込む Redactor を作成
+func NewRedactorFromEnv() (*Redactor, error) {
+	return NewRedactor(strings.Split(os.Getenv("REDACTION_IDENTIFIERS"), ","))
+}
+
+// NewSession リクエストごとの値とプレースホルダーの対応を開始
+func (r *Redactor) NewSession() *Session {
+	return &Session{
+		redactor:      r,
+		byValue:       make(map[string]int),
+		byPlaceholder: make(map[string]string),
+		counters:      make(map[string]int),
+	}
+}
+
+// Residual テキストに残っている機密情報の数を返す。送信前にマスク済みのプロンプトを
+// 確認するために使う
+func (r *Redactor) Residual(text string) int {
+	return len(r.find(text))
+}
+
+// find 重ならない機密情報の範囲を位置順に返す
+func (r *Redactor) find(text string) []span {
+	var spans []span
+
+	lineStarts := []int{0}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	for _, finding := range r.scanner.Scan("", text) {
+		base := lineStarts[finding.Line-1]
+		spans = append(spans, span{base + finding.Column - 1, base + finding.EndColumn - 1, "secret", finding.RuleID})
+	}
+
+	for _, m := range privateKeyPattern.FindAllStringIndex(text, -1) {
+		spans = append(spans, span{m[0], m[1], "secret", "private-key"})
+	}
+
+	for _, m := range emailPattern.FindAllStringIndex(text, -1) {
+		spans = append(spans, span{m[0], m[1], "email", ""})
+	}
+	for _, m := range ipv4Pattern.FindAllStringIndex(text, -1) {
+		if isSensitiveIP(text[m[0]:m[1]]) {
+			spans = append(spans, span{m[0], m[1], "ip", ""})
+		}
+	}
+	for _, m := range ipv6Pattern.FindAllStringIndex(text, -1) {
+		candidate := strings.TrimRight(text[m[0]:m[1]], ":.")
+		end := m[0] + len(candidate)
+		if isWordByte(text, m[0]-1) || isWordByte(text, end) {
+			continue
+		}
+		if looksLikeIPv6(candidate) && isSensitiveIP(candidate) {
+			spans = append(spans, span{m[0], end, "ip", ""})
+		}
+	}
+	for _, re := range r.identifiers {
+		for _, m := range re.FindAllStringIndex(text, -1) {
+			spans = append(spans, span{m[0], m[1], "identifier", ""})
+		}
+	}
+
+	// 既存のプレースホルダーは再検出しない（汎用ルールが値として拾うため）
+	placeholders := placeholderPattern.FindAllStringIndex(text, -1)
+
+	// 開始位置順に並べ、重なる場合は先に見つかった長い方を残す
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+	var result []span
+	for _, s := range spans {
+		if len(result) > 0 && s.start < result[len(result)-1].end {
+			continue
+		}
+		if overlapsAny(s, placeholders) {
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+func overlapsAny(s span, ranges [][]int) bool {
+	for _, r := range ranges {
+		if s.start < r[1] && r[0] < s.end {
+			return true
+		}
+	}
+	return false
+}
+
+func isWordByte(text string, i int) bool {
+	if i < 0 || i >= len(text) {
+		return false
+	}
+	c := text[i]
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// looksLikeIPv6 a::b のように、たまたまアドレスとして解釈できるスコープ演算子を除く
+func looksLikeIPv6(candidate string) bool {
+	groups := 0
+	for _, group := range strings.Split(candidate, ":") {
+		if group != "" {
+			groups++
+		}
+	}
+	return groups >= 2 && strings.ContainsAny(candidate, "0123456789")
+}
+
+// isSensitiveIP ループバック、未指定、不正なアドレスを除く
+func isSensitiveIP(candidate string) bool {
+	ip := net.ParseIP(candidate)
+	if ip == nil {
+		return false
+	}
+	return !ip.IsLoopback() && !ip.IsUnspecified()
+}
+
+// Session 1 つの LLM リクエストのプレースホルダーを保持する
+// モデルが繰り返し出現する値を区別できるよう、セッション内では同じ値を常に
+// 同じプレースホルダーに置き換える
+type Session struct {
+	redactor      *Redactor
+	byValue       map[string]int
+	byPlaceholder map[string]string
+	counters      map[string]int
+	events        []entities.RedactionEvent
+}
+
+// Redact テキスト中の機密情報をすべてプレースホルダーに置き換える
+func (s *Session) Redact(text string) string {
+	spans := s.redactor.find(text)
+	if len(spans) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, sp := range spans {
+		b.WriteString(text[last:sp.start])
+		b.WriteString(s.placeholder(text[sp.start:sp.end], sp))
+		last = sp.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func (s *Session) placeholder(value string, sp span) string {
+	if i, ok := s.byValue[value]; ok {
+		s.events[i].Occurrences++
+		return s.events[i].Placeholder
+	}
+
+	s.counters[sp.kind]++
+	placeholder := fmt.Sprintf("[REDACTED_%s_%d]", strings.ToUpper(sp.kind), s.counters[sp.kind])
+	sum := sha256.Sum256([]byte(value))
+
+	s.byValue[value] = len(s.events)
+	s.byPlaceholder[placeholder] = value
+	s.events = append(s.events, entities.RedactionEvent{
+		Kind:        sp.kind,
+		RuleID:      sp.ruleID,
+		Placeholder: placeholder,
+		Fingerprint: hex.EncodeToString(sum[:16]),
+		Occurrences: 1,
+	})
+	return placeholder
+}
+
+// Restore LLM が返したテキストに元の値を戻す
+func (s *Session) Restore(text string) string {
+	return s.replacer(func(value string) string { return value }).Replace(text)
+}
+
+// RestoreJSON JSON ドキュメント内の値を元に戻す。元の値の引用符やバックスラッシュで
+// ドキュメントが壊れないようエスケープする
+func (s *Session) RestoreJSON(text string) string {
+	return s.replacer(func(value string) string {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return value
+		}
+		return string(encoded[1 : len(encoded)-1])
+	}).Replace(text)
+}
+
+func (s *Session) replacer(encode func(string) string) *strings.Replacer {
+	pairs := make([]string, 0, len(s.byPlaceholder)*2)
+	for placeholder, value := range s.byPlaceholder {
+		pairs = append(pairs, placeholder, encode(value))
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// Events このセッションで行ったマスキングを返す
+func (s *Session) Events() []entities.RedactionEvent {
+	return s.events
+}
@@ -0,0 +1,95 @@
+package report
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// 一般的な CI（Jenkins、GitLab、GitHub Actions のレポーター）が読み込める JUnit XML の構造
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     float64          `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      float64         `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+	SystemOut string          `xml:"system-out,omitempty"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",cdata"`
+}
+
+// BuildJUnit 品質ゲートの判定結果を、条件ごとに 1 テストケースの JUnit XML レポートとして出力
+func BuildJUnit(projectName string, verdict entities.GateVerdict, finishedAt time.Time) ([]byte, error) {
+	className := "quality-gate." + projectName
+	suite := junitTestSuite{
+		Name:      "quality-gate: " + projectName,
+		Time:      verdict.Duration,
+		Timestamp: finishedAt.UTC().Format("2006-01-02T15:04:05"),
+		SystemOut: "status: " + verdict.Status,
+	}
+
+	for _, condition := range verdict.Conditions {
+		testCase := junitTestCase{Name: condition.Name, ClassName: className}
+		problem := &junitProblem{
+			Message: condition.Reason,
+			Body:    "threshold: " + condition.Threshold + "\nactual: " + condition.Actual,
+		}
+		if len(condition.Details) > 0 {
+			problem.Body += "\n\n" + strings.Join(condition.Details, "\n")
+		}
+
+		switch condition.Status {
+		case "failed":
+			problem.Type = "threshold"
+			testCase.Failure = problem
+			suite.Failures++
+		case "error":
+			problem.Type = "analysis"
+			testCase.Error = problem
+			suite.Errors++
+		}
+		suite.Tests++
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	suites := junitTestSuites{
+		Name:     "quality-gate",
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Errors:   suite.Errors,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}
+	body, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,292 @@
+package report
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+const (
+	toolName            = "reverse-engineering-backend"
+	sarifSchema         = "https://json.schemastore.org/sarif-2.1.0.json"
+	fingerprintProperty = "findingFingerprint/v1"
+)
+
+// SARIF 2.1.0 の構造
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool              sarifTool              `json:"tool"`
+	AutomationDetails sarifAutomationDetails `json:"automationDetails"`
+	Results           []sarifResult          `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifAutomationDetails struct {
+	ID string `json:"id"`
+}
+
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	Name                 string              `json:"name,omitempty"`
+	ShortDescription     sarifMessage        `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration  `json:"defaultConfiguration"`
+	Properties           sarifRuleProperties `json:"properties"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifRuleProperties struct {
+	Tags             []string `json:"tags,omitempty"`
+	SecuritySeverity string   `json:"security-severity,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string                `json:"ruleId"`
+	RuleIndex           int                   `json:"ruleIndex"`
+	Level               string                `json:"level"`
+	Message             sarifMessage          `json:"message"`
+	Locations           []sarifLocation       `json:"locations,omitempty"`
+	PartialFingerprints map[string]string     `json:"partialFingerprints"`
+	Properties          sarifResultProperties `json:"properties"`
+}
+
+type sarifResultProperties struct {
+	Source   string `json:"source"`
+	Severity string `json:"severity"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type sarifRegion struct {
+	StartLine   int           `json:"startLine"`
+	EndLine     int           `json:"endLine,omitempty"`
+	StartColumn int           `json:"startColumn,omitempty"`
+	EndColumn   int           `json:"endColumn,omitempty"`
+	Snippet     *sarifMessage `json:"snippet,omitempty"`
+}
+
+// FingerprintedFinding 安定したフィンガープリント付きの指摘
+type FingerprintedFinding struct {
+	entities.Finding
+	Fingerprint string `json:"fingerprint"`
+}
+
+// Fingerprint 指摘を決定的な順序に並べ、複数の解析で重複した指摘を除き、残りの指摘に
+// FingerprintKey から求めたフィンガープリントを付ける
+// 1 つの解析内で同じキー（ファイル内の同じスニペットが 2 回）は出現順で区別する
+func Fingerprint(findings []entities.Finding) []FingerprintedFinding {
+	sorted := make([]entities.Finding, len(findings))
+	copy(sorted, findings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.StartLine != b.StartLine {
+			return a.StartLine < b.StartLine
+		}
+		if a.RuleID != b.RuleID {
+			return a.RuleID < b.RuleID
+		}
+		return a.Message < b.Message
+	})
+
+	type occurrence struct {
+		analysisID uint
+		count      int
+	}
+	occurrences := make(map[string]*occurrence)
+
+	result := make([]FingerprintedFinding, 0, len(sorted))
+	for _, finding := range sorted {
+		key := finding.FingerprintKey
+		if key == "" {
+			key = strings.Join([]string{finding.Source, finding.RuleID, finding.Path, finding.Message}, "|")
+		}
+
+		seen, ok := occurrences[key]
+		if ok && seen.analysisID != finding.AnalysisID {
+			// 別の解析で同じ問題が報告されている（再アップロード時のシークレットスキャンなど）
+			continue
+		}
+		if !ok {
+			seen = &occurrence{analysisID: finding.AnalysisID}
+			occurrences[key] = seen
+		}
+		if seen.count > 0 {
+			key = fmt.Sprintf("%s|%d", key, seen.count)
+		}
+		seen.count++
+
+		sum := sha256.Sum256([]byte(key))
+		result = append(result, FingerprintedFinding{Finding: finding, Fingerprint: hex.EncodeToString(sum[:16])})
+	}
+	return result
+}
+
+// BuildSARIF 指摘を 1 つの run を持つ SARIF 2.1.0 のログとして出力
+func BuildSARIF(projectName string, findings []entities.Finding) interface{} {
+	var rules []sarifRule
+	ruleIndex := make(map[string]int)
+	results := make([]sarifResult, 0, len(findings))
+
+	for _, finding := range Fingerprint(findings) {
+		ruleID := finding.Source + "/" + finding.RuleID
+		if finding.Source == "llm" {
+			// LLM の RuleID は既に "llm/<解析タイプ>" の形
+			ruleID = finding.RuleID
+		}
+
+		index, ok := ruleIndex[ruleID]
+		if !ok {
+			index = len(rules)
+			ruleIndex[ruleID] = index
+			rules = append(rules, newSARIFRule(ruleID, finding.Finding))
+		}
+
+		result := sarifResult{
+			RuleID:              ruleID,
+			RuleIndex:           index,
+			Level:               sarifLevel(finding.Severity),
+			Message:             sarifMessage{Text: finding.Message},
+			PartialFingerprints: map[string]string{fingerprintProperty: finding.Fingerprint},
+			Properties:          sarifResultProperties{Source: finding.Source, Severity: finding.Severity},
+		}
+		if finding.Path != "" {
+			location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: artifactURI(finding.Path), URIBaseID: "%SRCROOT%"},
+			}}
+			if finding.StartLine > 0 {
+				region := &sarifRegion{StartLine: finding.StartLine, StartColumn: finding.StartColumn, EndColumn: finding.EndColumn}
+				if finding.EndLine > finding.StartLine {
+					region.EndLine = finding.EndLine
+				}
+				if finding.Snippet != "" {
+					region.Snippet = &sarifMessage{Text: finding.Snippet}
+				}
+				location.PhysicalLocation.Region = region
+			}
+			result.Locations = []sarifLocation{location}
+		}
+		results = append(results, result)
+	}
+
+	if rules == nil {
+		rules = []sarifRule{}
+	}
+	return sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool:              sarifTool{Driver: sarifDriver{Name: toolName, Rules: rules}},
+			AutomationDetails: sarifAutomationDetails{ID: projectName + "/"},
+			Results:           results,
+		}},
+	}
+}
+
+func newSARIFRule(ruleID string, finding entities.Finding) sarifRule {
+	description := finding.RuleName
+	if description == "" {
+		switch finding.Source {
+		case "sast":
+			description = finding.Message
+		case "osv":
+			description = "Vulnerable dependency (" + finding.RuleID + ")"
+		case "llm":
+			description = "Issue reported by LLM " + strings.TrimPrefix(finding.RuleID, "llm/")
+		default:
+			description = finding.RuleID
+		}
+	}
+
+	rule := sarifRule{
+		ID:                   ruleID,
+		Name:                 finding.RuleID,
+		ShortDescription:     sarifMessage{Text: description},
+		DefaultConfiguration: sarifConfiguration{Level: sarifLevel(finding.Severity)},
+		Properties:           sarifRuleProperties{Tags: []string{finding.Source}},
+	}
+	// LLM の指摘は推測を含むため security-severity を付けない
+	if finding.Source != "llm" {
+		rule.Properties.Tags = append(rule.Properties.Tags, "security")
+		rule.Properties.SecuritySeverity = securitySeverity(finding.Severity)
+	}
+	if finding.CWE != "" {
+		rule.Properties.Tags = append(rule.Properties.Tags, "external/cwe/"+strings.ToLower(finding.CWE))
+	}
+	return rule
+}
+
+func sarifLevel(severity string) string {
+	switch severity {
+	case "critical", "high":
+		return "error"
+	case "medium":
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// securitySeverity 深刻度を code scanning のダッシュボードが使う CVSS 相当のスコアに変換
+func securitySeverity(severity string) string {
+	switch severity {
+	case "critical":
+		return "9.5"
+	case "high":
+		return "8.0"
+	case "medium":
+		return "5.5"
+	case "low":
+		return "3.0"
+	default:
+		return "0.0"
+	}
+}
+
+// artifactURI 保存されたパスを相対 URI 参照に変換
+func artifactURI(path string) string {
+	segments := strings.Split(strings.TrimPrefix(strings.ReplaceAll(path, "\\", "/"), "/"), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
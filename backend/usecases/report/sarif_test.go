@@ -0,0 +1,199 @@
+package report
+
+import (
+	"testing"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+func fingerprints(findings []entities.Finding) []string {
+	var result []string
+	for _, finding := range Fingerprint(findings) {
+		result = append(result, finding.Fingerprint)
+	}
+	return result
+}
+
+func TestFingerprintStability(t *testing.T) {
+	base := entities.Finding{Source: "sast", RuleID: "go-weak-hash", Path: "main.go", StartLine: 10, Message: "weak hash", AnalysisID: 1}
+	want := fingerprints([]entities.Finding{base})[0]
+
+	tests := []struct {
+		name   string
+		modify func(f *entities.Finding)
+		same   bool
+	}{
+		{name: "identical", modify: func(f *entities.Finding) {}, same: true},
+		{name: "moved to another line", modify: func(f *entities.Finding) { f.StartLine, f.EndLine = 42, 43 }, same: true},
+		{name: "reported by a later analysis", modify: func(f *entities.Finding) { f.AnalysisID = 7 }, same: true},
+		{name: "other snippet", modify: func(f *entities.Finding) { f.Snippet = "md5.New()" }, same: true},
+		{name: "other rule", modify: func(f *entities.Finding) { f.RuleID = "go-math-rand-token" }},
+		{name: "other file", modify: func(f *entities.Finding) { f.Path = "util.go" }},
+		{name: "other source", modify: func(f *entities.Finding) { f.Source = "secret" }},
+		{name: "other message", modify: func(f *entities.Finding) { f.Message = "weak cipher" }},
+		{name: "explicit key", modify: func(f *entities.Finding) { f.FingerprintKey = "custom" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finding := base
+			tt.modify(&finding)
+			got := fingerprints([]entities.Finding{finding})[0]
+			if (got == want) != tt.same {
+				t.Errorf("fingerprint %s, base %s: same = %v, want %v", got, want, got == want, tt.same)
+			}
+		})
+	}
+}
+
+func TestFingerprintExplicitKeyIgnoresMessage(t *testing.T) {
+	a := entities.Finding{Source: "llm", RuleID: "llm/pattern_detection", Message: "SQL injection", FingerprintKey: "k"}
+	b := a
+	b.Message = "Possible SQL injection"
+	if fingerprints([]entities.Finding{a})[0] != fingerprints([]entities.Finding{b})[0] {
+		t.Error("findings with the same FingerprintKey got different fingerprints")
+	}
+}
+
+func TestFingerprintDuplicates(t *testing.T) {
+	finding := func(analysisID uint, line int) entities.Finding {
+		return entities.Finding{Source: "secret", RuleID: "aws-access-key", Path: "config.env", StartLine: line, Message: "AWS key", AnalysisID: analysisID}
+	}
+
+	tests := []struct {
+		name     string
+		findings []entities.Finding
+		count    int
+		distinct int
+	}{
+		{
+			name:     "same finding from two analyses is reported once",
+			findings: []entities.Finding{finding(1, 3), finding(2, 3)},
+			count:    1, distinct: 1,
+		},
+		{
+			name:     "same key twice in one analysis is kept apart",
+			findings: []entities.Finding{finding(1, 3), finding(1, 9)},
+			count:    2, distinct: 2,
+		},
+		{
+			name:     "repeated occurrences from a second analysis are dropped",
+			findings: []entities.Finding{finding(1, 3), finding(1, 9), finding(2, 3), finding(2, 9)},
+			count:    2, distinct: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fingerprints(tt.findings)
+			distinct := make(map[string]bool)
+			for _, fp := range got {
+				distinct[fp] = true
+			}
+			if len(got) != tt.count || len(distinct) != tt.distinct {
+				t.Errorf("got %d findings with %d distinct fingerprints, want %d and %d", len(got), len(distinct), tt.count, tt.distinct)
+			}
+		})
+	}
+}
+
+func TestFingerprintOrderIndependent(t *testing.T) {
+	findings := []entities.Finding{
+		{Source: "sast", RuleID: "b", Path: "b.go", StartLine: 1, Message: "m", AnalysisID: 1},
+		{Source: "sast", RuleID: "a", Path: "a.go", StartLine: 5, Message: "m", AnalysisID: 1},
+		{Source: "sast", RuleID: "a", Path: "a.go", StartLine: 2, Message: "m", AnalysisID: 1},
+	}
+	reversed := []entities.Finding{findings[2], findings[1], findings[0]}
+
+	got, want := Fingerprint(reversed), Fingerprint(findings)
+	for i := range want {
+		if got[i].Fingerprint != want[i].Fingerprint || got[i].StartLine != want[i].StartLine {
+			t.Fatalf("result %d differs with input order: %+v vs %+v", i, got[i], want[i])
+		}
+	}
+	if want[0].Path != "a.go" || want[0].StartLine != 2 {
+		t.Errorf("first finding = %s:%d, want a.go:2", want[0].Path, want[0].StartLine)
+	}
+}
+
+func TestArtifactURI(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"main.go", "main.go"},
+		{"/src/main.go", "src/main.go"},
+		{`src\app\main.go`, "src/app/main.go"},
+		{"docs/read me.md", "docs/read%20me.md"},
+		{"a/b#c.txt", "a/b%23c.txt"},
+		{"日本語/ファイル.go", "%E6%97%A5%E6%9C%AC%E8%AA%9E/%E3%83%95%E3%82%A1%E3%82%A4%E3%83%AB.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := artifactURI(tt.path); got != tt.want {
+				t.Errorf("artifactURI(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildSARIFResults(t *testing.T) {
+	findings := []entities.Finding{
+		{Source: "sast", RuleID: "go-weak-hash", Severity: "low", Path: "a.go", StartLine: 3, Message: "weak", CWE: "CWE-328"},
+		{Source: "sast", RuleID: "go-weak-hash", Severity: "low", Path: "b.go", StartLine: 8, EndLine: 8, Message: "weak"},
+		{Source: "llm", RuleID: "llm/pattern_detection", Severity: "high", Path: "c.go", Message: "smell"},
+		{Source: "osv", RuleID: "GHSA-xxxx", Severity: "critical", Message: "vulnerable"},
+	}
+	log := BuildSARIF("demo", findings).(sarifLog)
+	run := log.Runs[0]
+
+	if len(run.Tool.Driver.Rules) != 3 {
+		t.Fatalf("got %d rules, want 3", len(run.Tool.Driver.Rules))
+	}
+	tests := []struct {
+		ruleID    string
+		ruleIndex int
+		level     string
+		located   bool
+		startLine int
+		endLine   int
+	}{
+		// パスのない依存関係の指摘が先頭に並ぶ
+		{"osv/GHSA-xxxx", 0, "error", false, 0, 0},
+		{"sast/go-weak-hash", 1, "note", true, 3, 0},
+		{"sast/go-weak-hash", 1, "note", true, 8, 0},
+		{"llm/pattern_detection", 2, "error", true, 0, 0},
+	}
+	if len(run.Results) != len(tests) {
+		t.Fatalf("got %d results, want %d", len(run.Results), len(tests))
+	}
+	for i, tt := range tests {
+		result := run.Results[i]
+		if result.RuleID != tt.ruleID || result.RuleIndex != tt.ruleIndex || result.Level != tt.level {
+			t.Errorf("result %d = %s[%d] %s, want %s[%d] %s", i, result.RuleID, result.RuleIndex, result.Level, tt.ruleID, tt.ruleIndex, tt.level)
+		}
+		if (len(result.Locations) > 0) != tt.located {
+			t.Errorf("result %d: located = %v, want %v", i, len(result.Locations) > 0, tt.located)
+			continue
+		}
+		if !tt.located {
+			continue
+		}
+		region := result.Locations[0].PhysicalLocation.Region
+		switch {
+		case tt.startLine == 0 && region != nil:
+			t.Errorf("result %d: unexpected region %+v", i, region)
+		case tt.startLine > 0 && (region == nil || region.StartLine != tt.startLine || region.EndLine != tt.endLine):
+			t.Errorf("result %d: region = %+v, want lines %d-%d", i, region, tt.startLine, tt.endLine)
+		}
+		if result.PartialFingerprints[fingerprintProperty] == "" {
+			t.Errorf("result %d has no %s", i, fingerprintProperty)
+		}
+	}
+
+	// LLM の指摘には security-severity を付けない
+	if got := run.Tool.Driver.Rules[2].Properties.SecuritySeverity; got != "" {
+		t.Errorf("llm rule security-severity = %q, want empty", got)
+	}
+	if got := run.Tool.Driver.Rules[0].Properties.SecuritySeverity; got != "9.5" {
+		t.Errorf("osv rule security-severity = %q, want 9.5", got)
+	}
+}
@@ -0,0 +1,272 @@
+package sast
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// goSource スキャン中のすべての AST ルールで共有する解析済みの Go ファイル
+type goSource struct {
+	fset *token.FileSet
+	file *ast.File
+	err  error
+}
+
+func parseGo(name, content string) *goSource {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, content, parser.SkipObjectResolution)
+	return &goSource{fset: fset, file: file, err: err}
+}
+
+// match 構造パターンを評価し、マッチしたノードの行範囲を返す
+func (s *goSource) match(p *entities.ASTPattern) [][2]int {
+	var matches [][2]int
+	add := func(node ast.Node) {
+		matches = append(matches, [2]int{s.fset.Position(node.Pos()).Line, s.fset.Position(node.End()).Line})
+	}
+
+	// 関数単位でローカル変数への代入を追跡し、変数経由の文字列連結も検出する
+	ast.Inspect(s.file, func(n ast.Node) bool {
+		switch decl := n.(type) {
+		case *ast.FuncDecl:
+		case *ast.GenDecl:
+			// パッケージレベルの var 宣言
+			if decl.Tok != token.VAR {
+				return false
+			}
+		default:
+			return true
+		}
+
+		scope := newGoScope(n)
+		ast.Inspect(n, func(inner ast.Node) bool {
+			switch node := inner.(type) {
+			case *ast.CallExpr:
+				if p.Kind == "call" && scope.matchCall(p, node) {
+					add(node)
+				}
+			case *ast.CompositeLit:
+				if p.Kind == "field" && matchCompositeField(p, node) {
+					add(node)
+				}
+			case *ast.AssignStmt:
+				if p.Kind == "field" && matchFieldAssign(p, node) {
+					add(node)
+				}
+			}
+			return true
+		})
+		return false
+	})
+	return matches
+}
+
+// goScope 関数内のローカル識別子に代入された値を記録する
+type goScope struct {
+	assigned map[string][]ast.Expr
+	params   map[string]bool
+}
+
+func newGoScope(root ast.Node) *goScope {
+	scope := &goScope{assigned: make(map[string][]ast.Expr), params: make(map[string]bool)}
+	if fn, ok := root.(*ast.FuncDecl); ok {
+		for _, list := range []*ast.FieldList{fn.Recv, fn.Type.Params} {
+			if list == nil {
+				continue
+			}
+			for _, field := range list.List {
+				for _, name := range field.Names {
+					scope.params[name.Name] = true
+				}
+			}
+		}
+	}
+
+	ast.Inspect(root, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.AssignStmt:
+			if len(node.Lhs) != len(node.Rhs) {
+				// 多値代入（関数の戻り値など）は動的な値として扱う
+				for _, lhs := range node.Lhs {
+					if ident, ok := lhs.(*ast.Ident); ok {
+						scope.assigned[ident.Name] = append(scope.assigned[ident.Name], &ast.BadExpr{})
+					}
+				}
+				return true
+			}
+			for i, lhs := range node.Lhs {
+				ident, ok := lhs.(*ast.Ident)
+				if !ok {
+					continue
+				}
+				rhs := node.Rhs[i]
+				if node.Tok == token.ADD_ASSIGN {
+					// s += x は s + x と同じ
+					rhs = &ast.BinaryExpr{X: ident, Op: token.ADD, Y: rhs}
+				}
+				scope.assigned[ident.Name] = append(scope.assigned[ident.Name], rhs)
+			}
+		case *ast.ValueSpec:
+			for i, name := range node.Names {
+				if i < len(node.Values) {
+					scope.assigned[name.Name] = append(scope.assigned[name.Name], node.Values[i])
+				} else if len(node.Values) > 0 {
+					scope.assigned[name.Name] = append(scope.assigned[name.Name], &ast.BadExpr{})
+				}
+			}
+		}
+		return true
+	})
+	return scope
+}
+
+func (s *goScope) matchCall(p *entities.ASTPattern, call *ast.CallExpr) bool {
+	if !matchCallee(p.Callee, call.Fun) {
+		return false
+	}
+	if p.ArgIs == "" {
+		return true
+	}
+
+	check := func(arg ast.Expr) bool {
+		if p.ArgIs == "concat" {
+			return s.isConcat(arg, 0)
+		}
+		return !s.isStatic(arg, 0)
+	}
+	if p.Arg < 0 {
+		for _, arg := range call.Args {
+			if check(arg) {
+				return true
+			}
+		}
+		return false
+	}
+	return p.Arg < len(call.Args) && check(call.Args[p.Arg])
+}
+
+// matchCallee "pkg.Func"、"Func"、"*.Method" にマッチするかを判定
+func matchCallee(callees []string, fun ast.Expr) bool {
+	name := types.ExprString(fun)
+	method := ""
+	if sel, ok := fun.(*ast.SelectorExpr); ok {
+		method = sel.Sel.Name
+	}
+	for _, callee := range callees {
+		if callee == name || (method != "" && strings.HasPrefix(callee, "*.") && callee[2:] == method) {
+			return true
+		}
+	}
+	return false
+}
+
+const maxScopeDepth = 8
+
+// isStatic expr がコンパイル時定数かどうかを、ローカルの代入をたどって判定
+func (s *goScope) isStatic(expr ast.Expr, depth int) bool {
+	if depth > maxScopeDepth {
+		return false
+	}
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.ParenExpr:
+		return s.isStatic(e.X, depth+1)
+	case *ast.BinaryExpr:
+		return s.isStatic(e.X, depth+1) && s.isStatic(e.Y, depth+1)
+	case *ast.Ident:
+		if e.Name == "true" || e.Name == "false" || e.Name == "nil" {
+			return true
+		}
+		values, ok := s.assigned[e.Name]
+		if !ok || s.params[e.Name] {
+			return false
+		}
+		for _, v := range values {
+			if !s.isStatic(v, depth+1) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// isConcat expr が定数でない部分から文字列を組み立てているかを判定
+func (s *goScope) isConcat(expr ast.Expr, depth int) bool {
+	if depth > maxScopeDepth || s.isStatic(expr, depth) {
+		return false
+	}
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return s.isConcat(e.X, depth+1)
+	case *ast.BinaryExpr:
+		// 数値の加算と区別するため、文字列リテラルを含む + のみ対象とする
+		return e.Op == token.ADD && (hasStringLiteral(e) || s.isConcat(e.X, depth+1) || s.isConcat(e.Y, depth+1))
+	case *ast.CallExpr:
+		switch types.ExprString(e.Fun) {
+		case "fmt.Sprintf", "fmt.Sprint", "fmt.Sprintln", "strings.Join", "strings.ReplaceAll", "strings.Replace":
+			for _, arg := range e.Args {
+				if !s.isStatic(arg, depth+1) {
+					return true
+				}
+			}
+		}
+	case *ast.Ident:
+		for _, v := range s.assigned[e.Name] {
+			if s.isConcat(v, depth+1) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func hasStringLiteral(expr ast.Expr) bool {
+	found := false
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func matchCompositeField(p *entities.ASTPattern, lit *ast.CompositeLit) bool {
+	if p.Type != "" && (lit.Type == nil || types.ExprString(lit.Type) != p.Type) {
+		return false
+	}
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok && key.Name == p.Field && matchValue(p.Value, kv.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchFieldAssign(p *entities.ASTPattern, assign *ast.AssignStmt) bool {
+	// 型指定のあるルールは代入文では型を判定できないため対象外
+	if p.Type != "" || len(assign.Lhs) != len(assign.Rhs) {
+		return false
+	}
+	for i, lhs := range assign.Lhs {
+		if sel, ok := lhs.(*ast.SelectorExpr); ok && sel.Sel.Name == p.Field && matchValue(p.Value, assign.Rhs[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchValue(want string, expr ast.Expr) bool {
+	return want == "" || types.ExprString(expr) == want
+}
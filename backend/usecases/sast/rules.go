@@ -0,0 +1,112 @@
+package sast
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed rules/*.yaml
+var builtinPacks embed.FS
+
+// rulePack ルール YAML ファイルの構造
+type rulePack struct {
+	Rules []entities.SASTRule `yaml:"rules"`
+}
+
+var validSeverities = map[string]bool{"critical": true, "high": true, "medium": true, "low": true, "info": true}
+
+// BuiltinRules バイナリに同梱したパックのルールを返す
+func BuiltinRules() ([]entities.SASTRule, error) {
+	names, err := builtinPacks.ReadDir("rules")
+	if err != nil {
+		return nil, err
+	}
+
+	var rules []entities.SASTRule
+	for _, entry := range names {
+		data, err := builtinPacks.ReadFile("rules/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		pack, err := ParseRules(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		rules = append(rules, pack...)
+	}
+	return rules, nil
+}
+
+// LoadRulesDir dir 内のすべての *.yaml / *.yml ファイルを読み込む
+func LoadRulesDir(dir string) ([]entities.SASTRule, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var rules []entities.SASTRule
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		pack, err := ParseRules(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		rules = append(rules, pack...)
+	}
+	return rules, nil
+}
+
+// ParseRules ルールパックをデコードして検証
+func ParseRules(data []byte) ([]entities.SASTRule, error) {
+	var pack rulePack
+	if err := yaml.Unmarshal(data, &pack); err != nil {
+		return nil, err
+	}
+	for _, rule := range pack.Rules {
+		if err := ValidateRule(rule); err != nil {
+			return nil, err
+		}
+	}
+	return pack.Rules, nil
+}
+
+// ValidateRule ルールが揃っていて、パターンをコンパイルできるかを判定
+func ValidateRule(rule entities.SASTRule) error {
+	if rule.ID == "" {
+		return fmt.Errorf("rule id is required")
+	}
+	if len(rule.Languages) == 0 {
+		return fmt.Errorf("rule %s: languages are required", rule.ID)
+	}
+	if rule.Message == "" {
+		return fmt.Errorf("rule %s: message is required", rule.ID)
+	}
+	if !validSeverities[rule.Severity] {
+		return fmt.Errorf("rule %s: invalid severity %q", rule.ID, rule.Severity)
+	}
+	if (rule.Pattern == "") == (rule.AST == nil) {
+		return fmt.Errorf("rule %s: exactly one of pattern or ast is required", rule.ID)
+	}
+	_, err := compileRule(rule)
+	return err
+}
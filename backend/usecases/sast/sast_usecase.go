@@ -0,0 +1,187 @@
+package sast
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// suppressMarkers 検出箇所の最初の行にあると、その検出を抑制するマーカー
+var suppressMarkers = []string{"sast:ignore", "#nosec", "// nosec", "nosemgrep"}
+
+type compiledRule struct {
+	rule       entities.SASTRule
+	pattern    *regexp.Regexp
+	patternNot *regexp.Regexp
+	languages  map[string]bool
+}
+
+// SASTUseCase YAML で定義したルールをソースファイルに適用する
+// LLM によるパターン検出と違い結果が決定的なため、ゲートに使える
+type SASTUseCase struct {
+	rules []compiledRule
+}
+
+// NewSASTUseCase 指定したルールでエンジンを作成
+// 先のルールと同じ ID を持つ後のルールはそれを置き換えるため、カスタムパックで組み込みルールを上書きできる
+func NewSASTUseCase(rules []entities.SASTRule) (*SASTUseCase, error) {
+	index := make(map[string]int)
+	uc := &SASTUseCase{}
+	for _, rule := range rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := index[rule.ID]; ok {
+			uc.rules[i] = compiled
+			continue
+		}
+		index[rule.ID] = len(uc.rules)
+		uc.rules = append(uc.rules, compiled)
+	}
+	return uc, nil
+}
+
+func compileRule(rule entities.SASTRule) (compiledRule, error) {
+	compiled := compiledRule{rule: rule, languages: make(map[string]bool)}
+	for _, language := range rule.Languages {
+		compiled.languages[strings.ToLower(language)] = true
+	}
+
+	var err error
+	if rule.Pattern != "" {
+		if compiled.pattern, err = regexp.Compile(rule.Pattern); err != nil {
+			return compiled, fmt.Errorf("rule %s: invalid pattern: %w", rule.ID, err)
+		}
+	}
+	if rule.PatternNot != "" {
+		if compiled.patternNot, err = regexp.Compile(rule.PatternNot); err != nil {
+			return compiled, fmt.Errorf("rule %s: invalid pattern_not: %w", rule.ID, err)
+		}
+	}
+	if rule.AST != nil {
+		// 構文木パターンは現状 Go のみ対応
+		if len(compiled.languages) != 1 || !compiled.languages["go"] {
+			return compiled, fmt.Errorf("rule %s: ast patterns are only supported for go", rule.ID)
+		}
+		switch rule.AST.Kind {
+		case "call":
+			if len(rule.AST.Callee) == 0 {
+				return compiled, fmt.Errorf("rule %s: call pattern needs a callee", rule.ID)
+			}
+			if rule.AST.ArgIs != "" && rule.AST.ArgIs != "dynamic" && rule.AST.ArgIs != "concat" {
+				return compiled, fmt.Errorf("rule %s: arg_is must be dynamic or concat", rule.ID)
+			}
+		case "field":
+			if rule.AST.Field == "" {
+				return compiled, fmt.Errorf("rule %s: field pattern needs a field", rule.ID)
+			}
+		default:
+			return compiled, fmt.Errorf("rule %s: unknown ast kind %q", rule.ID, rule.AST.Kind)
+		}
+	}
+	return compiled, nil
+}
+
+// Rules 有効なルールを評価順に返す
+func (uc *SASTUseCase) Rules() []entities.SASTRule {
+	rules := make([]entities.SASTRule, 0, len(uc.rules))
+	for _, r := range uc.rules {
+		rules = append(rules, r.rule)
+	}
+	return rules
+}
+
+// Execute すべてのファイルにすべてのルールを適用し、検出結果をパスと行の順に返す
+func (uc *SASTUseCase) Execute(ctx context.Context, files []entities.FileInfo) ([]entities.RuleFinding, error) {
+	var findings []entities.RuleFinding
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		findings = append(findings, uc.Scan(file)...)
+	}
+	return findings, nil
+}
+
+// Scan 1 ファイルの言語に当てはまるルールを適用
+func (uc *SASTUseCase) Scan(file entities.FileInfo) []entities.RuleFinding {
+	language := strings.ToLower(file.Language)
+	lines := strings.Split(file.Content, "\n")
+
+	var findings []entities.RuleFinding
+	var goFile *goSource
+	for _, r := range uc.rules {
+		if !r.languages[language] {
+			continue
+		}
+
+		var matches [][2]int // 開始行・終了行
+		if r.pattern != nil {
+			matches = r.matchRegex(file.Content)
+		} else {
+			// 構文エラーのファイルは正規表現ルールのみ適用する
+			if goFile == nil {
+				goFile = parseGo(file.Name, file.Content)
+			}
+			if goFile.err != nil {
+				continue
+			}
+			matches = goFile.match(r.rule.AST)
+		}
+
+		for _, m := range matches {
+			if isSuppressed(lines, m[0]) {
+				continue
+			}
+			findings = append(findings, entities.RuleFinding{
+				RuleID:    r.rule.ID,
+				Message:   r.rule.Message,
+				Severity:  r.rule.Severity,
+				CWE:       r.rule.CWE,
+				Path:      file.Name,
+				StartLine: m[0],
+				EndLine:   m[1],
+				Snippet:   strings.TrimSpace(lines[m[0]-1]),
+			})
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].StartLine != findings[j].StartLine {
+			return findings[i].StartLine < findings[j].StartLine
+		}
+		return findings[i].RuleID < findings[j].RuleID
+	})
+	return findings
+}
+
+// matchRegex pattern_not で除外されなかったパターンのマッチの行範囲を返す
+func (r compiledRule) matchRegex(content string) [][2]int {
+	var matches [][2]int
+	for _, loc := range r.pattern.FindAllStringIndex(content, -1) {
+		if r.patternNot != nil && r.patternNot.MatchString(content[loc[0]:loc[1]]) {
+			continue
+		}
+		start := strings.Count(content[:loc[0]], "\n") + 1
+		end := start + strings.Count(strings.TrimRight(content[loc[0]:loc[1]], "\n"), "\n")
+		matches = append(matches, [2]int{start, end})
+	}
+	return matches
+}
+
+func isSuppressed(lines []string, line int) bool {
+	if line < 1 || line > len(lines) {
+		return false
+	}
+	for _, marker := range suppressMarkers {
+		if strings.Contains(lines[line-1], marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,311 @@
+package sast
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+func newBuiltinUseCase(t *testing.T) *SASTUseCase {
+	t.Helper()
+	rules, err := BuiltinRules()
+	if err != nil {
+		t.Fatalf("BuiltinRules returned error: %v", err)
+	}
+	uc, err := NewSASTUseCase(rules)
+	if err != nil {
+		t.Fatalf("NewSASTUseCase returned error: %v", err)
+	}
+	return uc
+}
+
+func goFile(body string) string {
+	return "package main\n\n" + body
+}
+
+func TestScanGoAST(t *testing.T) {
+	uc := newBuiltinUseCase(t)
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string // 検出されるルール ID と行（id:line）
+	}{
+		{
+			name: "sql concat",
+			content: goFile(`func f(db *sql.DB, id string) {
+	db.Query("SELECT * FROM users WHERE id = " + id)
+}`),
+			want: []string{"go-sql-string-concat:4"},
+		},
+		{
+			name: "sql sprintf through a local variable",
+			content: goFile(`func f(db *sql.DB, id string) {
+	q := fmt.Sprintf("SELECT * FROM users WHERE id = %s", id)
+	db.QueryRow(q)
+}`),
+			want: []string{"go-sql-string-concat:5"},
+		},
+		{
+			name: "sql placeholder",
+			content: goFile(`func f(db *sql.DB, id string) {
+	db.Query("SELECT * FROM users WHERE id = $1", id)
+}`),
+		},
+		{
+			name: "sql constant concat",
+			content: goFile(`const table = "users"
+
+func f(db *sql.DB) {
+	q := "SELECT * FROM " + "users"
+	db.Exec(q)
+}`),
+		},
+		{
+			name: "command from parameter",
+			content: goFile(`func f(name string) {
+	exec.Command(name, "-v")
+}`),
+			want: []string{"go-command-injection:4"},
+		},
+		{
+			name: "constant command",
+			content: goFile(`func f() {
+	bin := "git"
+	exec.Command(bin, "status")
+}`),
+		},
+		{
+			name: "command context checks the command argument",
+			content: goFile(`func f(ctx context.Context, name string) {
+	exec.CommandContext(ctx, "git", name)
+	exec.CommandContext(ctx, name)
+}`),
+			want: []string{"go-command-injection-context:5"},
+		},
+		{
+			name: "shell argument concat",
+			content: goFile(`func f(file string) {
+	exec.Command("sh", "-c", "cat "+file)
+}`),
+			want: []string{"go-command-arg-concat:4"},
+		},
+		{
+			name: "insecure skip verify literal",
+			content: goFile(`var cfg = &tls.Config{
+	InsecureSkipVerify: true,
+}`),
+			want: []string{"go-insecure-skip-verify:3"},
+		},
+		{
+			name: "insecure skip verify assignment",
+			content: goFile(`func f(cfg *tls.Config) {
+	cfg.InsecureSkipVerify = true
+}`),
+			want: []string{"go-insecure-skip-verify:4"},
+		},
+		{
+			name: "skip verify disabled",
+			content: goFile(`var cfg = &tls.Config{
+	InsecureSkipVerify: false,
+}`),
+		},
+		{
+			name: "suppressed",
+			content: goFile(`func f(name string) {
+	exec.Command(name) // #nosec
+}`),
+		},
+		{
+			name: "syntax error only runs regex rules",
+			content: goFile(`import "math/rand"
+
+func f(name string) {
+	exec.Command(name
+}`),
+			want: []string{"go-math-rand-token:3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findingKeys(uc.Scan(entities.FileInfo{Name: "main.go", Language: "go", Content: tt.content}))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanRegex(t *testing.T) {
+	uc := newBuiltinUseCase(t)
+
+	tests := []struct {
+		name     string
+		language string
+		content  string
+		want     []string
+	}{
+		{
+			name:     "python shell true",
+			language: "python",
+			content:  "import subprocess\nsubprocess.run(cmd, shell=True)\n",
+			want:     []string{"python-shell-true:2"},
+		},
+		{
+			name:     "python eval of a variable",
+			language: "python",
+			content:  "x = eval(expr)\n",
+			want:     []string{"python-eval:1"},
+		},
+		{
+			name:     "python eval of a literal is excluded by pattern_not",
+			language: "python",
+			content:  "x = eval('1 + 1')\n",
+		},
+		{
+			name:     "python eval in a comment",
+			language: "python",
+			content:  "# eval(expr)\n",
+		},
+		{
+			name:     "python yaml load with safe loader",
+			language: "python",
+			content:  "yaml.load(data, Loader=yaml.SafeLoader)\n",
+		},
+		{
+			name:     "python yaml load",
+			language: "python",
+			content:  "\n\nyaml.load(data)\n",
+			want:     []string{"python-yaml-load:3"},
+		},
+		{
+			name:     "javascript template literal query",
+			language: "javascript",
+			content:  "db.query(`SELECT * FROM users WHERE id = ${id}`)\n",
+			want:     []string{"js-sql-string-concat:1"},
+		},
+		{
+			name:     "javascript suppressed",
+			language: "javascript",
+			content:  "el.innerHTML = html; // sast:ignore\n",
+		},
+		{
+			name:     "rules of other languages do not apply",
+			language: "ruby",
+			content:  "eval(expr)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := findingKeys(uc.Scan(entities.FileInfo{Name: "file", Language: tt.language, Content: tt.content}))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchRegexLineRange(t *testing.T) {
+	rule := entities.SASTRule{ID: "multi", Languages: []string{"text"}, Message: "m", Severity: "low", Pattern: `(?s)BEGIN.*?END\n?`}
+	compiled, err := compileRule(rule)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := compiled.matchRegex("a\nBEGIN\nb\nEND\nc\nBEGIN END\n")
+	want := [][2]int{{2, 4}, {6, 6}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("matchRegex() = %v, want %v", got, want)
+	}
+}
+
+func TestValidateRule(t *testing.T) {
+	valid := entities.SASTRule{ID: "r", Languages: []string{"go"}, Message: "m", Severity: "high", Pattern: "x"}
+
+	tests := []struct {
+		name   string
+		modify func(r *entities.SASTRule)
+		err    string
+	}{
+		{name: "valid", modify: func(r *entities.SASTRule) {}},
+		{name: "missing id", modify: func(r *entities.SASTRule) { r.ID = "" }, err: "id is required"},
+		{name: "missing languages", modify: func(r *entities.SASTRule) { r.Languages = nil }, err: "languages are required"},
+		{name: "invalid severity", modify: func(r *entities.SASTRule) { r.Severity = "urgent" }, err: "invalid severity"},
+		{name: "invalid pattern", modify: func(r *entities.SASTRule) { r.Pattern = "(" }, err: "invalid pattern"},
+		{name: "invalid pattern_not", modify: func(r *entities.SASTRule) { r.PatternNot = "[" }, err: "invalid pattern_not"},
+		{
+			name: "pattern and ast",
+			modify: func(r *entities.SASTRule) {
+				r.AST = &entities.ASTPattern{Kind: "call", Callee: []string{"f"}}
+			},
+			err: "exactly one of pattern or ast",
+		},
+		{
+			name: "ast for another language",
+			modify: func(r *entities.SASTRule) {
+				r.Pattern, r.Languages = "", []string{"python"}
+				r.AST = &entities.ASTPattern{Kind: "call", Callee: []string{"f"}}
+			},
+			err: "only supported for go",
+		},
+		{
+			name: "call without callee",
+			modify: func(r *entities.SASTRule) {
+				r.Pattern, r.AST = "", &entities.ASTPattern{Kind: "call"}
+			},
+			err: "needs a callee",
+		},
+		{
+			name: "unknown arg_is",
+			modify: func(r *entities.SASTRule) {
+				r.Pattern, r.AST = "", &entities.ASTPattern{Kind: "call", Callee: []string{"f"}, ArgIs: "tainted"}
+			},
+			err: "arg_is must be",
+		},
+		{
+			name: "unknown kind",
+			modify: func(r *entities.SASTRule) {
+				r.Pattern, r.AST = "", &entities.ASTPattern{Kind: "import"}
+			},
+			err: "unknown ast kind",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := valid
+			tt.modify(&rule)
+			err := ValidateRule(rule)
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("ValidateRule() returned error: %v", err)
+			case tt.err != "" && (err == nil || !strings.Contains(err.Error(), tt.err)):
+				t.Errorf("ValidateRule() = %v, want error containing %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNewSASTUseCaseOverridesRules(t *testing.T) {
+	uc, err := NewSASTUseCase([]entities.SASTRule{
+		{ID: "a", Languages: []string{"go"}, Message: "first", Severity: "low", Pattern: "x"},
+		{ID: "b", Languages: []string{"go"}, Message: "second", Severity: "low", Pattern: "y"},
+		{ID: "a", Languages: []string{"go"}, Message: "override", Severity: "high", Pattern: "x"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := uc.Rules()
+	if len(rules) != 2 || rules[0].ID != "a" || rules[0].Message != "override" || rules[1].ID != "b" {
+		t.Errorf("Rules() = %+v, want a (override) followed by b", rules)
+	}
+}
+
+func findingKeys(findings []entities.RuleFinding) []string {
+	var keys []string
+	for _, finding := range findings {
+		keys = append(keys, finding.RuleID+":"+strconv.Itoa(finding.StartLine))
+	}
+	return keys
+}
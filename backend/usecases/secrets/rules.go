@@ -0,0 +1,130 @@
+package secrets
+
+import "reverse-engineering-backend/domain/entities"
+
+// BuiltinRules よく知られたトークン形式をもとにした既定の検出ルール
+// サービス固有の形式は十分に厳密なためエントロピーを確認しない。汎用的な代入は
+// MinEntropy でプレースホルダーを除く
+var BuiltinRules = []entities.SecretRule{
+	{
+		ID:          "private-key",
+		Description: "Private key block",
+		Pattern:     `-----BEGIN (?:RSA |DSA |EC |OPENSSH |PGP |ENCRYPTED )?PRIVATE KEY(?: BLOCK)?-----`,
+		Keywords:    []string{"PRIVATE KEY"},
+		Severity:    "critical",
+	},
+	{
+		ID:          "aws-access-key-id",
+		Description: "AWS access key ID",
+		Pattern:     `\b((?:AKIA|ASIA|ABIA|ACCA)[A-Z0-9]{16})\b`,
+		SecretGroup: 1,
+		Keywords:    []string{"AKIA", "ASIA", "ABIA", "ACCA"},
+		Severity:    "high",
+	},
+	{
+		ID:          "aws-secret-access-key",
+		Description: "AWS secret access key",
+		Pattern:     `(?i)aws_?(?:secret)?_?(?:access)?_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`,
+		SecretGroup: 1,
+		Keywords:    []string{"aws"},
+		MinEntropy:  3.5,
+		Severity:    "critical",
+	},
+	{
+		ID:          "github-token",
+		Description: "GitHub token",
+		Pattern:     `\b((?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`,
+		SecretGroup: 1,
+		Keywords:    []string{"ghp_", "gho_", "ghu_", "ghs_", "ghr_", "github_pat_"},
+		Severity:    "critical",
+	},
+	{
+		ID:          "gitlab-token",
+		Description: "GitLab personal access token",
+		Pattern:     `\b(glpat-[A-Za-z0-9_-]{20})\b`,
+		SecretGroup: 1,
+		Keywords:    []string{"glpat-"},
+		Severity:    "critical",
+	},
+	{
+		ID:          "slack-token",
+		Description: "Slack token",
+		Pattern:     `\b(xox[baprs]-[0-9A-Za-z-]{10,72})\b`,
+		SecretGroup: 1,
+		Keywords:    []string{"xoxb-", "xoxa-", "xoxp-", "xoxr-", "xoxs-"},
+		Severity:    "high",
+	},
+	{
+		ID:          "slack-webhook",
+		Description: "Slack incoming webhook URL",
+		Pattern:     `https://hooks\.slack\.com/(?:services|workflows)/[A-Za-z0-9+/]{43,56}`,
+		Keywords:    []string{"hooks.slack.com"},
+		Severity:    "medium",
+	},
+	{
+		ID:          "stripe-key",
+		Description: "Stripe secret key",
+		Pattern:     `\b((?:sk|rk)_(?:live|test)_[0-9A-Za-z]{24,99})\b`,
+		SecretGroup: 1,
+		Keywords:    []string{"sk_live_", "sk_test_", "rk_live_", "rk_test_"},
+		Severity:    "critical",
+	},
+	{
+		ID:          "google-api-key",
+		Description: "Google API key",
+		Pattern:     `\b(AIza[0-9A-Za-z_-]{35})\b`,
+		SecretGroup: 1,
+		Keywords:    []string{"AIza"},
+		Severity:    "high",
+	},
+	{
+		ID:          "openai-api-key",
+		Description: "OpenAI API key",
+		Pattern:     `\b(sk-(?:proj-|svcacct-|admin-)?[A-Za-z0-9_-]{20,}T3BlbkFJ[A-Za-z0-9_-]{20,}|sk-[A-Za-z0-9]{48})\b`,
+		SecretGroup: 1,
+		Keywords:    []string{"sk-"},
+		Severity:    "critical",
+	},
+	{
+		ID:          "sendgrid-api-key",
+		Description: "SendGrid API key",
+		Pattern:     `\b(SG\.[A-Za-z0-9_-]{22}\.[A-Za-z0-9_-]{43})\b`,
+		SecretGroup: 1,
+		Keywords:    []string{"SG."},
+		Severity:    "high",
+	},
+	{
+		ID:          "npm-token",
+		Description: "npm access token",
+		Pattern:     `\b(npm_[A-Za-z0-9]{36})\b`,
+		SecretGroup: 1,
+		Keywords:    []string{"npm_"},
+		Severity:    "high",
+	},
+	{
+		ID:          "jwt",
+		Description: "JSON Web Token",
+		Pattern:     `\b(eyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})\b`,
+		SecretGroup: 1,
+		Keywords:    []string{"eyJ"},
+		Severity:    "medium",
+	},
+	{
+		ID:          "connection-string-password",
+		Description: "Password embedded in a connection URL",
+		Pattern:     `\b[a-z][a-z0-9+.-]{1,20}://[^\s:/@"']+:([^\s:/@"']{4,})@[^\s/"']+`,
+		SecretGroup: 1,
+		Keywords:    []string{"://"},
+		MinEntropy:  3.0,
+		Severity:    "high",
+	},
+	{
+		ID:          "generic-secret-assignment",
+		Description: "Hard-coded secret assigned to a credential-like variable",
+		Pattern:     `(?i)\b[\w.-]*(?:password|passwd|pwd|secret|api_?key|access_?key|auth_?token|access_?token|client_?secret|private_?key)[\w.-]*["']?\s*(?::=|=>|[:=])\s*["']([^"'\s]{8,})["']`,
+		SecretGroup: 1,
+		Keywords:    []string{"pass", "pwd", "secret", "key", "token"},
+		MinEntropy:  3.0,
+		Severity:    "medium",
+	},
+}
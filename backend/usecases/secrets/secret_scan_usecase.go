@@ -0,0 +1,280 @@
+package secrets
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// シークレットを含むファイルがアップロードされたときの扱い
+const (
+	// PolicyRecord ファイルはそのまま保存し、検出結果だけを記録する
+	PolicyRecord = "record"
+	// PolicyRedact シークレットをマーカーに置き換えて保存する
+	PolicyRedact = "redact"
+	// PolicyQuarantine ファイルをデータベースにも解析にも含めない
+	PolicyQuarantine = "quarantine"
+	// PolicyBlock ファイルを拒否する
+	PolicyBlock = "block"
+)
+
+// IsValidPolicy 対応しているポリシーかどうかを判定
+func IsValidPolicy(policy string) bool {
+	switch policy {
+	case PolicyRecord, PolicyRedact, PolicyQuarantine, PolicyBlock:
+		return true
+	}
+	return false
+}
+
+// placeholderMarkers 実際のシークレットではなく、ドキュメントやテンプレートの値を示す文字列
+var placeholderMarkers = []string{
+	"example", "sample", "dummy", "placeholder", "changeme", "change_me", "your_", "your-",
+	"xxxxxx", "******", "${", "{{", "<", "process.env", "os.getenv", "getenv(", "env[",
+}
+
+type compiledRule struct {
+	entities.SecretRule
+	re       *regexp.Regexp
+	keywords []string
+}
+
+// SecretScanUseCase 正規表現ルールとエントロピーでハードコードされた認証情報を検出する
+type SecretScanUseCase struct {
+	rules []compiledRule
+}
+
+// NewSecretScanUseCase 組み込みルールとその後にカスタムルールを持つスキャナーを作成
+// 組み込みルールと同じ ID のカスタムルールはそれを置き換える
+func NewSecretScanUseCase(custom []entities.SecretRule) (*SecretScanUseCase, error) {
+	overridden := make(map[string]bool)
+	for _, rule := range custom {
+		overridden[rule.ID] = true
+	}
+
+	var rules []entities.SecretRule
+	for _, rule := range BuiltinRules {
+		if !overridden[rule.ID] {
+			rules = append(rules, rule)
+		}
+	}
+	rules = append(rules, custom...)
+
+	uc := &SecretScanUseCase{}
+	for _, rule := range rules {
+		compiled, err := compileRule(rule)
+		if err != nil {
+			return nil, err
+		}
+		uc.rules = append(uc.rules, compiled)
+	}
+	return uc, nil
+}
+
+// ValidateRule カスタムルールをコンパイルできるかを判定
+func ValidateRule(rule entities.SecretRule) error {
+	_, err := compileRule(rule)
+	return err
+}
+
+func compileRule(rule entities.SecretRule) (compiledRule, error) {
+	if rule.ID == "" {
+		return compiledRule{}, fmt.Errorf("secret rule id is required")
+	}
+	re, err := regexp.Compile(rule.Pattern)
+	if err != nil {
+		return compiledRule{}, fmt.Errorf("invalid pattern for secret rule %s: %w", rule.ID, err)
+	}
+	if rule.SecretGroup < 0 || rule.SecretGroup > re.NumSubexp() {
+		return compiledRule{}, fmt.Errorf("secret rule %s: secret_group %d does not exist in pattern", rule.ID, rule.SecretGroup)
+	}
+	if rule.Severity == "" {
+		rule.Severity = "high"
+	}
+
+	keywords := make([]string, 0, len(rule.Keywords))
+	for _, keyword := range rule.Keywords {
+		keywords = append(keywords, strings.ToLower(keyword))
+	}
+	return compiledRule{SecretRule: rule, re: re, keywords: keywords}, nil
+}
+
+// Execute すべてのファイルをスキャンし、検出結果を返す
+func (uc *SecretScanUseCase) Execute(ctx context.Context, files []entities.FileInfo) ([]entities.SecretFinding, error) {
+	findings := []entities.SecretFinding{}
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		findings = append(findings, uc.Scan(file.Name, file.Content)...)
+	}
+	return findings, nil
+}
+
+// Scan 1 ファイルで見つかったシークレットを返す
+// 同じ範囲に複数のルールがマッチした場合は最初の（最も具体的な）ルールだけを報告する
+func (uc *SecretScanUseCase) Scan(path, content string) []entities.SecretFinding {
+	var findings []entities.SecretFinding
+
+	for lineNo, line := range strings.Split(content, "\n") {
+		lower := strings.ToLower(line)
+		var spans [][2]int
+
+		for _, rule := range uc.rules {
+			if !rule.hasKeyword(lower) {
+				continue
+			}
+			for _, match := range rule.re.FindAllStringSubmatchIndex(line, -1) {
+				start, end := match[0], match[1]
+				if rule.SecretGroup > 0 {
+					start, end = match[2*rule.SecretGroup], match[2*rule.SecretGroup+1]
+				}
+				// 空文字列にマッチするカスタムルールもあるため、空のシークレットは報告しない
+				if start < 0 || start == end || overlaps(spans, start, end) {
+					continue
+				}
+
+				secret := line[start:end]
+				entropy := ShannonEntropy(secret)
+				if rule.MinEntropy > 0 && entropy < rule.MinEntropy {
+					continue
+				}
+				if rule.ID != "private-key" && isPlaceholder(secret) {
+					continue
+				}
+
+				spans = append(spans, [2]int{start, end})
+				findings = append(findings, entities.SecretFinding{
+					RuleID:      rule.ID,
+					Description: rule.Description,
+					Severity:    rule.Severity,
+					Path:        path,
+					Line:        lineNo + 1,
+					Column:      start + 1,
+					EndColumn:   end + 1,
+					Redacted:    Redact(secret),
+					Entropy:     math.Round(entropy*100) / 100,
+					Fingerprint: Fingerprint(rule.ID, secret),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+func (r compiledRule) hasKeyword(lowerLine string) bool {
+	if len(r.keywords) == 0 {
+		return true
+	}
+	for _, keyword := range r.keywords {
+		if strings.Contains(lowerLine, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactContent 報告されたシークレットをルール名入りのマーカーに置き換える
+func RedactContent(content string, findings []entities.SecretFinding) string {
+	byLine := make(map[int][]entities.SecretFinding)
+	for _, finding := range findings {
+		byLine[finding.Line] = append(byLine[finding.Line], finding)
+	}
+	if len(byLine) == 0 {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	for lineNo, lineFindings := range byLine {
+		if lineNo < 1 || lineNo > len(lines) {
+			continue
+		}
+		// 後ろから置換して前方の列位置を保つ
+		sort.Slice(lineFindings, func(i, j int) bool { return lineFindings[i].Column > lineFindings[j].Column })
+		line := lines[lineNo-1]
+		for _, finding := range lineFindings {
+			start, end := finding.Column-1, finding.EndColumn-1
+			if start < 0 || end > len(line) || start >= end {
+				continue
+			}
+			line = line[:start] + "[REDACTED:" + finding.RuleID + "]" + line[end:]
+		}
+		lines[lineNo-1] = line
+	}
+
+	// 秘密鍵は BEGIN 行だけでなく END 行までの本文もすべて取り除く
+	for _, finding := range findings {
+		if finding.RuleID != "private-key" {
+			continue
+		}
+		for i := finding.Line; i < len(lines); i++ {
+			end := strings.Contains(lines[i], "-----END ")
+			lines[i] = ""
+			if end {
+				break
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ShannonEntropy s の 1 文字あたりのエントロピー（ビット）を返す
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+	var entropy float64
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// Redact シークレットを露出せずに確認できるよう、短い先頭部分だけを残す
+func Redact(secret string) string {
+	if len(secret) < 12 {
+		return "********"
+	}
+	return secret[:4] + "********"
+}
+
+// Fingerprint シークレットを保存せずに、ファイルやスキャンをまたいで識別する
+func Fingerprint(ruleID, secret string) string {
+	sum := sha256.Sum256([]byte(ruleID + ":" + secret))
+	return hex.EncodeToString(sum[:16])
+}
+
+func overlaps(spans [][2]int, start, end int) bool {
+	for _, span := range spans {
+		if start < span[1] && span[0] < end {
+			return true
+		}
+	}
+	return false
+}
+
+func isPlaceholder(secret string) bool {
+	lower := strings.ToLower(secret)
+	for _, marker := range placeholderMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	// 同じ文字の繰り返し（"aaaaaaaa" など）は実際の値ではない
+	return strings.Count(lower, lower[:1]) == len(lower)
+}
@@ -0,0 +1,121 @@
+package vulnerability
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss3BaseScore CVSS v3.0/v3.1 ベクター（"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H" など）の
+// 基本値を計算
+func cvss3BaseScore(vector string) (float64, error) {
+	if !strings.HasPrefix(vector, "CVSS:3.") {
+		return 0, fmt.Errorf("not a CVSS v3 vector: %q", vector)
+	}
+
+	metrics := make(map[string]string)
+	for _, part := range strings.Split(vector, "/")[1:] {
+		if key, value, ok := strings.Cut(part, ":"); ok {
+			metrics[key] = value
+		}
+	}
+
+	weight := func(metric string) (float64, error) {
+		w, ok := cvss3Weights[metric][metrics[metric]]
+		if !ok {
+			return 0, fmt.Errorf("missing or invalid CVSS metric %s in %q", metric, vector)
+		}
+		return w, nil
+	}
+
+	scopeChanged := metrics["S"] == "C"
+	var pr float64
+	switch metrics["PR"] {
+	case "N":
+		pr = 0.85
+	case "L":
+		pr = 0.62
+		if scopeChanged {
+			pr = 0.68
+		}
+	case "H":
+		pr = 0.27
+		if scopeChanged {
+			pr = 0.5
+		}
+	default:
+		return 0, fmt.Errorf("missing or invalid CVSS metric PR in %q", vector)
+	}
+
+	values := make(map[string]float64)
+	for _, metric := range []string{"AV", "AC", "UI", "C", "I", "A"} {
+		w, err := weight(metric)
+		if err != nil {
+			return 0, err
+		}
+		values[metric] = w
+	}
+
+	iss := 1 - (1-values["C"])*(1-values["I"])*(1-values["A"])
+	var impact float64
+	if scopeChanged {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+	if impact <= 0 {
+		return 0, nil
+	}
+
+	exploitability := 8.22 * values["AV"] * values["AC"] * pr * values["UI"]
+	if scopeChanged {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), nil
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), nil
+}
+
+// roundUp CVSS v3.1 仕様の "Roundup" 関数
+func roundUp(value float64) float64 {
+	scaled := int(math.Round(value * 100000))
+	if scaled%10000 == 0 {
+		return float64(scaled) / 100000
+	}
+	return float64(scaled/10000+1) / 10
+}
+
+// severityFromScore CVSS スコアを定性的な深刻度に変換
+func severityFromScore(score float64) string {
+	switch {
+	case score >= 9.0:
+		return "critical"
+	case score >= 7.0:
+		return "high"
+	case score >= 4.0:
+		return "medium"
+	}
+	return "low"
+}
+
+// normalizeSeverity エコシステム固有の評価（GHSA の "MODERATE" など）を Issue の深刻度に変換
+func normalizeSeverity(rating string) string {
+	switch strings.ToLower(strings.TrimSpace(rating)) {
+	case "critical":
+		return "critical"
+	case "high", "important":
+		return "high"
+	case "moderate", "medium":
+		return "medium"
+	case "low", "negligible":
+		return "low"
+	}
+	return ""
+}
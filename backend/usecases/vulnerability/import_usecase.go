@@ -0,0 +1,71 @@
+package vulnerability
+
+import (
+	"context"
+	"fmt"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/domain/repositories"
+)
+
+const importBatchSize = 500
+
+// OSVImportStats インポート結果の集計
+type OSVImportStats struct {
+	Read     int `json:"read"`
+	Imported int `json:"imported"`
+	// Skipped は対応エコシステムの影響情報を持たないレコード
+	Skipped int `json:"skipped"`
+}
+
+// OSVImportUseCase ディスク上の OSV ダンプをローカルのアドバイザリーデータベースに取り込む
+type OSVImportUseCase struct {
+	advisoryRepo repositories.AdvisoryRepository
+}
+
+// NewOSVImportUseCase OSV インポートのユースケースを作成
+func NewOSVImportUseCase(advisoryRepo repositories.AdvisoryRepository) *OSVImportUseCase {
+	return &OSVImportUseCase{
+		advisoryRepo: advisoryRepo,
+	}
+}
+
+// Execute path 以下のすべてのレコードを取り込み、同じ ID のアドバイザリーを置き換える
+func (uc *OSVImportUseCase) Execute(ctx context.Context, path string) (*OSVImportStats, error) {
+	stats := &OSVImportStats{}
+	batch := make([]entities.Advisory, 0, importBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := uc.advisoryRepo.SaveAdvisories(ctx, batch); err != nil {
+			return fmt.Errorf("failed to save advisories: %w", err)
+		}
+		stats.Imported += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	err := ReadOSVDump(path, func(record *OSVRecord) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		stats.Read++
+
+		advisory := record.ToAdvisory()
+		if len(advisory.Affected) == 0 {
+			stats.Skipped++
+			return nil
+		}
+		batch = append(batch, advisory)
+		if len(batch) >= importBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+	return stats, flush()
+}
@@ -0,0 +1,252 @@
+package vulnerability
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/domain/repositories"
+	"reverse-engineering-backend/usecases/dependency"
+)
+
+// VulnerabilityScanResult 依存関係の一覧をローカルデータベースと照合した結果
+type VulnerabilityScanResult struct {
+	Findings []entities.VulnerabilityFinding `json:"findings"`
+	Scanned  int                             `json:"scanned"`
+	// Unresolved は範囲指定のみでバージョンが確定しておらず照合できなかった依存
+	Unresolved []entities.Dependency `json:"unresolved"`
+}
+
+// VulnerabilityMatchUseCase 依存関係をローカルに保存したアドバイザリーと照合する
+// ネットワークにはアクセスしない。データベースは OSVImportUseCase で登録する
+type VulnerabilityMatchUseCase struct {
+	advisoryRepo repositories.AdvisoryRepository
+}
+
+// NewVulnerabilityMatchUseCase 脆弱性照合のユースケースを作成
+func NewVulnerabilityMatchUseCase(advisoryRepo repositories.AdvisoryRepository) *VulnerabilityMatchUseCase {
+	return &VulnerabilityMatchUseCase{
+		advisoryRepo: advisoryRepo,
+	}
+}
+
+// Execute バージョンが固定された依存関係ごとに、影響するアドバイザリーを報告
+func (uc *VulnerabilityMatchUseCase) Execute(ctx context.Context, deps []entities.Dependency) (*VulnerabilityScanResult, error) {
+	result := &VulnerabilityScanResult{
+		Findings:   []entities.VulnerabilityFinding{},
+		Unresolved: []entities.Dependency{},
+	}
+
+	for _, dep := range deps {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !SupportedEcosystems[dep.Ecosystem] {
+			continue
+		}
+		if !dependency.IsPinnedVersion(dep.Version) {
+			result.Unresolved = append(result.Unresolved, dep)
+			continue
+		}
+
+		advisories, err := uc.advisoryRepo.FindByPackage(ctx, dep.Ecosystem, dependency.NormalizePackageName(dep.Ecosystem, dep.Name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up advisories for %s: %w", dep.Name, err)
+		}
+		result.Scanned++
+		result.Findings = append(result.Findings, Match(dep, advisories)...)
+	}
+
+	sort.SliceStable(result.Findings, func(i, j int) bool {
+		return severityRank(result.Findings[i].Severity) > severityRank(result.Findings[j].Severity)
+	})
+	return result, nil
+}
+
+// Match 影響範囲に依存関係のバージョンが含まれるアドバイザリーを返す
+func Match(dep entities.Dependency, advisories []entities.Advisory) []entities.VulnerabilityFinding {
+	var findings []entities.VulnerabilityFinding
+	name := dependency.NormalizePackageName(dep.Ecosystem, dep.Name)
+
+	for _, advisory := range advisories {
+		affected := false
+		var fixed []string
+		for _, pkg := range advisory.Affected {
+			if pkg.Ecosystem != dep.Ecosystem || dependency.NormalizePackageName(pkg.Ecosystem, pkg.Name) != name {
+				continue
+			}
+			if hit, fixes := affects(dep.Ecosystem, dep.Version, pkg); hit {
+				affected = true
+				fixed = append(fixed, fixes...)
+			}
+		}
+		if !affected {
+			continue
+		}
+
+		findings = append(findings, entities.VulnerabilityFinding{
+			Dependency:    dep,
+			AdvisoryID:    advisory.ID,
+			Aliases:       advisory.Aliases,
+			Summary:       advisory.Summary,
+			Severity:      advisory.Severity,
+			CVSSScore:     advisory.CVSSScore,
+			FixedVersions: uniqueSortedVersions(dep.Ecosystem, fixed),
+		})
+	}
+	return findings
+}
+
+// affects パッケージの OSV 範囲と明示されたバージョンを評価する
+// 依存関係より新しい修正バージョンをアップグレードの候補として返す
+func affects(ecosystem, version string, pkg entities.AffectedPackage) (bool, []string) {
+	for _, listed := range pkg.Versions {
+		if c, err := CompareVersions(ecosystem, version, listed); err == nil && c == 0 {
+			return true, fixedAfter(ecosystem, version, pkg.Ranges)
+		}
+	}
+
+	hit := false
+	var fixed []string
+	for _, rng := range pkg.Ranges {
+		affected, fix, err := inRange(ecosystem, version, rng)
+		if err != nil || !affected {
+			continue
+		}
+		hit = true
+		if fix != "" {
+			fixed = append(fixed, fix)
+		}
+	}
+	return hit, fixed
+}
+
+// inRange OSV の範囲イベントをバージョン順に評価する。バージョン以下の "introduced" で
+// 影響ありとし、その後のバージョン以下の "fixed" または "last_affected" で解除する
+// 影響がある場合は、その区間を閉じる "fixed" イベントがあれば合わせて返す
+func inRange(ecosystem, version string, rng entities.AffectedRange) (bool, string, error) {
+	type event struct {
+		kind    string
+		version string
+	}
+	var events []event
+	for _, e := range rng.Events {
+		switch {
+		case e.Introduced != "":
+			events = append(events, event{"introduced", e.Introduced})
+		case e.Fixed != "":
+			events = append(events, event{"fixed", e.Fixed})
+		case e.LastAffected != "":
+			events = append(events, event{"last_affected", e.LastAffected})
+		}
+	}
+
+	compare := func(a, b string) (int, error) {
+		if rng.Type == "SEMVER" {
+			return dependency.CompareSemver(a, b), nil
+		}
+		return CompareVersions(ecosystem, a, b)
+	}
+
+	var sortErr error
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].version == "0" || events[j].version == "0" {
+			return events[i].version == "0" && events[j].version != "0"
+		}
+		c, err := compare(events[i].version, events[j].version)
+		if err != nil {
+			sortErr = err
+		}
+		return c < 0
+	})
+	if sortErr != nil {
+		return false, "", sortErr
+	}
+
+	affected := false
+	for _, e := range events {
+		if e.kind == "introduced" && e.version == "0" {
+			affected = true
+			continue
+		}
+		c, err := compare(version, e.version)
+		if err != nil {
+			return false, "", err
+		}
+		if c < 0 {
+			// ここから先のイベントはすべて対象バージョンより新しいため、状態は確定している
+			if affected && e.kind == "fixed" {
+				return true, e.version, nil
+			}
+			return affected, "", nil
+		}
+		switch e.kind {
+		case "introduced":
+			affected = true
+		case "fixed":
+			affected = false
+		case "last_affected":
+			if c > 0 {
+				affected = false
+			}
+		}
+	}
+	return affected, "", nil
+}
+
+func fixedAfter(ecosystem, version string, ranges []entities.AffectedRange) []string {
+	var fixed []string
+	for _, rng := range ranges {
+		for _, e := range rng.Events {
+			if e.Fixed == "" {
+				continue
+			}
+			if c, err := CompareVersions(ecosystem, e.Fixed, version); err == nil && c > 0 {
+				fixed = append(fixed, e.Fixed)
+			}
+		}
+	}
+	return fixed
+}
+
+// CompareVersions エコシステムの規則で 2 つのバージョンを比較
+func CompareVersions(ecosystem, a, b string) (int, error) {
+	switch ecosystem {
+	case "PyPI":
+		return comparePEP440(a, b)
+	case "Maven":
+		return compareMaven(a, b), nil
+	}
+	return dependency.CompareSemver(a, b), nil
+}
+
+func uniqueSortedVersions(ecosystem string, versions []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, v := range versions {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		c, _ := CompareVersions(ecosystem, result[i], result[j])
+		return c < 0
+	})
+	return result
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case "critical":
+		return 4
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	}
+	return 0
+}
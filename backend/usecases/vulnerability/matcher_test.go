@@ -0,0 +1,185 @@
+package vulnerability
+
+import (
+	"reflect"
+	"testing"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+func TestInRange(t *testing.T) {
+	introduced := func(v string) entities.RangeEvent { return entities.RangeEvent{Introduced: v} }
+	fixed := func(v string) entities.RangeEvent { return entities.RangeEvent{Fixed: v} }
+	lastAffected := func(v string) entities.RangeEvent { return entities.RangeEvent{LastAffected: v} }
+
+	tests := []struct {
+		name      string
+		ecosystem string
+		rng       entities.AffectedRange
+		version   string
+		affected  bool
+		fix       string
+	}{
+		{
+			name:    "introduced zero before fix",
+			rng:     entities.AffectedRange{Type: "SEMVER", Events: []entities.RangeEvent{introduced("0"), fixed("1.2.3")}},
+			version: "1.2.2", affected: true, fix: "1.2.3",
+		},
+		{
+			name:    "fixed version is not affected",
+			rng:     entities.AffectedRange{Type: "SEMVER", Events: []entities.RangeEvent{introduced("0"), fixed("1.2.3")}},
+			version: "1.2.3",
+		},
+		{
+			name:    "before introduced",
+			rng:     entities.AffectedRange{Type: "SEMVER", Events: []entities.RangeEvent{introduced("1.0.0"), fixed("1.5.0")}},
+			version: "0.9.0",
+		},
+		{
+			name:    "introduced version is affected",
+			rng:     entities.AffectedRange{Type: "SEMVER", Events: []entities.RangeEvent{introduced("1.0.0"), fixed("1.5.0")}},
+			version: "1.0.0", affected: true, fix: "1.5.0",
+		},
+		{
+			name:    "no fix",
+			rng:     entities.AffectedRange{Type: "SEMVER", Events: []entities.RangeEvent{introduced("2.0.0")}},
+			version: "3.1.0", affected: true,
+		},
+		{
+			name:    "last affected is inclusive",
+			rng:     entities.AffectedRange{Type: "SEMVER", Events: []entities.RangeEvent{introduced("1.0.0"), lastAffected("1.4.0")}},
+			version: "1.4.0", affected: true,
+		},
+		{
+			name:    "after last affected",
+			rng:     entities.AffectedRange{Type: "SEMVER", Events: []entities.RangeEvent{introduced("1.0.0"), lastAffected("1.4.0")}},
+			version: "1.4.1",
+		},
+		{
+			name: "second interval",
+			rng: entities.AffectedRange{Type: "SEMVER", Events: []entities.RangeEvent{
+				introduced("1.0.0"), fixed("1.1.0"), introduced("2.0.0"), fixed("2.3.0"),
+			}},
+			version: "2.1.0", affected: true, fix: "2.3.0",
+		},
+		{
+			name: "between intervals",
+			rng: entities.AffectedRange{Type: "SEMVER", Events: []entities.RangeEvent{
+				introduced("1.0.0"), fixed("1.1.0"), introduced("2.0.0"), fixed("2.3.0"),
+			}},
+			version: "1.5.0",
+		},
+		{
+			name: "unordered events",
+			rng: entities.AffectedRange{Type: "SEMVER", Events: []entities.RangeEvent{
+				fixed("2.3.0"), introduced("2.0.0"), fixed("1.1.0"), introduced("1.0.0"),
+			}},
+			version: "1.0.5", affected: true, fix: "1.1.0",
+		},
+		{
+			name:      "PyPI pre-release before fix",
+			ecosystem: "PyPI",
+			rng:       entities.AffectedRange{Type: "ECOSYSTEM", Events: []entities.RangeEvent{introduced("0"), fixed("2.0")}},
+			version:   "2.0rc1", affected: true, fix: "2.0",
+		},
+		{
+			name:      "PyPI post-release after fix",
+			ecosystem: "PyPI",
+			rng:       entities.AffectedRange{Type: "ECOSYSTEM", Events: []entities.RangeEvent{introduced("0"), fixed("2.0")}},
+			version:   "2.0.post1",
+		},
+		{
+			name:      "Maven milestone before fix",
+			ecosystem: "Maven",
+			rng:       entities.AffectedRange{Type: "ECOSYSTEM", Events: []entities.RangeEvent{introduced("5.0.0"), fixed("5.3.18")}},
+			version:   "5.3.18-M1", affected: true, fix: "5.3.18",
+		},
+		{
+			name:      "Maven release qualifier is the release",
+			ecosystem: "Maven",
+			rng:       entities.AffectedRange{Type: "ECOSYSTEM", Events: []entities.RangeEvent{introduced("5.0.0"), fixed("5.3.18")}},
+			version:   "5.3.18.RELEASE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ecosystem := tt.ecosystem
+			if ecosystem == "" {
+				ecosystem = "npm"
+			}
+			affected, fix, err := inRange(ecosystem, tt.version, tt.rng)
+			if err != nil {
+				t.Fatalf("inRange returned error: %v", err)
+			}
+			if affected != tt.affected || fix != tt.fix {
+				t.Errorf("inRange(%q) = (%v, %q), want (%v, %q)", tt.version, affected, fix, tt.affected, tt.fix)
+			}
+		})
+	}
+}
+
+func TestInRangeInvalidVersion(t *testing.T) {
+	rng := entities.AffectedRange{Type: "ECOSYSTEM", Events: []entities.RangeEvent{{Introduced: "0"}, {Fixed: "2.0"}}}
+	if _, _, err := inRange("PyPI", "not a version", rng); err == nil {
+		t.Error("inRange returned no error for an invalid PyPI version")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	advisories := []entities.Advisory{
+		{
+			ID:       "GHSA-1",
+			Severity: "high",
+			Affected: []entities.AffectedPackage{{
+				Ecosystem: "PyPI",
+				Name:      "DjangoRestFramework",
+				Ranges: []entities.AffectedRange{
+					{Type: "ECOSYSTEM", Events: []entities.RangeEvent{{Introduced: "0"}, {Fixed: "3.11.2"}}},
+					{Type: "ECOSYSTEM", Events: []entities.RangeEvent{{Introduced: "3.12.0"}, {Fixed: "3.12.4"}}},
+				},
+			}},
+		},
+		{
+			ID:       "GHSA-2",
+			Severity: "low",
+			Affected: []entities.AffectedPackage{{
+				Ecosystem: "PyPI",
+				Name:      "djangorestframework",
+				Versions:  []string{"3.12.1"},
+				Ranges: []entities.AffectedRange{
+					{Type: "ECOSYSTEM", Events: []entities.RangeEvent{{Introduced: "3.12.1"}, {Fixed: "3.12.2"}}},
+				},
+			}},
+		},
+		{
+			ID: "GHSA-3",
+			Affected: []entities.AffectedPackage{{
+				Ecosystem: "npm",
+				Name:      "djangorestframework",
+				Ranges:    []entities.AffectedRange{{Type: "SEMVER", Events: []entities.RangeEvent{{Introduced: "0"}}}},
+			}},
+		},
+	}
+
+	tests := []struct {
+		version string
+		want    map[string][]string // アドバイザリ ID → 修正バージョン
+	}{
+		{"3.10.0", map[string][]string{"GHSA-1": {"3.11.2"}}},
+		{"3.11.2", map[string][]string{}},
+		{"3.12.1", map[string][]string{"GHSA-1": {"3.12.4"}, "GHSA-2": {"3.12.2"}}},
+		{"3.12.4", map[string][]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			dep := entities.Dependency{Ecosystem: "PyPI", Name: "djangorestframework", Version: tt.version}
+			got := make(map[string][]string)
+			for _, finding := range Match(dep, advisories) {
+				got[finding.AdvisoryID] = finding.FixedVersions
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Match(%s) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package vulnerability
+
+import (
+	"math/big"
+	"strings"
+)
+
+// mavenQualifiers Maven の ComparableVersion が知っている修飾子の順序
+// 未知の修飾子はこれらすべての後に辞書順で並ぶ
+var mavenQualifiers = map[string]int{
+	"alpha":     0,
+	"a":         0,
+	"beta":      1,
+	"b":         1,
+	"milestone": 2,
+	"m":         2,
+	"rc":        3,
+	"cr":        3,
+	"snapshot":  4,
+	"":          5,
+	"ga":        5,
+	"final":     5,
+	"release":   5,
+	"sp":        6,
+}
+
+// mavenItem Maven バージョンの 1 トークン
+type mavenItem struct {
+	number    *big.Int
+	qualifier string
+}
+
+// parseMavenVersion バージョンを '.'、'-'、数字と英字の境界で数値と修飾子のトークンに分割
+// '-' で区切られた各グループ末尾の null 値（"0"、"ga"、"final"）は除くため、
+// "1.0.0" == "1"、"1.0-alpha" == "1-alpha" となる
+func parseMavenVersion(v string) []mavenItem {
+	v = strings.ToLower(strings.TrimSpace(v))
+	var items []mavenItem
+	groupStart := 0
+
+	trimGroup := func() {
+		for len(items) > groupStart+1 && items[len(items)-1].isNull() {
+			items = items[:len(items)-1]
+		}
+	}
+
+	var current strings.Builder
+	flush := func(startsGroup bool) {
+		token := current.String()
+		current.Reset()
+		item := mavenItem{}
+		if token != "" && isDigit(token[0]) {
+			item.number, _ = new(big.Int).SetString(token, 10)
+		} else {
+			item.qualifier = token
+		}
+		items = append(items, item)
+		if startsGroup {
+			trimGroup()
+			groupStart = len(items)
+		}
+	}
+
+	for i := 0; i < len(v); i++ {
+		ch := v[i]
+		switch {
+		case ch == '.':
+			flush(false)
+		case ch == '-' || ch == '_':
+			flush(true)
+		case current.Len() > 0 && isDigit(ch) != isDigit(v[i-1]):
+			// 数字と文字の境界は "-" として扱う（1.0alpha1 == 1.0-alpha-1）
+			flush(true)
+			current.WriteByte(ch)
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	flush(false)
+	trimGroup()
+
+	return items
+}
+
+func (i mavenItem) isNull() bool {
+	if i.number != nil {
+		return i.number.Sign() == 0
+	}
+	switch i.qualifier {
+	case "", "ga", "final", "release":
+		return true
+	}
+	return false
+}
+
+// compareMaven Maven の ComparableVersion の規則でバージョンを比較
+func compareMaven(a, b string) int {
+	ia := parseMavenVersion(a)
+	ib := parseMavenVersion(b)
+	for i := 0; i < len(ia) || i < len(ib); i++ {
+		var x, y *mavenItem
+		if i < len(ia) {
+			x = &ia[i]
+		}
+		if i < len(ib) {
+			y = &ib[i]
+		}
+		if c := compareMavenItems(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareMavenItems 2 つのトークンを比較。存在しないトークンは修飾子との比較では ""（リリース）、
+// 数値との比較では 0 として扱う
+func compareMavenItems(x, y *mavenItem) int {
+	switch {
+	case x == nil && y == nil:
+		return 0
+	case x == nil:
+		return -compareMavenItems(y, nil)
+	}
+
+	if y == nil {
+		if x.number != nil {
+			return x.number.Sign()
+		}
+		return compareQualifiers(x.qualifier, "")
+	}
+
+	switch {
+	case x.number != nil && y.number != nil:
+		return x.number.Cmp(y.number)
+	case x.number != nil:
+		// 数値は常に修飾子より新しい（1.1 > 1-sp）
+		return 1
+	case y.number != nil:
+		return -1
+	}
+	return compareQualifiers(x.qualifier, y.qualifier)
+}
+
+func compareQualifiers(a, b string) int {
+	ra, knownA := mavenQualifiers[a]
+	rb, knownB := mavenQualifiers[b]
+	switch {
+	case knownA && knownB:
+		return compareInt(ra, rb)
+	case knownA:
+		return -1
+	case knownB:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(ch byte) bool {
+	return ch >= '0' && ch <= '9'
+}
@@ -0,0 +1,41 @@
+package vulnerability
+
+import "testing"
+
+func TestCompareMaven(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1", "1.0.0", 0},
+		{"1.0-alpha", "1-alpha", 0},
+		{"1.0alpha1", "1.0-alpha-1", 0},
+		{"1.0-ga", "1.0", 0},
+		{"1.0-FINAL", "1.0", 0},
+		{"1.0", "1.1", -1},
+		{"1.10", "1.9", 1},
+		{"1.0-alpha", "1.0-beta", -1},
+		{"1.0-a1", "1.0-alpha1", 0},
+		{"1.0-beta", "1.0-milestone", -1},
+		{"1.0-m1", "1.0-rc1", -1},
+		{"1.0-cr1", "1.0-rc1", 0},
+		{"1.0-rc1", "1.0-SNAPSHOT", -1},
+		{"1.0-SNAPSHOT", "1.0", -1},
+		{"1.0", "1.0-sp1", -1},
+		{"1.0-sp1", "1.1", -1},
+		{"1.0-sp", "1.0-foo", -1},
+		{"1.0-bar", "1.0-foo", -1},
+		{"2.0.0.RELEASE", "2.0.0", 0},
+		{"12345678901234567890", "12345678901234567891", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := compareMaven(tt.a, tt.b); got != tt.want {
+				t.Errorf("compareMaven(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if got := compareMaven(tt.b, tt.a); got != -tt.want {
+				t.Errorf("compareMaven(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+			}
+		})
+	}
+}
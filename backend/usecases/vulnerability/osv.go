@@ -0,0 +1,250 @@
+package vulnerability
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// SupportedEcosystems 依存関係の一覧が生成する OSV のエコシステム
+var SupportedEcosystems = map[string]bool{
+	"Go":        true,
+	"npm":       true,
+	"PyPI":      true,
+	"Maven":     true,
+	"crates.io": true,
+	"Packagist": true,
+}
+
+// OSVRecord OSV スキーマ（https://ossf.github.io/osv-schema/）の脆弱性
+type OSVRecord struct {
+	ID        string        `json:"id"`
+	Modified  time.Time     `json:"modified"`
+	Published time.Time     `json:"published"`
+	Withdrawn *time.Time    `json:"withdrawn,omitempty"`
+	Aliases   []string      `json:"aliases"`
+	Summary   string        `json:"summary"`
+	Details   string        `json:"details"`
+	Severity  []osvSeverity `json:"severity"`
+	Affected  []osvAffected `json:"affected"`
+
+	DatabaseSpecific struct {
+		Severity string `json:"severity"`
+	} `json:"database_specific"`
+}
+
+type osvSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Severity []osvSeverity            `json:"severity"`
+	Ranges   []entities.AffectedRange `json:"ranges"`
+	Versions []string                 `json:"versions"`
+}
+
+// ToAdvisory レコードをアドバイザリーに変換する。対応しているエコシステムだけを残し、
+// 深刻度は CVSS ベクターまたはデータベース固有の評価から決める
+func (r *OSVRecord) ToAdvisory() entities.Advisory {
+	advisory := entities.Advisory{
+		ID:        r.ID,
+		Summary:   r.Summary,
+		Details:   r.Details,
+		Aliases:   r.Aliases,
+		Published: r.Published,
+		Modified:  r.Modified,
+	}
+	if advisory.Summary == "" {
+		advisory.Summary = firstLine(r.Details)
+	}
+
+	severities := r.Severity
+	for _, affected := range r.Affected {
+		// "Debian:11" のようなリリース付きエコシステムは対象外
+		if !SupportedEcosystems[affected.Package.Ecosystem] {
+			continue
+		}
+		severities = append(severities, affected.Severity...)
+
+		var ranges []entities.AffectedRange
+		for _, rng := range affected.Ranges {
+			if rng.Type == "SEMVER" || rng.Type == "ECOSYSTEM" {
+				ranges = append(ranges, rng)
+			}
+		}
+		if len(ranges) == 0 && len(affected.Versions) == 0 {
+			continue
+		}
+		advisory.Affected = append(advisory.Affected, entities.AffectedPackage{
+			Ecosystem: affected.Package.Ecosystem,
+			Name:      affected.Package.Name,
+			Ranges:    ranges,
+			Versions:  affected.Versions,
+		})
+	}
+
+	for _, severity := range severities {
+		if severity.Type != "CVSS_V3" {
+			continue
+		}
+		if score, err := cvss3BaseScore(severity.Score); err == nil && score > advisory.CVSSScore {
+			advisory.CVSSScore = score
+		}
+	}
+	switch {
+	case advisory.CVSSScore > 0:
+		advisory.Severity = severityFromScore(advisory.CVSSScore)
+	case normalizeSeverity(r.DatabaseSpecific.Severity) != "":
+		advisory.Severity = normalizeSeverity(r.DatabaseSpecific.Severity)
+	default:
+		// 深刻度が記録されていないアドバイザリは中程度として扱う
+		advisory.Severity = "medium"
+	}
+
+	return advisory
+}
+
+// ReadOSVDump ローカルのダンプから OSV レコードを読み、レコードごとに fn を呼ぶ
+// path は JSON ファイル（1 レコードまたはレコードの配列）、エコシステムごとの all.zip の
+// ような zip アーカイブ、またはそれらを含むディレクトリ。取り下げられたレコードは飛ばす
+func ReadOSVDump(path string, fn func(*OSVRecord) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return readOSVFile(path, fn)
+	}
+
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(p))
+		if ext != ".json" && ext != ".zip" {
+			return nil
+		}
+		return readOSVFile(p, fn)
+	})
+}
+
+func readOSVFile(path string, fn func(*OSVRecord) error) error {
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		return readOSVZip(path, fn)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := decodeOSV(f, fn); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+func readOSVZip(path string, fn func(*OSVRecord) error) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() || !strings.EqualFold(filepath.Ext(entry.Name), ".json") {
+			continue
+		}
+		r, err := entry.Open()
+		if err != nil {
+			return err
+		}
+		err = decodeOSV(r, fn)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("%s!%s: %w", path, entry.Name, err)
+		}
+	}
+	return nil
+}
+
+// decodeOSV 1 レコードまたはレコードの配列をデコード
+func decodeOSV(r io.Reader, fn func(*OSVRecord) error) error {
+	reader := bufio.NewReader(r)
+	first, err := peekNonSpace(reader)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(reader)
+	emit := func() error {
+		var record OSVRecord
+		if err := decoder.Decode(&record); err != nil {
+			return err
+		}
+		if record.ID == "" || record.Withdrawn != nil {
+			return nil
+		}
+		return fn(&record)
+	}
+
+	if first != '[' {
+		return emit()
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	for decoder.More() {
+		if err := emit(); err != nil {
+			return err
+		}
+	}
+	_, err = decoder.Token()
+	return err
+}
+
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n', 0xEF, 0xBB, 0xBF:
+			_, _ = r.ReadByte()
+		default:
+			return b[0], nil
+		}
+	}
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	if runes := []rune(s); len(runes) > 200 {
+		s = string(runes[:200]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,142 @@
+package vulnerability
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pep440Pattern PEP 440 Appendix B の寛容なバージョンパターン
+var pep440Pattern = regexp.MustCompile(`^v?(?:(\d+)!)?(\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(a|b|c|rc|alpha|beta|pre|preview)[-_.]?(\d+)?)?` +
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d+)?)?` +
+	`(?:[-_.]?(dev)[-_.]?(\d+)?)?` +
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+type pep440Version struct {
+	epoch   int
+	release []int
+	// pre は a=0, b=1, rc=2。プレリリースでなければ -1
+	preKind int
+	preNum  int
+	post    int // ポストリリースでなければ -1
+	dev     int // 開発版でなければ -1
+}
+
+func parsePEP440(v string) (pep440Version, error) {
+	m := pep440Pattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(v)))
+	if m == nil {
+		return pep440Version{}, fmt.Errorf("invalid PEP 440 version: %q", v)
+	}
+
+	version := pep440Version{preKind: -1, post: -1, dev: -1}
+	version.epoch = atoiOrZero(m[1])
+	for _, part := range strings.Split(m[2], ".") {
+		version.release = append(version.release, atoiOrZero(part))
+	}
+
+	switch m[3] {
+	case "":
+	case "a", "alpha":
+		version.preKind = 0
+	case "b", "beta":
+		version.preKind = 1
+	default: // c, rc, pre, preview
+		version.preKind = 2
+	}
+	version.preNum = atoiOrZero(m[4])
+
+	switch {
+	case m[5] != "":
+		version.post = atoiOrZero(m[5])
+	case m[6] != "":
+		version.post = atoiOrZero(m[7])
+	}
+	if m[8] != "" {
+		version.dev = atoiOrZero(m[9])
+	}
+	return version, nil
+}
+
+// comparePEP440 PEP 440 の定義でバージョンを比較。ローカルセグメントは無視する
+func comparePEP440(a, b string) (int, error) {
+	va, err := parsePEP440(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := parsePEP440(b)
+	if err != nil {
+		return 0, err
+	}
+
+	if c := compareInt(va.epoch, vb.epoch); c != 0 {
+		return c, nil
+	}
+	if c := compareRelease(va.release, vb.release); c != 0 {
+		return c, nil
+	}
+	if c := compareInt(va.preKey(), vb.preKey()); c != 0 {
+		return c, nil
+	}
+	if va.preKind >= 0 && vb.preKind >= 0 {
+		if c := compareInt(va.preNum, vb.preNum); c != 0 {
+			return c, nil
+		}
+	}
+	if c := compareInt(va.post, vb.post); c != 0 {
+		return c, nil
+	}
+	return compareInt(va.devKey(), vb.devKey()), nil
+}
+
+// preKey "1.0.dev0" を "1.0a1" より前に、正式リリースをすべてのプレリリースの後に並べる
+func (v pep440Version) preKey() int {
+	switch {
+	case v.preKind >= 0:
+		return v.preKind
+	case v.post < 0 && v.dev >= 0:
+		return -1
+	}
+	return 3
+}
+
+// devKey 開発版リリースを対応するリリースより前に並べる
+func (v pep440Version) devKey() int {
+	if v.dev < 0 {
+		return int(^uint(0) >> 1)
+	}
+	return v.dev
+}
+
+// compareRelease リリースセグメントを比較。短い方は 0 で埋める
+func compareRelease(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := compareInt(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func atoiOrZero(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,52 @@
+package vulnerability
+
+import "testing"
+
+func TestComparePEP440(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.0.0", 0},
+		{"v1.0", "1.0", 0},
+		{"1.0+local.1", "1.0", 0},
+		{"1.0", "1.1", -1},
+		{"1.10", "1.9", 1},
+		{"1!0.1", "2.0", 1},
+		{"1.0.dev0", "1.0a1", -1},
+		{"1.0a1", "1.0b1", -1},
+		{"1.0b2", "1.0rc1", -1},
+		{"1.0c1", "1.0rc1", 0},
+		{"1.0alpha1", "1.0a1", 0},
+		{"1.0rc1", "1.0", -1},
+		{"1.0a1.dev1", "1.0a1", -1},
+		{"1.0", "1.0.post1", -1},
+		{"1.0-1", "1.0.post1", 0},
+		{"1.0.post1.dev0", "1.0.post1", -1},
+		{"1.0.post1.dev0", "1.0", 1},
+		{"1.0.POST1", "1.0.post1", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			got, err := comparePEP440(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("comparePEP440(%q, %q) returned error: %v", tt.a, tt.b, err)
+			}
+			if got != tt.want {
+				t.Errorf("comparePEP440(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComparePEP440Invalid(t *testing.T) {
+	tests := []string{"", "latest", "1.0-beta-x", "1..0"}
+	for _, version := range tests {
+		t.Run(version, func(t *testing.T) {
+			if _, err := comparePEP440(version, "1.0"); err == nil {
+				t.Errorf("comparePEP440(%q, \"1.0\") returned no error", version)
+			}
+		})
+	}
+}
@@ -0,0 +1,585 @@
+package wasm
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+// Magic すべての WebAssembly バイナリモジュールの先頭 4 バイト
+var Magic = []byte("\x00asm")
+
+// セクション ID
+const (
+	sectionCustom    = 0
+	sectionType      = 1
+	sectionImport    = 2
+	sectionFunction  = 3
+	sectionTable     = 4
+	sectionMemory    = 5
+	sectionGlobal    = 6
+	sectionExport    = 7
+	sectionStart     = 8
+	sectionElement   = 9
+	sectionCode      = 10
+	sectionData      = 11
+	sectionDataCount = 12
+	sectionTag       = 13
+)
+
+var errTruncated = errors.New("truncated wasm module")
+
+var externalKinds = []string{"func", "table", "memory", "global", "tag"}
+
+// reader バイナリ形式の LEB128 でエンコードされた値を読み、最初のエラーを記録する
+type reader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *reader) eof() bool {
+	return r.err != nil || r.pos >= len(r.data)
+}
+
+func (r *reader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *reader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.pos+n > len(r.data) {
+		r.fail(errTruncated)
+		return nil
+	}
+	b := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) byte() byte {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *reader) u64() uint64 {
+	var result uint64
+	for shift := uint(0); shift < 70; shift += 7 {
+		b := r.byte()
+		if r.err != nil {
+			return 0
+		}
+		result |= uint64(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return result
+		}
+	}
+	r.fail(errors.New("invalid LEB128 value"))
+	return 0
+}
+
+func (r *reader) u32() uint32 {
+	v := r.u64()
+	if v > math.MaxUint32 {
+		r.fail(errors.New("LEB128 value overflows u32"))
+		return 0
+	}
+	return uint32(v)
+}
+
+func (r *reader) s64() int64 {
+	var result int64
+	var shift uint
+	for shift < 70 {
+		b := r.byte()
+		if r.err != nil {
+			return 0
+		}
+		result |= int64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			if shift < 64 && b&0x40 != 0 {
+				result |= -1 << shift
+			}
+			return result
+		}
+	}
+	r.fail(errors.New("invalid LEB128 value"))
+	return 0
+}
+
+func (r *reader) name() string {
+	return string(r.bytes(int(r.u32())))
+}
+
+// count ベクターの長さを読む。壊れたモジュールで巨大な領域を確保しないよう、
+// 残りのバイト数に収まらない長さは拒否する
+func (r *reader) count() int {
+	n := r.u32()
+	if r.err == nil && int(n) > len(r.data)-r.pos {
+		r.fail(errTruncated)
+		return 0
+	}
+	return int(n)
+}
+
+func valueType(b byte) string {
+	switch b {
+	case 0x7f:
+		return "i32"
+	case 0x7e:
+		return "i64"
+	case 0x7d:
+		return "f32"
+	case 0x7c:
+		return "f64"
+	case 0x7b:
+		return "v128"
+	case 0x70:
+		return "funcref"
+	case 0x6f:
+		return "externref"
+	}
+	return fmt.Sprintf("type(0x%02x)", b)
+}
+
+// module 解析したエンティティと、テキスト形式の出力に必要な生のデータを保持する
+type module struct {
+	entity *entities.WasmModule
+	// funcTypes はインポートを含む全関数の型インデックス
+	funcTypes     []uint32
+	importedFuncs int
+	funcNames     map[uint32]string
+	bodies        []functionBody
+	globalInits   [][]byte
+	data          []dataSegment
+	codeSize      int
+}
+
+type functionBody struct {
+	locals []localDecl
+	code   []byte
+}
+
+type localDecl struct {
+	count uint32
+	typ   string
+}
+
+type dataSegment struct {
+	passive bool
+	memory  uint32
+	offset  []byte
+	init    []byte
+}
+
+// Parse WebAssembly バイナリモジュールを解析
+func Parse(data []byte) (*entities.WasmModule, error) {
+	m, err := parseModule(data)
+	if err != nil {
+		return nil, err
+	}
+	return m.entity, nil
+}
+
+func parseModule(data []byte) (*module, error) {
+	if len(data) < 8 || !bytes.Equal(data[:4], Magic) {
+		return nil, errors.New("not a wasm module")
+	}
+	version := uint32(data[4]) | uint32(data[5])<<8 | uint32(data[6])<<16 | uint32(data[7])<<24
+	if version != 1 {
+		// バージョン 1 以外はコンポーネントモデルなど別のレイアウト
+		return nil, fmt.Errorf("unsupported wasm version %d", version)
+	}
+
+	m := &module{
+		entity: &entities.WasmModule{
+			Version:        version,
+			Types:          []entities.WasmFuncType{},
+			Imports:        []entities.WasmImport{},
+			Exports:        []entities.WasmExport{},
+			Functions:      []entities.WasmFunction{},
+			Tables:         []entities.WasmTable{},
+			Memories:       []entities.WasmMemory{},
+			Globals:        []entities.WasmGlobal{},
+			CustomSections: []entities.WasmCustomSection{},
+		},
+		funcNames: make(map[uint32]string),
+	}
+
+	r := &reader{data: data, pos: 8}
+	for !r.eof() {
+		id := r.byte()
+		size := r.count()
+		body := r.bytes(size)
+		if r.err != nil {
+			return nil, r.err
+		}
+		section := &reader{data: body}
+		if err := m.parseSection(id, section); err != nil {
+			return nil, fmt.Errorf("section %d: %w", id, err)
+		}
+	}
+
+	m.buildFunctions()
+	return m, nil
+}
+
+func (m *module) parseSection(id byte, r *reader) error {
+	e := m.entity
+	switch id {
+	case sectionCustom:
+		name := r.name()
+		if r.err != nil {
+			return r.err
+		}
+		e.CustomSections = append(e.CustomSections, entities.WasmCustomSection{Name: name, Size: len(r.data)})
+		// name・producers セクションは壊れていても無視する（必須ではないため）
+		rest := &reader{data: r.data[r.pos:]}
+		switch name {
+		case "name":
+			m.parseNames(rest)
+		case "producers":
+			m.parseProducers(rest)
+		}
+		return nil
+	case sectionType:
+		for n := r.count(); n > 0 && r.err == nil; n-- {
+			if form := r.byte(); form != 0x60 {
+				return fmt.Errorf("unsupported type form 0x%02x", form)
+			}
+			var ft entities.WasmFuncType
+			ft.Params = m.valueTypes(r)
+			ft.Results = m.valueTypes(r)
+			e.Types = append(e.Types, ft)
+		}
+	case sectionImport:
+		for n := r.count(); n > 0 && r.err == nil; n-- {
+			imp := entities.WasmImport{Module: r.name(), Name: r.name()}
+			kind := r.byte()
+			switch kind {
+			case 0:
+				typeIndex := r.u32()
+				m.funcTypes = append(m.funcTypes, typeIndex)
+				m.importedFuncs++
+				imp.Type = m.signature(typeIndex)
+			case 1:
+				table := m.table(r)
+				e.Tables = append(e.Tables, table)
+				imp.Type = formatTable(table)
+			case 2:
+				memory := m.memory(r)
+				e.Memories = append(e.Memories, memory)
+				imp.Type = formatMemory(memory)
+			case 3:
+				global := entities.WasmGlobal{Type: valueType(r.byte()), Mutable: r.byte() == 1}
+				e.Globals = append(e.Globals, global)
+				m.globalInits = append(m.globalInits, nil)
+				imp.Type = formatGlobalType(global)
+			case 4:
+				r.byte()
+				imp.Type = m.signature(r.u32())
+			default:
+				return fmt.Errorf("unknown import kind %d", kind)
+			}
+			imp.Kind = externalKinds[kind]
+			e.Imports = append(e.Imports, imp)
+		}
+	case sectionFunction:
+		for n := r.count(); n > 0 && r.err == nil; n-- {
+			m.funcTypes = append(m.funcTypes, r.u32())
+		}
+	case sectionTable:
+		for n := r.count(); n > 0 && r.err == nil; n-- {
+			e.Tables = append(e.Tables, m.table(r))
+		}
+	case sectionMemory:
+		for n := r.count(); n > 0 && r.err == nil; n-- {
+			e.Memories = append(e.Memories, m.memory(r))
+		}
+	case sectionGlobal:
+		for n := r.count(); n > 0 && r.err == nil; n-- {
+			global := entities.WasmGlobal{Type: valueType(r.byte()), Mutable: r.byte() == 1}
+			init := constExpr(r)
+			global.Init = formatConstExpr(init)
+			e.Globals = append(e.Globals, global)
+			m.globalInits = append(m.globalInits, init)
+		}
+	case sectionExport:
+		for n := r.count(); n > 0 && r.err == nil; n-- {
+			exp := entities.WasmExport{Name: r.name()}
+			kind := r.byte()
+			if int(kind) >= len(externalKinds) {
+				return fmt.Errorf("unknown export kind %d", kind)
+			}
+			exp.Kind = externalKinds[kind]
+			exp.Index = r.u32()
+			e.Exports = append(e.Exports, exp)
+		}
+	case sectionStart:
+		start := r.u32()
+		e.Start = &start
+	case sectionElement:
+		// 要素セグメントは形式が多岐にわたるため件数のみ数える
+		e.ElementSegments = int(r.u32())
+		return r.err
+	case sectionCode:
+		m.codeSize = len(r.data)
+		for n := r.count(); n > 0 && r.err == nil; n-- {
+			size := r.count()
+			body := &reader{data: r.bytes(size)}
+			var fn functionBody
+			for locals := body.count(); locals > 0 && body.err == nil; locals-- {
+				fn.locals = append(fn.locals, localDecl{count: body.u32(), typ: valueType(body.byte())})
+			}
+			if body.err != nil {
+				return body.err
+			}
+			fn.code = body.data[body.pos:]
+			m.bodies = append(m.bodies, fn)
+		}
+	case sectionData:
+		for n := r.count(); n > 0 && r.err == nil; n-- {
+			var segment dataSegment
+			switch flag := r.u32(); flag {
+			case 0:
+				segment.offset = constExpr(r)
+			case 1:
+				segment.passive = true
+			case 2:
+				segment.memory = r.u32()
+				segment.offset = constExpr(r)
+			default:
+				return fmt.Errorf("unknown data segment flag %d", flag)
+			}
+			segment.init = r.bytes(r.count())
+			m.data = append(m.data, segment)
+		}
+		e.DataSegments = len(m.data)
+	case sectionDataCount, sectionTag:
+		return nil
+	default:
+		return fmt.Errorf("unknown section id %d", id)
+	}
+	return r.err
+}
+
+func (m *module) valueTypes(r *reader) []string {
+	types := []string{}
+	for n := r.count(); n > 0 && r.err == nil; n-- {
+		types = append(types, valueType(r.byte()))
+	}
+	return types
+}
+
+func limits(r *reader) (uint64, *uint64, byte) {
+	flags := r.byte()
+	read := r.u64
+	if flags&0x04 == 0 {
+		read = func() uint64 { return uint64(r.u32()) }
+	}
+	min := read()
+	if flags&0x01 == 0 {
+		return min, nil, flags
+	}
+	max := read()
+	return min, &max, flags
+}
+
+func (m *module) table(r *reader) entities.WasmTable {
+	table := entities.WasmTable{ElemType: valueType(r.byte())}
+	table.Min, table.Max, _ = limits(r)
+	return table
+}
+
+func (m *module) memory(r *reader) entities.WasmMemory {
+	var memory entities.WasmMemory
+	var flags byte
+	memory.Min, memory.Max, flags = limits(r)
+	memory.Shared = flags&0x02 != 0
+	memory.Memory64 = flags&0x04 != 0
+	return memory
+}
+
+// constExpr end オペコードを含む定数式のバイト列を返す
+func constExpr(r *reader) []byte {
+	start := r.pos
+	for !r.eof() {
+		op := r.byte()
+		switch op {
+		case 0x0b:
+			return r.data[start:r.pos]
+		case 0x41, 0x42:
+			r.s64()
+		case 0x43:
+			r.bytes(4)
+		case 0x44:
+			r.bytes(8)
+		case 0x23, 0xd2:
+			r.u32()
+		case 0xd0:
+			r.byte()
+		case 0x6a, 0x6b, 0x6c, 0x7c, 0x7d, 0x7e:
+			// 拡張定数式の加減乗算
+		default:
+			r.fail(fmt.Errorf("unsupported opcode 0x%02x in constant expression", op))
+		}
+	}
+	r.fail(errTruncated)
+	return nil
+}
+
+// parseNames name セクションからモジュール名と関数名を読む
+func (m *module) parseNames(r *reader) {
+	for !r.eof() {
+		id := r.byte()
+		sub := &reader{data: r.bytes(r.count())}
+		switch id {
+		case 0:
+			m.entity.Name = sub.name()
+		case 1:
+			for n := sub.count(); n > 0 && sub.err == nil; n-- {
+				index := sub.u32()
+				name := sub.name()
+				if sub.err == nil {
+					m.funcNames[index] = name
+				}
+			}
+		}
+	}
+}
+
+// parseProducers producers セクション（language、processed-by、sdk）を読む
+func (m *module) parseProducers(r *reader) {
+	producers := make(map[string][]string)
+	for n := r.count(); n > 0 && r.err == nil; n-- {
+		field := r.name()
+		for values := r.count(); values > 0 && r.err == nil; values-- {
+			name := r.name()
+			version := r.name()
+			if version != "" {
+				name += " " + version
+			}
+			producers[field] = append(producers[field], name)
+		}
+	}
+	if r.err == nil && len(producers) > 0 {
+		m.entity.Producers = producers
+	}
+}
+
+func (m *module) buildFunctions() {
+	e := m.entity
+	exported := make(map[uint32]string)
+	for _, exp := range e.Exports {
+		if exp.Kind == "func" {
+			if _, ok := exported[exp.Index]; !ok {
+				exported[exp.Index] = exp.Name
+			}
+		}
+	}
+
+	e.FunctionsTotal = len(m.funcTypes)
+	for i, typeIndex := range m.funcTypes {
+		if len(e.Functions) >= maxFunctions {
+			e.FunctionsCapped = true
+			break
+		}
+		index := uint32(i)
+		fn := entities.WasmFunction{
+			Index:     index,
+			Name:      m.functionName(index),
+			Signature: m.signature(typeIndex),
+			Imported:  i < m.importedFuncs,
+		}
+		if fn.Name == "" {
+			fn.Name = exported[index]
+		}
+		if !fn.Imported {
+			if body := i - m.importedFuncs; body < len(m.bodies) {
+				for _, local := range m.bodies[body].locals {
+					fn.Locals += int(local.count)
+				}
+				fn.CodeSize = len(m.bodies[body].code)
+			}
+		}
+		e.Functions = append(e.Functions, fn)
+	}
+
+	// インポートした関数の名前は name セクションがなければ module.name とする
+	funcImport := 0
+	for _, imp := range e.Imports {
+		if imp.Kind != "func" {
+			continue
+		}
+		if funcImport < len(e.Functions) && e.Functions[funcImport].Name == "" {
+			e.Functions[funcImport].Name = imp.Module + "." + imp.Name
+		}
+		funcImport++
+	}
+}
+
+func (m *module) functionName(index uint32) string {
+	return m.funcNames[index]
+}
+
+func (m *module) signature(typeIndex uint32) string {
+	if int(typeIndex) >= len(m.entity.Types) {
+		return fmt.Sprintf("(type %d)", typeIndex)
+	}
+	return formatFuncType(m.entity.Types[typeIndex])
+}
+
+// formatFuncType シグネチャをテキスト形式と同じ (param i32) (result i32) の形にする
+func formatFuncType(ft entities.WasmFuncType) string {
+	var parts []string
+	if len(ft.Params) > 0 {
+		parts = append(parts, "(param "+strings.Join(ft.Params, " ")+")")
+	}
+	if len(ft.Results) > 0 {
+		parts = append(parts, "(result "+strings.Join(ft.Results, " ")+")")
+	}
+	if len(parts) == 0 {
+		return "()"
+	}
+	return strings.Join(parts, " ")
+}
+
+func formatLimits(min uint64, max *uint64) string {
+	if max == nil {
+		return fmt.Sprintf("%d", min)
+	}
+	return fmt.Sprintf("%d %d", min, *max)
+}
+
+func formatTable(table entities.WasmTable) string {
+	return formatLimits(table.Min, table.Max) + " " + table.ElemType
+}
+
+func formatMemory(memory entities.WasmMemory) string {
+	s := formatLimits(memory.Min, memory.Max)
+	if memory.Memory64 {
+		s = "i64 " + s
+	}
+	if memory.Shared {
+		s += " shared"
+	}
+	return s
+}
+
+func formatGlobalType(global entities.WasmGlobal) string {
+	if global.Mutable {
+		return "(mut " + global.Type + ")"
+	}
+	return global.Type
+}
@@ -0,0 +1,180 @@
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// section セクション ID と LEB128 の長さを本体に付ける（テストでは 128 バイト未満のみ）
+func section(id byte, body ...byte) []byte {
+	return append([]byte{id, byte(len(body))}, body...)
+}
+
+// testModule 次のモジュールをバイナリ形式にする
+//
+//	(module $demo
+//	  (import "env" "log" (func $log (param i32)))
+//	  (memory 1 2)
+//	  (global i32 (i32.const 1024))
+//	  (func $add (param i32 i32) (result i32)
+//	    local.get 0
+//	    if (result i32) local.get 1 else i32.const 0 end
+//	    local.get 1
+//	    i32.add)
+//	  (export "add" (func $add))
+//	  (data (i32.const 8) "hi"))
+func testModule() []byte {
+	var b bytes.Buffer
+	b.Write(Magic)
+	b.Write([]byte{1, 0, 0, 0})
+	b.Write(section(sectionType, 2,
+		0x60, 1, 0x7f, 0,
+		0x60, 2, 0x7f, 0x7f, 1, 0x7f))
+	b.Write(section(sectionImport, 1, 3, 'e', 'n', 'v', 3, 'l', 'o', 'g', 0, 0))
+	b.Write(section(sectionFunction, 1, 1))
+	b.Write(section(sectionMemory, 1, 1, 1, 2))
+	b.Write(section(sectionGlobal, 1, 0x7f, 0, 0x41, 0x80, 0x08, 0x0b))
+	b.Write(section(sectionExport, 1, 3, 'a', 'd', 'd', 0, 1))
+	code := []byte{0, 0x20, 0, 0x04, 0x7f, 0x20, 1, 0x05, 0x41, 0, 0x0b, 0x20, 1, 0x6a, 0x0b}
+	b.Write(section(sectionCode, append([]byte{1, byte(len(code))}, code...)...))
+	b.Write(section(sectionData, 1, 0, 0x41, 8, 0x0b, 2, 'h', 'i'))
+	b.Write(section(sectionCustom,
+		4, 'n', 'a', 'm', 'e',
+		0, 5, 4, 'd', 'e', 'm', 'o',
+		1, 11, 2, 0, 3, 'l', 'o', 'g', 1, 3, 'a', 'd', 'd'))
+	return b.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	module, err := Parse(testModule())
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	if module.Name != "demo" || len(module.Types) != 2 || len(module.Imports) != 1 || len(module.Exports) != 1 {
+		t.Errorf("Parse() = %+v, want module $demo with 2 types, 1 import and 1 export", module)
+	}
+	if len(module.Functions) != 2 {
+		t.Fatalf("Functions = %+v, want log and add", module.Functions)
+	}
+	add := module.Functions[1]
+	if add.Name != "add" || add.Signature != "(param i32 i32) (result i32)" || add.Imported {
+		t.Errorf("Functions[1] = %+v, want add (param i32 i32) (result i32)", add)
+	}
+	if len(module.Globals) != 1 || module.Globals[0].Init != "(i32.const 1024)" {
+		t.Errorf("Globals = %+v, want i32 initialized to 1024", module.Globals)
+	}
+	if len(module.Memories) != 1 || module.Memories[0].Min != 1 || module.Memories[0].Max == nil || *module.Memories[0].Max != 2 {
+		t.Errorf("Memories = %+v, want 1..2 pages", module.Memories)
+	}
+}
+
+func TestRenderText(t *testing.T) {
+	m, err := parseModule(testModule())
+	if err != nil {
+		t.Fatalf("parseModule returned error: %v", err)
+	}
+	text, truncated := m.renderText()
+	if truncated {
+		t.Errorf("renderText() truncated = true, want false")
+	}
+	for _, want := range []string{
+		`(import "env" "log" (func $log (;0;) (type 0)))`,
+		"  (func $add (;1;) (type 1) (param i32 i32) (result i32)\n    local.get 0\n    if (result i32)\n      local.get 1\n    else\n      i32.const 0\n    end\n    local.get 1\n    i32.add\n  )",
+		`(data (;0;) (i32.const 8) "hi")`,
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("renderText() = %s, want it to contain %q", text, want)
+		}
+	}
+}
+
+func TestParseTruncated(t *testing.T) {
+	data := testModule()
+	nameSection := bytes.LastIndexByte(data, sectionCustom)
+	// セクションの途中で切れたモジュールはエラーになる（セクションの境界で切れたものは正しい）
+	boundaries := make(map[int]bool)
+	for r := (&reader{data: data, pos: 8}); !r.eof(); {
+		boundaries[r.pos] = true
+		r.byte()
+		r.bytes(r.count())
+	}
+	for n := 0; n < nameSection; n++ {
+		if boundaries[n] {
+			continue
+		}
+		if module, err := Parse(data[:n]); err == nil {
+			t.Errorf("Parse(%d of %d bytes) = %+v, want error", n, len(data), module)
+		}
+	}
+}
+
+func TestParseMalformed(t *testing.T) {
+	header := append(append([]byte(nil), Magic...), 1, 0, 0, 0)
+	module := func(sections ...[]byte) []byte {
+		return append(append([]byte(nil), header...), bytes.Join(sections, nil)...)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"not wasm", []byte("\x7fELF\x02\x01\x01\x00")},
+		{"unsupported version", append(append([]byte(nil), Magic...), 0x0d, 0, 1, 0)},
+		{"section size past end", module([]byte{sectionType, 0x7f, 0})},
+		{"section size overflows", module([]byte{sectionType, 0xff, 0xff, 0xff, 0xff, 0x7f})},
+		{"unterminated LEB128", module([]byte{sectionType, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80})},
+		{"unknown section", module(section(0x7f))},
+		{"vector count past end", module(section(sectionType, 0x7f))},
+		{"unsupported type form", module(section(sectionType, 1, 0x50, 0, 0))},
+		{"unknown import kind", module(section(sectionImport, 1, 1, 'a', 1, 'b', 9, 0))},
+		{"unknown export kind", module(section(sectionExport, 1, 1, 'a', 9, 0))},
+		{"unterminated constant expression", module(section(sectionGlobal, 1, 0x7f, 0, 0x41, 0))},
+		{"unsupported constant opcode", module(section(sectionGlobal, 1, 0x7f, 0, 0x10, 0, 0x0b))},
+		{"unknown data segment flag", module(section(sectionData, 1, 7))},
+		{"function body past end", module(section(sectionCode, 1, 0x7f, 0))},
+		{"local count past end", module(section(sectionCode, 1, 2, 0x7f, 0))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if module, err := Parse(tt.data); err == nil {
+				t.Errorf("Parse() = %+v, want error", module)
+			}
+		})
+	}
+}
+
+func TestExecuteMalformedCode(t *testing.T) {
+	// 関数本体の命令が壊れていても、モジュールの情報はそのまま返しテキスト形式にコメントを残す
+	data := testModule()
+	code := bytes.Index(data, []byte{0x20, 0, 0x04, 0x7f})
+	data[code+2] = 0xff
+
+	path := filepath.Join(t.TempDir(), "broken.wasm")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("failed to write test module: %v", err)
+	}
+	module, err := NewWasmInspectionUseCase().Execute(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Execute returned error: %v", err)
+	}
+	if !strings.Contains(module.Text, ";; unsupported opcode 0xff, rest of function omitted") {
+		t.Errorf("Text = %s, want a comment for the unsupported opcode", module.Text)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add(testModule())
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// 壊れたモジュールでもエラーを返し、panic しない
+		m, err := parseModule(data)
+		if err == nil {
+			m.renderText()
+		}
+	})
+}
@@ -0,0 +1,277 @@
+package wasm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// 即値を持たない数値命令（0x45〜0xC4）
+var numericOpcodes = map[byte]string{
+	0x45: "i32.eqz", 0x46: "i32.eq", 0x47: "i32.ne", 0x48: "i32.lt_s", 0x49: "i32.lt_u",
+	0x4a: "i32.gt_s", 0x4b: "i32.gt_u", 0x4c: "i32.le_s", 0x4d: "i32.le_u", 0x4e: "i32.ge_s", 0x4f: "i32.ge_u",
+	0x50: "i64.eqz", 0x51: "i64.eq", 0x52: "i64.ne", 0x53: "i64.lt_s", 0x54: "i64.lt_u",
+	0x55: "i64.gt_s", 0x56: "i64.gt_u", 0x57: "i64.le_s", 0x58: "i64.le_u", 0x59: "i64.ge_s", 0x5a: "i64.ge_u",
+	0x5b: "f32.eq", 0x5c: "f32.ne", 0x5d: "f32.lt", 0x5e: "f32.gt", 0x5f: "f32.le", 0x60: "f32.ge",
+	0x61: "f64.eq", 0x62: "f64.ne", 0x63: "f64.lt", 0x64: "f64.gt", 0x65: "f64.le", 0x66: "f64.ge",
+	0x67: "i32.clz", 0x68: "i32.ctz", 0x69: "i32.popcnt", 0x6a: "i32.add", 0x6b: "i32.sub", 0x6c: "i32.mul",
+	0x6d: "i32.div_s", 0x6e: "i32.div_u", 0x6f: "i32.rem_s", 0x70: "i32.rem_u", 0x71: "i32.and", 0x72: "i32.or",
+	0x73: "i32.xor", 0x74: "i32.shl", 0x75: "i32.shr_s", 0x76: "i32.shr_u", 0x77: "i32.rotl", 0x78: "i32.rotr",
+	0x79: "i64.clz", 0x7a: "i64.ctz", 0x7b: "i64.popcnt", 0x7c: "i64.add", 0x7d: "i64.sub", 0x7e: "i64.mul",
+	0x7f: "i64.div_s", 0x80: "i64.div_u", 0x81: "i64.rem_s", 0x82: "i64.rem_u", 0x83: "i64.and", 0x84: "i64.or",
+	0x85: "i64.xor", 0x86: "i64.shl", 0x87: "i64.shr_s", 0x88: "i64.shr_u", 0x89: "i64.rotl", 0x8a: "i64.rotr",
+	0x8b: "f32.abs", 0x8c: "f32.neg", 0x8d: "f32.ceil", 0x8e: "f32.floor", 0x8f: "f32.trunc", 0x90: "f32.nearest",
+	0x91: "f32.sqrt", 0x92: "f32.add", 0x93: "f32.sub", 0x94: "f32.mul", 0x95: "f32.div", 0x96: "f32.min",
+	0x97: "f32.max", 0x98: "f32.copysign",
+	0x99: "f64.abs", 0x9a: "f64.neg", 0x9b: "f64.ceil", 0x9c: "f64.floor", 0x9d: "f64.trunc", 0x9e: "f64.nearest",
+	0x9f: "f64.sqrt", 0xa0: "f64.add", 0xa1: "f64.sub", 0xa2: "f64.mul", 0xa3: "f64.div", 0xa4: "f64.min",
+	0xa5: "f64.max", 0xa6: "f64.copysign",
+	0xa7: "i32.wrap_i64", 0xa8: "i32.trunc_f32_s", 0xa9: "i32.trunc_f32_u", 0xaa: "i32.trunc_f64_s",
+	0xab: "i32.trunc_f64_u", 0xac: "i64.extend_i32_s", 0xad: "i64.extend_i32_u", 0xae: "i64.trunc_f32_s",
+	0xaf: "i64.trunc_f32_u", 0xb0: "i64.trunc_f64_s", 0xb1: "i64.trunc_f64_u", 0xb2: "f32.convert_i32_s",
+	0xb3: "f32.convert_i32_u", 0xb4: "f32.convert_i64_s", 0xb5: "f32.convert_i64_u", 0xb6: "f32.demote_f64",
+	0xb7: "f64.convert_i32_s", 0xb8: "f64.convert_i32_u", 0xb9: "f64.convert_i64_s", 0xba: "f64.convert_i64_u",
+	0xbb: "f64.promote_f32", 0xbc: "i32.reinterpret_f32", 0xbd: "i64.reinterpret_f64", 0xbe: "f32.reinterpret_i32",
+	0xbf: "f64.reinterpret_i64",
+	0xc0: "i32.extend8_s", 0xc1: "i32.extend16_s", 0xc2: "i64.extend8_s", 0xc3: "i64.extend16_s", 0xc4: "i64.extend32_s",
+}
+
+// メモリアクセス命令（0x28〜0x3E）と自然なアラインメント（2 の指数）
+var memoryOpcodes = map[byte]struct {
+	name  string
+	align uint32
+}{
+	0x28: {"i32.load", 2}, 0x29: {"i64.load", 3}, 0x2a: {"f32.load", 2}, 0x2b: {"f64.load", 3},
+	0x2c: {"i32.load8_s", 0}, 0x2d: {"i32.load8_u", 0}, 0x2e: {"i32.load16_s", 1}, 0x2f: {"i32.load16_u", 1},
+	0x30: {"i64.load8_s", 0}, 0x31: {"i64.load8_u", 0}, 0x32: {"i64.load16_s", 1}, 0x33: {"i64.load16_u", 1},
+	0x34: {"i64.load32_s", 2}, 0x35: {"i64.load32_u", 2},
+	0x36: {"i32.store", 2}, 0x37: {"i64.store", 3}, 0x38: {"f32.store", 2}, 0x39: {"f64.store", 3},
+	0x3a: {"i32.store8", 0}, 0x3b: {"i32.store16", 1}, 0x3c: {"i64.store8", 0}, 0x3d: {"i64.store16", 1},
+	0x3e: {"i64.store32", 2},
+}
+
+var saturatingOpcodes = []string{
+	"i32.trunc_sat_f32_s", "i32.trunc_sat_f32_u", "i32.trunc_sat_f64_s", "i32.trunc_sat_f64_u",
+	"i64.trunc_sat_f32_s", "i64.trunc_sat_f32_u", "i64.trunc_sat_f64_s", "i64.trunc_sat_f64_u",
+}
+
+// instruction デコードした命令
+// depth はブロックを開く命令で +1、end で -1、それ以外は 0。else は 1 段外側に出力する
+type instruction struct {
+	text   string
+	depth  int
+	isEnd  bool
+	isElse bool
+}
+
+// readInstruction 1 命令をデコード。m は関数名の参照に使い、nil でもよい
+func readInstruction(r *reader, m *module) (instruction, error) {
+	op := r.byte()
+	if r.err != nil {
+		return instruction{}, r.err
+	}
+	plain := func(text string) (instruction, error) {
+		return instruction{text: text}, r.err
+	}
+	index := func(name string) (instruction, error) {
+		return plain(fmt.Sprintf("%s %d", name, r.u32()))
+	}
+
+	switch {
+	case op == 0x00:
+		return plain("unreachable")
+	case op == 0x01:
+		return plain("nop")
+	case op >= 0x02 && op <= 0x04:
+		name := [...]string{"block", "loop", "if"}[op-0x02]
+		text := name + blockType(r, m)
+		return instruction{text: strings.TrimSpace(text), depth: 1}, r.err
+	case op == 0x05:
+		return instruction{text: "else", isElse: true}, nil
+	case op == 0x0b:
+		return instruction{text: "end", depth: -1, isEnd: true}, nil
+	case op == 0x0c:
+		return index("br")
+	case op == 0x0d:
+		return index("br_if")
+	case op == 0x0e:
+		var labels []string
+		for n := r.count(); n >= 0 && r.err == nil; n-- {
+			labels = append(labels, fmt.Sprint(r.u32()))
+		}
+		return plain("br_table " + strings.Join(labels, " "))
+	case op == 0x0f:
+		return plain("return")
+	case op == 0x10 || op == 0x12:
+		name := "call"
+		if op == 0x12 {
+			name = "return_call"
+		}
+		return plain(name + " " + funcRef(r.u32(), m))
+	case op == 0x11 || op == 0x13:
+		name := "call_indirect"
+		if op == 0x13 {
+			name = "return_call_indirect"
+		}
+		typeIndex := r.u32()
+		table := r.u32()
+		if table != 0 {
+			return plain(fmt.Sprintf("%s %d (type %d)", name, table, typeIndex))
+		}
+		return plain(fmt.Sprintf("%s (type %d)", name, typeIndex))
+	case op == 0x1a:
+		return plain("drop")
+	case op == 0x1b:
+		return plain("select")
+	case op == 0x1c:
+		var types []string
+		for n := r.count(); n > 0 && r.err == nil; n-- {
+			types = append(types, valueType(r.byte()))
+		}
+		return plain("select (result " + strings.Join(types, " ") + ")")
+	case op >= 0x20 && op <= 0x26:
+		name := [...]string{"local.get", "local.set", "local.tee", "global.get", "global.set", "table.get", "table.set"}[op-0x20]
+		return index(name)
+	case op >= 0x28 && op <= 0x3e:
+		mem := memoryOpcodes[op]
+		align := r.u32()
+		offset := r.u64()
+		text := mem.name
+		if offset != 0 {
+			text += fmt.Sprintf(" offset=%d", offset)
+		}
+		if align != mem.align {
+			text += fmt.Sprintf(" align=%d", uint64(1)<<(align&0x3f))
+		}
+		return plain(text)
+	case op == 0x3f || op == 0x40:
+		r.byte()
+		return plain(map[byte]string{0x3f: "memory.size", 0x40: "memory.grow"}[op])
+	case op == 0x41:
+		return plain(fmt.Sprintf("i32.const %d", int32(r.s64())))
+	case op == 0x42:
+		return plain(fmt.Sprintf("i64.const %d", r.s64()))
+	case op == 0x43:
+		b := r.bytes(4)
+		if b == nil {
+			return plain("")
+		}
+		return plain(fmt.Sprintf("f32.const %v", math.Float32frombits(binary.LittleEndian.Uint32(b))))
+	case op == 0x44:
+		b := r.bytes(8)
+		if b == nil {
+			return plain("")
+		}
+		return plain(fmt.Sprintf("f64.const %v", math.Float64frombits(binary.LittleEndian.Uint64(b))))
+	case op >= 0x45 && op <= 0xc4:
+		return plain(numericOpcodes[op])
+	case op == 0xd0:
+		return plain("ref.null " + strings.TrimSuffix(valueType(r.byte()), "ref"))
+	case op == 0xd1:
+		return plain("ref.is_null")
+	case op == 0xd2:
+		return plain("ref.func " + funcRef(r.u32(), m))
+	case op == 0xfc:
+		return readPrefixed(r)
+	}
+	return instruction{}, fmt.Errorf("unsupported opcode 0x%02x", op)
+}
+
+// readPrefixed 0xFC プレフィックスの命令（飽和変換、バルクメモリー、テーブル操作）をデコード
+func readPrefixed(r *reader) (instruction, error) {
+	sub := r.u32()
+	plain := func(text string) (instruction, error) {
+		return instruction{text: text}, r.err
+	}
+	switch {
+	case sub < 8:
+		return plain(saturatingOpcodes[sub])
+	case sub == 8:
+		segment := r.u32()
+		r.byte()
+		return plain(fmt.Sprintf("memory.init %d", segment))
+	case sub == 9:
+		return plain(fmt.Sprintf("data.drop %d", r.u32()))
+	case sub == 10:
+		r.byte()
+		r.byte()
+		return plain("memory.copy")
+	case sub == 11:
+		r.byte()
+		return plain("memory.fill")
+	case sub == 12:
+		segment := r.u32()
+		return plain(fmt.Sprintf("table.init %d %d", r.u32(), segment))
+	case sub == 13:
+		return plain(fmt.Sprintf("elem.drop %d", r.u32()))
+	case sub == 14:
+		dst := r.u32()
+		return plain(fmt.Sprintf("table.copy %d %d", dst, r.u32()))
+	case sub >= 15 && sub <= 17:
+		name := [...]string{"table.grow", "table.size", "table.fill"}[sub-15]
+		return plain(fmt.Sprintf("%s %d", name, r.u32()))
+	}
+	return instruction{}, fmt.Errorf("unsupported opcode 0xfc %d", sub)
+}
+
+// blockType block、loop、if に続くブロック型を文字列にする
+func blockType(r *reader, m *module) string {
+	if r.eof() {
+		r.fail(errTruncated)
+		return ""
+	}
+	b := r.data[r.pos]
+	switch {
+	case b == 0x40:
+		r.pos++
+		return ""
+	case b >= 0x6f && b <= 0x7f:
+		r.pos++
+		return " (result " + valueType(b) + ")"
+	}
+	typeIndex := r.s64()
+	if m != nil && typeIndex >= 0 && typeIndex < int64(len(m.entity.Types)) {
+		return fmt.Sprintf(" (type %d) %s", typeIndex, formatFuncType(m.entity.Types[typeIndex]))
+	}
+	return fmt.Sprintf(" (type %d)", typeIndex)
+}
+
+func funcRef(index uint32, m *module) string {
+	if m != nil {
+		if name := m.functionName(index); name != "" {
+			return "$" + identifier(name)
+		}
+	}
+	return fmt.Sprint(index)
+}
+
+// formatConstExpr (i32.const 1024) のような定数式を文字列にする
+func formatConstExpr(expr []byte) string {
+	r := &reader{data: expr}
+	var parts []string
+	for !r.eof() {
+		inst, err := readInstruction(r, nil)
+		if err != nil {
+			return ""
+		}
+		if inst.isEnd {
+			break
+		}
+		parts = append(parts, "("+inst.text+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// identifier テキスト形式の識別子に使えない文字を置き換える
+func identifier(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case strings.ContainsRune("!#$%&'*+-./:<=>?@\\^_`|~", r):
+			return r
+		}
+		return '_'
+	}, name)
+}
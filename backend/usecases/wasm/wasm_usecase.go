@@ -0,0 +1,145 @@
+package wasm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+)
+
+const (
+	// maxWasmSize を超えるファイルは読み込まない
+	maxWasmSize = 256 << 20
+	// maxFunctions を超える関数は一覧に含めない（件数だけ数える）
+	maxFunctions = 20000
+	// 説明用の要約に含める件数・文字数の上限
+	maxSummaryItems = 200
+	maxSummaryText  = 12000
+)
+
+// WasmInspectionUseCase WebAssembly モジュールを解析し、小さいモジュールは WAT 風の
+// テキスト形式でも出力する
+type WasmInspectionUseCase struct{}
+
+// NewWasmInspectionUseCase WebAssembly 解析のユースケースを作成
+func NewWasmInspectionUseCase() *WasmInspectionUseCase {
+	return &WasmInspectionUseCase{}
+}
+
+// IsWasmFile ファイルが WebAssembly のマジックで始まるかを判定
+func IsWasmFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(Magic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return false
+	}
+	return bytes.Equal(magic, Magic)
+}
+
+// Execute path に保存されたモジュールを解析
+func (uc *WasmInspectionUseCase) Execute(ctx context.Context, path string) (*entities.WasmModule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if stat.Size() > maxWasmSize {
+		return nil, fmt.Errorf("file is larger than %d bytes", maxWasmSize)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := parseModule(data)
+	if err != nil {
+		return nil, err
+	}
+	if m.codeSize <= maxTextCodeSize {
+		m.entity.Text, m.entity.TextTruncated = m.renderText()
+	}
+	return m.entity, nil
+}
+
+// FormatModuleSummary LLM に渡すモジュールの概要を作成
+// ツールチェーン、インポート、エクスポート、メモリー、可能ならテキスト形式の先頭を含める
+func FormatModuleSummary(module *entities.WasmModule) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "WebAssembly module (version %d)", module.Version)
+	if module.Name != "" {
+		fmt.Fprintf(&b, " $%s", module.Name)
+	}
+	fmt.Fprintf(&b, "\nfunctions: %d, types: %d, data segments: %d, element segments: %d\n",
+		module.FunctionsTotal, len(module.Types), module.DataSegments, module.ElementSegments)
+
+	fields := make([]string, 0, len(module.Producers))
+	for field := range module.Producers {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		fmt.Fprintf(&b, "producers %s: %s\n", field, strings.Join(module.Producers[field], ", "))
+	}
+	for _, memory := range module.Memories {
+		fmt.Fprintf(&b, "memory: %s pages\n", formatMemory(memory))
+	}
+	for _, table := range module.Tables {
+		fmt.Fprintf(&b, "table: %s\n", formatTable(table))
+	}
+	if len(module.CustomSections) > 0 {
+		var names []string
+		for _, section := range module.CustomSections {
+			names = append(names, fmt.Sprintf("%s (%d bytes)", section.Name, section.Size))
+		}
+		fmt.Fprintf(&b, "custom sections: %s\n", strings.Join(names, ", "))
+	}
+
+	b.WriteString("\nimports:\n")
+	for i, imp := range module.Imports {
+		if i >= maxSummaryItems {
+			fmt.Fprintf(&b, "  ... %d more\n", len(module.Imports)-i)
+			break
+		}
+		fmt.Fprintf(&b, "  %s.%s %s %s\n", imp.Module, imp.Name, imp.Kind, imp.Type)
+	}
+
+	b.WriteString("\nexports:\n")
+	signatures := make(map[uint32]string)
+	for _, fn := range module.Functions {
+		signatures[fn.Index] = fn.Signature
+	}
+	for i, exp := range module.Exports {
+		if i >= maxSummaryItems {
+			fmt.Fprintf(&b, "  ... %d more\n", len(module.Exports)-i)
+			break
+		}
+		signature := ""
+		if exp.Kind == "func" {
+			signature = " " + signatures[exp.Index]
+		}
+		fmt.Fprintf(&b, "  %s %s %d%s\n", exp.Name, exp.Kind, exp.Index, signature)
+	}
+
+	if module.Text != "" {
+		text := module.Text
+		if len(text) > maxSummaryText {
+			text = text[:maxSummaryText] + "\n;; ..."
+		}
+		b.WriteString("\ntext format:\n")
+		b.WriteString(text)
+	}
+	return b.String()
+}
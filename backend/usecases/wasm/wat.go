@@ -0,0 +1,197 @@
+package wasm
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// maxTextCodeSize を超えるコードセクションはテキスト形式に変換しない
+	maxTextCodeSize = 256 << 10
+	// maxTextSize はテキスト形式の出力の上限
+	maxTextSize = 1 << 20
+	// maxDataPreview はデータセグメントごとに表示するバイト数
+	maxDataPreview = 256
+)
+
+// renderText モジュールを WAT 風のテキスト形式で出力
+// デコーダーが知らない命令（SIMD、スレッドなど）があると、コメントを出してその関数を終える
+func (m *module) renderText() (string, bool) {
+	e := m.entity
+	var b strings.Builder
+	truncated := false
+
+	b.WriteString("(module")
+	if e.Name != "" {
+		b.WriteString(" $" + identifier(e.Name))
+	}
+	b.WriteString("\n")
+
+	for i, ft := range e.Types {
+		sig := ""
+		if s := formatFuncType(ft); s != "()" {
+			sig = " " + s
+		}
+		fmt.Fprintf(&b, "  (type (;%d;) (func%s))\n", i, sig)
+	}
+
+	var funcs, tables, memories, globals int
+	for _, imp := range e.Imports {
+		var desc string
+		switch imp.Kind {
+		case "func":
+			desc = fmt.Sprintf("(func%s (;%d;) (type %d))", m.funcLabel(uint32(funcs)), funcs, m.funcTypes[funcs])
+			funcs++
+		case "table":
+			desc = fmt.Sprintf("(table (;%d;) %s)", tables, imp.Type)
+			tables++
+		case "memory":
+			desc = fmt.Sprintf("(memory (;%d;) %s)", memories, imp.Type)
+			memories++
+		case "global":
+			desc = fmt.Sprintf("(global (;%d;) %s)", globals, imp.Type)
+			globals++
+		default:
+			desc = fmt.Sprintf("(%s %s)", imp.Kind, imp.Type)
+		}
+		fmt.Fprintf(&b, "  (import %q %q %s)\n", imp.Module, imp.Name, desc)
+	}
+
+	for i, body := range m.bodies {
+		if b.Len() > maxTextSize {
+			truncated = true
+			fmt.Fprintf(&b, "  ;; %d more functions omitted\n", len(m.bodies)-i)
+			break
+		}
+		index := uint32(m.importedFuncs + i)
+		if int(index) >= len(m.funcTypes) {
+			break
+		}
+		m.renderFunction(&b, index, body)
+	}
+
+	for i := tables; i < len(e.Tables); i++ {
+		fmt.Fprintf(&b, "  (table (;%d;) %s)\n", i, formatTable(e.Tables[i]))
+	}
+	for i := memories; i < len(e.Memories); i++ {
+		fmt.Fprintf(&b, "  (memory (;%d;) %s)\n", i, formatMemory(e.Memories[i]))
+	}
+	for i := globals; i < len(e.Globals); i++ {
+		fmt.Fprintf(&b, "  (global (;%d;) %s %s)\n", i, formatGlobalType(e.Globals[i]), e.Globals[i].Init)
+	}
+	for _, exp := range e.Exports {
+		fmt.Fprintf(&b, "  (export %q (%s %d))\n", exp.Name, exp.Kind, exp.Index)
+	}
+	if e.Start != nil {
+		fmt.Fprintf(&b, "  (start %d)\n", *e.Start)
+	}
+	if e.ElementSegments > 0 {
+		fmt.Fprintf(&b, "  ;; %d element segments\n", e.ElementSegments)
+	}
+	for i, segment := range m.data {
+		if b.Len() > maxTextSize {
+			truncated = true
+			fmt.Fprintf(&b, "  ;; %d more data segments omitted\n", len(m.data)-i)
+			break
+		}
+		preview := segment.init
+		if len(preview) > maxDataPreview {
+			preview = preview[:maxDataPreview]
+		}
+		fmt.Fprintf(&b, "  (data (;%d;)", i)
+		if !segment.passive {
+			if segment.memory != 0 {
+				fmt.Fprintf(&b, " (memory %d)", segment.memory)
+			}
+			fmt.Fprintf(&b, " %s", formatConstExpr(segment.offset))
+		}
+		fmt.Fprintf(&b, " \"%s\")", escapeData(preview))
+		if len(preview) < len(segment.init) {
+			fmt.Fprintf(&b, " ;; %d of %d bytes", len(preview), len(segment.init))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(")\n")
+	return b.String(), truncated
+}
+
+func (m *module) funcLabel(index uint32) string {
+	if name := m.functionName(index); name != "" {
+		return " $" + identifier(name)
+	}
+	return ""
+}
+
+func (m *module) renderFunction(b *strings.Builder, index uint32, body functionBody) {
+	typeIndex := m.funcTypes[index]
+	fmt.Fprintf(b, "  (func%s (;%d;) (type %d)", m.funcLabel(index), index, typeIndex)
+	if int(typeIndex) < len(m.entity.Types) {
+		if sig := formatFuncType(m.entity.Types[typeIndex]); sig != "()" {
+			b.WriteString(" " + sig)
+		}
+	}
+	b.WriteString("\n")
+
+	if len(body.locals) > 0 {
+		var locals []string
+		for _, local := range body.locals {
+			// 同じ型が大量に並ぶ場合は個数で表す
+			if local.count > 8 {
+				locals = append(locals, fmt.Sprintf("%s×%d", local.typ, local.count))
+				continue
+			}
+			for i := uint32(0); i < local.count; i++ {
+				locals = append(locals, local.typ)
+			}
+		}
+		fmt.Fprintf(b, "    (local %s)\n", strings.Join(locals, " "))
+	}
+
+	r := &reader{data: body.code}
+	depth := 0
+	for !r.eof() {
+		inst, err := readInstruction(r, m)
+		if err != nil {
+			fmt.Fprintf(b, "%s;; %s, rest of function omitted\n", indent(depth), err)
+			break
+		}
+		if inst.isEnd {
+			depth--
+			// 関数本体の最後の end は閉じ括弧で表す
+			if depth < 0 {
+				break
+			}
+		}
+		if inst.isElse {
+			b.WriteString(indent(depth-1) + inst.text + "\n")
+			continue
+		}
+		b.WriteString(indent(depth) + inst.text + "\n")
+		depth += max(inst.depth, 0)
+	}
+	b.WriteString("  )\n")
+}
+
+func indent(depth int) string {
+	if depth < 0 {
+		depth = 0
+	}
+	return strings.Repeat("  ", depth+2)
+}
+
+// escapeData データセグメントのバイト列をテキスト形式の文字列リテラルとしてエスケープ
+func escapeData(data []byte) string {
+	var b strings.Builder
+	for _, c := range data {
+		switch {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c >= 0x20 && c < 0x7f:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "\\%02x", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,271 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	textunicode "golang.org/x/text/encoding/unicode"
+)
+
+// 対応する文字コード（File.Encoding・Project.Charset の値）
+const (
+	EncodingAuto      = "auto"
+	EncodingUTF8      = "utf-8"
+	EncodingUTF16LE   = "utf-16le"
+	EncodingUTF16BE   = "utf-16be"
+	EncodingShiftJIS  = "shift_jis"
+	EncodingEUCJP     = "euc-jp"
+	EncodingISO2022JP = "iso-2022-jp"
+)
+
+// encodingAliases 表記ゆれのある文字コード名の正規化
+var encodingAliases = map[string]string{
+	"auto": EncodingAuto, "": EncodingAuto,
+	"utf-8": EncodingUTF8, "utf8": EncodingUTF8,
+	"utf-16le": EncodingUTF16LE, "utf-16be": EncodingUTF16BE,
+	"shift_jis": EncodingShiftJIS, "shift-jis": EncodingShiftJIS, "sjis": EncodingShiftJIS, "cp932": EncodingShiftJIS, "windows-31j": EncodingShiftJIS, "ms932": EncodingShiftJIS,
+	"euc-jp": EncodingEUCJP, "eucjp": EncodingEUCJP, "euc_jp": EncodingEUCJP,
+	"iso-2022-jp": EncodingISO2022JP, "jis": EncodingISO2022JP,
+}
+
+// EncodingDetection ファイルの文字コードの判定結果
+type EncodingDetection struct {
+	Encoding   string  `json:"encoding"` // 判定できなければ空（バイナリ）
+	BOM        bool    `json:"bom,omitempty"`
+	Confidence float64 `json:"confidence"`
+}
+
+// NormalizeEncoding 対応している文字コードの正式名と、対応しているかどうかを返す
+func NormalizeEncoding(name string) (string, bool) {
+	canonical, ok := encodingAliases[strings.ToLower(strings.TrimSpace(name))]
+	return canonical, ok
+}
+
+// DetectEncoding BOM、UTF-8 としての妥当性、プロジェクトの文字コードの指定、Shift_JIS と
+// EUC-JP のバイトパターンからテキストの文字コードを判定する
+// 文字コードが空の場合、内容は対応している文字コードのテキストではない
+func DetectEncoding(content []byte, hint string) EncodingDetection {
+	switch {
+	case bytes.HasPrefix(content, []byte{0xEF, 0xBB, 0xBF}):
+		return EncodingDetection{Encoding: EncodingUTF8, BOM: true, Confidence: 1}
+	case bytes.HasPrefix(content, []byte{0xFF, 0xFE}):
+		return EncodingDetection{Encoding: EncodingUTF16LE, BOM: true, Confidence: 1}
+	case bytes.HasPrefix(content, []byte{0xFE, 0xFF}):
+		return EncodingDetection{Encoding: EncodingUTF16BE, BOM: true, Confidence: 1}
+	}
+	// BOM のない UTF-16 は内容からは判定しにくいため、プロジェクトで指定されている場合だけ扱う
+	hint, _ = NormalizeEncoding(hint)
+	hasNUL := bytes.IndexByte(content, 0) >= 0
+	if (hint == EncodingUTF16LE || hint == EncodingUTF16BE) && (hasNUL || !utf8.Valid(content)) && isUTF16Text(content, hint == EncodingUTF16BE) {
+		return EncodingDetection{Encoding: hint, Confidence: 0.9}
+	}
+	// UTF-16 以外では NUL を含むものはバイナリ
+	if hasNUL {
+		return EncodingDetection{}
+	}
+	if utf8.Valid(content) {
+		// ISO-2022-JP はエスケープシーケンスを含む 7 ビットのテキスト
+		if bytes.Contains(content, []byte("\x1b$B")) || bytes.Contains(content, []byte("\x1b$@")) {
+			return EncodingDetection{Encoding: EncodingISO2022JP, Confidence: 0.95}
+		}
+		return EncodingDetection{Encoding: EncodingUTF8, Confidence: 1}
+	}
+
+	sjisErrors := invalidShiftJIS(content)
+	eucErrors := invalidEUCJP(content)
+	if (hint == EncodingShiftJIS && sjisErrors == 0) || (hint == EncodingEUCJP && eucErrors == 0) {
+		return EncodingDetection{Encoding: hint, Confidence: 0.95}
+	}
+
+	switch {
+	case sjisErrors == 0 && eucErrors > 0:
+		return EncodingDetection{Encoding: EncodingShiftJIS, Confidence: 0.9}
+	case eucErrors == 0 && sjisErrors > 0:
+		return EncodingDetection{Encoding: EncodingEUCJP, Confidence: 0.9}
+	case sjisErrors == 0 && eucErrors == 0:
+		// どちらとしても正しいバイト列は、復号した文字の自然さで選ぶ
+		sjisScore := japaneseScore(content, japanese.ShiftJIS)
+		eucScore := japaneseScore(content, japanese.EUCJP)
+		if eucScore > sjisScore {
+			return EncodingDetection{Encoding: EncodingEUCJP, Confidence: 0.7}
+		}
+		return EncodingDetection{Encoding: EncodingShiftJIS, Confidence: 0.7}
+	}
+
+	// 少数の不正なバイトはファイル末尾の切れた文字などとみなす
+	total := len(content)/100 + 1
+	switch {
+	case sjisErrors <= eucErrors && sjisErrors <= total:
+		return EncodingDetection{Encoding: EncodingShiftJIS, Confidence: 0.5}
+	case eucErrors < sjisErrors && eucErrors <= total:
+		return EncodingDetection{Encoding: EncodingEUCJP, Confidence: 0.5}
+	}
+	return EncodingDetection{}
+}
+
+// isUTF16Text BOM のない UTF-16 として正しく、NUL や改行以外の制御文字を含まないかどうか
+func isUTF16Text(content []byte, bigEndian bool) bool {
+	if len(content) == 0 || len(content)%2 != 0 {
+		return false
+	}
+	units := make([]uint16, len(content)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(content[2*i])<<8 | uint16(content[2*i+1])
+		} else {
+			units[i] = uint16(content[2*i+1])<<8 | uint16(content[2*i])
+		}
+	}
+	for _, r := range utf16.Decode(units) {
+		switch {
+		case r == utf8.RuneError:
+			// 対になっていないサロゲート
+			return false
+		case r < 0x20 && r != '\t' && r != '\n' && r != '\r' && r != '\f', r == 0x7F:
+			return false
+		}
+	}
+	return true
+}
+
+// invalidShiftJIS Shift_JIS（CP932）として不正なバイト列の数を数える
+func invalidShiftJIS(content []byte) int {
+	errors := 0
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c < 0x80, c >= 0xA1 && c <= 0xDF: // ASCII・半角カナ
+		case c >= 0x81 && c <= 0x9F, c >= 0xE0 && c <= 0xFC:
+			if i+1 >= len(content) {
+				errors++
+				break
+			}
+			t := content[i+1]
+			if t < 0x40 || t == 0x7F || t > 0xFC {
+				errors++
+				break
+			}
+			i++
+		default:
+			errors++
+		}
+	}
+	return errors
+}
+
+// invalidEUCJP EUC-JP として不正なバイト列の数を数える
+func invalidEUCJP(content []byte) int {
+	errors := 0
+	isTrail := func(b byte) bool { return b >= 0xA1 && b <= 0xFE }
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		switch {
+		case c < 0x80:
+		case c == 0x8E: // 半角カナ
+			if i+1 < len(content) && content[i+1] >= 0xA1 && content[i+1] <= 0xDF {
+				i++
+			} else {
+				errors++
+			}
+		case c == 0x8F: // JIS X 0212
+			if i+2 < len(content) && isTrail(content[i+1]) && isTrail(content[i+2]) {
+				i += 2
+			} else {
+				errors++
+			}
+		case isTrail(c):
+			if i+1 < len(content) && isTrail(content[i+1]) {
+				i++
+			} else {
+				errors++
+			}
+		default:
+			errors++
+		}
+	}
+	return errors
+}
+
+// japaneseScore デコードしたテキストの自然さを評価
+// ひらがなと漢字は加点、半角カタカナと置換文字は減点する
+func japaneseScore(content []byte, enc encoding.Encoding) int {
+	decoded, err := enc.NewDecoder().Bytes(content)
+	if err != nil {
+		return -len(content)
+	}
+	score := 0
+	for _, r := range string(decoded) {
+		switch {
+		case r >= 0x3040 && r <= 0x309F: // ひらがな
+			score += 3
+		case r >= 0x30A0 && r <= 0x30FF, r >= 0x4E00 && r <= 0x9FFF, r >= 0x3000 && r <= 0x303F:
+			score++
+		case r >= 0xFF61 && r <= 0xFF9F: // 半角カナ
+			score--
+		case r == utf8.RuneError:
+			score -= 5
+		}
+	}
+	return score
+}
+
+func textEncoding(name string, bom bool) (encoding.Encoding, error) {
+	switch name {
+	case EncodingShiftJIS:
+		return japanese.ShiftJIS, nil
+	case EncodingEUCJP:
+		return japanese.EUCJP, nil
+	case EncodingISO2022JP:
+		return japanese.ISO2022JP, nil
+	case EncodingUTF16LE:
+		if bom {
+			return textunicode.UTF16(textunicode.LittleEndian, textunicode.ExpectBOM), nil
+		}
+		return textunicode.UTF16(textunicode.LittleEndian, textunicode.IgnoreBOM), nil
+	case EncodingUTF16BE:
+		if bom {
+			return textunicode.UTF16(textunicode.BigEndian, textunicode.ExpectBOM), nil
+		}
+		return textunicode.UTF16(textunicode.BigEndian, textunicode.IgnoreBOM), nil
+	case EncodingUTF8:
+		if bom {
+			return textunicode.UTF8BOM, nil
+		}
+		return textunicode.UTF8, nil
+	}
+	return nil, fmt.Errorf("unsupported encoding: %s", name)
+}
+
+// DecodeToUTF8 判定した文字コードの内容を UTF-8 に変換し、BOM を取り除く
+func DecodeToUTF8(content []byte, detection EncodingDetection) (string, error) {
+	if detection.Encoding == EncodingUTF8 && !detection.BOM {
+		return string(content), nil
+	}
+	enc, err := textEncoding(detection.Encoding, detection.BOM)
+	if err != nil {
+		return "", err
+	}
+	decoded, err := enc.NewDecoder().Bytes(content)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+// EncodeFromUTF8 エクスポートしたファイルがアップロードと一致するよう、UTF-8 のテキストを
+// ファイルの元の文字コードに戻し、BOM も復元する
+func EncodeFromUTF8(content string, encodingName string, bom bool) ([]byte, error) {
+	if encodingName == "" || encodingName == EncodingUTF8 && !bom {
+		return []byte(content), nil
+	}
+	enc, err := textEncoding(encodingName, bom)
+	if err != nil {
+		return nil, err
+	}
+	return enc.NewEncoder().Bytes([]byte(content))
+}
@@ -0,0 +1,215 @@
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	textunicode "golang.org/x/text/encoding/unicode"
+)
+
+const japaneseText = "// 設定ファイルを読み込む\nfunc load() {}\n"
+
+func mustEncode(t *testing.T, enc encoding.Encoding, text string) []byte {
+	t.Helper()
+	data, err := enc.NewEncoder().Bytes([]byte(text))
+	if err != nil {
+		t.Fatalf("failed to encode test data: %v", err)
+	}
+	return data
+}
+
+func TestDetectEncoding(t *testing.T) {
+	utf16le := textunicode.UTF16(textunicode.LittleEndian, textunicode.IgnoreBOM)
+	utf16be := textunicode.UTF16(textunicode.BigEndian, textunicode.IgnoreBOM)
+
+	tests := []struct {
+		name    string
+		content []byte
+		hint    string
+		want    EncodingDetection
+	}{
+		{
+			name:    "ascii",
+			content: []byte("package main\n"),
+			want:    EncodingDetection{Encoding: EncodingUTF8, Confidence: 1},
+		},
+		{
+			name:    "utf-8",
+			content: []byte(japaneseText),
+			want:    EncodingDetection{Encoding: EncodingUTF8, Confidence: 1},
+		},
+		{
+			name:    "utf-8 bom",
+			content: append([]byte{0xEF, 0xBB, 0xBF}, japaneseText...),
+			want:    EncodingDetection{Encoding: EncodingUTF8, BOM: true, Confidence: 1},
+		},
+		{
+			name:    "utf-16le bom",
+			content: append([]byte{0xFF, 0xFE}, mustEncode(t, utf16le, japaneseText)...),
+			want:    EncodingDetection{Encoding: EncodingUTF16LE, BOM: true, Confidence: 1},
+		},
+		{
+			name:    "utf-16be bom",
+			content: append([]byte{0xFE, 0xFF}, mustEncode(t, utf16be, japaneseText)...),
+			want:    EncodingDetection{Encoding: EncodingUTF16BE, BOM: true, Confidence: 1},
+		},
+		{
+			name:    "shift_jis",
+			content: mustEncode(t, japanese.ShiftJIS, japaneseText),
+			want:    EncodingDetection{Encoding: EncodingShiftJIS, Confidence: 0.9},
+		},
+		{
+			name:    "euc-jp",
+			content: mustEncode(t, japanese.EUCJP, japaneseText),
+			want:    EncodingDetection{Encoding: EncodingEUCJP, Confidence: 0.9},
+		},
+		{
+			// ひらがなだけの EUC-JP は Shift_JIS の半角カナとしても正しい
+			name:    "euc-jp also valid as shift_jis",
+			content: mustEncode(t, japanese.EUCJP, "ひらがな"),
+			want:    EncodingDetection{Encoding: EncodingEUCJP, Confidence: 0.7},
+		},
+		{
+			name:    "shift_jis with hint",
+			content: mustEncode(t, japanese.ShiftJIS, japaneseText),
+			hint:    "cp932",
+			want:    EncodingDetection{Encoding: EncodingShiftJIS, Confidence: 0.95},
+		},
+		{
+			name:    "euc-jp with hint",
+			content: mustEncode(t, japanese.EUCJP, japaneseText),
+			hint:    "EUC-JP",
+			want:    EncodingDetection{Encoding: EncodingEUCJP, Confidence: 0.95},
+		},
+		{
+			name:    "hint does not override valid utf-8",
+			content: []byte(japaneseText),
+			hint:    EncodingShiftJIS,
+			want:    EncodingDetection{Encoding: EncodingUTF8, Confidence: 1},
+		},
+		{
+			name:    "iso-2022-jp",
+			content: mustEncode(t, japanese.ISO2022JP, japaneseText),
+			want:    EncodingDetection{Encoding: EncodingISO2022JP, Confidence: 0.95},
+		},
+		{
+			name:    "utf-16le without bom and hint",
+			content: mustEncode(t, utf16le, japaneseText),
+			hint:    EncodingUTF16LE,
+			want:    EncodingDetection{Encoding: EncodingUTF16LE, Confidence: 0.9},
+		},
+		{
+			name:    "utf-16be without bom and hint",
+			content: mustEncode(t, utf16be, japaneseText),
+			hint:    "UTF-16BE",
+			want:    EncodingDetection{Encoding: EncodingUTF16BE, Confidence: 0.9},
+		},
+		{
+			name:    "utf-16le without bom and no hint is binary",
+			content: mustEncode(t, utf16le, japaneseText),
+			want:    EncodingDetection{},
+		},
+		{
+			name:    "utf-16 hint does not apply to utf-8",
+			content: []byte("plain ascii\n"),
+			hint:    EncodingUTF16LE,
+			want:    EncodingDetection{Encoding: EncodingUTF8, Confidence: 1},
+		},
+		{
+			name:    "utf-16 hint does not apply to binary",
+			content: []byte{0x7F, 'E', 'L', 'F', 0x02, 0x01, 0x01, 0x00, 0x00, 0x00},
+			hint:    EncodingUTF16LE,
+			want:    EncodingDetection{},
+		},
+		{
+			name:    "binary",
+			content: []byte{0x00, 0x61, 0x73, 0x6D, 0x01, 0x00, 0x00, 0x00},
+			want:    EncodingDetection{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectEncoding(tt.content, tt.hint); got != tt.want {
+				t.Errorf("DetectEncoding() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodingRoundTrip(t *testing.T) {
+	tests := []struct {
+		encoding string
+		bom      bool
+		hint     string
+	}{
+		{encoding: EncodingUTF8},
+		{encoding: EncodingUTF8, bom: true},
+		{encoding: EncodingShiftJIS},
+		{encoding: EncodingEUCJP},
+		{encoding: EncodingISO2022JP},
+		{encoding: EncodingUTF16LE, bom: true},
+		{encoding: EncodingUTF16BE, bom: true},
+		{encoding: EncodingUTF16LE, hint: EncodingUTF16LE},
+		{encoding: EncodingUTF16BE, hint: EncodingUTF16BE},
+	}
+
+	for _, tt := range tests {
+		name := tt.encoding
+		if tt.bom {
+			name += "_bom"
+		}
+		t.Run(name, func(t *testing.T) {
+			original, err := EncodeFromUTF8(japaneseText, tt.encoding, tt.bom)
+			if err != nil {
+				t.Fatalf("EncodeFromUTF8 returned error: %v", err)
+			}
+
+			detection := DetectEncoding(original, tt.hint)
+			if detection.Encoding != tt.encoding || detection.BOM != tt.bom {
+				t.Fatalf("DetectEncoding() = %+v, want %s (bom %v)", detection, tt.encoding, tt.bom)
+			}
+			text, err := DecodeToUTF8(original, detection)
+			if err != nil {
+				t.Fatalf("DecodeToUTF8 returned error: %v", err)
+			}
+			if text != japaneseText {
+				t.Errorf("DecodeToUTF8() = %q, want %q", text, japaneseText)
+			}
+
+			// 元の文字コードに戻すとアップロードされたバイト列と一致する
+			encoded, err := EncodeFromUTF8(text, detection.Encoding, detection.BOM)
+			if err != nil {
+				t.Fatalf("EncodeFromUTF8 returned error: %v", err)
+			}
+			if !bytes.Equal(encoded, original) {
+				t.Errorf("EncodeFromUTF8() = %x, want %x", encoded, original)
+			}
+		})
+	}
+}
+
+func TestNormalizeEncoding(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"", EncodingAuto, true},
+		{" SJIS ", EncodingShiftJIS, true},
+		{"Windows-31J", EncodingShiftJIS, true},
+		{"eucjp", EncodingEUCJP, true},
+		{"UTF8", EncodingUTF8, true},
+		{"utf-16le", EncodingUTF16LE, true},
+		{"latin1", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NormalizeEncoding(tt.name)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizeEncoding(%q) = (%q, %v), want (%q, %v)", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
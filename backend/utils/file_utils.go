@@ -1,71 +1,100 @@
 package utils
 
 import (
+	"os"
 	"path/filepath"
 	"strings"
 	"unicode/utf8"
 )
 
+// languageExtensions 拡張子と言語の対応表
+var languageExtensions = map[string]string{
+	".go":         "go",
+	".js":         "javascript",
+	".ts":         "typescript",
+	".jsx":        "javascript",
+	".mjs":        "javascript",
+	".cjs":        "javascript",
+	".tsx":        "typescript",
+	".py":         "python",
+	".java":       "java",
+	".class":      "java-bytecode",
+	".jar":        "java-archive",
+	".war":        "java-archive",
+	".ear":        "java-archive",
+	".wasm":       "wasm",
+	".wat":        "wat",
+	".apk":        "android-package",
+	".c":          "c",
+	".cpp":        "cpp",
+	".cc":         "cpp",
+	".cxx":        "cpp",
+	".h":          "c",
+	".hpp":        "cpp",
+	".cs":         "csharp",
+	".php":        "php",
+	".rb":         "ruby",
+	".rs":         "rust",
+	".swift":      "swift",
+	".kt":         "kotlin",
+	".scala":      "scala",
+	".r":          "r",
+	".sql":        "sql",
+	".sh":         "shell",
+	".bash":       "shell",
+	".zsh":        "shell",
+	".ps1":        "powershell",
+	".html":       "html",
+	".htm":        "html",
+	".css":        "css",
+	".scss":       "scss",
+	".sass":       "sass",
+	".json":       "json",
+	".xml":        "xml",
+	".yaml":       "yaml",
+	".yml":        "yaml",
+	".toml":       "toml",
+	".md":         "markdown",
+	".txt":        "text",
+	".dockerfile": "dockerfile",
+}
+
 // DetectLanguage ファイル名から言語を推測
 func DetectLanguage(filename string) string {
 	ext := strings.ToLower(filepath.Ext(filename))
 
-	languageMap := map[string]string{
-		".go":         "go",
-		".js":         "javascript",
-		".ts":         "typescript",
-		".jsx":        "javascript",
-		".tsx":        "typescript",
-		".py":         "python",
-		".java":       "java",
-		".c":          "c",
-		".cpp":        "cpp",
-		".cc":         "cpp",
-		".cxx":        "cpp",
-		".h":          "c",
-		".hpp":        "cpp",
-		".cs":         "csharp",
-		".php":        "php",
-		".rb":         "ruby",
-		".rs":         "rust",
-		".swift":      "swift",
-		".kt":         "kotlin",
-		".scala":      "scala",
-		".r":          "r",
-		".sql":        "sql",
-		".sh":         "shell",
-		".bash":       "shell",
-		".zsh":        "shell",
-		".ps1":        "powershell",
-		".html":       "html",
-		".htm":        "html",
-		".css":        "css",
-		".scss":       "scss",
-		".sass":       "sass",
-		".json":       "json",
-		".xml":        "xml",
-		".yaml":       "yaml",
-		".yml":        "yaml",
-		".toml":       "toml",
-		".md":         "markdown",
-		".txt":        "text",
-		".dockerfile": "dockerfile",
-	}
-
-	if lang, exists := languageMap[ext]; exists {
+	if lang, exists := languageExtensions[ext]; exists {
 		return lang
 	}
 
 	// Dockerfileなどの特別なファイル名をチェック
+	if lang := filenameLanguage(filename); lang != "" {
+		return lang
+	}
+
+	return "unknown"
+}
+
+// filenameLanguage 拡張子ではなくファイル名で言語が決まるファイル（Dockerfile など）の言語を返す
+func filenameLanguage(filename string) string {
 	baseName := strings.ToLower(filepath.Base(filename))
-	if baseName == "dockerfile" || strings.HasPrefix(baseName, "dockerfile.") {
+	if baseName == "dockerfile" || strings.HasPrefix(baseName, "dockerfile.") || strings.HasSuffix(baseName, ".dockerfile") {
 		return "dockerfile"
 	}
-	if baseName == "makefile" || strings.HasPrefix(baseName, "makefile.") {
+	if baseName == "makefile" || baseName == "gnumakefile" || strings.HasPrefix(baseName, "makefile.") {
 		return "makefile"
 	}
-
-	return "unknown"
+	switch baseName {
+	case "gemfile", "rakefile", "vagrantfile", "podfile", "guardfile":
+		return "ruby"
+	case "jenkinsfile":
+		return "groovy"
+	case "cmakelists.txt":
+		return "cmake"
+	case ".bashrc", ".bash_profile", ".profile", ".zshrc", "pkgbuild":
+		return "shell"
+	}
+	return ""
 }
 
 // IsTextFile バイト配列がテキストファイルかどうかを判定
@@ -168,3 +197,18 @@ func SanitizeFilename(filename string) string {
 
 	return filename
 }
+
+// RemoveEmptyDirs root 以下の空のディレクトリを（root 自身も含めて）削除する
+func RemoveEmptyDirs(root string) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			RemoveEmptyDirs(filepath.Join(root, entry.Name()))
+		}
+	}
+	// 空でなければ os.Remove は失敗するので、ファイルが残っているディレクトリはそのまま
+	os.Remove(root)
+}
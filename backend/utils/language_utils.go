@@ -0,0 +1,355 @@
+package utils
+
+import (
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 言語判定の根拠
+const (
+	LanguageSourceModeline  = "modeline"
+	LanguageSourceShebang   = "shebang"
+	LanguageSourceFilename  = "filename"
+	LanguageSourceTemplate  = "template"
+	LanguageSourceExtension = "extension"
+	LanguageSourceContent   = "content"
+	LanguageSourceManual    = "manual"
+)
+
+const (
+	// classifierSampleSize 分類器が読む内容の先頭バイト数
+	classifierSampleSize = 64 << 10
+	// minContentConfidence これ未満の内容判定は採用しない
+	minContentConfidence = 0.2
+)
+
+// LanguageDetection ファイルの言語の判定結果と、その確信度、決め手になった手がかり
+type LanguageDetection struct {
+	Language   string  `json:"language"`
+	Confidence float64 `json:"confidence"` // 0〜1
+	Source     string  `json:"source,omitempty"`
+}
+
+// languageAliases shebang・modeline で使われる名前と言語の対応表
+var languageAliases = map[string]string{
+	"sh": "shell", "bash": "shell", "zsh": "shell", "dash": "shell", "ksh": "shell", "ash": "shell", "shell": "shell",
+	"python": "python", "python2": "python", "python3": "python", "py": "python", "pypy": "python", "pypy3": "python",
+	"node": "javascript", "nodejs": "javascript", "bun": "javascript", "javascript": "javascript", "js": "javascript",
+	"deno": "typescript", "ts-node": "typescript", "tsx": "typescript", "typescript": "typescript", "ts": "typescript",
+	"ruby": "ruby", "rb": "ruby", "perl": "perl", "perl5": "perl", "pl": "perl", "php": "php", "lua": "lua", "luajit": "lua",
+	"pwsh": "powershell", "powershell": "powershell", "ps1": "powershell",
+	"c": "c", "cpp": "cpp", "c++": "cpp", "cs": "csharp", "csharp": "csharp", "go": "go", "golang": "go",
+	"java": "java", "kotlin": "kotlin", "scala": "scala", "rust": "rust", "rs": "rust", "swift": "swift",
+	"objc": "objective-c", "objective-c": "objective-c", "sql": "sql", "html": "html", "css": "css",
+	"json": "json", "xml": "xml", "yaml": "yaml", "yml": "yaml", "markdown": "markdown", "md": "markdown",
+	"make": "makefile", "makefile": "makefile", "dockerfile": "dockerfile", "groovy": "groovy", "cmake": "cmake",
+}
+
+// templateExtensions テンプレートエンジンの拡張子（index.html.erb の .erb）
+var templateExtensions = map[string]bool{
+	".erb": true, ".ejs": true, ".j2": true, ".jinja": true, ".jinja2": true, ".tmpl": true, ".tpl": true,
+	".hbs": true, ".handlebars": true, ".mustache": true, ".twig": true, ".liquid": true, ".njk": true,
+}
+
+// ambiguousExtensions 複数の言語で使われる拡張子と、内容で判別する候補
+var ambiguousExtensions = map[string][]string{
+	".h":   {"c", "cpp", "objective-c"},
+	".inc": {"php", "c", "cpp", "sql"},
+	".pl":  {"perl"},
+	".m":   {"objective-c"},
+}
+
+var (
+	modelinePattern = regexp.MustCompile(`(?:vim?|ex):.*?\b(?:ft|filetype|syntax)=([\w+-]+)`)
+	emacsPattern    = regexp.MustCompile(`-\*-\s*(?:mode:\s*)?([\w+-]+)\s*(?:;.*)?-\*-`)
+	templatePattern = regexp.MustCompile(`(?s)\{\{.*?\}\}|\{%.*?%\}|<%.*?%>`)
+	tokenPattern    = regexp.MustCompile(`<\?php|<\?xml|#\s*[a-z]+|[A-Za-z_$@][A-Za-z0-9_]*|:=|::|->|=>|<-|===|!==|=~|~=|\$\(|\[\[|\]\]|</|\.\.|[{}();<>=\[\]]`)
+	yamlLinePattern = regexp.MustCompile(`^\s*(- |-$|[\w.\-"']+:(\s|$)|---|#)`)
+)
+
+// languageProfiles 言語ごとの特徴的なトークンと重み（1〜8）
+var languageProfiles = parseProfiles(map[string]string{
+	"go":          "package:1 func:3 :=:3 chan:4 defer:4 go:1 struct:1 interface:1 nil:2 fmt:3 err:2 range:2 <-:2 var:1",
+	"javascript":  "function:2 const:1 let:1 var:1 =>:1 ===:3 !==:3 require:3 module:2 exports:3 console:3 undefined:2 this:1 async:1 await:1 null:1 document:3 window:3 prototype:3",
+	"typescript":  "interface:2 type:1 const:1 let:1 =>:1 ===:2 export:2 import:2 from:2 readonly:3 implements:2 private:1 public:1 enum:2 any:3 string:1 number:3 boolean:3 void:1 unknown:3 keyof:4 as:1",
+	"python":      "def:4 self:3 elif:4 None:4 True:2 False:2 import:1 from:1 lambda:2 print:2 __init__:4 __name__:4 pass:3 raise:2 except:3 yield:1 with:1 not:1 and:1 or:1 is:1",
+	"java":        "public:2 private:2 protected:2 class:1 static:1 void:1 final:2 extends:1 implements:2 package:1 import:1 new:1 String:2 System:3 throws:4 @Override:4 null:1 boolean:2 ArrayList:3",
+	"c":           "#include:3 #define:3 int:1 char:2 void:1 struct:2 typedef:3 unsigned:3 sizeof:3 malloc:4 free:3 NULL:4 printf:3 static:1 const:1 ->:2 #ifdef:2 #ifndef:2 #endif:2",
+	"cpp":         "#include:2 std:4 :::3 template:4 typename:4 namespace:3 class:2 public:1 private:1 virtual:4 override:2 nullptr:4 auto:2 new:1 delete:2 cout:4 const:1 operator:3 using:2 constexpr:4",
+	"csharp":      "using:2 namespace:2 public:1 class:1 static:1 void:1 string:1 var:1 get:2 set:2 async:1 await:1 Task:3 Console:4 override:2 readonly:2 internal:3 sealed:3 foreach:3 =>:1",
+	"php":         "<?php:8 $this:4 function:1 echo:3 array:2 public:1 ->:2 =>:1 namespace:1 use:1 foreach:2 isset:4 $var:2",
+	"ruby":        "def:2 end:4 require:2 module:2 class:1 attr_accessor:5 attr_reader:5 puts:4 elsif:5 unless:3 do:1 nil:2 self:1 yield:1 lambda:1 each:2",
+	"rust":        "fn:4 let:1 mut:4 impl:4 pub:3 use:1 struct:1 enum:1 match:2 Some:3 Ok:3 Err:3 unwrap:4 crate:4 trait:3 ->:1 :::2 mod:2 Self:2 Vec:3 println:3",
+	"swift":       "func:3 let:2 var:1 import:1 guard:4 struct:1 class:1 extension:3 protocol:3 self:1 nil:2 override:1 init:2 @objc:4 ->:1 fileprivate:5 UIKit:5 Foundation:4",
+	"kotlin":      "fun:5 val:4 var:1 package:1 import:1 class:1 data:2 object:2 companion:5 when:3 override:2 null:1 it:2 lateinit:5 suspend:4 println:2",
+	"scala":       "def:2 val:3 var:1 object:3 class:1 trait:3 case:2 match:3 extends:1 with:1 implicit:5 import:1 =>:2 sealed:3 Some:2",
+	"shell":       "if:1 then:4 fi:5 else:1 elif:2 do:1 done:5 esac:5 case:1 echo:3 export:2 local:2 $var:1 $(:3 exit:2 [[:3 ]]:3",
+	"powershell":  "param:3 $_:4 foreach:1 Write:2 Output:2 Host:2 ChildItem:4 Object:1 $true:4 $false:4 $null:4 PSObject:5 CmdletBinding:5 Parameter:3",
+	"sql":         "SELECT:4 FROM:2 WHERE:3 INSERT:4 INTO:3 UPDATE:2 DELETE:2 CREATE:2 TABLE:4 JOIN:4 VALUES:3 select:3 from:1 where:2 insert:2 table:2 join:2 varchar:4 VARCHAR:4 PRIMARY:4",
+	"html":        "DOCTYPE:5 html:3 div:3 span:2 href:3 head:2 body:2 meta:3 title:1 </:2",
+	"css":         "px:4 color:3 background:3 margin:3 padding:3 display:3 font:2 border:2 important:3 rgba:3 @media:5",
+	"objective-c": "@interface:5 @implementation:5 @end:4 @property:4 #import:4 NSString:5 self:1 @synthesize:5 alloc:4 YES:3",
+	"perl":        "my:4 sub:3 use:1 strict:3 warnings:3 $var:1 @_:5 foreach:2 elsif:2 unless:1 =~:4 qw:4 __END__:4",
+	"lua":         "local:3 function:1 end:3 then:2 elseif:4 nil:2 require:1 ~=:4 pairs:4 ipairs:5 ..:1",
+	"dockerfile":  "FROM:3 RUN:4 COPY:3 WORKDIR:5 ENTRYPOINT:5 CMD:3 EXPOSE:5 ENV:3 ARG:2",
+})
+
+// parseProfiles "token:weight" のリストを解析
+// トークン自体にコロンを含むことがあるため、重みは最後のコロンの後ろとする
+func parseProfiles(source map[string]string) map[string]map[string]float64 {
+	profiles := make(map[string]map[string]float64, len(source))
+	for language, list := range source {
+		profile := make(map[string]float64)
+		for _, field := range strings.Fields(list) {
+			i := strings.LastIndexByte(field, ':')
+			weight, err := strconv.ParseFloat(field[i+1:], 64)
+			if err != nil {
+				panic("invalid language profile " + language + ": " + field)
+			}
+			profile[field[:i]] = weight
+		}
+		profiles[language] = profile
+	}
+	return profiles
+}
+
+// DetectLanguageFromContent モードライン、シバン、ファイル名、拡張子、内容の優先順でファイルの言語を判定
+// 内容は拡張子のないファイルか曖昧なファイルの場合だけ参照する
+func DetectLanguageFromContent(filename, content string) LanguageDetection {
+	if content != "" {
+		if lang := modelineLanguage(content); lang != "" {
+			return LanguageDetection{Language: lang, Confidence: 0.99, Source: LanguageSourceModeline}
+		}
+		if lang := shebangLanguage(content); lang != "" {
+			return LanguageDetection{Language: lang, Confidence: 0.98, Source: LanguageSourceShebang}
+		}
+	}
+	if lang := filenameLanguage(filename); lang != "" {
+		return LanguageDetection{Language: lang, Confidence: 0.95, Source: LanguageSourceFilename}
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	// テンプレートは内側の拡張子（index.html.erb の .html）か、タグを除いた内容で判定する
+	if templateExtensions[ext] {
+		inner := strings.TrimSuffix(filename, filepath.Ext(filename))
+		if lang, ok := languageExtensions[strings.ToLower(filepath.Ext(inner))]; ok {
+			return LanguageDetection{Language: lang, Confidence: 0.8, Source: LanguageSourceTemplate}
+		}
+		if content != "" {
+			if detection := classifyContent(templatePattern.ReplaceAllString(content, " "), nil); detection.Language != "unknown" {
+				detection.Source = LanguageSourceTemplate
+				return detection
+			}
+		}
+		return LanguageDetection{Language: "template", Confidence: 0.5, Source: LanguageSourceExtension}
+	}
+
+	if candidates, ok := ambiguousExtensions[ext]; ok && content != "" {
+		if detection := classifyContent(content, candidates); detection.Language != "unknown" {
+			return detection
+		}
+		return LanguageDetection{Language: candidates[0], Confidence: 0.5, Source: LanguageSourceExtension}
+	}
+	if lang, ok := languageExtensions[ext]; ok {
+		// Qt の翻訳ファイル（.ts）のように拡張子が別の形式と衝突する場合
+		if strings.HasPrefix(strings.TrimSpace(content), "<?xml") && lang != "xml" && lang != "html" {
+			return LanguageDetection{Language: "xml", Confidence: 0.9, Source: LanguageSourceContent}
+		}
+		return LanguageDetection{Language: lang, Confidence: 0.9, Source: LanguageSourceExtension}
+	}
+	if lang, ok := ambiguousExtensions[ext]; ok {
+		return LanguageDetection{Language: lang[0], Confidence: 0.5, Source: LanguageSourceExtension}
+	}
+
+	if content == "" {
+		return LanguageDetection{Language: "unknown"}
+	}
+	return classifyContent(content, nil)
+}
+
+// KnownLanguages DetectLanguageFromContent が返しうるすべての言語をソートして返す
+func KnownLanguages() []string {
+	languages := make([]string, 0, len(knownLanguages))
+	for language := range knownLanguages {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// IsKnownLanguage language が DetectLanguageFromContent の返しうる言語かを判定
+func IsKnownLanguage(language string) bool {
+	return knownLanguages[language]
+}
+
+// knownLanguages 判定結果になりうる言語（unknown は含めない）
+var knownLanguages = func() map[string]bool {
+	languages := map[string]bool{"template": true, "xml": true, "json": true, "yaml": true}
+	for _, language := range languageExtensions {
+		languages[language] = true
+	}
+	for _, language := range languageAliases {
+		languages[language] = true
+	}
+	for _, candidates := range ambiguousExtensions {
+		for _, language := range candidates {
+			languages[language] = true
+		}
+	}
+	for language := range languageProfiles {
+		languages[language] = true
+	}
+	return languages
+}()
+
+// modelineLanguage 先頭または末尾の 5 行にある vim または emacs のモードラインを読む
+func modelineLanguage(content string) string {
+	lines := strings.SplitN(content, "\n", 6)
+	if len(lines) > 5 {
+		lines = lines[:5]
+	}
+	if tail := strings.Split(strings.TrimRight(content, "\n"), "\n"); len(tail) > 5 {
+		lines = append(lines, tail[len(tail)-5:]...)
+	}
+	for _, line := range lines {
+		if len(line) > 512 {
+			continue
+		}
+		for _, pattern := range []*regexp.Regexp{modelinePattern, emacsPattern} {
+			if m := pattern.FindStringSubmatch(line); m != nil {
+				if lang, ok := languageAliases[strings.ToLower(m[1])]; ok {
+					return lang
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// shebangLanguage #! 行のインタープリターから言語を判定
+func shebangLanguage(content string) string {
+	if !strings.HasPrefix(content, "#!") {
+		return ""
+	}
+	line := content[2:]
+	if i := strings.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+	interpreter := filepath.Base(fields[0])
+	// #!/usr/bin/env [-S] python3 の形式
+	if interpreter == "env" {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if !strings.HasPrefix(field, "-") && !strings.Contains(field, "=") {
+				interpreter = filepath.Base(field)
+				break
+			}
+		}
+	}
+	// python3.11 のようなバージョン付きの名前
+	interpreter = strings.TrimRight(strings.ToLower(interpreter), "0123456789.")
+	return languageAliases[interpreter]
+}
+
+// classifyContent 内容を言語のプロファイルで採点し、最も合うものを返す
+// candidates は対象にする言語を限定する
+func classifyContent(content string, candidates []string) LanguageDetection {
+	if len(content) > classifierSampleSize {
+		content = content[:classifierSampleSize]
+	}
+	trimmed := strings.TrimSpace(content)
+
+	// 構造で判定できる形式
+	if candidates == nil {
+		switch {
+		case strings.HasPrefix(trimmed, "<?xml"):
+			return LanguageDetection{Language: "xml", Confidence: 0.95, Source: LanguageSourceContent}
+		case (strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")) && json.Valid([]byte(trimmed)):
+			return LanguageDetection{Language: "json", Confidence: 0.95, Source: LanguageSourceContent}
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, token := range tokenPattern.FindAllString(content, -1) {
+		if strings.HasPrefix(token, "#") {
+			token = "#" + strings.TrimSpace(token[1:])
+		}
+		counts[token]++
+		if strings.HasPrefix(token, "$") && len(token) > 1 && token != "$(" {
+			counts["$var"]++
+		}
+	}
+
+	languages := candidates
+	if languages == nil {
+		for language := range languageProfiles {
+			languages = append(languages, language)
+		}
+	}
+	type score struct {
+		language string
+		value    float64
+	}
+	var scores []score
+	for _, language := range languages {
+		profile := languageProfiles[language]
+		value := 0.0
+		for token, weight := range profile {
+			// 同じトークンの繰り返しは対数で効かせる
+			if n := counts[token]; n > 0 {
+				value += weight * (1 + math.Log(float64(n)))
+			}
+		}
+		scores = append(scores, score{language, value})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].value != scores[j].value {
+			return scores[i].value > scores[j].value
+		}
+		return scores[i].language < scores[j].language
+	})
+
+	if candidates == nil && len(scores) > 0 && scores[0].value < 10 && isYAML(trimmed) {
+		return LanguageDetection{Language: "yaml", Confidence: 0.7, Source: LanguageSourceContent}
+	}
+	if len(scores) == 0 || scores[0].value == 0 {
+		return LanguageDetection{Language: "unknown"}
+	}
+
+	best, second := scores[0].value, 0.0
+	if len(scores) > 1 {
+		second = scores[1].value
+	}
+	// 2 位との差（どれだけ他の言語と区別できたか）と根拠の量の両方を反映する
+	margin := (best - second) / best
+	evidence := 1 - math.Exp(-best/15)
+	confidence := math.Round(math.Sqrt(margin)*evidence*100) / 100
+	if confidence < minContentConfidence {
+		return LanguageDetection{Language: "unknown", Confidence: confidence}
+	}
+	return LanguageDetection{Language: scores[0].language, Confidence: confidence, Source: LanguageSourceContent}
+}
+
+// isYAML ほとんどの行が YAML のキー、リストの要素、コメントに見えるかを判定
+func isYAML(content string) bool {
+	lines, matched := 0, 0
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines++
+		if yamlLinePattern.MatchString(line) || strings.HasPrefix(line, "  ") {
+			matched++
+		}
+	}
+	return lines >= 3 && matched*10 >= lines*8
+}
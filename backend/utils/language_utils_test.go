@@ -0,0 +1,268 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguageFromContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		want     string
+		source   string
+	}{
+		{
+			name:     "c header",
+			filename: "list.h",
+			content:  "#include <stdio.h>\ntypedef struct node { int v; struct node *next; } node_t;\nvoid *xmalloc(size_t n);\n#define MAX 10\n",
+			want:     "c",
+			source:   LanguageSourceContent,
+		},
+		{
+			name:     "c++ header",
+			filename: "stack.h",
+			content:  "#include <vector>\nnamespace app {\ntemplate <typename T>\nclass Stack {\npublic:\n  virtual ~Stack() = default;\n  std::vector<T> items;\n};\n}\n",
+			want:     "cpp",
+			source:   LanguageSourceContent,
+		},
+		{
+			name:     "objective-c header",
+			filename: "Person.h",
+			content:  "#import <Foundation/Foundation.h>\n@interface Person : NSObject\n@property NSString *name;\n@end\n",
+			want:     "objective-c",
+			source:   LanguageSourceContent,
+		},
+		{
+			name:     "header without evidence falls back to c",
+			filename: "empty.h",
+			content:  "\n",
+			want:     "c",
+			source:   LanguageSourceExtension,
+		},
+		{
+			name:     "objective-c implementation",
+			filename: "Person.m",
+			content:  "#import \"Person.h\"\n@implementation Person\n@synthesize name;\n- (id)init { self = [super init]; return self; }\n@end\n",
+			want:     "objective-c",
+			source:   LanguageSourceContent,
+		},
+		{
+			// MATLAB は判定対象外。内容からは判定せず、拡張子の第一候補を低い確信度で返す
+			name:     "matlab is not confidently objective-c",
+			filename: "square.m",
+			content:  "function y = square(x)\n  y = x.^2;\nend\n",
+			want:     "objective-c",
+			source:   LanguageSourceExtension,
+		},
+		{
+			name:     "php include",
+			filename: "config.inc",
+			content:  "<?php\n$config = array('db' => 'x');\necho $config['db'];\n",
+			want:     "php",
+			source:   LanguageSourceContent,
+		},
+		{
+			name:     "sql include",
+			filename: "schema.inc",
+			content:  "SELECT id, name FROM users WHERE id = 1;\nINSERT INTO logs VALUES (1);\n",
+			want:     "sql",
+			source:   LanguageSourceContent,
+		},
+		{
+			name:     "c include",
+			filename: "buffer.inc",
+			content:  "#define BUF 256\nstatic unsigned char buf[BUF];\n",
+			want:     "c",
+			source:   LanguageSourceContent,
+		},
+		{
+			name:     "env -S shebang",
+			filename: "run",
+			content:  "#!/usr/bin/env -S python3 -u\nprint('hi')\n",
+			want:     "python",
+			source:   LanguageSourceShebang,
+		},
+		{
+			name:     "versioned interpreter",
+			filename: "run",
+			content:  "#!/usr/bin/python3.11\nprint('hi')\n",
+			want:     "python",
+			source:   LanguageSourceShebang,
+		},
+		{
+			name:     "shebang wins over extension",
+			filename: "deploy.txt",
+			content:  "#!/bin/bash\r\necho hi\r\n",
+			want:     "shell",
+			source:   LanguageSourceShebang,
+		},
+		{
+			name:     "vim modeline",
+			filename: "notes.txt",
+			content:  "# vim: set ft=ruby :\nputs 1\n",
+			want:     "ruby",
+			source:   LanguageSourceModeline,
+		},
+		{
+			name:     "emacs modeline wins over extension",
+			filename: "vector.c",
+			content:  "/* -*- mode: c++ -*- */\nint x;\n",
+			want:     "cpp",
+			source:   LanguageSourceModeline,
+		},
+		{
+			name:     "special file name",
+			filename: "Dockerfile.dev",
+			content:  "FROM alpine\n",
+			want:     "dockerfile",
+			source:   LanguageSourceFilename,
+		},
+		{
+			name:     "template with inner extension",
+			filename: "index.html.erb",
+			content:  "<% x %>",
+			want:     "html",
+			source:   LanguageSourceTemplate,
+		},
+		{
+			name:     "qt translation file",
+			filename: "strings.ts",
+			content:  "<?xml version=\"1.0\"?><TS/>",
+			want:     "xml",
+			source:   LanguageSourceContent,
+		},
+		{
+			name:     "yaml without extension",
+			filename: "config",
+			content:  "key: value\nlist:\n  - a\n  - b\n",
+			want:     "yaml",
+			source:   LanguageSourceContent,
+		},
+		{
+			name:     "json without extension",
+			filename: "data",
+			content:  `{"a": [1, 2]}`,
+			want:     "json",
+			source:   LanguageSourceContent,
+		},
+		{
+			name:     "binary",
+			filename: "blob",
+			content:  "\x00\x01\xff\xfe",
+			want:     "unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectLanguageFromContent(tt.filename, tt.content)
+			if got.Language != tt.want || got.Source != tt.source {
+				t.Errorf("DetectLanguageFromContent(%q) = %+v, want %s from %q", tt.filename, got, tt.want, tt.source)
+			}
+			if tt.source == LanguageSourceExtension && got.Confidence > 0.5 {
+				t.Errorf("DetectLanguageFromContent(%q) confidence = %v, want at most 0.5 for a guess", tt.filename, got.Confidence)
+			}
+		})
+	}
+}
+
+func TestShebangLanguage(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+	}{
+		{"#!/bin/sh\n", "shell"},
+		{"#! /bin/bash -e\n", "shell"},
+		{"#!/usr/bin/env node\n", "javascript"},
+		{"#!/usr/bin/env -S python3 -u\n", "python"},
+		{"#!/usr/bin/env -S VAR=1 node --experimental\n", "javascript"},
+		{"#!/usr/bin/env -i PATH=/bin ruby\n", "ruby"},
+		{"#!/usr/bin/python3.11\n", "python"},
+		{"#!/usr/local/bin/pypy3\n", "python"},
+		{"#!/usr/bin/env php8.2\n", "php"},
+		{"#!/usr/bin/env\n", ""},
+		{"#!\n", ""},
+		{"#!/opt/tools/unknown\n", ""},
+		{"# not a shebang\n", ""},
+	}
+
+	for _, tt := range tests {
+		if got := shebangLanguage(tt.content); got != tt.want {
+			t.Errorf("shebangLanguage(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestModelineLanguage(t *testing.T) {
+	body := strings.Repeat("x = 1\n", 10)
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"vim first line", "# vim: set ft=python :\n" + body, "python"},
+		{"vim last line", body + "// vim: filetype=javascript\n", "javascript"},
+		{"vi syntax", "/* vi: syntax=c */\n", "c"},
+		{"emacs", "# -*- mode: ruby; coding: utf-8 -*-\n", "ruby"},
+		{"emacs short form", ";; -*- lua -*-\n", "lua"},
+		{"middle of file is ignored", body + "# vim: set ft=ruby :\n" + body, ""},
+		{"unknown file type", "# vim: set ft=unknownlang :\n", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := modelineLanguage(tt.content); got != tt.want {
+				t.Errorf("modelineLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectLanguageFromContentMalformed(t *testing.T) {
+	// 分類器のサンプルの境界でマルチバイト文字が切れる内容
+	cut := strings.Repeat("a", classifierSampleSize-1) + "設定"
+
+	tests := []struct {
+		filename string
+		content  string
+	}{
+		{"blob", "\xff\xfe\xfd\x00\x80"},
+		{"blob.h", "\xc3\x28\xa0\xa1"},
+		{"cut", cut},
+		{"cut.inc", cut},
+		{"shebang", "#!"},
+		{"shebang", "#!\xff\xfe"},
+		{"modeline", "-*- -*-"},
+		{"template.tpl", "{{ {% <% %> %} }}"},
+		{"json", "[" + strings.Repeat("{", 1000)},
+	}
+
+	for _, tt := range tests {
+		got := DetectLanguageFromContent(tt.filename, tt.content)
+		if got.Language != "unknown" && !IsKnownLanguage(got.Language) {
+			t.Errorf("DetectLanguageFromContent(%q) = %q, want a known language", tt.filename, got.Language)
+		}
+		if got.Confidence < 0 || got.Confidence > 1 {
+			t.Errorf("DetectLanguageFromContent(%q) confidence = %v, want 0..1", tt.filename, got.Confidence)
+		}
+	}
+}
+
+func FuzzDetectLanguageFromContent(f *testing.F) {
+	f.Add("script", "#!/usr/bin/env -S python3 -u\nprint('hi')\n")
+	f.Add("Person.h", "#import <Foundation/Foundation.h>\n@interface Person : NSObject\n@end\n")
+	f.Add("config.inc", "<?php echo $x; ?>")
+	f.Add("index.html.erb", "<% x %>")
+	f.Fuzz(func(t *testing.T, filename, content string) {
+		got := DetectLanguageFromContent(filename, content)
+		if got.Language != "unknown" && !IsKnownLanguage(got.Language) {
+			t.Errorf("DetectLanguageFromContent(%q, %q) = %q, want a known language", filename, content, got.Language)
+		}
+		if got.Confidence < 0 || got.Confidence > 1 {
+			t.Errorf("DetectLanguageFromContent(%q, %q) confidence = %v, want 0..1", filename, content, got.Confidence)
+		}
+	})
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"strings"
+)
+
+// commentSyntax 言語ごとのコメント・文字列リテラルの書式
+type commentSyntax struct {
+	line         []string
+	blockStart   string
+	blockEnd     string
+	quotes       string
+	tripleQuotes bool
+}
+
+var (
+	cStyleSyntax = commentSyntax{
+		line:       []string{"//"},
+		blockStart: "/*",
+		blockEnd:   "*/",
+		quotes:     "\"'`",
+	}
+	hashSyntax = commentSyntax{
+		line:   []string{"#"},
+		quotes: "\"'",
+	}
+	pythonSyntax = commentSyntax{
+		line:         []string{"#"},
+		quotes:       "\"'",
+		tripleQuotes: true,
+	}
+	phpSyntax = commentSyntax{
+		line:       []string{"//", "#"},
+		blockStart: "/*",
+		blockEnd:   "*/",
+		quotes:     "\"'",
+	}
+	sqlSyntax = commentSyntax{
+		line:       []string{"--"},
+		blockStart: "/*",
+		blockEnd:   "*/",
+		quotes:     "'\"",
+	}
+)
+
+var syntaxByLanguage = map[string]commentSyntax{
+	"javascript": cStyleSyntax,
+	"typescript": cStyleSyntax,
+	"java":       cStyleSyntax,
+	"c":          cStyleSyntax,
+	"cpp":        cStyleSyntax,
+	"csharp":     cStyleSyntax,
+	"rust":       cStyleSyntax,
+	"swift":      cStyleSyntax,
+	"kotlin":     cStyleSyntax,
+	"scala":      cStyleSyntax,
+	"go":         cStyleSyntax,
+	"css":        cStyleSyntax,
+	"scss":       cStyleSyntax,
+	"php":        phpSyntax,
+	"python":     pythonSyntax,
+	"ruby":       hashSyntax,
+	"shell":      hashSyntax,
+	"powershell": hashSyntax,
+	"r":          hashSyntax,
+	"yaml":       hashSyntax,
+	"toml":       hashSyntax,
+	"makefile":   hashSyntax,
+	"dockerfile": hashSyntax,
+	"sql":        sqlSyntax,
+}
+
+// StripComments コメントと文字列の中身を取り除いてコードのトークンだけを残す。
+// 併せてコードを含む行とコメントを含む行（1始まり）を返す。書式が未知の言語では
+// 行をそのまま返す
+func StripComments(lines []string, language string) ([]string, map[int]bool, map[int]bool) {
+	syntax := syntaxByLanguage[language]
+	code := make(map[int]bool)
+	comments := make(map[int]bool)
+	stripped := make([]string, len(lines))
+
+	inBlock := false
+	var inString string
+
+	for i, line := range lines {
+		lineNo := i + 1
+		var out strings.Builder
+		j := 0
+		for j < len(line) {
+			rest := line[j:]
+
+			if inBlock {
+				comments[lineNo] = true
+				if end := strings.Index(rest, syntax.blockEnd); end >= 0 {
+					j += end + len(syntax.blockEnd)
+					inBlock = false
+					continue
+				}
+				break
+			}
+
+			if inString != "" {
+				code[lineNo] = true
+				if strings.HasPrefix(rest, "\\") && len(inString) == 1 {
+					out.WriteString("  ")
+					j += 2
+					continue
+				}
+				if strings.HasPrefix(rest, inString) {
+					out.WriteString(inString)
+					j += len(inString)
+					inString = ""
+					continue
+				}
+				// 文字列の中身は桁位置を保ったまま空白に置き換える
+				out.WriteByte(' ')
+				j++
+				continue
+			}
+
+			if syntax.blockStart != "" && strings.HasPrefix(rest, syntax.blockStart) {
+				comments[lineNo] = true
+				inBlock = true
+				j += len(syntax.blockStart)
+				continue
+			}
+
+			isLineComment := false
+			for _, marker := range syntax.line {
+				if strings.HasPrefix(rest, marker) {
+					isLineComment = true
+					break
+				}
+			}
+			if isLineComment {
+				comments[lineNo] = true
+				break
+			}
+
+			if syntax.tripleQuotes && (strings.HasPrefix(rest, `"""`) || strings.HasPrefix(rest, "'''")) {
+				inString = rest[:3]
+				out.WriteString(inString)
+				code[lineNo] = true
+				j += 3
+				continue
+			}
+
+			if strings.IndexByte(syntax.quotes, rest[0]) >= 0 {
+				inString = rest[:1]
+				out.WriteString(inString)
+				code[lineNo] = true
+				j++
+				continue
+			}
+
+			if rest[0] != ' ' && rest[0] != '\t' && rest[0] != '\r' {
+				code[lineNo] = true
+			}
+			out.WriteByte(rest[0])
+			j++
+		}
+
+		// 単一引用符の文字列は行末で終わる
+		if len(inString) == 1 && inString != "`" {
+			inString = ""
+		}
+		stripped[i] = out.String()
+	}
+
+	return stripped, code, comments
+}
@@ -0,0 +1,234 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/domain/services"
+	"reverse-engineering-backend/infrastructure/storage"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/rag"
+
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+)
+
+// AnalysisHandler 解析タイプごとの処理。戻り値は Analysis.Result に JSON として保存される
+type AnalysisHandler func(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error)
+
+// AnalysisWorker Redisキューから解析タスクを取り出して実行するワーカー
+type AnalysisWorker struct {
+	db            *gorm.DB
+	redis         *redis.Client
+	llmService    services.LLMService
+	ragIndexing   *rag.RAGIndexingUseCase
+	blobs         *storage.BlobStore
+	analysisQueue string
+	handlers      map[string]AnalysisHandler
+}
+
+// analysisTask AnalysisController.StartAnalysis がキューに積むタスク
+type analysisTask struct {
+	AnalysisID uint   `json:"analysis_id"`
+	ProjectID  uint   `json:"project_id"`
+	Type       string `json:"type"`
+}
+
+func NewAnalysisWorker(db *gorm.DB, redis *redis.Client, llmService services.LLMService, ragIndexing *rag.RAGIndexingUseCase) *AnalysisWorker {
+	w := &AnalysisWorker{
+		db:            db,
+		redis:         redis,
+		llmService:    llmService,
+		ragIndexing:   ragIndexing,
+		blobs:         storage.NewBlobStore(db),
+		analysisQueue: "analysis:queue",
+		handlers:      make(map[string]AnalysisHandler),
+	}
+
+	w.registerHandlers()
+	return w
+}
+
+// registerHandlers 解析タイプごとの処理を登録
+func (w *AnalysisWorker) registerHandlers() {
+	w.Register("code_analysis", w.handleCodeAnalysis)
+	w.Register("documentation", w.handleDocumentation)
+	w.Register("dependency_map", w.handleDependencyMap)
+	w.Register("pattern_detection", w.handlePatternDetection)
+	w.Register("code_metrics", w.handleCodeMetrics)
+	w.Register("dead_code", w.handleDeadCode)
+	w.Register("vulnerability_scan", w.handleVulnerabilityScan)
+	w.Register("sast", w.handleSAST)
+	w.Register("binary_inspection", w.handleBinaryInspection)
+	w.Register("go_binary", w.handleGoBinary)
+	w.Register("debug_info", w.handleDebugInfo)
+	w.Register("binary_triage", w.handleBinaryTriage)
+	w.Register("java_inventory", w.handleJavaInventory)
+	w.Register("wasm_inspection", w.handleWasmInspection)
+	w.Register("android_inspection", w.handleAndroidInspection)
+	w.Register("js_bundle", w.handleJSBundle)
+}
+
+// AnalysisTypes ワーカーが処理できる解析タイプの一覧
+func AnalysisTypes() []string {
+	w := &AnalysisWorker{handlers: make(map[string]AnalysisHandler)}
+	w.registerHandlers()
+	types := make([]string, 0, len(w.handlers))
+	for analysisType := range w.handlers {
+		types = append(types, analysisType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Register 解析タイプに処理を登録
+func (w *AnalysisWorker) Register(analysisType string, handler AnalysisHandler) {
+	w.handlers[analysisType] = handler
+}
+
+// Start コンテキストがキャンセルされるまでキューを処理する
+func (w *AnalysisWorker) Start(ctx context.Context) {
+	log.Printf("Analysis worker started (queue: %s)", w.analysisQueue)
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		result, err := w.redis.BRPop(ctx, 5*time.Second, w.analysisQueue).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Failed to pop analysis task: %v", err)
+				time.Sleep(time.Second)
+			}
+			continue
+		}
+
+		// BRPop は [キー名, 値] を返す
+		var task analysisTask
+		if err := json.Unmarshal([]byte(result[1]), &task); err != nil {
+			log.Printf("Invalid analysis task payload: %v", err)
+			continue
+		}
+
+		w.process(ctx, task)
+	}
+}
+
+func (w *AnalysisWorker) process(ctx context.Context, task analysisTask) {
+	var analysis models.Analysis
+	if err := w.db.First(&analysis, task.AnalysisID).Error; err != nil {
+		log.Printf("Analysis %d not found: %v", task.AnalysisID, err)
+		return
+	}
+
+	handler, exists := w.handlers[analysis.Type]
+	if !exists {
+		w.fail(&analysis, fmt.Errorf("unsupported analysis type: %s", analysis.Type))
+		return
+	}
+
+	var project models.Project
+	if err := w.db.First(&project, analysis.ProjectID).Error; err != nil {
+		w.fail(&analysis, fmt.Errorf("failed to load project: %w", err))
+		return
+	}
+
+	// git から取り込んだプロジェクトは対象コミットのツリーと、個別にアップロードされたファイルを解析する。
+	// 隔離されたファイルは解析・LLM への送信対象から除外する
+	files := w.db.Scopes(models.ProjectFiles(project, analysis.CommitHash)).Where("quarantined = ?", false)
+	if err := files.Find(&project.Files).Error; err != nil {
+		w.fail(&analysis, fmt.Errorf("failed to load project files: %w", err))
+		return
+	}
+	// テキストの内容は DB には保存せず Blob から読み込む
+	if err := w.blobs.LoadContents(project.Files); err != nil {
+		w.fail(&analysis, err)
+		return
+	}
+
+	if err := w.db.Model(&analysis).Update("status", "processing").Error; err != nil {
+		log.Printf("Failed to update analysis %d status: %v", analysis.ID, err)
+	}
+
+	output, err := handler(ctx, &analysis, &project)
+	if err != nil {
+		w.fail(&analysis, err)
+		return
+	}
+
+	resultJSON, err := json.Marshal(output)
+	if err != nil {
+		w.fail(&analysis, fmt.Errorf("failed to marshal result: %w", err))
+		return
+	}
+
+	if err := w.db.Model(&analysis).Updates(map[string]interface{}{
+		"status": "completed",
+		"result": string(resultJSON),
+	}).Error; err != nil {
+		log.Printf("Failed to save analysis %d result: %v", analysis.ID, err)
+	}
+
+	w.updateProjectStatus(project.ID)
+}
+
+func (w *AnalysisWorker) fail(analysis *models.Analysis, cause error) {
+	log.Printf("Analysis %d (%s) failed: %v", analysis.ID, analysis.Type, cause)
+
+	errorJSON, _ := json.Marshal(map[string]string{"error": cause.Error()})
+	if err := w.db.Model(analysis).Updates(map[string]interface{}{
+		"status": "failed",
+		"result": string(errorJSON),
+	}).Error; err != nil {
+		log.Printf("Failed to save analysis %d failure: %v", analysis.ID, err)
+	}
+
+	w.updateProjectStatus(analysis.ProjectID)
+}
+
+// updateProjectStatus 未完了の解析がなくなったらプロジェクトのステータスを更新
+func (w *AnalysisWorker) updateProjectStatus(projectID uint) {
+	var pending int64
+	w.db.Model(&models.Analysis{}).
+		Where("project_id = ? AND status IN ?", projectID, []string{"pending", "processing"}).
+		Count(&pending)
+	if pending > 0 {
+		return
+	}
+
+	var failed int64
+	w.db.Model(&models.Analysis{}).
+		Where("project_id = ? AND status = ?", projectID, "failed").
+		Count(&failed)
+
+	status := "completed"
+	if failed > 0 {
+		status = "failed"
+	}
+	w.db.Model(&models.Project{}).Where("id = ?", projectID).Update("status", status)
+}
+
+// fileInfos プロジェクトのファイルを解析用のエンティティに変換
+func fileInfos(files []models.File) []entities.FileInfo {
+	infos := make([]entities.FileInfo, 0, len(files))
+	for _, file := range files {
+		infos = append(infos, fileInfo(file))
+	}
+	return infos
+}
+
+func fileInfo(file models.File) entities.FileInfo {
+	return entities.FileInfo{
+		Name:     file.Path,
+		Language: file.Language,
+		Content:  file.Content,
+	}
+}
@@ -0,0 +1,62 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/android"
+)
+
+// androidResult ファイル単位の APK 解析結果
+type androidResult struct {
+	FileID     uint                        `json:"file_id"`
+	Name       string                      `json:"name"`
+	Inspection *entities.AndroidInspection `json:"inspection,omitempty"`
+	Indexed    int                         `json:"indexed"`
+	IndexError string                      `json:"index_error,omitempty"`
+	Error      string                      `json:"error,omitempty"`
+}
+
+// handleAndroidInspection APK の AndroidManifest.xml（パーミッション・コンポーネント）と classes.dex（クラス・メソッド）を解析し、RAG の知識ベースに登録する
+func (w *AnalysisWorker) handleAndroidInspection(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	useCase := android.NewApkInspectionUseCase()
+
+	results := []androidResult{}
+	for _, file := range w.rawFiles(project.Files) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !android.IsApkFile(file.Name, w.blobs.Locate(file)) {
+			continue
+		}
+
+		result := androidResult{FileID: file.ID, Name: file.Path}
+		inspection, err := useCase.Execute(ctx, w.blobs.Locate(file))
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		result.Inspection = inspection
+
+		// 知識ベースへの登録に失敗しても解析結果は保存する
+		if w.ragIndexing != nil {
+			documents := android.AndroidDocuments(inspection, fmt.Sprintf("apk_%d", file.ID), file.Path, map[string]interface{}{
+				"project_id":  project.ID,
+				"file_id":     file.ID,
+				"analysis_id": analysis.ID,
+			})
+			if err := w.ragIndexing.Execute(ctx, documents); err != nil {
+				log.Printf("Failed to index APK %d: %v", file.ID, err)
+				result.IndexError = err.Error()
+			} else {
+				result.Indexed = len(documents)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,61 @@
+package workers
+
+import (
+	"context"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/binary"
+)
+
+// binaryResult ファイル単位のバイナリ解析結果
+type binaryResult struct {
+	FileID uint                 `json:"file_id"`
+	Name   string               `json:"name"`
+	Info   *entities.BinaryInfo `json:"info,omitempty"`
+	Error  string               `json:"error,omitempty"`
+}
+
+// rawFiles テキストとして判定されなかった（バイナリの）ファイルを返す
+func (w *AnalysisWorker) rawFiles(files []models.File) []models.File {
+	var result []models.File
+	for _, file := range files {
+		if !file.IsText() && w.blobs.Locate(file) != "" {
+			result = append(result, file)
+		}
+	}
+	return result
+}
+
+// binaryFiles バイナリのファイルのうち、実行形式として認識できるものを返す
+func (w *AnalysisWorker) binaryFiles(files []models.File) []models.File {
+	var result []models.File
+	for _, file := range w.rawFiles(files) {
+		if format, err := binary.DetectFileFormat(w.blobs.Locate(file)); err == nil && format != "" {
+			result = append(result, file)
+		}
+	}
+	return result
+}
+
+// handleBinaryInspection ELF / PE / Mach-O の構造（アーキテクチャ・セクション・シンボル・セキュリティフラグ）を解析する
+func (w *AnalysisWorker) handleBinaryInspection(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	useCase := binary.NewBinaryInspectionUseCase()
+
+	results := []binaryResult{}
+	for _, file := range w.binaryFiles(project.Files) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result := binaryResult{FileID: file.ID, Name: file.Path}
+		info, err := useCase.Execute(ctx, w.blobs.Locate(file))
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Info = info
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,13 @@
+package workers
+
+import (
+	"context"
+
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/deadcode"
+)
+
+// handleDeadCode ファイル横断の参照インデックスから未使用のシンボルを検出する
+func (w *AnalysisWorker) handleDeadCode(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	return deadcode.NewDeadCodeUseCase().Execute(ctx, fileInfos(project.Files))
+}
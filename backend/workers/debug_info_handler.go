@@ -0,0 +1,64 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/binary"
+)
+
+// debugInfoResult ファイル単位の DWARF 解析結果
+type debugInfoResult struct {
+	FileID     uint                `json:"file_id"`
+	Name       string              `json:"name"`
+	Info       *entities.DebugInfo `json:"info,omitempty"`
+	Indexed    int                 `json:"indexed"`
+	IndexError string              `json:"index_error,omitempty"`
+	Error      string              `json:"error,omitempty"`
+}
+
+// handleDebugInfo デバッグ情報を残した実行ファイルからコンパイル単位・ソースツリー・型・関数シグネチャを復元し、RAG の知識ベースに登録する
+func (w *AnalysisWorker) handleDebugInfo(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	useCase := binary.NewDebugInfoUseCase()
+
+	results := []debugInfoResult{}
+	for _, file := range w.binaryFiles(project.Files) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		info, err := useCase.Execute(ctx, w.blobs.Locate(file))
+		if errors.Is(err, binary.ErrNoDebugInfo) {
+			// ストリップ済みのファイルは結果に含めない
+			continue
+		}
+		result := debugInfoResult{FileID: file.ID, Name: file.Path}
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		result.Info = info
+
+		// 知識ベースへの登録に失敗しても解析結果は保存する
+		if w.ragIndexing != nil {
+			documents := binary.DebugInfoDocuments(info, fmt.Sprintf("dwarf_%d", file.ID), file.Path, map[string]interface{}{
+				"project_id":  project.ID,
+				"file_id":     file.ID,
+				"analysis_id": analysis.ID,
+			})
+			if err := w.ragIndexing.Execute(ctx, documents); err != nil {
+				log.Printf("Failed to index debug info of file %d: %v", file.ID, err)
+				result.IndexError = err.Error()
+			} else {
+				result.Indexed = len(documents)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,42 @@
+package workers
+
+import (
+	"context"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/binary"
+)
+
+// goBinaryResult ファイル単位の Go 実行ファイル解析結果
+type goBinaryResult struct {
+	FileID uint                   `json:"file_id"`
+	Name   string                 `json:"name"`
+	Info   *entities.GoBinaryInfo `json:"info,omitempty"`
+	Error  string                 `json:"error,omitempty"`
+}
+
+// handleGoBinary Go 実行ファイルのビルド情報（モジュール・依存・ビルド設定）と pclntab の関数名を復元する
+func (w *AnalysisWorker) handleGoBinary(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	useCase := binary.NewGoBinaryUseCase()
+
+	results := []goBinaryResult{}
+	for _, file := range w.binaryFiles(project.Files) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !binary.IsGoBinary(w.blobs.Locate(file)) {
+			continue
+		}
+
+		result := goBinaryResult{FileID: file.ID, Name: file.Path}
+		info, err := useCase.Execute(ctx, w.blobs.Locate(file))
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Info = info
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
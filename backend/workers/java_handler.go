@@ -0,0 +1,21 @@
+package workers
+
+import (
+	"context"
+
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/java"
+)
+
+// handleJavaInventory JAR・WAR・EAR・クラスファイルを解析し、パッケージとクラスの一覧、クラス間の依存グラフを作成する
+func (w *AnalysisWorker) handleJavaInventory(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	useCase := java.NewJavaInventoryUseCase()
+
+	var inputs []java.InputFile
+	for _, file := range w.rawFiles(project.Files) {
+		if java.IsJavaFile(file.Name, w.blobs.Locate(file)) {
+			inputs = append(inputs, java.InputFile{Name: file.Path, Path: w.blobs.Locate(file)})
+		}
+	}
+	return useCase.Execute(ctx, inputs)
+}
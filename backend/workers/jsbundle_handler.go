@@ -0,0 +1,269 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/infrastructure/persistence"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/jsbundle"
+	"reverse-engineering-backend/usecases/secrets"
+	"reverse-engineering-backend/utils"
+
+	"gorm.io/gorm"
+)
+
+// jsBundleResult ファイル単位の JavaScript バンドル復元結果
+type jsBundleResult struct {
+	FileID         uint               `json:"file_id"`
+	Name           string             `json:"name"`
+	Bundle         *entities.JSBundle `json:"bundle,omitempty"`
+	DerivedFileIDs []uint             `json:"derived_file_ids,omitempty"`
+	Error          string             `json:"error,omitempty"`
+}
+
+// handleJSBundle 圧縮された JavaScript を整形し、webpack などのバンドルをモジュールごとに分割する。ソースマップがあれば元のファイル名・識別子を復元する。復元したファイルは元のバンドルに紐づくファイルとして保存する
+func (w *AnalysisWorker) handleJSBundle(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	useCase := jsbundle.NewJSBundleUseCase()
+
+	// ソースマップの sourcesContent などから元のソースに含まれていたシークレットが戻るため、
+	// 復元したファイルにもアップロード時と同じルールとポリシーを適用する
+	customRules, err := persistence.NewPostgresSecretRuleRepository(w.db).FindByProject(ctx, project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secret rules: %w", err)
+	}
+	scanner, err := secrets.NewSecretScanUseCase(customRules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid secret rule: %w", err)
+	}
+	policy := project.SecretPolicy
+	if !secrets.IsValidPolicy(policy) {
+		policy = secrets.PolicyQuarantine
+	}
+
+	results := []jsBundleResult{}
+	for _, file := range project.Files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// 復元済みのファイルを再び分割しない
+		if file.Content == "" || file.DerivedFromID != nil || !jsbundle.IsJavaScriptFile(file.Path) {
+			continue
+		}
+
+		result := jsBundleResult{FileID: file.ID, Name: file.Path}
+		input := jsbundle.BundleInput{Name: file.Path, Code: file.Content}
+		if sourceMap := findSourceMap(project.Files, file); sourceMap != nil {
+			input.SourceMapName = sourceMap.Path
+			input.SourceMap = []byte(sourceMap.Content)
+			if sourceMap.Content == "" {
+				data, err := os.ReadFile(w.blobs.Locate(*sourceMap))
+				if err != nil {
+					result.Error = fmt.Sprintf("failed to read source map: %v", err)
+					results = append(results, result)
+					continue
+				}
+				input.SourceMap = data
+			}
+		}
+
+		bundle, err := useCase.Execute(ctx, input)
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		result.Bundle = bundle
+
+		ids, err := w.saveDerivedFiles(analysis, file, bundle.Files, scanner, policy)
+		if err != nil {
+			result.Error = err.Error()
+		}
+		result.DerivedFileIDs = ids
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// findSourceMap バンドルに対してアップロードされたソースマップを返す
+// <path>.map、または同じディレクトリで sourceMappingURL コメントが指すファイル
+func findSourceMap(files []models.File, bundle models.File) *models.File {
+	candidates := []string{bundle.Path + ".map"}
+	if mapURL := jsbundle.SourceMapURL(bundle.Content); mapURL != "" {
+		candidates = append(candidates, path.Join(path.Dir(bundle.Path), path.Base(mapURL)))
+	}
+	for _, candidate := range candidates {
+		for i := range files {
+			if files[i].Path == candidate && files[i].ID != bundle.ID && files[i].DerivedFromID == nil {
+				return &files[i]
+			}
+		}
+	}
+	return nil
+}
+
+// saveDerivedFiles バンドルから以前に復元したファイルを新しいものに置き換え、内容は blob として保存する。
+// シークレットを含むファイルにはポリシーを適用し、検出結果をこの解析の Issue として保存する
+func (w *AnalysisWorker) saveDerivedFiles(analysis *models.Analysis, bundle models.File, recovered []entities.RecoveredFile, scanner *secrets.SecretScanUseCase, policy string) ([]uint, error) {
+	if bundle.BlobHash == "" {
+		// 以前の形式ではバンドルと同じディレクトリの .derived/<ファイル ID>/ に置いていた
+		os.RemoveAll(filepath.Join(filepath.Dir(bundle.StoragePath), ".derived", strconv.FormatUint(uint64(bundle.ID), 10)))
+	}
+
+	files := make([]models.File, 0, len(recovered))
+	// fileFindings[i] は files[i] のシークレット。ブロックしたファイルの分は blocked に入れる
+	var fileFindings [][]entities.SecretFinding
+	var blocked []entities.SecretFinding
+	for _, r := range recovered {
+		// 名前はソースマップなどに由来するため、ディレクトリの外に出ないように正規化する
+		name := path.Clean("/" + r.Name)[1:]
+		if name == "" {
+			continue
+		}
+		bundleID := bundle.ID
+		language := utils.DetectLanguageFromContent(name, r.Content)
+		mimeType := "text/plain"
+		if language.Language == "javascript" {
+			mimeType = "text/javascript"
+		}
+		file := models.File{
+			ProjectID:          bundle.ProjectID,
+			Name:               path.Base(name),
+			Path:               name,
+			Size:               int64(len(r.Content)),
+			MimeType:           mimeType,
+			Encoding:           utils.EncodingUTF8,
+			Language:           language.Language,
+			LanguageConfidence: language.Confidence,
+			LanguageSource:     language.Source,
+			DerivedFromID:      &bundleID,
+			DerivedBy:          r.Origin,
+			CommitHash:         bundle.CommitHash,
+		}
+
+		content := r.Content
+		findings := scanner.Scan(name, content)
+		if len(findings) > 0 {
+			switch policy {
+			case secrets.PolicyBlock:
+				blocked = append(blocked, findings...)
+				continue
+			case secrets.PolicyQuarantine:
+				// 内容はバンドルから再び復元できるため、隔離ディレクトリにも残さない
+				file.Encoding = ""
+				file.Quarantined = true
+			case secrets.PolicyRedact:
+				content = secrets.RedactContent(content, findings)
+				file.Size = int64(len(content))
+			}
+		}
+		if !file.Quarantined {
+			hash, err := w.blobs.PutBytes([]byte(content))
+			if err != nil {
+				return nil, fmt.Errorf("failed to store %s: %w", name, err)
+			}
+			file.BlobHash = hash
+		}
+		files = append(files, file)
+		fileFindings = append(fileFindings, findings)
+	}
+
+	err := w.db.Transaction(func(tx *gorm.DB) error {
+		var previous []models.File
+		if err := tx.Where("derived_from_id = ?", bundle.ID).Find(&previous).Error; err != nil {
+			return err
+		}
+		if len(previous) > 0 {
+			// 以前のファイルで検出したシークレットは、新しいファイルで改めて報告する
+			previousIDs := make([]uint, len(previous))
+			for i, file := range previous {
+				previousIDs[i] = file.ID
+			}
+			if err := tx.Where("source = ? AND file_id IN ?", "secret", previousIDs).Delete(&models.Issue{}).Error; err != nil {
+				return err
+			}
+			if err := tx.Delete(&previous).Error; err != nil {
+				return err
+			}
+			if err := w.blobs.Release(tx, previous); err != nil {
+				return err
+			}
+		}
+		if len(files) > 0 {
+			if err := tx.CreateInBatches(files, 100).Error; err != nil {
+				return err
+			}
+			if err := w.blobs.Acquire(tx, files); err != nil {
+				return err
+			}
+		}
+
+		var issues []models.Issue
+		for i, file := range files {
+			fileID := file.ID
+			fileIssues, err := derivedSecretIssues(analysis, &fileID, policy, fileFindings[i])
+			if err != nil {
+				return err
+			}
+			issues = append(issues, fileIssues...)
+		}
+		blockedIssues, err := derivedSecretIssues(analysis, nil, policy, blocked)
+		if err != nil {
+			return err
+		}
+		issues = append(issues, blockedIssues...)
+		if len(issues) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(issues, 200).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save derived files: %w", err)
+	}
+
+	ids := make([]uint, len(files))
+	for i, file := range files {
+		ids[i] = file.ID
+	}
+	return ids, nil
+}
+
+// derivedSecretIssues 復元したファイルで検出したシークレットを、アップロード時と同じ形式の Issue にする
+// （ブロックしたファイルの場合 fileID は nil）
+func derivedSecretIssues(analysis *models.Analysis, fileID *uint, policy string, findings []entities.SecretFinding) ([]models.Issue, error) {
+	issues := make([]models.Issue, 0, len(findings))
+	for _, finding := range findings {
+		metadata, err := json.Marshal(map[string]interface{}{
+			"description":  finding.Description,
+			"redacted":     finding.Redacted,
+			"entropy":      finding.Entropy,
+			"fingerprint":  finding.Fingerprint,
+			"start_column": finding.Column,
+			"end_column":   finding.EndColumn,
+			"action":       policy,
+		})
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, models.Issue{
+			AnalysisID: analysis.ID,
+			ProjectID:  analysis.ProjectID,
+			FileID:     fileID,
+			Source:     "secret",
+			RuleID:     finding.RuleID,
+			Severity:   finding.Severity,
+			Message:    fmt.Sprintf("%s detected (%s)", finding.Description, finding.Redacted),
+			Path:       finding.Path,
+			StartLine:  finding.Line,
+			EndLine:    finding.Line,
+			Metadata:   string(metadata),
+		})
+	}
+	return issues, nil
+}
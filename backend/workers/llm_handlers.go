@@ -0,0 +1,88 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases"
+	"reverse-engineering-backend/usecases/metrics"
+)
+
+// patternEvidenceFunctions パターン検出プロンプトに渡す関数メトリクスの上限
+const patternEvidenceFunctions = 20
+
+// fileResult ファイル単位の LLM 解析結果
+type fileResult struct {
+	FileID uint                     `json:"file_id"`
+	Name   string                   `json:"name"`
+	Result *entities.AnalysisResult `json:"result,omitempty"`
+	Error  string                   `json:"error,omitempty"`
+}
+
+func (w *AnalysisWorker) handleCodeAnalysis(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	useCase := usecases.NewCodeAnalysisUseCase(w.llmService)
+
+	var results []fileResult
+	for _, file := range project.Files {
+		if file.Content == "" {
+			continue
+		}
+		result, err := useCase.Execute(ctx, file.Content, file.Language)
+		results = append(results, newFileResult(file, result, err))
+	}
+	return results, nil
+}
+
+func (w *AnalysisWorker) handleDocumentation(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	useCase := usecases.NewDocumentationUseCase(w.llmService)
+
+	documents := make(map[string]string)
+	for _, file := range project.Files {
+		if file.Content == "" {
+			continue
+		}
+		doc, err := useCase.Execute(ctx, file.Content, file.Language)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate documentation for %s: %w", file.Path, err)
+		}
+		documents[file.Path] = doc
+	}
+	return documents, nil
+}
+
+func (w *AnalysisWorker) handleDependencyMap(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	return w.llmService.AnalyzeDependencies(ctx, fileInfos(project.Files))
+}
+
+func (w *AnalysisWorker) handlePatternDetection(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	metricsUseCase := metrics.NewCodeMetricsUseCase()
+
+	var results []fileResult
+	for _, file := range project.Files {
+		if file.Content == "" {
+			continue
+		}
+
+		// 静的メトリクスを根拠としてプロンプトに含める
+		evidence := metricsUseCase.Analyze(fileInfo(file))
+		evidence.Functions = metrics.TopFunctions(evidence, patternEvidenceFunctions)
+
+		result, err := w.llmService.DetectPatterns(ctx, file.Content, file.Language, evidence)
+		results = append(results, newFileResult(file, result, err))
+	}
+	return results, nil
+}
+
+func newFileResult(file models.File, result *entities.AnalysisResult, err error) fileResult {
+	r := fileResult{
+		FileID: file.ID,
+		Name:   file.Path,
+		Result: result,
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	return r
+}
@@ -0,0 +1,78 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/metrics"
+
+	"gorm.io/gorm"
+)
+
+// handleCodeMetrics LLMを使わずにメトリクスを計測し、解析実行ごとに保存する
+func (w *AnalysisWorker) handleCodeMetrics(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	useCase := metrics.NewCodeMetricsUseCase()
+
+	var rows []models.CodeMetric
+	summary := map[string]int{"files": 0, "functions": 0, "physical_loc": 0, "logical_loc": 0}
+
+	for _, file := range project.Files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if file.Content == "" {
+			continue
+		}
+
+		fm := useCase.Analyze(fileInfo(file))
+		rows = append(rows, models.CodeMetric{
+			AnalysisID:     analysis.ID,
+			ProjectID:      project.ID,
+			FileID:         file.ID,
+			Path:           fm.Path,
+			Language:       fm.Language,
+			Scope:          "file",
+			StartLine:      1,
+			EndLine:        fm.PhysicalLOC,
+			PhysicalLOC:    fm.PhysicalLOC,
+			LogicalLOC:     fm.LogicalLOC,
+			CommentLines:   fm.CommentLines,
+			CommentDensity: fm.CommentDensity,
+		})
+		for _, fn := range fm.Functions {
+			rows = append(rows, models.CodeMetric{
+				AnalysisID:   analysis.ID,
+				ProjectID:    project.ID,
+				FileID:       file.ID,
+				Path:         fm.Path,
+				Language:     fm.Language,
+				Scope:        "function",
+				FunctionName: fn.Name,
+				StartLine:    fn.StartLine,
+				EndLine:      fn.EndLine,
+				PhysicalLOC:  fn.LOC,
+				Cyclomatic:   fn.Cyclomatic,
+				Cognitive:    fn.Cognitive,
+				MaxNesting:   fn.MaxNesting,
+				ParamCount:   fn.Params,
+			})
+		}
+
+		summary["files"]++
+		summary["functions"] += len(fm.Functions)
+		summary["physical_loc"] += fm.PhysicalLOC
+		summary["logical_loc"] += fm.LogicalLOC
+	}
+
+	if len(rows) > 0 {
+		err := w.db.Transaction(func(tx *gorm.DB) error {
+			return tx.CreateInBatches(rows, 200).Error
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to save code metrics: %w", err)
+		}
+	}
+
+	return summary, nil
+}
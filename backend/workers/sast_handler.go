@@ -0,0 +1,98 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/sast"
+
+	"gorm.io/gorm"
+)
+
+// handleSAST 組み込みルールパック（と SAST_RULES_DIR の独自ルール）でソースを検査し、Issue として保存する
+func (w *AnalysisWorker) handleSAST(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	rules, err := sast.BuiltinRules()
+	if err != nil {
+		return nil, err
+	}
+	if dir := os.Getenv("SAST_RULES_DIR"); dir != "" {
+		custom, err := sast.LoadRulesDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load custom SAST rules: %w", err)
+		}
+		rules = append(rules, custom...)
+	}
+
+	sastUseCase, err := sast.NewSASTUseCase(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	fileIDs := make(map[string]uint)
+	var infos []entities.FileInfo
+	for _, file := range project.Files {
+		if file.Content == "" {
+			continue
+		}
+		fileIDs[file.Path] = file.ID
+		infos = append(infos, fileInfo(file))
+	}
+
+	findings, err := sastUseCase.Execute(ctx, infos)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]models.Issue, 0, len(findings))
+	bySeverity := make(map[string]int)
+	byRule := make(map[string]int)
+	for _, finding := range findings {
+		metadata, err := json.Marshal(map[string]interface{}{
+			"cwe":     finding.CWE,
+			"snippet": finding.Snippet,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		issue := models.Issue{
+			AnalysisID: analysis.ID,
+			ProjectID:  project.ID,
+			Source:     "sast",
+			RuleID:     finding.RuleID,
+			Severity:   finding.Severity,
+			Message:    finding.Message,
+			Path:       finding.Path,
+			StartLine:  finding.StartLine,
+			EndLine:    finding.EndLine,
+			Metadata:   string(metadata),
+		}
+		if id, ok := fileIDs[finding.Path]; ok {
+			issue.FileID = &id
+		}
+		issues = append(issues, issue)
+		bySeverity[finding.Severity]++
+		byRule[finding.RuleID]++
+	}
+
+	if len(issues) > 0 {
+		err := w.db.Transaction(func(tx *gorm.DB) error {
+			return tx.CreateInBatches(issues, 200).Error
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to save SAST issues: %w", err)
+		}
+	}
+
+	return map[string]interface{}{
+		"files":       len(infos),
+		"rules":       len(sastUseCase.Rules()),
+		"findings":    len(findings),
+		"by_severity": bySeverity,
+		"by_rule":     byRule,
+	}, nil
+}
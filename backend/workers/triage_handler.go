@@ -0,0 +1,63 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/binary"
+
+	"gorm.io/gorm"
+)
+
+// triageResult ファイル単位のトリアージ結果（文字列は binary_strings に保存し、件数だけを持つ）
+type triageResult struct {
+	FileID uint                   `json:"file_id"`
+	Name   string                 `json:"name"`
+	Triage *entities.BinaryTriage `json:"triage,omitempty"`
+	Error  string                 `json:"error,omitempty"`
+}
+
+// handleBinaryTriage バイナリファイルの初期調査（文字列抽出・セクションごとのエントロピー・パッカー検出・IOC 抽出）を行う
+func (w *AnalysisWorker) handleBinaryTriage(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	useCase := binary.NewBinaryTriageUseCase()
+
+	results := []triageResult{}
+	for _, file := range w.rawFiles(project.Files) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		result := triageResult{FileID: file.ID, Name: file.Path}
+		triage, strs, err := useCase.Execute(ctx, w.blobs.Locate(file))
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		result.Triage = triage
+
+		rows := make([]models.BinaryString, 0, len(strs))
+		for _, s := range strs {
+			rows = append(rows, models.BinaryString{
+				AnalysisID: analysis.ID,
+				FileID:     file.ID,
+				Offset:     s.Offset,
+				Encoding:   s.Encoding,
+				Value:      s.Value,
+				Length:     len(s.Value),
+			})
+		}
+		if len(rows) > 0 {
+			err := w.db.Transaction(func(tx *gorm.DB) error {
+				return tx.CreateInBatches(rows, 1000).Error
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to save strings of %s: %w", file.Path, err)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,141 @@
+package workers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/infrastructure/persistence"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/binary"
+	"reverse-engineering-backend/usecases/dependency"
+	"reverse-engineering-backend/usecases/vulnerability"
+
+	"gorm.io/gorm"
+)
+
+// handleVulnerabilityScan マニフェストの依存関係をローカルの OSV データベースと照合し、Issue として保存する
+func (w *AnalysisWorker) handleVulnerabilityScan(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	manifests := make(map[string]models.File)
+	var infos []entities.FileInfo
+	for _, file := range project.Files {
+		if dependency.IsManifest(file.Path) {
+			manifests[file.Path] = file
+			infos = append(infos, fileInfo(file))
+		}
+	}
+	// Go 実行ファイルに埋め込まれたモジュール一覧も go.mod と同様に照合する
+	executables := make(map[string]models.File)
+	for _, file := range w.binaryFiles(project.Files) {
+		if manifest, ok := binary.GoBuildInfoManifest(w.blobs.Locate(file), file.Path); ok {
+			executables[manifest.Name] = file
+			infos = append(infos, manifest)
+		}
+	}
+
+	inventory, err := dependency.NewDependencyUseCase().Execute(ctx, infos)
+	if err != nil {
+		return nil, err
+	}
+
+	matchUseCase := vulnerability.NewVulnerabilityMatchUseCase(persistence.NewPostgresAdvisoryRepository(w.db))
+	result, err := matchUseCase.Execute(ctx, inventory.Dependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]models.Issue, 0, len(result.Findings))
+	for _, finding := range result.Findings {
+		dep := finding.Dependency
+		metadata, err := json.Marshal(map[string]interface{}{
+			"ecosystem":      dep.Ecosystem,
+			"package":        dep.Name,
+			"version":        dep.Version,
+			"purl":           dep.PURL,
+			"direct":         dep.Direct,
+			"aliases":        finding.Aliases,
+			"cvss_score":     finding.CVSSScore,
+			"fixed_versions": finding.FixedVersions,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		issue := models.Issue{
+			AnalysisID: analysis.ID,
+			ProjectID:  project.ID,
+			Source:     "osv",
+			RuleID:     finding.AdvisoryID,
+			Severity:   finding.Severity,
+			Message:    vulnerabilityMessage(finding),
+			Path:       dep.Manifest,
+			Metadata:   string(metadata),
+		}
+		if file, ok := manifests[dep.Manifest]; ok {
+			fileID := file.ID
+			issue.FileID = &fileID
+			issue.StartLine = findLine(file.Content, dep.Name)
+			issue.EndLine = issue.StartLine
+		} else if file, ok := executables[dep.Manifest]; ok {
+			fileID := file.ID
+			issue.FileID = &fileID
+			issue.Path = file.Path
+		}
+		issues = append(issues, issue)
+	}
+
+	if len(issues) > 0 {
+		err := w.db.Transaction(func(tx *gorm.DB) error {
+			return tx.CreateInBatches(issues, 200).Error
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to save vulnerability issues: %w", err)
+		}
+	}
+
+	bySeverity := make(map[string]int)
+	for _, finding := range result.Findings {
+		bySeverity[finding.Severity]++
+	}
+	return map[string]interface{}{
+		"manifests":    inventory.Manifests,
+		"dependencies": len(inventory.Dependencies),
+		"scanned":      result.Scanned,
+		"unresolved":   len(result.Unresolved),
+		"findings":     len(result.Findings),
+		"by_severity":  bySeverity,
+	}, nil
+}
+
+func vulnerabilityMessage(finding entities.VulnerabilityFinding) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s@%s is affected by %s", finding.Dependency.Name, finding.Dependency.Version, finding.AdvisoryID)
+	if len(finding.Aliases) > 0 {
+		fmt.Fprintf(&b, " (%s)", strings.Join(finding.Aliases, ", "))
+	}
+	if finding.Summary != "" {
+		fmt.Fprintf(&b, ": %s", finding.Summary)
+	}
+	if len(finding.FixedVersions) > 0 {
+		fmt.Fprintf(&b, ". Upgrade to %s or later", finding.FixedVersions[0])
+	} else {
+		b.WriteString(". No fixed version is available")
+	}
+	return b.String()
+}
+
+// findLine マニフェスト内でパッケージ名が最初に現れる行（1 始まり）。見つからなければ 0
+func findLine(content, name string) int {
+	// Maven は group:artifact で管理しているため artifactId で探す
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		name = name[i+1:]
+	}
+	for i, line := range strings.Split(content, "\n") {
+		if strings.Contains(line, name) {
+			return i + 1
+		}
+	}
+	return 0
+}
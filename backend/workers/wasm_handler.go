@@ -0,0 +1,52 @@
+package workers
+
+import (
+	"context"
+
+	"reverse-engineering-backend/domain/entities"
+	"reverse-engineering-backend/models"
+	"reverse-engineering-backend/usecases/wasm"
+)
+
+// wasmResult ファイル単位の WebAssembly モジュール解析結果
+type wasmResult struct {
+	FileID      uint                      `json:"file_id"`
+	Name        string                    `json:"name"`
+	Module      *entities.WasmModule      `json:"module,omitempty"`
+	Explanation *entities.WasmExplanation `json:"explanation,omitempty"`
+	Error       string                    `json:"error,omitempty"`
+}
+
+// handleWasmInspection WebAssembly モジュールのインポート・エクスポート・メモリ・カスタムセクション・関数シグネチャを解析し、LLM でモジュールの役割を説明する
+func (w *AnalysisWorker) handleWasmInspection(ctx context.Context, analysis *models.Analysis, project *models.Project) (interface{}, error) {
+	useCase := wasm.NewWasmInspectionUseCase()
+
+	results := []wasmResult{}
+	for _, file := range w.rawFiles(project.Files) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !wasm.IsWasmFile(w.blobs.Locate(file)) {
+			continue
+		}
+
+		result := wasmResult{FileID: file.ID, Name: file.Path}
+		module, err := useCase.Execute(ctx, w.blobs.Locate(file))
+		if err != nil {
+			result.Error = err.Error()
+			results = append(results, result)
+			continue
+		}
+		result.Module = module
+
+		// 説明に失敗しても構造の解析結果は残す
+		explanation, err := w.llmService.ExplainWasmModule(ctx, wasm.FormatModuleSummary(module))
+		if err != nil {
+			result.Error = "failed to explain module: " + err.Error()
+		} else {
+			result.Explanation = explanation
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}